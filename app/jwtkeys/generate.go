@@ -0,0 +1,57 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// Generate membuat kunci privat baru; alg = RS256 atau EdDSA
+func Generate(kid, alg string) (*Key, error) {
+	switch alg {
+	case AlgRS256:
+		k, err := rsa.GenerateKey(rand.Reader, minRSABits)
+		if err != nil {
+			return nil, err
+		}
+		return &Key{ID: kid, Alg: AlgRS256, Private: k, Public: &k.PublicKey}, nil
+	case AlgEdDSA:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return &Key{ID: kid, Alg: AlgEdDSA, Private: priv, Public: pub}, nil
+	}
+	return nil, fmt.Errorf("unsupported algorithm %q", alg)
+}
+
+// PrivatePEM = kunci privat dalam PKCS#8 (format yang dibaca Load)
+func (k *Key) PrivatePEM() ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.Private)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// PublicPEM = kunci publik PKIX, untuk instance yang hanya memverifikasi
+func (k *Key) PublicPEM() ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(k.Public)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// Ephemeral = key ring dengan satu kunci Ed25519 di memori; token tidak
+// bertahan setelah restart, hanya untuk pengembangan lokal
+func Ephemeral(issuer, audience string) (*KeyRing, error) {
+	k, err := Generate("ephemeral", AlgEdDSA)
+	if err != nil {
+		return nil, err
+	}
+	return New(issuer, audience, "", k)
+}
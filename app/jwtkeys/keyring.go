@@ -0,0 +1,200 @@
+// Package jwtkeys mengelola kunci asimetris penanda tangan JWT (RS256 / EdDSA).
+//
+// Semua kunci di direktori JWT_KEYS_DIR dipakai untuk verifikasi; hanya satu
+// (JWT_SIGNING_KID) yang menandatangani token baru. Rotasi tanpa downtime:
+//  1. tambahkan file kunci baru ke semua instance (token lama & baru sama-sama valid)
+//  2. ganti JWT_SIGNING_KID ke kunci baru
+//  3. hapus kunci lama setelah token terakhirnya kedaluwarsa (umur refresh token)
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+
+	minRSABits = 2048
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// Key = satu kunci; Private nil = hanya untuk verifikasi (kunci yang sudah pensiun)
+type Key struct {
+	ID      string
+	Alg     string
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+func (k *Key) method() jwt.SigningMethod {
+	if k.Alg == AlgRS256 {
+		return jwt.SigningMethodRS256
+	}
+	return jwt.SigningMethodEdDSA
+}
+
+// KeyRing = kunci aktif + klaim iss yang dipakai saat menandatangani & memverifikasi
+type KeyRing struct {
+	Issuer   string
+	Audience string // aud access token (layanan kampus lain memverifikasi dengan nilai ini)
+
+	signing *Key
+	keys    map[string]*Key
+}
+
+// New membuat key ring; signingKID kosong = kid privat terbesar (mis. "2026-10")
+func New(issuer, audience, signingKID string, keys ...*Key) (*KeyRing, error) {
+	r := &KeyRing{Issuer: issuer, Audience: audience, keys: map[string]*Key{}}
+	for _, k := range keys {
+		if _, dup := r.keys[k.ID]; dup {
+			return nil, fmt.Errorf("duplicate key id %q", k.ID)
+		}
+		r.keys[k.ID] = k
+		if signingKID == "" && k.Private != nil && (r.signing == nil || k.ID > r.signing.ID) {
+			r.signing = k
+		}
+	}
+	if signingKID != "" {
+		r.signing = r.keys[signingKID]
+	}
+	if r.signing == nil || r.signing.Private == nil {
+		return nil, fmt.Errorf("no private key for signing key id %q", signingKID)
+	}
+	return r, nil
+}
+
+// Load membaca semua <kid>.pem di dir: PRIVATE KEY (PKCS#8), RSA PRIVATE KEY
+// (PKCS#1) atau PUBLIC KEY (hanya verifikasi)
+func Load(dir, issuer, audience, signingKID string) (*KeyRing, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	// <kid>.pem dan <kid>.pub.pem boleh berdampingan; kunci privat diutamakan
+	byID := map[string]*Key{}
+	for _, f := range files {
+		raw, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		kid := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(f), ".pem"), ".pub")
+		k, err := ParsePEM(kid, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		if prev, ok := byID[kid]; ok && prev.Private != nil {
+			continue
+		}
+		byID[kid] = k
+	}
+	if len(byID) == 0 {
+		return nil, fmt.Errorf("no *.pem keys in %s", dir)
+	}
+	keys := make([]*Key, 0, len(byID))
+	for _, k := range byID {
+		keys = append(keys, k)
+	}
+	return New(issuer, audience, signingKID, keys...)
+}
+
+func ParsePEM(kid string, raw []byte) (*Key, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("no PEM block")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		return rsaKey(kid, k, &k.PublicKey)
+	case *rsa.PublicKey:
+		return rsaKey(kid, nil, k)
+	case ed25519.PrivateKey:
+		return &Key{ID: kid, Alg: AlgEdDSA, Private: k, Public: k.Public()}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: kid, Alg: AlgEdDSA, Public: k}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T (RSA or Ed25519 only)", parsed)
+}
+
+func rsaKey(kid string, private *rsa.PrivateKey, public *rsa.PublicKey) (*Key, error) {
+	if public.N.BitLen() < minRSABits {
+		return nil, fmt.Errorf("RSA key must be at least %d bits", minRSABits)
+	}
+	k := &Key{ID: kid, Alg: AlgRS256, Public: public}
+	if private != nil {
+		k.Private = private
+	}
+	return k, nil
+}
+
+// SigningKID = kid yang menandatangani token baru
+func (r *KeyRing) SigningKID() string {
+	return r.signing.ID
+}
+
+// Sign menandatangani klaim dengan kunci aktif; iss & aud diisi di sini
+func (r *KeyRing) Sign(claims jwt.MapClaims, audience string) (string, error) {
+	claims["iss"] = r.Issuer
+	claims["aud"] = audience
+
+	token := jwt.NewWithClaims(r.signing.method(), claims)
+	token.Header["kid"] = r.signing.ID
+	return token.SignedString(r.signing.Private)
+}
+
+// Verify memeriksa tanda tangan (kid wajib, alg harus sama dengan alg kunci),
+// exp, iss dan aud
+func (r *KeyRing) Verify(raw, audience string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(raw, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		k, ok := r.keys[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		if t.Method.Alg() != k.Alg {
+			return nil, fmt.Errorf("unexpected signing method %s for key %s", t.Method.Alg(), kid)
+		}
+		return k.Public, nil
+	},
+		jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}),
+		jwt.WithIssuer(r.Issuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return token.Claims.(jwt.MapClaims), nil
+}
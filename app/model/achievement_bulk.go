@@ -0,0 +1,28 @@
+package model
+
+// BulkReviewRequest: ids + note bersama, atau items dengan note per item
+// (note item kosong → memakai note bersama)
+type BulkReviewRequest struct {
+	IDs   []string         `json:"ids"`
+	Items []BulkReviewItem `json:"items"`
+	Note  string           `json:"note"`
+}
+
+type BulkReviewItem struct {
+	ID   string `json:"id"`
+	Note string `json:"note,omitempty"`
+}
+
+type BulkItemResult struct {
+	ID     string `json:"id"`
+	Result string `json:"result"` // succeeded | failed
+	Reason string `json:"reason,omitempty"`
+	Points *int   `json:"points,omitempty"` // hanya bulk verify
+}
+
+type BulkReviewResponse struct {
+	Action    string           `json:"action"` // verified | rejected
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// AchievementHistory = satu event perubahan status prestasi (immutable, append-only)
+type AchievementHistory struct {
+	ID            string    `json:"id"`
+	AchievementID string    `json:"achievement_id"`
+	ActorUserID   string    `json:"actor_user_id"`
+	ActorRole     string    `json:"actor_role"`
+	FromStatus    string    `json:"from_status"`
+	ToStatus      string    `json:"to_status"`
+	Note          string    `json:"note,omitempty"`
+	ChangedFields []string  `json:"changed_fields,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// AchievementListQuery = parameter GET /achievements (pagination, sort, filter)
+type AchievementListQuery struct {
+	Page  int
+	Limit int
+	Sort  string // created_at | submitted_at | points
+	Order string // asc | desc
+
+	Status          []string
+	AchievementType string
+	Tags            []string
+	StudentID       string
+	ProgramStudy    string
+	From            *time.Time
+	To              *time.Time
+
+	// diisi service, bukan dari query string
+	ScopeStudentIDs []string // nil = tanpa batasan (Admin)
+	MongoIDs        []string // hasil filter Mongo, urut sesuai sort
+}
+
+func (q AchievementListQuery) Offset() int {
+	return (q.Page - 1) * q.Limit
+}
+
+// NeedsMongoFilter → filter konten yang hanya ada di MongoDB (jenis & tag)
+func (q AchievementListQuery) NeedsMongoFilter() bool {
+	return q.AchievementType != "" || len(q.Tags) > 0
+}
+
+type AchievementListItem struct {
+	ID          string           `json:"id"`
+	StudentID   string           `json:"student_id"`
+	Status      string           `json:"status"`
+	SubmittedAt *time.Time       `json:"submitted_at"`
+	CreatedAt   time.Time        `json:"created_at"`
+	Detail      AchievementMongo `json:"detail"`
+}
+
+type PageMeta struct {
+	Page       int `json:"page"`
+	Limit      int `json:"limit"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+type AchievementListResponse struct {
+	Data []AchievementListItem `json:"data"`
+	Meta PageMeta              `json:"meta"`
+}
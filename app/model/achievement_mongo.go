@@ -19,13 +19,20 @@ type AchievementMongo struct {
 	Attachments []AttachmentFile `bson:"attachments"`
 
 	// Tag & poin
-	Tags   []string `bson:"tags"`
-	Points int      `bson:"points"`
+	Tags            []string         `bson:"tags"`
+	Points          int              `bson:"points"`
+	PointsBreakdown *PointsBreakdown `bson:"pointsBreakdown,omitempty"` // diisi saat verify
 
 	// Metadata
 	CreatedAt int64  `bson:"createdAt"`
 	UpdatedAt int64  `bson:"updatedAt"`
 	Status    string `bson:"status"` // draft, submitted, verified, rejected, deleted
+
+	// status_version PostgreSQL terakhir yang diterapkan (sync outbox)
+	StatusVersion int64 `bson:"statusVersion,omitempty" json:"-"`
+
+	// nilai string details yang diratakan, hanya untuk text index
+	DetailsText string `bson:"detailsText,omitempty" json:"-"`
 }
 
 type AttachmentFile struct {
@@ -7,14 +7,39 @@ type AchievementReference struct {
     StudentID          string      `json:"student_id"`
     MongoAchievementID string      `json:"mongo_achievement_id"`
     Status             string      `json:"status"`
+    StatusVersion      int64       `json:"-"` // naik setiap status berubah (urutan sync Mongo)
+    Points             int         `json:"-"` // total poin saat verified (sort GET /achievements)
 
     SubmittedAt  *time.Time `json:"submitted_at"`
     VerifiedAt   *time.Time `json:"verified_at"`
     VerifiedBy   *string    `json:"verified_by"`
-    RejectionNote *string   `json:"rejection_note"`
+    ReviewerID   *string    `json:"assigned_reviewer_id,omitempty"` // dosen pengganti saat SLA dilanggar
+    RejectionNotes []ReviewRound `json:"rejection_notes"` // satu entri per putaran review yang ditolak
 
     CreatedAt time.Time  `json:"created_at"`
     UpdatedAt time.Time  `json:"updated_at"`
 }
 
+// ReviewRound = catatan penolakan dosen wali untuk satu kali submit
+type ReviewRound struct {
+    Round      int       `json:"round"`
+    Note       string    `json:"note"`
+    RejectedBy *string   `json:"rejected_by"`
+    RejectedAt time.Time `json:"rejected_at"`
+}
+
+
 
+// StatusChange = satu transisi status; UPDATE hanya berlaku jika status di
+// database masih From (dua request yang berebut → hanya satu yang menang)
+type StatusChange struct {
+    AchievementID string
+    From          string
+    To            string
+    LecturerID    string // verified_by (verify) / rejected_by (reject)
+    Note          string // catatan penolakan
+    Points        *int   // verified: total poin, disimpan ke achievement_references.points
+
+    // History dicatat dalam transaksi yang sama dengan UPDATE status
+    History *AchievementHistory
+}
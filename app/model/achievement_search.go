@@ -0,0 +1,28 @@
+package model
+
+// SearchMatch = ID dokumen Mongo + skor relevansi $text
+type SearchMatch struct {
+	MongoID string
+	Score   float64
+}
+
+type SearchHighlight struct {
+	Field   string `json:"field"`
+	Snippet string `json:"snippet"` // kata yang cocok dibungkus <mark>…</mark>
+}
+
+type AchievementSearchHit struct {
+	ID         string            `json:"id"`
+	StudentID  string            `json:"student_id"`
+	Status     string            `json:"status"`
+	Score      float64           `json:"score"`
+	Title      string            `json:"title"`
+	Type       string            `json:"achievementType"`
+	Highlights []SearchHighlight `json:"highlights"`
+}
+
+type AchievementSearchResponse struct {
+	Query string                 `json:"query"`
+	Data  []AchievementSearchHit `json:"data"`
+	Meta  PageMeta               `json:"meta"`
+}
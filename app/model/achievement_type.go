@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+// Tipe field yang didukung di definisi details
+const (
+	FieldString  = "string"
+	FieldNumber  = "number"
+	FieldInteger = "integer"
+	FieldBoolean = "boolean"
+	FieldDate    = "date" // YYYY-MM-DD
+	FieldEnum    = "enum"
+	FieldArray   = "array"
+)
+
+// AchievementType = entri registry jenis prestasi (dikelola Admin)
+type AchievementType struct {
+	ID              string        `json:"id"`
+	Code            string        `json:"code"` // kanonik, lowercase (competition, publication, ...)
+	Name            string        `json:"name"`
+	Description     string        `json:"description"`
+	Aliases         []string      `json:"aliases"` // mis. "lomba" → competition
+	Fields          []DetailField `json:"fields"`
+	AllowAdditional bool          `json:"allow_additional"` // izinkan key details di luar Fields
+	IsActive        bool          `json:"is_active"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+}
+
+// DetailField = definisi satu key di details (mirip JSON Schema)
+type DetailField struct {
+	Name      string   `json:"name"`
+	Label     string   `json:"label,omitempty"`
+	Type      string   `json:"type"`
+	Required  bool     `json:"required,omitempty"`
+	Enum      []string `json:"enum,omitempty"`  // untuk type enum
+	Items     string   `json:"items,omitempty"` // tipe elemen untuk type array
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+	MinLength *int     `json:"min_length,omitempty"`
+	MaxLength *int     `json:"max_length,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+}
+
+type AchievementTypeRequest struct {
+	Code            string        `json:"code"`
+	Name            string        `json:"name"`
+	Description     string        `json:"description"`
+	Aliases         []string      `json:"aliases"`
+	Fields          []DetailField `json:"fields"`
+	AllowAdditional bool          `json:"allow_additional"`
+	IsActive        *bool         `json:"is_active"`
+}
+
+// FieldError = pesan validasi per field (mis. "details.competitionLevel")
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// TypeBackfillReport = hasil pemetaan achievementType lama di Mongo ke code kanonik
+type TypeBackfillReport struct {
+	DryRun  bool          `json:"dry_run"`
+	Checked int           `json:"checked"` // jumlah nilai achievementType berbeda
+	Updated int64         `json:"updated"` // dokumen yang diubah
+	Mapped  []TypeMapping `json:"mapped"`
+	Unknown []string      `json:"unknown"` // tidak ada di registry (perlu ditangani Admin)
+}
+
+type TypeMapping struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Documents int64  `json:"documents"`
+}
@@ -1,6 +1,75 @@
 package model
 
+import "time"
+
 type LoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 }
+
+// Alasan sesi / access token dicabut
+const (
+	RevokedLogout          = "logout"
+	RevokedLogoutAll       = "logout_all"
+	RevokedSession         = "revoked"     // dihapus user lewat DELETE /auth/sessions/:id
+	RevokedTokenReuse      = "token_reuse" // refresh token lama dipakai ulang
+	RevokedUserDeactivated = "user_deactivated"
+	RevokedUserDeleted     = "user_deleted"
+	RevokedRoleChanged     = "role_changed"
+)
+
+// AuthSession = satu perangkat login; refresh token dirotasi di dalam sesi
+// (satu "family"). Token lama yang dipakai lagi berarti token dicuri.
+type AuthSession struct {
+	ID            string     `json:"id"`
+	UserID        string     `json:"user_id"`
+	UserAgent     string     `json:"user_agent"`
+	IPAddress     string     `json:"ip_address"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastUsedAt    time.Time  `json:"last_used_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	RevokedReason string     `json:"revoked_reason,omitempty"`
+	Current       bool       `json:"current"`
+}
+
+// TokenDenylist = access token yang dicabut sebelum kedaluwarsa
+type TokenDenylist struct {
+	Tokens   map[string]time.Time // jti → exp token
+	Users    map[string]time.Time // user id → token yang terbit sebelum waktu ini dicabut
+	Sessions map[string]bool      // sid sesi yang dicabut
+}
+
+// Alasan percobaan login ditolak (audit login_attempts)
+const (
+	LoginFailedPassword = "invalid_password"
+	LoginUnknownUser    = "unknown_user"
+	LoginInactiveUser   = "inactive_user"
+	LoginThrottled      = "throttled" // ditolak sebelum password diperiksa (jeda / lockout)
+)
+
+// LoginAttempt = audit satu percobaan login yang gagal
+type LoginAttempt struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	UserID    *string   `json:"user_id,omitempty"` // kosong jika username tidak terdaftar
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	Reason    string    `json:"reason"`
+	Count     int       `json:"count"` // >1 = percobaan "throttled" yang digabung
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginThrottle = penghitung gagal login per akun ("user:<username>") atau per IP ("ip:<addr>")
+type LoginThrottle struct {
+	Key          string     `json:"key"`
+	Failures     int        `json:"failures"`
+	LastFailedAt time.Time  `json:"last_failed_at"`
+	BlockedUntil *time.Time `json:"blocked_until,omitempty"`
+}
+
+type LoginAttemptFilter struct {
+	Username string
+	IP       string
+	Limit    int
+}
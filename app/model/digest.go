@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// PendingItem = prestasi yang masih berstatus submitted
+type PendingItem struct {
+	AchievementID string    `json:"achievement_id"`
+	StudentID     string    `json:"student_id"`
+	StudentNIM    string    `json:"student_nim"`
+	StudentName   string    `json:"student_name"`
+	Title         string    `json:"title"`
+	SubmittedAt   time.Time `json:"submitted_at"`
+	AgeDays       int       `json:"age_days"`
+}
+
+// LecturerDigest = ringkasan harian prestasi bimbingan yang menunggu verifikasi
+type LecturerDigest struct {
+	LecturerID  string        `json:"lecturer_id"`
+	UserID      string        `json:"user_id"`
+	Name        string        `json:"name"`
+	Email       string        `json:"email"`
+	GeneratedAt time.Time     `json:"generated_at"`
+	Items       []PendingItem `json:"items"` // terlama dulu
+}
+
+type EscalationItem struct {
+	PendingItem
+	AdvisorID   string `json:"advisor_id,omitempty"` // kosong = mahasiswa belum punya dosen wali
+	AdvisorName string `json:"advisor_name,omitempty"`
+	ReviewerID  string `json:"assigned_reviewer_id,omitempty"` // dosen pengganti setelah SLA dialihkan
+}
+
+// EscalationReport = daftar untuk Admin: submitted lebih lama dari ThresholdDays
+type EscalationReport struct {
+	GeneratedAt   time.Time        `json:"generated_at"`
+	ThresholdDays int              `json:"threshold_days"`
+	Items         []EscalationItem `json:"items"`
+}
+
+type DigestRunSummary struct {
+	RunAt            time.Time `json:"run_at"`
+	Lecturers        int       `json:"lecturers"`
+	DigestsDelivered int       `json:"digests_delivered"`
+	Escalations      int       `json:"escalations"`
+	Errors           []string  `json:"errors"`
+}
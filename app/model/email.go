@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// Status antrean email
+const (
+	EmailPending = "pending"
+	EmailSent    = "sent"
+	EmailDead    = "dead" // jatah retry habis
+)
+
+// Bahasa template email
+const (
+	LangID = "id"
+	LangEN = "en"
+)
+
+// EmailMessage = email siap kirim (multipart text + HTML)
+type EmailMessage struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// EmailJob = satu email di email_outbox, dikirim oleh worker MailQueue
+type EmailJob struct {
+	ID              string     `json:"id"`
+	RecipientUserID string     `json:"recipient_user_id,omitempty"`
+	ToAddress       string     `json:"to_address"`
+	Template        string     `json:"template"`
+	Subject         string     `json:"subject"`
+	TextBody        string     `json:"-"`
+	HTMLBody        string     `json:"-"`
+	Status          string     `json:"status"`
+	Attempts        int        `json:"attempts"`
+	LastError       string     `json:"last_error,omitempty"`
+	NextAttemptAt   time.Time  `json:"next_attempt_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	SentAt          *time.Time `json:"sent_at,omitempty"`
+}
+
+// NotificationPreference = preferensi notifikasi per user (default: email aktif, bahasa Indonesia)
+type NotificationPreference struct {
+	UserID       string    `json:"user_id"`
+	EmailEnabled bool      `json:"email_enabled"`
+	Language     string    `json:"language"`    // id | en
+	MutedTypes   []string  `json:"muted_types"` // jenis notifikasi yang tidak dikirim lewat email
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type NotificationPreferenceRequest struct {
+	EmailEnabled *bool     `json:"email_enabled"`
+	Language     *string   `json:"language"`
+	MutedTypes   *[]string `json:"muted_types"`
+}
+
+func DefaultNotificationPreference(userID string) NotificationPreference {
+	return NotificationPreference{
+		UserID:       userID,
+		EmailEnabled: true,
+		Language:     LangID,
+		MutedTypes:   []string{},
+	}
+}
@@ -1,7 +1,60 @@
 package model
 
+import "time"
+
+// Jenis notifikasi
+const (
+	NotifAchievementSubmitted = "achievement_submitted" // ke dosen wali
+	NotifAchievementVerified  = "achievement_verified"  // ke mahasiswa
+	NotifAchievementRejected  = "achievement_rejected"  // ke mahasiswa
+	NotifAdvisorAssigned      = "advisor_assigned"      // ke mahasiswa
+	NotifAdviseeAssigned      = "advisee_assigned"      // ke dosen wali baru
+	NotifPendingDigest        = "pending_digest"        // ringkasan harian ke dosen wali
+	NotifPendingEscalation    = "pending_escalation"    // eskalasi ke Admin
+	NotifSLABreached          = "sla_breached"          // batas review terlewati, ke Admin
+	NotifReviewReassigned     = "review_reassigned"     // review dialihkan, ke dosen cadangan
+)
+
+// Notification = notifikasi in-app per penerima (users.id)
 type Notification struct {
-    Title   string `json:"title"`
-    Message string `json:"message"`
-    SentAt  int64  `json:"sent_at"`
+	ID            string     `json:"id"`
+	RecipientID   string     `json:"recipient_id"`
+	Type          string     `json:"type"`
+	Title         string     `json:"title"`
+	Message       string     `json:"message"`
+	AchievementID *string    `json:"achievement_id,omitempty"`
+	Link          string     `json:"link,omitempty"`
+	IsRead        bool       `json:"is_read"`
+	ReadAt        *time.Time `json:"read_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type NotificationListResponse struct {
+	Data   []Notification `json:"data"`
+	Meta   PageMeta       `json:"meta"`
+	Unread int            `json:"unread"`
+}
+
+// Jenis event real-time (SSE) selain notifikasi yang tersimpan
+const (
+	EventNotification = "notification"  // notifikasi baru (payload = Notification)
+	EventResync       = "resync"        // riwayat Last-Event-ID sudah tidak tersedia → client ambil ulang data
+	EventTokenExpired = "token_expired" // token habis / dicabut → client refresh token lalu sambung ulang
+)
+
+// StreamEvent = event yang dikirim ke stream milik satu user
+type StreamEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	Data      interface{} `json:"data"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// AchievementEvent = payload perubahan status prestasi
+type AchievementEvent struct {
+	AchievementID string `json:"achievement_id"`
+	StudentID     string `json:"student_id"`
+	Status        string `json:"status"`
+	Points        *int   `json:"points,omitempty"`
+	Note          string `json:"note,omitempty"`
 }
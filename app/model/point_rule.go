@@ -0,0 +1,75 @@
+package model
+
+import "time"
+
+// Jenis aturan poin
+const (
+	RuleBase       = "base"       // poin dasar: satu aturan dengan prioritas tertinggi
+	RuleBonus      = "bonus"      // poin tambahan: semua yang cocok dijumlahkan
+	RuleMultiplier = "multiplier" // faktor pengali: semua yang cocok dikalikan
+)
+
+// Operator kondisi
+const (
+	OpEq  = "eq"
+	OpIn  = "in"
+	OpGte = "gte"
+	OpLte = "lte"
+)
+
+// PointRule = satu aturan poin (dikelola Admin)
+type PointRule struct {
+	ID              string          `json:"id"`
+	Name            string          `json:"name"`
+	AchievementType string          `json:"achievement_type"` // kosong = semua jenis
+	Kind            string          `json:"kind"`
+	Conditions      []RuleCondition `json:"conditions"` // semua harus terpenuhi (AND)
+	Points          float64         `json:"points"`
+	Factor          float64         `json:"factor"`
+	Priority        int             `json:"priority"`
+	IsActive        bool            `json:"is_active"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+type PointRuleRequest struct {
+	Name            string          `json:"name"`
+	AchievementType string          `json:"achievement_type"`
+	Kind            string          `json:"kind"`
+	Conditions      []RuleCondition `json:"conditions"`
+	Points          float64         `json:"points"`
+	Factor          float64         `json:"factor"`
+	Priority        int             `json:"priority"`
+	IsActive        *bool           `json:"is_active"` // default true
+}
+
+// RuleCondition dicocokkan dengan details[Field]
+type RuleCondition struct {
+	Field string      `json:"field"`
+	Op    string      `json:"op"`
+	Value interface{} `json:"value"`
+}
+
+// PointsBreakdown disimpan bersama dokumen prestasi di MongoDB
+type PointsBreakdown struct {
+	Total        int                   `json:"total" bson:"total"`
+	Items        []PointsBreakdownItem `json:"items" bson:"items"`
+	CalculatedAt int64                 `json:"calculated_at" bson:"calculatedAt"`
+}
+
+type PointsBreakdownItem struct {
+	RuleID   string  `json:"rule_id" bson:"ruleId"`
+	RuleName string  `json:"rule_name" bson:"ruleName"`
+	Kind     string  `json:"kind" bson:"kind"`
+	Points   float64 `json:"points,omitempty" bson:"points,omitempty"`
+	Factor   float64 `json:"factor,omitempty" bson:"factor,omitempty"`
+}
+
+// PointsRecalculateSummary = hasil hitung ulang seluruh prestasi verified
+type PointsRecalculateSummary struct {
+	Checked   int      `json:"checked"`
+	Updated   int      `json:"updated"`
+	Unchanged int      `json:"unchanged"`
+	Failed    int      `json:"failed"`
+	Errors    []string `json:"errors"`
+}
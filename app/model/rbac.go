@@ -0,0 +1,26 @@
+package model
+
+// Permission = hak akses berbentuk "resource:action", mis. "achievement:verify"
+type Permission struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Resource    string `json:"resource"`
+	Action      string `json:"action"`
+	Description string `json:"description"`
+	System      bool   `json:"system"` // dipakai route, tidak dapat dihapus / diganti nama
+}
+
+type RoleRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type PermissionRequest struct {
+	Name        string `json:"name"` // resource:action
+	Description string `json:"description"`
+}
+
+// RolePermissionsRequest mengganti seluruh permission milik role
+type RolePermissionsRequest struct {
+	Permissions []string `json:"permissions"`
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type TopStudent struct {
     StudentID string `json:"student_id"`
     Count     int    `json:"count"`
@@ -19,3 +21,48 @@ type TopStudentDetail struct {
     AcademicYear  string `json:"academic_year"`
     Count         int    `json:"count"`
 }
+
+// ReportFilter = filter laporan statistik (query parameter).
+// Nilai kosong = tidak difilter; Statuses nil = semua status.
+type ReportFilter struct {
+    From            *time.Time `json:"from,omitempty"`
+    To              *time.Time `json:"to,omitempty"` // eksklusif (tanggal "to" + 1 hari)
+    Statuses        []string   `json:"status,omitempty"`
+    ProgramStudy    string     `json:"program_study,omitempty"`
+    AcademicYear    string     `json:"academic_year,omitempty"`
+    AchievementType string     `json:"achievement_type,omitempty"`
+    Period          string     `json:"period"` // year | semester | month
+}
+
+// StatsFact = kontribusi satu prestasi ke statistik materialized
+type StatsFact struct {
+    AchievementID   string
+    StudentID       string
+    Status          string
+    AchievementType string
+    Tags            []string
+    Month           time.Time // awal bulan createdAt
+}
+
+// StatsCount = satu baris counter: mahasiswa × status × (jenis | tag) × bulan
+type StatsCount struct {
+    StudentID string
+    Key       string // jenis prestasi atau tag
+    Month     time.Time
+    Total     int
+}
+
+// StatsQuery = filter yang bisa dilayani tabel materialized (granularitas bulan)
+type StatsQuery struct {
+    StudentIDs      []string
+    Statuses        []string   // nil = semua status
+    AchievementType string
+    From            *time.Time // awal bulan
+    To              *time.Time // awal bulan, eksklusif
+}
+
+type StatsRebuildResult struct {
+    Achievements int       `json:"achievements"`
+    RebuiltAt    time.Time `json:"rebuilt_at"`
+    DurationMs   int64     `json:"duration_ms"`
+}
@@ -6,5 +6,8 @@ type Role struct {
 	ID          string    `json:"id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	Users       int       `json:"users"`
+	System      bool      `json:"system"` // Admin / Dosen Wali / Mahasiswa
 	CreatedAt   time.Time `json:"created_at"`
 }
@@ -0,0 +1,102 @@
+package model
+
+import "time"
+
+// Tindakan otomatis saat SLA review dilanggar
+const (
+	SLAActionEscalate = "escalate" // notifikasi ke Admin
+	SLAActionReassign = "reassign" // pindahkan review ke dosen cadangan (fallback: escalate)
+	SLAActionNone     = "none"     // hanya tercatat di daftar pelanggaran
+)
+
+// SLASettings = batas waktu review prestasi submitted (diubah oleh Admin)
+type SLASettings struct {
+	ReviewDays int       `json:"review_days"`
+	Action     string    `json:"action"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type SLASettingsRequest struct {
+	ReviewDays *int    `json:"review_days"`
+	Action     *string `json:"action"`
+}
+
+// SLABreachItem = prestasi submitted yang melewati batas review
+type SLABreachItem struct {
+	AchievementID string     `json:"achievement_id"`
+	StudentID     string     `json:"student_id"`
+	StudentNIM    string     `json:"student_nim"`
+	StudentName   string     `json:"student_name"`
+	LecturerID    string     `json:"lecturer_id,omitempty"` // dosen wali; kosong = belum punya
+	LecturerName  string     `json:"lecturer_name,omitempty"`
+	ReviewerID    *string    `json:"assigned_reviewer_id,omitempty"`
+	SubmittedAt   time.Time  `json:"submitted_at"`
+	AgeDays       int        `json:"age_days"`
+	OverdueDays   int        `json:"overdue_days"`
+	Action        string     `json:"action,omitempty"` // tindakan yang sudah diambil
+	BreachedAt    *time.Time `json:"breached_at,omitempty"`
+}
+
+type LecturerSLABreaches struct {
+	LecturerID   string          `json:"lecturer_id"`
+	LecturerName string          `json:"lecturer_name"`
+	Items        []SLABreachItem `json:"items"`
+}
+
+type SLABreachReport struct {
+	GeneratedAt time.Time             `json:"generated_at"`
+	ReviewDays  int                   `json:"review_days"`
+	Total       int                   `json:"total"`
+	Lecturers   []LecturerSLABreaches `json:"lecturers"`
+}
+
+// SLABreach = catatan satu pelanggaran (sekali per putaran submit)
+type SLABreach struct {
+	AchievementID string
+	SubmittedAt   time.Time
+	LecturerID    string
+	Action        string
+	ReassignedTo  string
+}
+
+type SLACheckSummary struct {
+	CheckedAt  time.Time `json:"checked_at"`
+	Overdue    int       `json:"overdue"`
+	NewBreach  int       `json:"new_breaches"`
+	Escalated  int       `json:"escalated"`
+	Reassigned int       `json:"reassigned"`
+}
+
+type BackupLecturerRequest struct {
+	BackupLecturerID *string `json:"backup_lecturer_id"`
+}
+
+// ReviewTurnaround = satu kali review (submit → verified / rejected)
+type ReviewTurnaround struct {
+	LecturerID   string    `json:"lecturer_id"`
+	LecturerName string    `json:"lecturer_name"`
+	ToStatus     string    `json:"to_status"`
+	SubmittedAt  time.Time `json:"submitted_at"`
+	ReviewedAt   time.Time `json:"reviewed_at"`
+}
+
+// TurnaroundStat = statistik waktu review (jam)
+type TurnaroundStat struct {
+	LecturerID    string  `json:"lecturer_id,omitempty"`
+	LecturerName  string  `json:"lecturer_name,omitempty"`
+	Reviews       int     `json:"reviews"`
+	Verified      int     `json:"verified"`
+	Rejected      int     `json:"rejected"`
+	MedianHours   float64 `json:"median_hours"`
+	P90Hours      float64 `json:"p90_hours"`
+	WithinSLA     int     `json:"within_sla"`
+	WithinSLARate float64 `json:"within_sla_rate"` // 0..1
+}
+
+type TurnaroundReport struct {
+	From       time.Time        `json:"from"`
+	To         time.Time        `json:"to"`
+	ReviewDays int              `json:"review_days"`
+	Overall    TurnaroundStat   `json:"overall"`
+	Lecturers  []TurnaroundStat `json:"lecturers"`
+}
@@ -0,0 +1,73 @@
+package model
+
+import "time"
+
+// SyncOutboxEntry = perubahan MongoDB yang menunggu diterapkan.
+// set_status ditulis dalam transaksi yang sama dengan UPDATE status PostgreSQL.
+type SyncOutboxEntry struct {
+	ID          string     `json:"id"`
+	Operation   string     `json:"operation"` // set_status | delete
+	MongoID     string     `json:"mongo_id"`
+	Status      string     `json:"status,omitempty"`
+	Version     int64      `json:"version,omitempty"` // status_version PostgreSQL saat entry dibuat
+	Attempts    int        `json:"attempts"`
+	LastError   string     `json:"last_error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+	DeadAt      *time.Time `json:"dead_at,omitempty"` // jatah percobaan habis, tidak diulang lagi
+}
+
+// StatusDrift = status PostgreSQL dan MongoDB tidak sama
+type StatusDrift struct {
+	AchievementID string `json:"achievement_id"`
+	MongoID       string `json:"mongo_id"`
+	PGStatus      string `json:"pg_status"`
+	MongoStatus   string `json:"mongo_status"`
+}
+
+// DriftReport = hasil satu kali reconciliation job
+type DriftReport struct {
+	Checked int           `json:"checked"`
+	Drifts  []StatusDrift `json:"drifts"`
+}
+
+// =====================================================
+//
+//	RECONCILE REPORT (PostgreSQL ↔ MongoDB)
+//
+// =====================================================
+const (
+	ReconcileOrphanedDocument = "orphaned_document"  // dokumen Mongo tanpa achievement_references
+	ReconcileDanglingRef      = "dangling_reference" // mongo_achievement_id tidak ditemukan
+	ReconcileStatusMismatch   = "status_mismatch"
+)
+
+type ReconcileItem struct {
+	Kind          string `json:"kind"`
+	AchievementID string `json:"achievement_id,omitempty"`
+	MongoID       string `json:"mongo_id"`
+	StudentID     string `json:"student_id,omitempty"`
+	PGStatus      string `json:"pg_status,omitempty"`
+	MongoStatus   string `json:"mongo_status,omitempty"`
+	Action        string `json:"action,omitempty"`
+	Fixed         bool   `json:"fixed"`
+	Error         string `json:"error,omitempty"`
+}
+
+type ReconcileSummary struct {
+	PGReferences       int `json:"pg_references"`
+	MongoDocuments     int `json:"mongo_documents"`
+	OrphanedDocuments  int `json:"orphaned_documents"`
+	DanglingReferences int `json:"dangling_references"`
+	StatusMismatches   int `json:"status_mismatches"`
+	Fixed              int `json:"fixed"`
+	Unfixable          int `json:"unfixable"`
+}
+
+type ReconcileReport struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Fix         bool             `json:"fix"`
+	Source      string           `json:"source,omitempty"` // pg | mongo
+	Summary     ReconcileSummary `json:"summary"`
+	Items       []ReconcileItem  `json:"items"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// TranscriptEntry = satu prestasi terverifikasi di transkrip (SKPI)
+type TranscriptEntry struct {
+	AchievementID      string    `json:"achievement_id"`
+	MongoAchievementID string    `json:"-"`
+	Title              string    `json:"title"`
+	AchievementType    string    `json:"achievement_type"`
+	Points             int       `json:"points"`
+	VerifiedAt         time.Time `json:"verified_at"`
+	VerifierName       string    `json:"verifier_name"`
+}
+
+// Transcript = dokumen yang pernah diterbitkan; disimpan sebagai snapshot
+// sehingga /verify/:hash menampilkan isi persis seperti saat dicetak.
+type Transcript struct {
+	Hash         string            `json:"hash"`
+	KeyID        string            `json:"key_id,omitempty"` // kunci HMAC yang dipakai; kosong = dokumen sebelum ada key id
+	StudentID    string            `json:"student_id"`
+	StudentNIM   string            `json:"student_nim"`
+	StudentName  string            `json:"student_name"`
+	ProgramStudy string            `json:"program_study"`
+	AcademicYear string            `json:"academic_year"`
+	AdvisorName  string            `json:"advisor_name,omitempty"`
+	IssuedBy     string            `json:"issued_by"`
+	IssuedAt     time.Time         `json:"issued_at"`
+	TotalPoints  int               `json:"total_points"`
+	Entries      []TranscriptEntry `json:"entries"`
+}
+
+// TranscriptVerification = respons publik GET /verify/:hash
+type TranscriptVerification struct {
+	Valid            bool              `json:"valid"`
+	Hash             string            `json:"hash"`
+	StudentNIM       string            `json:"student_nim"`
+	StudentName      string            `json:"student_name"`
+	ProgramStudy     string            `json:"program_study"`
+	IssuedAt         time.Time         `json:"issued_at"`
+	AchievementCount int               `json:"achievement_count"`
+	TotalPoints      int               `json:"total_points"`
+	Entries          []TranscriptEntry `json:"entries"`
+	// false jika ada prestasi di dokumen yang sekarang sudah tidak berstatus verified
+	AllStillVerified bool `json:"all_still_verified"`
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backenduas/app/model"
+
+	"github.com/google/uuid"
+)
+
+type MockAchievementHistoryRepository struct {
+	Data     []model.AchievementHistory // append-only, urut sesuai insert
+	FailWith error                      // jika diisi, Create gagal
+}
+
+func NewMockAchievementHistoryRepository() *MockAchievementHistoryRepository {
+	return &MockAchievementHistoryRepository{
+		Data: []model.AchievementHistory{},
+	}
+}
+
+func (m *MockAchievementHistoryRepository) Create(ctx context.Context, h model.AchievementHistory) error {
+	if m.FailWith != nil {
+		return m.FailWith
+	}
+	if h.ID == "" {
+		h.ID = uuid.New().String()
+	}
+	if h.CreatedAt.IsZero() {
+		h.CreatedAt = time.Now()
+	}
+	m.Data = append(m.Data, h)
+	return nil
+}
+
+func (m *MockAchievementHistoryRepository) GetByAchievementID(ctx context.Context, achievementID string) ([]model.AchievementHistory, error) {
+	out := []model.AchievementHistory{}
+	for _, h := range m.Data {
+		if h.AchievementID == achievementID {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
@@ -3,6 +3,9 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"backenduas/app/model"
@@ -12,7 +15,8 @@ import (
 )
 
 type MockAchievementMongoRepository struct {
-	Data map[string]model.AchievementMongo // key = ObjectID.Hex()
+	Data     map[string]model.AchievementMongo // key = ObjectID.Hex()
+	FailWith error                             // jika diisi, operasi tulis selalu gagal
 }
 
 func NewMockAchievementMongoRepository() *MockAchievementMongoRepository {
@@ -48,6 +52,10 @@ func (m *MockAchievementMongoRepository) Update(
 	update bson.M,
 ) error {
 
+	if m.FailWith != nil {
+		return m.FailWith
+	}
+
 	ach, ok := m.Data[id.Hex()]
 	if !ok {
 		return errors.New("mongo data not found")
@@ -57,7 +65,89 @@ func (m *MockAchievementMongoRepository) Update(
 	if v, ok := update["status"]; ok {
 		ach.Status = v.(string)
 	}
+	if v, ok := update["title"].(string); ok {
+		ach.Title = v
+	}
+	if v, ok := update["description"].(string); ok {
+		ach.Description = v
+	}
+	if v, ok := update["achievementType"].(string); ok {
+		ach.AchievementType = v
+	}
+	if v, ok := update["details"].(map[string]interface{}); ok {
+		ach.Details = v
+	}
+	if v, ok := update["tags"].([]string); ok {
+		ach.Tags = v
+	}
+	if v, ok := update["points"].(int); ok {
+		ach.Points = v
+	}
+	if v, ok := update["pointsBreakdown"].(model.PointsBreakdown); ok {
+		ach.PointsBreakdown = &v
+	}
+
+	ach.UpdatedAt = time.Now().Unix()
+	m.Data[id.Hex()] = ach
+	return nil
+}
+
+// ===============================
+// TYPE BACKFILL
+// ===============================
+func (m *MockAchievementMongoRepository) DistinctTypes(ctx context.Context) ([]string, error) {
+	seen := map[string]bool{}
+	types := []string{}
+	for _, ach := range m.Data {
+		if !seen[ach.AchievementType] {
+			seen[ach.AchievementType] = true
+			types = append(types, ach.AchievementType)
+		}
+	}
+	sort.Strings(types)
+	return types, nil
+}
+
+func (m *MockAchievementMongoRepository) RenameType(ctx context.Context, from, to string, dryRun bool) (int64, error) {
+	if m.FailWith != nil {
+		return 0, m.FailWith
+	}
+
+	var n int64
+	for hex, ach := range m.Data {
+		if ach.AchievementType != from {
+			continue
+		}
+		n++
+		if !dryRun {
+			ach.AchievementType = to
+			m.Data[hex] = ach
+		}
+	}
+	return n, nil
+}
+
+// ===============================
+// SET STATUS (sync outbox)
+// ===============================
+func (m *MockAchievementMongoRepository) SetStatus(
+	ctx context.Context,
+	id primitive.ObjectID,
+	status string,
+	version int64,
+) error {
+
+	if m.FailWith != nil {
+		return m.FailWith
+	}
+
+	ach, ok := m.Data[id.Hex()]
+	if !ok || ach.StatusVersion >= version {
+		return nil // dokumen hilang ditangani reconcile; versi lama diabaikan
+	}
 
+	ach.Status = status
+	ach.StatusVersion = version
 	ach.UpdatedAt = time.Now().Unix()
 	m.Data[id.Hex()] = ach
 	return nil
@@ -82,6 +172,22 @@ func (m *MockAchievementMongoRepository) SoftDelete(
 	return nil
 }
 
+// ===============================
+// HARD DELETE
+// ===============================
+func (m *MockAchievementMongoRepository) Delete(
+	ctx context.Context,
+	id primitive.ObjectID,
+) error {
+
+	if m.FailWith != nil {
+		return m.FailWith
+	}
+
+	delete(m.Data, id.Hex())
+	return nil
+}
+
 // ===============================
 // FIND BY ID
 // ===============================
@@ -115,6 +221,124 @@ func (m *MockAchievementMongoRepository) FindManyByIDs(
 	return result, nil
 }
 
+// ===============================
+// FIND STATS BY IDS
+// ===============================
+func (m *MockAchievementMongoRepository) FindStatsByIDs(
+	ctx context.Context,
+	ids []primitive.ObjectID,
+) (map[string]model.AchievementMongo, error) {
+
+	return m.FindManyByIDs(ctx, ids)
+}
+
+// ===============================
+// FIND ALL
+// ===============================
+func (m *MockAchievementMongoRepository) FindAll(ctx context.Context) ([]model.AchievementMongo, error) {
+	result := []model.AchievementMongo{}
+	for hex, ach := range m.Data {
+		ach.ID, _ = primitive.ObjectIDFromHex(hex)
+		result = append(result, ach)
+	}
+	return result, nil
+}
+
+// ===============================
+// FIND IDS (filter jenis & tag)
+// ===============================
+func (m *MockAchievementMongoRepository) FindIDs(
+	ctx context.Context,
+	q model.AchievementListQuery,
+) ([]string, error) {
+
+	ids := []string{}
+
+	for hex, ach := range m.Data {
+		if q.AchievementType != "" && ach.AchievementType != q.AchievementType {
+			continue
+		}
+		if !hasAllTags(ach.Tags, q.Tags) {
+			continue
+		}
+		if q.ScopeStudentIDs != nil && !containsString(q.ScopeStudentIDs, ach.StudentID) {
+			continue
+		}
+		if q.StudentID != "" && ach.StudentID != q.StudentID {
+			continue
+		}
+		ids = append(ids, hex)
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+	return ids, nil
+}
+
+func hasAllTags(have, want []string) bool {
+	set := map[string]bool{}
+	for _, t := range have {
+		set[t] = true
+	}
+	for _, t := range want {
+		if !set[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// ===============================
+// SEARCH (pengganti $text: hitung kemunculan kata, bobot sama dengan index)
+// ===============================
+func (m *MockAchievementMongoRepository) SearchIDs(
+	ctx context.Context,
+	text string,
+	studentIDs []string,
+	limit int,
+) ([]model.SearchMatch, error) {
+
+	terms := strings.Fields(strings.ToLower(text))
+	scope := map[string]bool{}
+	for _, id := range studentIDs {
+		scope[id] = true
+	}
+
+	results := []model.SearchMatch{}
+	for hex, ach := range m.Data {
+		if ach.Status == "deleted" {
+			continue
+		}
+		if studentIDs != nil && !scope[ach.StudentID] {
+			continue
+		}
+
+		score := 0.0
+		for _, t := range terms {
+			score += 10 * float64(strings.Count(strings.ToLower(ach.Title), t))
+			score += 5 * float64(strings.Count(strings.ToLower(strings.Join(ach.Tags, " ")), t))
+			score += 3 * float64(strings.Count(strings.ToLower(ach.Description), t))
+			score += float64(strings.Count(strings.ToLower(fmt.Sprint(ach.Details)), t))
+		}
+		if score == 0 {
+			continue
+		}
+
+		results = append(results, model.SearchMatch{MongoID: hex, Score: score})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].MongoID < results[j].MongoID
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
 // ===============================
 // ADD ATTACHMENT
 // ===============================
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"time"
 
 	"backenduas/app/model"
@@ -10,7 +12,12 @@ import (
 )
 
 type MockAchievementPGRepository struct {
-	Data map[string]model.AchievementReference
+	Data     map[string]model.AchievementReference
+	FailWith error // jika diisi, CreateReference / ChangeStatus gagal (simulasi rollback)
+
+	// History / Outbox menerima riwayat & entry sync dari ChangeStatus (opsional)
+	History *MockAchievementHistoryRepository
+	Outbox  *MockSyncOutboxRepository
 }
 
 func NewMockAchievementPGRepository() *MockAchievementPGRepository {
@@ -51,48 +58,93 @@ func (m *MockAchievementPGRepository) SeedSubmitted(id string) string {
 // ===================================
 // IMPLEMENT INTERFACE
 // ===================================
-func (m *MockAchievementPGRepository) CreateReference(ref model.AchievementReference) error {
+func (m *MockAchievementPGRepository) CreateReference(ref model.AchievementReference, history *model.AchievementHistory) error {
+	if m.FailWith != nil {
+		return m.FailWith
+	}
 	ref.CreatedAt = time.Now()
 	ref.UpdatedAt = time.Now()
 	m.Data[ref.ID] = ref
+
+	if history != nil && m.History != nil {
+		m.History.Create(context.Background(), *history)
+	}
 	return nil
 }
 
-func (m *MockAchievementPGRepository) UpdateStatus(id string, status string) error {
-	ref, ok := m.Data[id]
-	if !ok {
-		return errors.New("achievement not found")
+// ChangeStatus: semua atau tidak sama sekali, seperti transaksi PG
+func (m *MockAchievementPGRepository) ChangeStatus(ctx context.Context, changes ...model.StatusChange) error {
+	if m.FailWith != nil {
+		return m.FailWith
+	}
+	for _, ch := range changes {
+		if ref, ok := m.Data[ch.AchievementID]; !ok || ref.Status != ch.From {
+			return fmt.Errorf("%w: %s", ErrStatusChanged, ch.AchievementID)
+		}
+	}
+	for _, ch := range changes {
+		m.applyStatusChange(ctx, ch)
 	}
-	ref.Status = status
-	ref.UpdatedAt = time.Now()
-	m.Data[id] = ref
 	return nil
 }
 
-func (m *MockAchievementPGRepository) Verify(id, verifier string) error {
-	ref, ok := m.Data[id]
-	if !ok {
-		return errors.New("achievement not found")
+// ChangeStatusEach: perubahan yang kalah balapan dilewati (seperti SAVEPOINT)
+func (m *MockAchievementPGRepository) ChangeStatusEach(ctx context.Context, changes ...model.StatusChange) ([]error, error) {
+	if m.FailWith != nil {
+		return nil, m.FailWith
 	}
+	results := make([]error, len(changes))
+	for i, ch := range changes {
+		if ref, ok := m.Data[ch.AchievementID]; !ok || ref.Status != ch.From {
+			results[i] = fmt.Errorf("%w: %s", ErrStatusChanged, ch.AchievementID)
+			continue
+		}
+		m.applyStatusChange(ctx, ch)
+	}
+	return results, nil
+}
+
+func (m *MockAchievementPGRepository) applyStatusChange(ctx context.Context, ch model.StatusChange) {
 	now := time.Now()
-	ref.Status = "verified"
-	ref.VerifiedBy = &verifier
-	ref.VerifiedAt = &now
+	ref := m.Data[ch.AchievementID]
+	ref.Status = ch.To
+	ref.StatusVersion++
 	ref.UpdatedAt = now
-	m.Data[id] = ref
-	return nil
-}
+	switch ch.To {
+	case "submitted":
+		ref.SubmittedAt = &now
+	case "verified":
+		verifier := ch.LecturerID
+		ref.VerifiedBy = &verifier
+		ref.VerifiedAt = &now
+		if ch.Points != nil {
+			ref.Points = *ch.Points
+		}
+	case "rejected":
+		rejectedBy := ch.LecturerID
+		ref.RejectionNotes = append(ref.RejectionNotes, model.ReviewRound{
+			Round:      len(ref.RejectionNotes) + 1,
+			Note:       ch.Note,
+			RejectedBy: &rejectedBy,
+			RejectedAt: now,
+		})
+	case "draft":
+		ref.SubmittedAt = nil
+		ref.ReviewerID = nil
+	}
+	m.Data[ch.AchievementID] = ref
 
-func (m *MockAchievementPGRepository) Reject(id, note string) error {
-	ref, ok := m.Data[id]
-	if !ok {
-		return errors.New("achievement not found")
+	if ch.History != nil && m.History != nil {
+		m.History.Create(ctx, *ch.History)
+	}
+	if m.Outbox != nil {
+		m.Outbox.Enqueue(ctx, model.SyncOutboxEntry{
+			Operation: "set_status",
+			MongoID:   ref.MongoAchievementID,
+			Status:    ch.To,
+			Version:   ref.StatusVersion,
+		})
 	}
-	ref.Status = "rejected"
-	ref.RejectionNote = &note
-	ref.UpdatedAt = time.Now()
-	m.Data[id] = ref
-	return nil
 }
 
 func (m *MockAchievementPGRepository) GetByID(ctx context.Context, id string) (model.AchievementReference, error) {
@@ -148,3 +200,103 @@ func (m *MockAchievementPGRepository) SeedWithMongo(studentID string) (string, p
 	m.Data[ref.ID] = ref
 	return ref.ID, oid
 }
+
+func (m *MockAchievementPGRepository) SetPoints(ctx context.Context, mongoID string, points int) error {
+	for id, ref := range m.Data {
+		if ref.MongoAchievementID == mongoID {
+			ref.Points = points
+			m.Data[id] = ref
+		}
+	}
+	return nil
+}
+
+func (m *MockAchievementPGRepository) GetByMongoIDs(ctx context.Context, mongoIDs []string) ([]model.AchievementReference, error) {
+	set := map[string]bool{}
+	for _, id := range mongoIDs {
+		set[id] = true
+	}
+	out := []model.AchievementReference{}
+	for _, ref := range m.Data {
+		if set[ref.MongoAchievementID] {
+			out = append(out, ref)
+		}
+	}
+	return out, nil
+}
+
+// LIST: filter sederhana di memori (program_study tidak didukung mock)
+func (m *MockAchievementPGRepository) List(ctx context.Context, q model.AchievementListQuery) ([]model.AchievementReference, int, error) {
+	inSet := func(list []string) map[string]bool {
+		set := map[string]bool{}
+		for _, v := range list {
+			set[v] = true
+		}
+		return set
+	}
+	scope, statuses, mongoIDs := inSet(q.ScopeStudentIDs), inSet(q.Status), inSet(q.MongoIDs)
+
+	filtered := []model.AchievementReference{}
+	for _, ref := range m.Data {
+		if q.ScopeStudentIDs != nil && !scope[ref.StudentID] {
+			continue
+		}
+		if q.StudentID != "" && ref.StudentID != q.StudentID {
+			continue
+		}
+		if len(q.Status) > 0 && !statuses[ref.Status] {
+			continue
+		}
+		if len(q.Status) == 0 && ref.Status == "deleted" {
+			continue
+		}
+		if q.From != nil && ref.CreatedAt.Before(*q.From) {
+			continue
+		}
+		if q.To != nil && !ref.CreatedAt.Before(*q.To) {
+			continue
+		}
+		if q.MongoIDs != nil && !mongoIDs[ref.MongoAchievementID] {
+			continue
+		}
+		filtered = append(filtered, ref)
+	}
+
+	sort.Slice(filtered, func(i, j int) bool {
+		a, b := filtered[i], filtered[j]
+		if q.Sort == "points" && a.Points != b.Points {
+			if q.Order == "asc" {
+				return a.Points < b.Points
+			}
+			return a.Points > b.Points
+		}
+		if q.Sort == "points" {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		if q.Sort == "submitted_at" && a.SubmittedAt != nil && b.SubmittedAt != nil {
+			if q.Order == "asc" {
+				return a.SubmittedAt.Before(*b.SubmittedAt)
+			}
+			return a.SubmittedAt.After(*b.SubmittedAt)
+		}
+		if q.Sort == "submitted_at" && (a.SubmittedAt == nil) != (b.SubmittedAt == nil) {
+			return a.SubmittedAt != nil // NULLS LAST
+		}
+		if q.Order == "asc" {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+
+	total := len(filtered)
+	start := q.Offset()
+	if start > total {
+		start = total
+	}
+	end := start + q.Limit
+	if end > total {
+		end = total
+	}
+
+	return filtered[start:end], total, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"backenduas/app/model"
+
+	"github.com/google/uuid"
+)
+
+type MockAchievementTypeRepository struct {
+	Data map[string]model.AchievementType // key = code
+}
+
+func NewMockAchievementTypeRepository() *MockAchievementTypeRepository {
+	return &MockAchievementTypeRepository{
+		Data: make(map[string]model.AchievementType),
+	}
+}
+
+func (m *MockAchievementTypeRepository) GetAll(ctx context.Context, includeInactive bool) ([]model.AchievementType, error) {
+	list := []model.AchievementType{}
+	for _, t := range m.Data {
+		if t.IsActive || includeInactive {
+			list = append(list, t)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list, nil
+}
+
+func (m *MockAchievementTypeRepository) GetByCode(ctx context.Context, codeOrAlias string) (*model.AchievementType, error) {
+	key := strings.ToLower(codeOrAlias)
+	for _, t := range m.Data {
+		if t.Code == key {
+			return &t, nil
+		}
+		for _, a := range t.Aliases {
+			if a == key {
+				return &t, nil
+			}
+		}
+	}
+	return nil, ErrAchievementTypeNotFound
+}
+
+func (m *MockAchievementTypeRepository) Create(ctx context.Context, t *model.AchievementType) error {
+	t.ID = uuid.New().String()
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = t.CreatedAt
+	m.Data[t.Code] = *t
+	return nil
+}
+
+func (m *MockAchievementTypeRepository) Update(ctx context.Context, t *model.AchievementType) error {
+	old, ok := m.Data[t.Code]
+	if !ok {
+		return ErrAchievementTypeNotFound
+	}
+	t.ID = old.ID
+	t.CreatedAt = old.CreatedAt
+	t.UpdatedAt = time.Now()
+	m.Data[t.Code] = *t
+	return nil
+}
+
+func (m *MockAchievementTypeRepository) Deactivate(ctx context.Context, code string) error {
+	t, ok := m.Data[code]
+	if !ok {
+		return ErrAchievementTypeNotFound
+	}
+	t.IsActive = false
+	m.Data[code] = t
+	return nil
+}
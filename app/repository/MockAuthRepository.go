@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"sort"
 	"time"
 
 	"backenduas/app/model"
@@ -10,12 +11,16 @@ import (
 )
 
 type MockAuthRepository struct {
-	Users map[string]model.User
+	Users    map[string]model.User
+	Sessions map[string]model.AuthSession // key = session id
+	Tokens   map[string]MockRefreshToken  // key = hash refresh token
 }
 
 func NewMockAuthRepository() *MockAuthRepository {
 	return &MockAuthRepository{
-		Users: make(map[string]model.User),
+		Users:    make(map[string]model.User),
+		Sessions: make(map[string]model.AuthSession),
+		Tokens:   make(map[string]MockRefreshToken),
 	}
 }
 
@@ -52,14 +57,83 @@ func (m *MockAuthRepository) GetPermissionsByRole(ctx context.Context, roleID st
 	return []string{"read", "write"}, nil
 }
 
-func (m *MockAuthRepository) SaveRefreshToken(ctx context.Context, userID, token string, exp time.Time) error {
+// MockRefreshToken = baris refresh_tokens (key = hash token)
+type MockRefreshToken struct {
+	SessionID string
+	ExpiresAt time.Time
+	Used      bool
+}
+
+func (m *MockAuthRepository) CreateSession(ctx context.Context, s *model.AuthSession, tokenHash string) error {
+	s.CreatedAt = time.Now()
+	s.LastUsedAt = s.CreatedAt
+	m.Sessions[s.ID] = *s
+	m.Tokens[tokenHash] = MockRefreshToken{SessionID: s.ID, ExpiresAt: s.ExpiresAt}
+	return nil
+}
+
+func (m *MockAuthRepository) RotateRefreshToken(ctx context.Context, sessionID, oldHash, newHash string, exp time.Time) (*model.AuthSession, error) {
+	rt, ok := m.Tokens[oldHash]
+	if !ok {
+		return nil, ErrRefreshTokenInvalid
+	}
+	s := m.Sessions[rt.SessionID]
+	if s.ID != sessionID || s.RevokedAt != nil || !rt.ExpiresAt.After(time.Now()) || !s.ExpiresAt.After(time.Now()) {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if rt.Used {
+		return &s, ErrRefreshTokenReused
+	}
+
+	rt.Used = true
+	m.Tokens[oldHash] = rt
+	m.Tokens[newHash] = MockRefreshToken{SessionID: s.ID, ExpiresAt: exp}
+	s.LastUsedAt, s.ExpiresAt = time.Now(), exp
+	m.Sessions[s.ID] = s
+	return &s, nil
+}
+
+func (m *MockAuthRepository) revoke(id, reason string) {
+	s := m.Sessions[id]
+	now := time.Now()
+	s.RevokedAt, s.RevokedReason = &now, reason
+	m.Sessions[id] = s
+}
+
+func (m *MockAuthRepository) RevokeSessionByToken(ctx context.Context, tokenHash, reason string) error {
+	if rt, ok := m.Tokens[tokenHash]; ok && m.Sessions[rt.SessionID].RevokedAt == nil {
+		m.revoke(rt.SessionID, reason)
+	}
 	return nil
 }
 
-func (m *MockAuthRepository) IsRefreshTokenValid(ctx context.Context, token string) (bool, error) {
-	return true, nil
+func (m *MockAuthRepository) ListSessions(ctx context.Context, userID string) ([]model.AuthSession, error) {
+	list := []model.AuthSession{}
+	for _, s := range m.Sessions {
+		if s.UserID == userID && s.RevokedAt == nil && s.ExpiresAt.After(time.Now()) {
+			list = append(list, s)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].LastUsedAt.After(list[j].LastUsedAt) })
+	return list, nil
 }
 
-func (m *MockAuthRepository) DeleteRefreshToken(ctx context.Context, token string) error {
+func (m *MockAuthRepository) RevokeSession(ctx context.Context, userID, sessionID, reason string) error {
+	s, ok := m.Sessions[sessionID]
+	if !ok || s.UserID != userID || s.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+	m.revoke(sessionID, reason)
 	return nil
 }
+
+func (m *MockAuthRepository) RevokeUserSessions(ctx context.Context, userID, reason string) (int, error) {
+	n := 0
+	for id, s := range m.Sessions {
+		if s.UserID == userID && s.RevokedAt == nil {
+			m.revoke(id, reason)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backenduas/app/model"
+
+	"github.com/google/uuid"
+)
+
+type MockEmailOutboxRepository struct {
+	Data []model.EmailJob
+}
+
+func NewMockEmailOutboxRepository() *MockEmailOutboxRepository {
+	return &MockEmailOutboxRepository{
+		Data: []model.EmailJob{},
+	}
+}
+
+func (m *MockEmailOutboxRepository) Enqueue(ctx context.Context, job *model.EmailJob) error {
+	job.ID = uuid.New().String()
+	job.Status = model.EmailPending
+	job.CreatedAt = time.Now()
+	job.NextAttemptAt = job.CreatedAt
+	m.Data = append(m.Data, *job)
+	return nil
+}
+
+func (m *MockEmailOutboxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]model.EmailJob, error) {
+	now := time.Now()
+	out := []model.EmailJob{}
+	for i, j := range m.Data {
+		if j.Status != model.EmailPending || j.NextAttemptAt.After(now) || len(out) >= limit {
+			continue
+		}
+		m.Data[i].NextAttemptAt = now.Add(lease)
+		out = append(out, m.Data[i])
+	}
+	return out, nil
+}
+
+func (m *MockEmailOutboxRepository) MarkSent(ctx context.Context, id string) error {
+	for i, j := range m.Data {
+		if j.ID == id {
+			now := time.Now()
+			m.Data[i].Status = model.EmailSent
+			m.Data[i].SentAt = &now
+			m.Data[i].Attempts++
+			m.Data[i].LastError = ""
+			return nil
+		}
+	}
+	return errors.New("email job not found")
+}
+
+func (m *MockEmailOutboxRepository) MarkFailed(ctx context.Context, id string, errMsg string, nextAttempt time.Time, dead bool) error {
+	for i, j := range m.Data {
+		if j.ID == id {
+			m.Data[i].Attempts++
+			m.Data[i].LastError = errMsg
+			m.Data[i].NextAttemptAt = nextAttempt
+			if dead {
+				m.Data[i].Status = model.EmailDead
+			}
+			return nil
+		}
+	}
+	return errors.New("email job not found")
+}
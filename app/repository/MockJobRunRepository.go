@@ -0,0 +1,22 @@
+package repository
+
+import "context"
+
+type MockJobRunRepository struct {
+	Claimed map[string]bool // job + "/" + runKey
+}
+
+func NewMockJobRunRepository() *MockJobRunRepository {
+	return &MockJobRunRepository{
+		Claimed: make(map[string]bool),
+	}
+}
+
+func (m *MockJobRunRepository) Claim(ctx context.Context, job, runKey string) (bool, error) {
+	key := job + "/" + runKey
+	if m.Claimed[key] {
+		return false, nil
+	}
+	m.Claimed[key] = true
+	return true, nil
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"backenduas/app/model"
+
+	"github.com/google/uuid"
+)
+
+type MockLoginAttemptRepository struct {
+	Throttle map[string]model.LoginThrottle // key = "user:<username>" / "ip:<addr>"
+	Attempts []model.LoginAttempt
+	Now      func() time.Time // pengganti NOW() database
+}
+
+func NewMockLoginAttemptRepository() *MockLoginAttemptRepository {
+	return &MockLoginAttemptRepository{
+		Throttle: make(map[string]model.LoginThrottle),
+		Now:      time.Now,
+	}
+}
+
+func (m *MockLoginAttemptRepository) Reserve(ctx context.Context, key string, window time.Duration) (model.LoginThrottle, error) {
+	now := m.Now()
+	t, ok := m.Throttle[key]
+	if ok && t.BlockedUntil != nil && t.BlockedUntil.After(now) {
+		return t, nil
+	}
+	if !ok || t.LastFailedAt.Before(now.Add(-window)) {
+		t = model.LoginThrottle{Key: key}
+	}
+	t.Failures++
+	t.LastFailedAt = now
+	m.Throttle[key] = t
+	return t, nil
+}
+
+func (m *MockLoginAttemptRepository) Release(ctx context.Context, key string, free int) error {
+	t, ok := m.Throttle[key]
+	if !ok {
+		return nil
+	}
+	if t.Failures > 0 {
+		t.Failures--
+	}
+	if t.Failures <= free {
+		t.BlockedUntil = nil
+	}
+	m.Throttle[key] = t
+	return nil
+}
+
+func (m *MockLoginAttemptRepository) Block(ctx context.Context, key string, until time.Time) error {
+	t, ok := m.Throttle[key]
+	if !ok {
+		return nil
+	}
+	if t.BlockedUntil == nil || until.After(*t.BlockedUntil) {
+		t.BlockedUntil = &until
+	}
+	m.Throttle[key] = t
+	return nil
+}
+
+func (m *MockLoginAttemptRepository) ResetThrottle(ctx context.Context, key string) error {
+	delete(m.Throttle, key)
+	return nil
+}
+
+func (m *MockLoginAttemptRepository) RecordAttempt(ctx context.Context, a *model.LoginAttempt) error {
+	a.ID = uuid.New().String()
+	a.CreatedAt = m.Now()
+	if a.Count < 1 {
+		a.Count = 1
+	}
+	m.Attempts = append(m.Attempts, *a)
+	return nil
+}
+
+func (m *MockLoginAttemptRepository) AddThrottled(ctx context.Context, a *model.LoginAttempt, since time.Time) (bool, error) {
+	for i := len(m.Attempts) - 1; i >= 0; i-- {
+		p := &m.Attempts[i]
+		if p.Reason != a.Reason || !strings.EqualFold(p.Username, a.Username) || p.IPAddress != a.IPAddress || p.CreatedAt.Before(since) {
+			continue
+		}
+		p.Count++
+		p.UserAgent = a.UserAgent
+		p.CreatedAt = m.Now()
+		*a = *p
+		return true, nil
+	}
+	return false, nil
+}
+
+func (m *MockLoginAttemptRepository) Purge(ctx context.Context, before time.Time, window time.Duration) (int64, error) {
+	kept := m.Attempts[:0]
+	for _, a := range m.Attempts {
+		if !a.CreatedAt.Before(before) {
+			kept = append(kept, a)
+		}
+	}
+	n := int64(len(m.Attempts) - len(kept))
+	m.Attempts = kept
+
+	now := m.Now()
+	for k, t := range m.Throttle {
+		if t.LastFailedAt.Before(now.Add(-window)) && (t.BlockedUntil == nil || t.BlockedUntil.Before(now)) {
+			delete(m.Throttle, k)
+		}
+	}
+	return n, nil
+}
+
+func (m *MockLoginAttemptRepository) ListAttempts(ctx context.Context, f model.LoginAttemptFilter) ([]model.LoginAttempt, error) {
+	list := []model.LoginAttempt{}
+	for _, a := range m.Attempts {
+		if f.Username != "" && !strings.EqualFold(a.Username, f.Username) {
+			continue
+		}
+		if f.IP != "" && a.IPAddress != f.IP {
+			continue
+		}
+		list = append(list, a)
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	if f.Limit > 0 && len(list) > f.Limit {
+		list = list[:f.Limit]
+	}
+	return list, nil
+}
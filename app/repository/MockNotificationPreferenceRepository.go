@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backenduas/app/model"
+)
+
+type MockNotificationPreferenceRepository struct {
+	Data map[string]model.NotificationPreference // userID -> preferensi
+}
+
+func NewMockNotificationPreferenceRepository() *MockNotificationPreferenceRepository {
+	return &MockNotificationPreferenceRepository{
+		Data: make(map[string]model.NotificationPreference),
+	}
+}
+
+func (m *MockNotificationPreferenceRepository) Get(ctx context.Context, userID string) (model.NotificationPreference, error) {
+	if p, ok := m.Data[userID]; ok {
+		return p, nil
+	}
+	return model.DefaultNotificationPreference(userID), nil
+}
+
+func (m *MockNotificationPreferenceRepository) Upsert(ctx context.Context, p *model.NotificationPreference) error {
+	p.UpdatedAt = time.Now()
+	m.Data[p.UserID] = *p
+	return nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backenduas/app/model"
+
+	"github.com/google/uuid"
+)
+
+type MockNotificationRepository struct {
+	Data []model.Notification // urut sesuai insert
+}
+
+func NewMockNotificationRepository() *MockNotificationRepository {
+	return &MockNotificationRepository{
+		Data: []model.Notification{},
+	}
+}
+
+func (m *MockNotificationRepository) Create(ctx context.Context, n *model.Notification) error {
+	n.ID = uuid.New().String()
+	n.CreatedAt = time.Now()
+	m.Data = append(m.Data, *n)
+	return nil
+}
+
+func (m *MockNotificationRepository) List(
+	ctx context.Context,
+	recipientID string,
+	unreadOnly bool,
+	limit, offset int,
+) ([]model.Notification, int, error) {
+
+	matched := []model.Notification{}
+	// terbaru dulu
+	for i := len(m.Data) - 1; i >= 0; i-- {
+		n := m.Data[i]
+		if n.RecipientID != recipientID || (unreadOnly && n.IsRead) {
+			continue
+		}
+		matched = append(matched, n)
+	}
+
+	total := len(matched)
+	if offset >= total {
+		return []model.Notification{}, total, nil
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return matched[offset:end], total, nil
+}
+
+func (m *MockNotificationRepository) CountUnread(ctx context.Context, recipientID string) (int, error) {
+	count := 0
+	for _, n := range m.Data {
+		if n.RecipientID == recipientID && !n.IsRead {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockNotificationRepository) MarkRead(ctx context.Context, id, recipientID string) error {
+	for i, n := range m.Data {
+		if n.ID == id && n.RecipientID == recipientID {
+			if !n.IsRead {
+				now := time.Now()
+				m.Data[i].IsRead = true
+				m.Data[i].ReadAt = &now
+			}
+			return nil
+		}
+	}
+	return ErrNotificationNotFound
+}
+
+func (m *MockNotificationRepository) MarkAllRead(ctx context.Context, recipientID string) (int, error) {
+	count := 0
+	now := time.Now()
+	for i, n := range m.Data {
+		if n.RecipientID == recipientID && !n.IsRead {
+			m.Data[i].IsRead = true
+			m.Data[i].ReadAt = &now
+			count++
+		}
+	}
+	return count, nil
+}
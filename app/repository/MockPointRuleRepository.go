@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"backenduas/app/model"
+
+	"github.com/google/uuid"
+)
+
+type MockPointRuleRepository struct {
+	Data map[string]model.PointRule // key = rule ID
+}
+
+func NewMockPointRuleRepository() *MockPointRuleRepository {
+	return &MockPointRuleRepository{
+		Data: make(map[string]model.PointRule),
+	}
+}
+
+func (m *MockPointRuleRepository) GetAll(ctx context.Context, includeInactive bool) ([]model.PointRule, error) {
+	list := []model.PointRule{}
+	for _, r := range m.Data {
+		if r.IsActive || includeInactive {
+			list = append(list, r)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+func (m *MockPointRuleRepository) GetByID(ctx context.Context, id string) (*model.PointRule, error) {
+	if r, ok := m.Data[id]; ok {
+		return &r, nil
+	}
+	return nil, ErrPointRuleNotFound
+}
+
+func (m *MockPointRuleRepository) Create(ctx context.Context, r *model.PointRule) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	r.CreatedAt = time.Now()
+	r.UpdatedAt = r.CreatedAt
+	m.Data[r.ID] = *r
+	return nil
+}
+
+func (m *MockPointRuleRepository) Update(ctx context.Context, r *model.PointRule) error {
+	old, ok := m.Data[r.ID]
+	if !ok {
+		return ErrPointRuleNotFound
+	}
+	r.CreatedAt = old.CreatedAt
+	r.UpdatedAt = time.Now()
+	m.Data[r.ID] = *r
+	return nil
+}
+
+func (m *MockPointRuleRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := m.Data[id]; !ok {
+		return ErrPointRuleNotFound
+	}
+	delete(m.Data, id)
+	return nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"backenduas/app/model"
+
+	"github.com/google/uuid"
+)
+
+type MockRBACRepository struct {
+	Roles       map[string]model.Role       // key = id
+	Permissions map[string]model.Permission // key = id
+	Grants      map[string][]string         // role id → nama permission
+	Users       map[string]int              // role id → jumlah user
+	Lookups     int                         // jumlah panggilan PermissionsByRole
+}
+
+func NewMockRBACRepository() *MockRBACRepository {
+	return &MockRBACRepository{
+		Roles:       make(map[string]model.Role),
+		Permissions: make(map[string]model.Permission),
+		Grants:      make(map[string][]string),
+		Users:       make(map[string]int),
+	}
+}
+
+func (m *MockRBACRepository) role(r model.Role) model.Role {
+	r.Permissions = append([]string{}, m.Grants[r.ID]...)
+	sort.Strings(r.Permissions)
+	r.Users = m.Users[r.ID]
+	return r
+}
+
+func (m *MockRBACRepository) ListRoles(ctx context.Context) ([]model.Role, error) {
+	list := []model.Role{}
+	for _, r := range m.Roles {
+		list = append(list, m.role(r))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+func (m *MockRBACRepository) GetRole(ctx context.Context, id string) (model.Role, error) {
+	r, ok := m.Roles[id]
+	if !ok {
+		return model.Role{}, ErrRoleNotFound
+	}
+	return m.role(r), nil
+}
+
+func (m *MockRBACRepository) CreateRole(ctx context.Context, r *model.Role) error {
+	r.ID = uuid.New().String()
+	r.CreatedAt = time.Now()
+	m.Roles[r.ID] = *r
+	return nil
+}
+
+func (m *MockRBACRepository) UpdateRole(ctx context.Context, r *model.Role) error {
+	old, ok := m.Roles[r.ID]
+	if !ok {
+		return ErrRoleNotFound
+	}
+	old.Name, old.Description = r.Name, r.Description
+	m.Roles[r.ID] = old
+	return nil
+}
+
+func (m *MockRBACRepository) DeleteRole(ctx context.Context, id string) error {
+	if _, ok := m.Roles[id]; !ok {
+		return ErrRoleNotFound
+	}
+	delete(m.Roles, id)
+	delete(m.Grants, id)
+	return nil
+}
+
+func (m *MockRBACRepository) ListPermissions(ctx context.Context) ([]model.Permission, error) {
+	list := []model.Permission{}
+	for _, p := range m.Permissions {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+func (m *MockRBACRepository) GetPermission(ctx context.Context, id string) (model.Permission, error) {
+	p, ok := m.Permissions[id]
+	if !ok {
+		return model.Permission{}, ErrPermissionNotFound
+	}
+	return p, nil
+}
+
+func (m *MockRBACRepository) CreatePermission(ctx context.Context, p *model.Permission) error {
+	p.ID = uuid.New().String()
+	p.Resource, p.Action = splitPermission(p.Name)
+	m.Permissions[p.ID] = *p
+	return nil
+}
+
+func (m *MockRBACRepository) UpdatePermission(ctx context.Context, p *model.Permission) error {
+	old, ok := m.Permissions[p.ID]
+	if !ok {
+		return ErrPermissionNotFound
+	}
+	p.Resource, p.Action = splitPermission(p.Name)
+	m.Permissions[p.ID] = *p
+
+	for rid, names := range m.Grants {
+		for i, n := range names {
+			if n == old.Name {
+				m.Grants[rid][i] = p.Name
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MockRBACRepository) DeletePermission(ctx context.Context, id string) error {
+	p, ok := m.Permissions[id]
+	if !ok {
+		return ErrPermissionNotFound
+	}
+	delete(m.Permissions, id)
+
+	for rid, names := range m.Grants {
+		kept := []string{}
+		for _, n := range names {
+			if n != p.Name {
+				kept = append(kept, n)
+			}
+		}
+		m.Grants[rid] = kept
+	}
+	return nil
+}
+
+func (m *MockRBACRepository) SetRolePermissions(ctx context.Context, roleID string, names []string) error {
+	if _, ok := m.Roles[roleID]; !ok {
+		return ErrRoleNotFound
+	}
+	m.Grants[roleID] = []string{}
+	for _, p := range m.Permissions {
+		if containsString(names, p.Name) {
+			m.Grants[roleID] = append(m.Grants[roleID], p.Name)
+		}
+	}
+	return nil
+}
+
+func (m *MockRBACRepository) PermissionsByRole(ctx context.Context, roleID string) ([]string, error) {
+	m.Lookups++
+	perms := append([]string{}, m.Grants[roleID]...)
+	sort.Strings(perms)
+	return perms, nil
+}
+
+func (m *MockRBACRepository) SeedPermissions(ctx context.Context, perms []model.Permission, grants map[string][]string) ([]string, error) {
+	existing := map[string]bool{}
+	for _, p := range m.Permissions {
+		existing[p.Name] = true
+	}
+
+	inserted := []string{}
+	for _, p := range perms {
+		if existing[p.Name] {
+			continue
+		}
+		if err := m.CreatePermission(ctx, &p); err != nil {
+			return nil, err
+		}
+		for id, r := range m.Roles {
+			for _, name := range grants[p.Name] {
+				if strings.EqualFold(r.Name, name) {
+					m.Grants[id] = append(m.Grants[id], p.Name)
+				}
+			}
+		}
+		inserted = append(inserted, p.Name)
+	}
+	return inserted, nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backenduas/app/model"
+)
+
+type MockSLARepository struct {
+	Settings    model.SLASettings
+	Overdue     []model.SLABreachItem      // semua prestasi submitted; difilter cutoff saat ListOverdue
+	Breaches    map[string]model.SLABreach // achievementID + "/" + submitted_at
+	Backups     map[string]string          // lecturerID → backup lecturerID
+	Reviewers   map[string]string          // achievementID → lecturerID
+	Turnarounds []model.ReviewTurnaround
+}
+
+func NewMockSLARepository() *MockSLARepository {
+	return &MockSLARepository{
+		Settings:  model.SLASettings{ReviewDays: 14, Action: model.SLAActionEscalate},
+		Breaches:  make(map[string]model.SLABreach),
+		Backups:   make(map[string]string),
+		Reviewers: make(map[string]string),
+	}
+}
+
+func breachKey(achievementID string, submittedAt time.Time) string {
+	return achievementID + "/" + submittedAt.UTC().Format(time.RFC3339Nano)
+}
+
+func (m *MockSLARepository) GetSettings(ctx context.Context) (model.SLASettings, error) {
+	return m.Settings, nil
+}
+
+func (m *MockSLARepository) UpdateSettings(ctx context.Context, s *model.SLASettings) error {
+	s.UpdatedAt = time.Now()
+	m.Settings = *s
+	return nil
+}
+
+func (m *MockSLARepository) ListOverdue(ctx context.Context, cutoff time.Time) ([]model.SLABreachItem, error) {
+	items := []model.SLABreachItem{}
+	for _, it := range m.Overdue {
+		if !it.SubmittedAt.Before(cutoff) {
+			continue
+		}
+		if b, ok := m.Breaches[breachKey(it.AchievementID, it.SubmittedAt)]; ok {
+			it.Action = b.Action
+		}
+		if reviewer, ok := m.Reviewers[it.AchievementID]; ok {
+			it.ReviewerID = &reviewer
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+func (m *MockSLARepository) RecordBreach(ctx context.Context, b model.SLABreach) (bool, error) {
+	key := breachKey(b.AchievementID, b.SubmittedAt)
+	if _, ok := m.Breaches[key]; ok {
+		return false, nil
+	}
+	m.Breaches[key] = b
+	return true, nil
+}
+
+func (m *MockSLARepository) GetBackupLecturer(ctx context.Context, lecturerID string) (string, error) {
+	return m.Backups[lecturerID], nil
+}
+
+func (m *MockSLARepository) SetBackupLecturer(ctx context.Context, lecturerID string, backupID *string) error {
+	if lecturerID == "" {
+		return errors.New("lecturer not found")
+	}
+	if backupID == nil {
+		delete(m.Backups, lecturerID)
+		return nil
+	}
+	m.Backups[lecturerID] = *backupID
+	return nil
+}
+
+func (m *MockSLARepository) AssignReviewer(ctx context.Context, achievementID, lecturerID string) error {
+	m.Reviewers[achievementID] = lecturerID
+	return nil
+}
+
+func (m *MockSLARepository) ListTurnarounds(ctx context.Context, from, to time.Time) ([]model.ReviewTurnaround, error) {
+	result := []model.ReviewTurnaround{}
+	for _, t := range m.Turnarounds {
+		if !t.ReviewedAt.Before(from) && t.ReviewedAt.Before(to) {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"backenduas/app/model"
+)
+
+// MockStatsRepository menyimpan fakta saja; counter dihitung saat query
+type MockStatsRepository struct {
+	mu       sync.Mutex                 // pengganti kunci tabel / advisory lock
+	Facts    map[string]model.StatsFact // achievementID → fakta
+	Rebuilt  *time.Time
+	Applied  int // jumlah panggilan ApplyFact
+	FailWith error
+}
+
+func NewMockStatsRepository() *MockStatsRepository {
+	return &MockStatsRepository{Facts: make(map[string]model.StatsFact)}
+}
+
+func (m *MockStatsRepository) ApplyFact(ctx context.Context, achievementID string, fact *model.StatsFact) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.FailWith != nil {
+		return m.FailWith
+	}
+	m.Applied++
+	if fact == nil {
+		delete(m.Facts, achievementID)
+		return nil
+	}
+	m.Facts[achievementID] = *fact
+	return nil
+}
+
+func (m *MockStatsRepository) Rebuild(ctx context.Context, snapshot func(ctx context.Context) ([]model.StatsFact, error)) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.FailWith != nil {
+		return time.Time{}, m.FailWith
+	}
+	facts, err := snapshot(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	m.Facts = make(map[string]model.StatsFact, len(facts))
+	for _, f := range facts {
+		m.Facts[f.AchievementID] = f
+	}
+	now := time.Now()
+	m.Rebuilt = &now
+	return now, nil
+}
+
+func (m *MockStatsRepository) RebuiltAt(ctx context.Context) (*time.Time, error) {
+	return m.Rebuilt, nil
+}
+
+func (m *MockStatsRepository) matches(q model.StatsQuery, f model.StatsFact) bool {
+	if !containsString(q.StudentIDs, f.StudentID) {
+		return false
+	}
+	if q.Statuses != nil && !containsString(q.Statuses, f.Status) || q.Statuses == nil && f.Status == "deleted" {
+		return false
+	}
+	if q.AchievementType != "" && !strings.EqualFold(q.AchievementType, f.AchievementType) {
+		return false
+	}
+	if q.From != nil && f.Month.Before(*q.From) || q.To != nil && !f.Month.Before(*q.To) {
+		return false
+	}
+	return true
+}
+
+func (m *MockStatsRepository) count(q model.StatsQuery, keys func(model.StatsFact) []string) []model.StatsCount {
+	type bucket struct {
+		student, key string
+		month        time.Time
+	}
+	totals := map[bucket]int{}
+	order := []bucket{}
+	for _, f := range m.Facts {
+		if !m.matches(q, f) {
+			continue
+		}
+		for _, k := range keys(f) {
+			b := bucket{f.StudentID, k, f.Month}
+			if totals[b] == 0 {
+				order = append(order, b)
+			}
+			totals[b]++
+		}
+	}
+
+	list := []model.StatsCount{}
+	for _, b := range order {
+		list = append(list, model.StatsCount{StudentID: b.student, Key: b.key, Month: b.month, Total: totals[b]})
+	}
+	return list
+}
+
+func (m *MockStatsRepository) CountTypes(ctx context.Context, q model.StatsQuery) ([]model.StatsCount, error) {
+	return m.count(q, func(f model.StatsFact) []string { return []string{f.AchievementType} }), nil
+}
+
+func (m *MockStatsRepository) CountTags(ctx context.Context, q model.StatsQuery) ([]model.StatsCount, error) {
+	return m.count(q, func(f model.StatsFact) []string { return f.Tags }), nil
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -77,6 +77,15 @@ func (m *MockStudentRepository) GetLecturerIDByUserID(ctx context.Context, userI
 	return "", errors.New("lecturer not found")
 }
 
+func (m *MockStudentRepository) GetUserIDByLecturerID(ctx context.Context, lecturerID string) (string, error) {
+	for uid, lid := range m.UserToLecturer {
+		if lid == lecturerID {
+			return uid, nil
+		}
+	}
+	return "", errors.New("lecturer not found")
+}
+
 func (m *MockStudentRepository) GetStudentsByAdvisor(ctx context.Context, advisorID string) ([]string, error) {
 	out := []string{}
 	for sid, aid := range m.AdvisorMap {
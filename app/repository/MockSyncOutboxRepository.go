@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backenduas/app/model"
+
+	"github.com/google/uuid"
+)
+
+type MockSyncOutboxRepository struct {
+	Data     []model.SyncOutboxEntry
+	FailWith error // jika diisi, Enqueue gagal
+}
+
+func NewMockSyncOutboxRepository() *MockSyncOutboxRepository {
+	return &MockSyncOutboxRepository{
+		Data: []model.SyncOutboxEntry{},
+	}
+}
+
+func (m *MockSyncOutboxRepository) Enqueue(ctx context.Context, e model.SyncOutboxEntry) error {
+	if m.FailWith != nil {
+		return m.FailWith
+	}
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	e.CreatedAt = time.Now()
+	m.Data = append(m.Data, e)
+	return nil
+}
+
+func (m *MockSyncOutboxRepository) GetPending(ctx context.Context, limit int) ([]model.SyncOutboxEntry, error) {
+	out := []model.SyncOutboxEntry{}
+	for _, e := range m.Data {
+		if e.ProcessedAt == nil && e.DeadAt == nil && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (m *MockSyncOutboxRepository) PendingFor(ctx context.Context, mongoID string) ([]model.SyncOutboxEntry, error) {
+	out := []model.SyncOutboxEntry{}
+	for _, e := range m.Data {
+		if e.MongoID == mongoID && e.ProcessedAt == nil && e.DeadAt == nil {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (m *MockSyncOutboxRepository) MarkDone(ctx context.Context, ids ...string) error {
+	for _, id := range ids {
+		i := m.find(id)
+		if i < 0 {
+			return errors.New("outbox entry not found")
+		}
+		if m.Data[i].ProcessedAt != nil {
+			continue
+		}
+		now := time.Now()
+		m.Data[i].ProcessedAt = &now
+		m.Data[i].Attempts++
+		m.Data[i].LastError = ""
+	}
+	return nil
+}
+
+func (m *MockSyncOutboxRepository) MarkFailed(ctx context.Context, id string, errMsg string, maxAttempts int) (bool, error) {
+	i := m.find(id)
+	if i < 0 {
+		return false, errors.New("outbox entry not found")
+	}
+	m.Data[i].Attempts++
+	m.Data[i].LastError = errMsg
+	if m.Data[i].Attempts >= maxAttempts {
+		now := time.Now()
+		m.Data[i].DeadAt = &now
+	}
+	return m.Data[i].DeadAt != nil, nil
+}
+
+func (m *MockSyncOutboxRepository) find(id string) int {
+	for i, e := range m.Data {
+		if e.ID == id {
+			return i
+		}
+	}
+	return -1
+}
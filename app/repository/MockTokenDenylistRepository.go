@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backenduas/app/model"
+)
+
+type MockTokenDenylistRepository struct {
+	Denylist model.TokenDenylist
+	Loads    int   // jumlah panggilan LoadDenylist
+	FailWith error // dikembalikan LoadDenylist jika diisi
+}
+
+func NewMockTokenDenylistRepository() *MockTokenDenylistRepository {
+	return &MockTokenDenylistRepository{Denylist: model.TokenDenylist{
+		Tokens:   make(map[string]time.Time),
+		Users:    make(map[string]time.Time),
+		Sessions: make(map[string]bool),
+	}}
+}
+
+func (m *MockTokenDenylistRepository) RevokeToken(ctx context.Context, jti, userID string, exp time.Time, reason string) error {
+	m.Denylist.Tokens[jti] = exp
+	return nil
+}
+
+func (m *MockTokenDenylistRepository) RevokeUserTokens(ctx context.Context, userID string, before time.Time, reason string) error {
+	if before.After(m.Denylist.Users[userID]) {
+		m.Denylist.Users[userID] = before
+	}
+	return nil
+}
+
+func (m *MockTokenDenylistRepository) LoadDenylist(ctx context.Context, since time.Time) (model.TokenDenylist, error) {
+	m.Loads++
+	if m.FailWith != nil {
+		return model.TokenDenylist{}, m.FailWith
+	}
+
+	d := model.TokenDenylist{Tokens: map[string]time.Time{}, Users: map[string]time.Time{}, Sessions: map[string]bool{}}
+	for jti, exp := range m.Denylist.Tokens {
+		d.Tokens[jti] = exp
+	}
+	for uid, before := range m.Denylist.Users {
+		if before.After(since) {
+			d.Users[uid] = before
+		}
+	}
+	for sid := range m.Denylist.Sessions {
+		d.Sessions[sid] = true
+	}
+	return d, nil
+}
+
+func (m *MockTokenDenylistRepository) PurgeDenylist(ctx context.Context, since time.Time) error {
+	return nil
+}
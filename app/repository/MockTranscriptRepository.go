@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"backenduas/app/model"
+)
+
+type MockTranscriptRepository struct {
+	Verified map[string][]model.TranscriptEntry // studentID → prestasi verified
+	Issued   map[string]model.Transcript        // hash → transkrip
+}
+
+func NewMockTranscriptRepository() *MockTranscriptRepository {
+	return &MockTranscriptRepository{
+		Verified: make(map[string][]model.TranscriptEntry),
+		Issued:   make(map[string]model.Transcript),
+	}
+}
+
+func (m *MockTranscriptRepository) ListVerified(ctx context.Context, studentID string) ([]model.TranscriptEntry, error) {
+	return append([]model.TranscriptEntry{}, m.Verified[studentID]...), nil
+}
+
+func (m *MockTranscriptRepository) Create(ctx context.Context, t *model.Transcript) error {
+	if _, ok := m.Issued[t.Hash]; ok {
+		return errors.New("duplicate transcript hash")
+	}
+	m.Issued[t.Hash] = *t
+	return nil
+}
+
+func (m *MockTranscriptRepository) GetByHash(ctx context.Context, hash string) (model.Transcript, error) {
+	t, ok := m.Issued[hash]
+	if !ok {
+		return t, errors.New("transcript not found")
+	}
+	return t, nil
+}
@@ -51,6 +51,16 @@ func (m *MockUserRepository) UpdateRole(ctx context.Context, userID string, role
 	return nil
 }
 
+func (m *MockUserRepository) SetActive(ctx context.Context, userID string, active bool) error {
+	u, ok := m.Data[userID]
+	if !ok {
+		return errors.New("user tidak ditemukan")
+	}
+	u.IsActive = active
+	m.Data[userID] = u
+	return nil
+}
+
 func (m *MockUserRepository) GetByID(ctx context.Context, id string) (*model.User, error) {
 	u, ok := m.Data[id]
 	if !ok {
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+
+	"backenduas/app/model"
+	"backenduas/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type AchievementHistoryRepository struct{}
+
+func NewAchievementHistoryRepository() *AchievementHistoryRepository {
+	return &AchievementHistoryRepository{}
+}
+
+// INSERT ONLY — riwayat tidak pernah diubah / dihapus
+func (r *AchievementHistoryRepository) Create(ctx context.Context, h model.AchievementHistory) error {
+	return insertHistory(ctx, database.DB, h)
+}
+
+// execer = *pgxpool.Pool atau pgx.Tx
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// insertHistory dipakai Create dan ChangeStatus (di dalam transaksi)
+func insertHistory(ctx context.Context, db execer, h model.AchievementHistory) error {
+	if h.ID == "" {
+		h.ID = uuid.New().String()
+	}
+	if h.ChangedFields == nil {
+		h.ChangedFields = []string{}
+	}
+
+	var actor interface{}
+	if h.ActorUserID != "" {
+		actor = h.ActorUserID
+	}
+
+	_, err := db.Exec(ctx, `
+		INSERT INTO achievement_histories
+			(id, achievement_id, actor_user_id, actor_role, from_status, to_status, note, changed_fields, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,clock_timestamp())
+	`,
+		h.ID,
+		h.AchievementID,
+		actor,
+		h.ActorRole,
+		h.FromStatus,
+		h.ToStatus,
+		h.Note,
+		h.ChangedFields,
+	)
+	return err
+}
+
+// Timeline lengkap, urut dari event paling awal
+func (r *AchievementHistoryRepository) GetByAchievementID(ctx context.Context, achievementID string) ([]model.AchievementHistory, error) {
+	rows, err := database.DB.Query(ctx, `
+		SELECT id, achievement_id, COALESCE(actor_user_id::text, ''), actor_role,
+		       from_status, to_status, note, changed_fields, created_at
+		FROM achievement_histories
+		WHERE achievement_id = $1
+		ORDER BY created_at ASC
+	`, achievementID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []model.AchievementHistory{}
+
+	for rows.Next() {
+		var h model.AchievementHistory
+		if err := rows.Scan(
+			&h.ID,
+			&h.AchievementID,
+			&h.ActorUserID,
+			&h.ActorRole,
+			&h.FromStatus,
+			&h.ToStatus,
+			&h.Note,
+			&h.ChangedFields,
+			&h.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, h)
+	}
+
+	return list, rows.Err()
+}
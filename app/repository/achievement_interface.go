@@ -14,15 +14,23 @@ POSTGRES ACHIEVEMENT (REFERENCE)
 =====================================================
 */
 type IAchievementPGRepository interface {
-	CreateReference(ref model.AchievementReference) error
-	UpdateStatus(id string, status string) error
-	Verify(id, verifier string) error
-	Reject(id, note string) error
+	// CreateReference: INSERT reference + riwayat pembuatan (nil = tanpa riwayat) dalam satu transaksi
+	CreateReference(ref model.AchievementReference, history *model.AchievementHistory) error
+	// ChangeStatus: UPDATE bersyarat (status = From) untuk semua perubahan
+	// dalam satu transaksi; status yang sudah berubah → ErrStatusChanged
+	ChangeStatus(ctx context.Context, changes ...model.StatusChange) error
+	// ChangeStatusEach: satu transaksi, SAVEPOINT per perubahan; hasil per indeks
+	// (nil = diterapkan, ErrStatusChanged = kalah balapan). Error kedua = semua batal.
+	ChangeStatusEach(ctx context.Context, changes ...model.StatusChange) ([]error, error)
 
 	GetByID(ctx context.Context, id string) (model.AchievementReference, error)
 	GetByStudentID(ctx context.Context, studentID string) ([]model.AchievementReference, error)
 	GetByStudentIDs(ctx context.Context, studentIDs []string) ([]model.AchievementReference, error)
 	GetAll(ctx context.Context) ([]model.AchievementReference, error)
+	List(ctx context.Context, q model.AchievementListQuery) ([]model.AchievementReference, int, error)
+	GetByMongoIDs(ctx context.Context, mongoIDs []string) ([]model.AchievementReference, error)
+	// SetPoints: total poin untuk sort GET /achievements (diisi saat verify & recalculate)
+	SetPoints(ctx context.Context, mongoID string, points int) error
 }
 
 /*
@@ -35,8 +43,31 @@ type IAchievementMongoRepository interface {
 
 	FindById(ctx context.Context, id primitive.ObjectID) (model.AchievementMongo, error)
 	FindManyByIDs(ctx context.Context, ids []primitive.ObjectID) (map[string]model.AchievementMongo, error)
+	FindStatsByIDs(ctx context.Context, ids []primitive.ObjectID) (map[string]model.AchievementMongo, error)
+	FindAll(ctx context.Context) ([]model.AchievementMongo, error)
+	// FindIDs: filter konten GET /achievements (jenis & tag), dipersempit scope mahasiswa
+	FindIDs(ctx context.Context, q model.AchievementListQuery) ([]string, error)
+	// SearchIDs: maksimal limit dokumen teratas, urut skor (paging setelah difilter PostgreSQL)
+	SearchIDs(ctx context.Context, text string, studentIDs []string, limit int) ([]model.SearchMatch, error)
+
+	// DistinctTypes / RenameType dipakai backfill code jenis prestasi
+	DistinctTypes(ctx context.Context) ([]string, error)
+	RenameType(ctx context.Context, from, to string, dryRun bool) (int64, error)
 
 	Update(ctx context.Context, id primitive.ObjectID, update bson.M) error
+	// SetStatus hanya menulis jika version lebih baru dari statusVersion dokumen
+	SetStatus(ctx context.Context, id primitive.ObjectID, status string, version int64) error
 	AddAttachment(ctx context.Context, id primitive.ObjectID, file model.AttachmentFile) error
 	SoftDelete(ctx context.Context, id primitive.ObjectID) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+/*
+=====================================================
+POSTGRES ACHIEVEMENT HISTORY (AUDIT TRAIL)
+=====================================================
+*/
+type IAchievementHistoryRepository interface {
+	Create(ctx context.Context, h model.AchievementHistory) error
+	GetByAchievementID(ctx context.Context, achievementID string) ([]model.AchievementHistory, error)
 }
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"sort"
+	"strings"
 	"time"
 
 	"backenduas/app/model"
@@ -9,6 +11,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -28,6 +31,7 @@ func (r *AchievementMongoRepository) Create(ctx context.Context, ach model.Achie
 	ach.CreatedAt = time.Now().Unix()
 	ach.UpdatedAt = time.Now().Unix()
 	ach.Status = "draft"
+	ach.DetailsText = flattenDetails(ach.Details)
 
 	res, err := r.collection().InsertOne(ctx, ach)
 	if err != nil {
@@ -39,6 +43,9 @@ func (r *AchievementMongoRepository) Create(ctx context.Context, ach model.Achie
 
 func (r *AchievementMongoRepository) Update(ctx context.Context, id primitive.ObjectID, update bson.M) error {
 	update["updatedAt"] = time.Now().Unix()
+	if details, ok := update["details"].(map[string]interface{}); ok {
+		update["detailsText"] = flattenDetails(details)
+	}
 
 	_, err := r.collection().UpdateOne(
 		ctx,
@@ -49,6 +56,59 @@ func (r *AchievementMongoRepository) Update(ctx context.Context, id primitive.Ob
 	return err
 }
 
+// DistinctTypes: semua nilai achievementType yang tersimpan
+func (r *AchievementMongoRepository) DistinctTypes(ctx context.Context) ([]string, error) {
+	values, err := r.collection().Distinct(ctx, "achievementType", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	types := []string{}
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			types = append(types, s)
+		}
+	}
+	return types, nil
+}
+
+// RenameType mengganti achievementType from → to; dryRun hanya menghitung
+func (r *AchievementMongoRepository) RenameType(ctx context.Context, from, to string, dryRun bool) (int64, error) {
+	filter := bson.M{"achievementType": from}
+	if dryRun {
+		return r.collection().CountDocuments(ctx, filter)
+	}
+
+	res, err := r.collection().UpdateMany(ctx, filter, bson.M{"$set": bson.M{
+		"achievementType": to,
+		"updatedAt":       time.Now().Unix(),
+	}})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// SetStatus: status dari sync outbox; versi lama (atau sama) diabaikan
+func (r *AchievementMongoRepository) SetStatus(ctx context.Context, id primitive.ObjectID, status string, version int64) error {
+	_, err := r.collection().UpdateOne(
+		ctx,
+		bson.M{
+			"_id": id,
+			"$or": bson.A{
+				bson.M{"statusVersion": bson.M{"$exists": false}},
+				bson.M{"statusVersion": bson.M{"$lt": version}},
+			},
+		},
+		bson.M{"$set": bson.M{
+			"status":        status,
+			"statusVersion": version,
+			"updatedAt":     time.Now().Unix(),
+		}},
+	)
+	return err
+}
+
 func (r *AchievementMongoRepository) SoftDelete(ctx context.Context, id primitive.ObjectID) error {
 	_, err := r.collection().UpdateOne(
 		ctx,
@@ -63,6 +123,12 @@ func (r *AchievementMongoRepository) SoftDelete(ctx context.Context, id primitiv
 	return err
 }
 
+// Delete = hard delete, hanya untuk kompensasi dual-write yang gagal
+func (r *AchievementMongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection().DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
 func (r *AchievementMongoRepository) FindById(ctx context.Context, id primitive.ObjectID) (model.AchievementMongo, error) {
 	var ach model.AchievementMongo
 	err := r.collection().FindOne(ctx, bson.M{"_id": id}).Decode(&ach)
@@ -89,6 +155,232 @@ func (r *AchievementMongoRepository) FindManyByIDs(ctx context.Context, ids []pr
 	return result, nil
 }
 
+// statsBatchSize = jumlah id per query $in saat mengambil data statistik
+const statsBatchSize = 1000
+
+// FindStatsByIDs: hanya field yang dipakai statistik (jenis, tag, createdAt),
+// diambil per batch sehingga laporan tidak lagi FindById per reference.
+func (r *AchievementMongoRepository) FindStatsByIDs(ctx context.Context, ids []primitive.ObjectID) (map[string]model.AchievementMongo, error) {
+	opts := options.Find().SetProjection(bson.M{
+		"_id":             1,
+		"studentId":       1,
+		"achievementType": 1,
+		"tags":            1,
+		"createdAt":       1,
+	})
+
+	result := make(map[string]model.AchievementMongo, len(ids))
+	for start := 0; start < len(ids); start += statsBatchSize {
+		end := start + statsBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		cursor, err := r.collection().Find(ctx, bson.M{"_id": bson.M{"$in": ids[start:end]}}, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		var docs []model.AchievementMongo
+		err = cursor.All(ctx, &docs)
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range docs {
+			result[d.ID.Hex()] = d
+		}
+	}
+
+	return result, nil
+}
+
+// FindAll: seluruh dokumen (ringkas) untuk reconcile
+func (r *AchievementMongoRepository) FindAll(ctx context.Context) ([]model.AchievementMongo, error) {
+	opts := options.Find().SetProjection(bson.M{
+		"_id":       1,
+		"studentId": 1,
+		"status":    1,
+		"createdAt": 1,
+		"updatedAt": 1,
+	})
+
+	cursor, err := r.collection().Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	result := []model.AchievementMongo{}
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindIDs: ObjectID (hex) yang cocok dengan filter konten (jenis & tag).
+// Scope mahasiswa hanya mempersempit hasil; status, sort & paging dilakukan
+// PostgreSQL (achievement_references adalah sumber kebenaran status & poin).
+func (r *AchievementMongoRepository) FindIDs(ctx context.Context, q model.AchievementListQuery) ([]string, error) {
+
+	filter := bson.M{}
+	if q.AchievementType != "" {
+		filter["achievementType"] = q.AchievementType
+	}
+	if len(q.Tags) > 0 {
+		filter["tags"] = bson.M{"$all": q.Tags}
+	}
+
+	students := bson.A{}
+	if q.ScopeStudentIDs != nil {
+		students = append(students, bson.M{"studentId": bson.M{"$in": q.ScopeStudentIDs}})
+	}
+	if q.StudentID != "" {
+		students = append(students, bson.M{"studentId": q.StudentID})
+	}
+	if len(students) > 0 {
+		filter["$and"] = students
+	}
+
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	cursor, err := r.collection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	ids := []string{}
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		ids = append(ids, doc.ID.Hex())
+	}
+
+	return ids, cursor.Err()
+}
+
+// SearchIDs: $text search (index achievement_text_search), urut skor relevansi.
+// studentIDs nil = semua mahasiswa. Dokumen berstatus deleted tidak ikut.
+// Scope & limit diterapkan di server Mongo supaya hasil tidak memuat seluruh koleksi.
+func (r *AchievementMongoRepository) SearchIDs(
+	ctx context.Context,
+	text string,
+	studentIDs []string,
+	limit int,
+) ([]model.SearchMatch, error) {
+
+	filter := bson.M{
+		"$text":  bson.M{"$search": text},
+		"status": bson.M{"$ne": "deleted"},
+	}
+	if studentIDs != nil {
+		filter["studentId"] = bson.M{"$in": studentIDs}
+	}
+
+	opts := options.Find().
+		SetProjection(bson.M{"_id": 1, "score": bson.M{"$meta": "textScore"}}).
+		SetSort(bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	matches := []model.SearchMatch{}
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID    primitive.ObjectID `bson:"_id"`
+			Score float64            `bson:"score"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		matches = append(matches, model.SearchMatch{MongoID: doc.ID.Hex(), Score: doc.Score})
+	}
+
+	return matches, cursor.Err()
+}
+
+// BackfillDetailsText mengisi detailsText untuk dokumen lama (idempotent)
+func (r *AchievementMongoRepository) BackfillDetailsText(ctx context.Context) (int, error) {
+	cursor, err := r.collection().Find(ctx,
+		bson.M{"detailsText": bson.M{"$exists": false}},
+		options.Find().SetProjection(bson.M{"details": 1}),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	n := 0
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID      primitive.ObjectID     `bson:"_id"`
+			Details map[string]interface{} `bson:"details"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return n, err
+		}
+		if _, err := r.collection().UpdateOne(ctx,
+			bson.M{"_id": doc.ID},
+			bson.M{"$set": bson.M{"detailsText": flattenDetails(doc.Details)}},
+		); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	return n, cursor.Err()
+}
+
+// flattenDetails menggabungkan semua nilai string details (nested map / array)
+func flattenDetails(details map[string]interface{}) string {
+	parts := []string{}
+
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch val := v.(type) {
+		case string:
+			if val != "" {
+				parts = append(parts, val)
+			}
+		case map[string]interface{}:
+			keys := make([]string, 0, len(val))
+			for k := range val {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(val[k])
+			}
+		case bson.M:
+			walk(map[string]interface{}(val))
+		case bson.D:
+			walk(map[string]interface{}(val.Map()))
+		case bson.A:
+			walk([]interface{}(val))
+		case []interface{}:
+			for _, item := range val {
+				walk(item)
+			}
+		case []string:
+			for _, item := range val {
+				walk(item)
+			}
+		}
+	}
+	walk(details)
+
+	return strings.Join(parts, " ")
+}
+
 func (r *AchievementMongoRepository) AddAttachment(ctx context.Context, id primitive.ObjectID, file model.AttachmentFile) error {
 
     update := bson.M{
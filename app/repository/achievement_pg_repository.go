@@ -2,18 +2,36 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+
 	"backenduas/app/model"
 	"backenduas/database"
+
+	"github.com/jackc/pgx/v5"
 )
 
+// ErrStatusChanged: status berubah sejak divalidasi (request lain lebih dulu)
+var ErrStatusChanged = errors.New("achievement status changed concurrently")
+
 type AchievementPGRepository struct{}
 
 func NewAchievementPGRepository() *AchievementPGRepository {
 	return &AchievementPGRepository{}
 }
 
-func (r *AchievementPGRepository) CreateReference(ref model.AchievementReference) error {
-	_, err := database.DB.Exec(context.Background(), `
+// CreateReference menyimpan reference beserta riwayat pembuatannya (opsional)
+// dalam satu transaksi
+func (r *AchievementPGRepository) CreateReference(ref model.AchievementReference, history *model.AchievementHistory) error {
+	ctx := context.Background()
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
 		INSERT INTO achievement_references
 			(id, student_id, mongo_achievement_id, status, created_at, updated_at)
 		VALUES ($1,$2,$3,$4,NOW(),NOW())
@@ -22,60 +40,162 @@ func (r *AchievementPGRepository) CreateReference(ref model.AchievementReference
 		ref.StudentID,
 		ref.MongoAchievementID,
 		ref.Status,
-	)
-	return err
-}
-
-func (r *AchievementPGRepository) UpdateStatus(id string, status string) error {
-	ctx := context.Background()
+	); err != nil {
+		return err
+	}
 
-	// jika submitted → update submitted_at dulu
-	if status == "submitted" {
-		_, err := database.DB.Exec(ctx, `
-			UPDATE achievement_references
-			SET submitted_at = NOW()
-			WHERE id = $1
-		`, id)
-		if err != nil {
+	if history != nil {
+		if err := insertHistory(ctx, tx, *history); err != nil {
 			return err
 		}
 	}
 
-	// update status biasa (harus cast ENUM)
-	_, err := database.DB.Exec(ctx, `
+	return tx.Commit(ctx)
+}
+
+// statusUpdates = UPDATE per status tujuan; $1 = id, $2 = status asal.
+// status_version dipakai sync outbox agar Mongo tidak ditimpa status lama.
+var statusUpdates = map[string]string{
+	"submitted": `
+		UPDATE achievement_references
+		SET status='submitted', submitted_at=NOW(), updated_at=NOW(), status_version=status_version+1
+		WHERE id=$1 AND status=$2::achievement_status
+		RETURNING mongo_achievement_id, status_version`,
+	"verified": `
+		UPDATE achievement_references
+		SET status='verified', verified_by=$3, verified_at=NOW(), points=COALESCE($4::int, points),
+		    updated_at=NOW(), status_version=status_version+1
+		WHERE id=$1 AND status=$2::achievement_status
+		RETURNING mongo_achievement_id, status_version`,
+	// rejection_note = JSONB list, satu entri per putaran review
+	"rejected": `
 		UPDATE achievement_references
-		SET status = $1::achievement_status,
-		    updated_at = NOW()
-		WHERE id = $2
-	`, status, id)
+		SET status='rejected',
+		    rejection_note = COALESCE(rejection_note, '[]'::jsonb) || jsonb_build_array(jsonb_build_object(
+		        'round',       jsonb_array_length(COALESCE(rejection_note, '[]'::jsonb)) + 1,
+		        'note',        $4::text,
+		        'rejected_by', $3::text,
+		        'rejected_at', NOW()
+		    )),
+		    updated_at=NOW(), status_version=status_version+1
+		WHERE id=$1 AND status=$2::achievement_status
+		RETURNING mongo_achievement_id, status_version`,
+	// revisi: riwayat catatan penolakan tetap disimpan
+	"draft": `
+		UPDATE achievement_references
+		SET status='draft', submitted_at=NULL, assigned_reviewer_id=NULL, updated_at=NOW(), status_version=status_version+1
+		WHERE id=$1 AND status=$2::achievement_status
+		RETURNING mongo_achievement_id, status_version`,
+	"deleted": `
+		UPDATE achievement_references
+		SET status='deleted', updated_at=NOW(), status_version=status_version+1
+		WHERE id=$1 AND status=$2::achievement_status
+		RETURNING mongo_achievement_id, status_version`,
+}
 
-	return err
+// ChangeStatus menerapkan semua perubahan (beserta riwayat dan entry
+// sync_outbox untuk MongoDB) dalam SATU transaksi. Jika status salah satu
+// prestasi sudah bukan From lagi, semuanya dibatalkan (ErrStatusChanged).
+func (r *AchievementPGRepository) ChangeStatus(ctx context.Context, changes ...model.StatusChange) error {
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, ch := range changes {
+		if err := applyStatusChange(ctx, tx, ch); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
 }
 
+// ChangeStatusEach seperti ChangeStatus, tetapi setiap perubahan memakai
+// SAVEPOINT sendiri: yang kalah balapan (ErrStatusChanged) dibatalkan sendiri
+// dan dilaporkan per indeks, sisanya tetap di-commit. Error lain membatalkan semua.
+func (r *AchievementPGRepository) ChangeStatusEach(ctx context.Context, changes ...model.StatusChange) ([]error, error) {
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
 
-func (r *AchievementPGRepository) Verify(id, verifier string) error {
-	_, err := database.DB.Exec(context.Background(), `
-		UPDATE achievement_references
-		SET status='verified', verified_by=$1, verified_at=NOW()
-		WHERE id=$2
-	`, verifier, id)
-	return err
+	results := make([]error, len(changes))
+	for i, ch := range changes {
+		sp, err := tx.Begin(ctx) // SAVEPOINT
+		if err != nil {
+			return nil, err
+		}
+
+		err = applyStatusChange(ctx, sp, ch)
+		if errors.Is(err, ErrStatusChanged) {
+			if err := sp.Rollback(ctx); err != nil {
+				return nil, err
+			}
+			results[i] = err
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := sp.Commit(ctx); err != nil { // RELEASE SAVEPOINT
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return results, nil
 }
 
-func (r *AchievementPGRepository) Reject(id, note string) error {
-	_, err := database.DB.Exec(context.Background(), `
-		UPDATE achievement_references
-		SET status='rejected', rejection_note=$1, updated_at=NOW()
-		WHERE id=$2
-	`, note, id)
-	return err
+// applyStatusChange: UPDATE bersyarat + entry outbox + riwayat di dalam tx
+func applyStatusChange(ctx context.Context, tx pgx.Tx, ch model.StatusChange) error {
+	query, ok := statusUpdates[ch.To]
+	if !ok {
+		return fmt.Errorf("unsupported achievement status %q", ch.To)
+	}
+
+	args := []interface{}{ch.AchievementID, ch.From}
+	switch ch.To {
+	case "verified":
+		args = append(args, ch.LecturerID, ch.Points)
+	case "rejected":
+		args = append(args, ch.LecturerID, ch.Note)
+	}
+
+	var mongoID string
+	var version int64
+	err := tx.QueryRow(ctx, query, args...).Scan(&mongoID, &version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: %s", ErrStatusChanged, ch.AchievementID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := enqueueOutbox(ctx, tx, model.SyncOutboxEntry{
+		Operation: "set_status",
+		MongoID:   mongoID,
+		Status:    ch.To,
+		Version:   version,
+	}); err != nil {
+		return err
+	}
+
+	if ch.History != nil {
+		return insertHistory(ctx, tx, *ch.History)
+	}
+	return nil
 }
 
 func (r *AchievementPGRepository) GetByID(ctx context.Context, id string) (model.AchievementReference, error) {
 	row := database.DB.QueryRow(ctx, `
 		SELECT id, student_id, mongo_achievement_id, status,
-		       submitted_at, verified_at, verified_by,
-		       rejection_note, created_at, updated_at
+		       submitted_at, verified_at, verified_by, assigned_reviewer_id::text,
+		       COALESCE(rejection_note, '[]'::jsonb), created_at, updated_at
 		FROM achievement_references
 		WHERE id = $1
 	`, id)
@@ -89,7 +209,8 @@ func (r *AchievementPGRepository) GetByID(ctx context.Context, id string) (model
 		&ref.SubmittedAt,
 		&ref.VerifiedAt,
 		&ref.VerifiedBy,
-		&ref.RejectionNote,
+		&ref.ReviewerID,
+		&ref.RejectionNotes,
 		&ref.CreatedAt,
 		&ref.UpdatedAt,
 	)
@@ -146,7 +267,8 @@ func (r *AchievementPGRepository) GetAll(ctx context.Context) ([]model.Achieveme
 func (r *AchievementPGRepository) GetByStudentIDs(ctx context.Context, studentIDs []string) ([]model.AchievementReference, error) {
 
 	rows, err := database.DB.Query(ctx, `
-        SELECT id, student_id, mongo_achievement_id, status
+        SELECT id, student_id, mongo_achievement_id, status,
+               submitted_at, verified_at, assigned_reviewer_id::text, created_at, updated_at
         FROM achievement_references
         WHERE student_id = ANY($1)
     `, studentIDs)
@@ -154,15 +276,148 @@ func (r *AchievementPGRepository) GetByStudentIDs(ctx context.Context, studentID
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	list := []model.AchievementReference{}
 
 	for rows.Next() {
 		var ref model.AchievementReference
-		rows.Scan(&ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.Status)
+		if err := rows.Scan(
+			&ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.Status,
+			&ref.SubmittedAt, &ref.VerifiedAt, &ref.ReviewerID, &ref.CreatedAt, &ref.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
 		list = append(list, ref)
 	}
 
-	return list, nil
+	return list, rows.Err()
 }
 
+// SetPoints menyimpan total poin hasil recalculate (sort GET /achievements)
+func (r *AchievementPGRepository) SetPoints(ctx context.Context, mongoID string, points int) error {
+	_, err := database.DB.Exec(ctx, `
+		UPDATE achievement_references
+		SET points = $2
+		WHERE mongo_achievement_id = $1 AND points <> $2
+	`, mongoID, points)
+	return err
+}
+
+// GET by mongo_achievement_id (hasil full-text search)
+func (r *AchievementPGRepository) GetByMongoIDs(ctx context.Context, mongoIDs []string) ([]model.AchievementReference, error) {
+	rows, err := database.DB.Query(ctx, `
+        SELECT id, student_id, mongo_achievement_id, status, status_version
+        FROM achievement_references
+        WHERE mongo_achievement_id = ANY($1)
+    `, mongoIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []model.AchievementReference{}
+
+	for rows.Next() {
+		var ref model.AchievementReference
+		if err := rows.Scan(&ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.Status, &ref.StatusVersion); err != nil {
+			return nil, err
+		}
+		list = append(list, ref)
+	}
+
+	return list, rows.Err()
+}
+
+// ⭐ LIST dengan filter + sort + pagination (GET /achievements)
+func (r *AchievementPGRepository) List(ctx context.Context, q model.AchievementListQuery) ([]model.AchievementReference, int, error) {
+	where := []string{"1=1"}
+	args := []interface{}{}
+
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if q.ScopeStudentIDs != nil {
+		where = append(where, "ar.student_id = ANY("+arg(q.ScopeStudentIDs)+")")
+	}
+	if q.StudentID != "" {
+		where = append(where, "ar.student_id = "+arg(q.StudentID))
+	}
+	if len(q.Status) > 0 {
+		where = append(where, "ar.status::text = ANY("+arg(q.Status)+")")
+	} else {
+		where = append(where, "ar.status <> 'deleted'") // hanya jika diminta eksplisit
+	}
+	if q.ProgramStudy != "" {
+		where = append(where, "s.program_study = "+arg(q.ProgramStudy))
+	}
+	if q.From != nil {
+		where = append(where, "ar.created_at >= "+arg(*q.From))
+	}
+	if q.To != nil {
+		where = append(where, "ar.created_at < "+arg(*q.To))
+	}
+	if q.MongoIDs != nil {
+		where = append(where, "ar.mongo_achievement_id = ANY("+arg(q.MongoIDs)+")")
+	}
+
+	from := `
+		FROM achievement_references ar
+		JOIN students s ON s.id = ar.student_id
+		WHERE ` + strings.Join(where, " AND ")
+
+	var total int
+	if err := database.DB.QueryRow(ctx, "SELECT COUNT(*) "+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	dir := "DESC"
+	if q.Order == "asc" {
+		dir = "ASC"
+	}
+
+	var orderBy string
+	switch q.Sort {
+	case "submitted_at":
+		orderBy = "ar.submitted_at " + dir + " NULLS LAST, ar.created_at DESC"
+	case "points":
+		orderBy = "ar.points " + dir + ", ar.created_at DESC"
+	default:
+		orderBy = "ar.created_at " + dir
+	}
+
+	query := `
+		SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status,
+		       ar.submitted_at, ar.created_at, ar.updated_at
+	` + from + `
+		ORDER BY ` + orderBy + `
+		LIMIT ` + arg(q.Limit) + ` OFFSET ` + arg(q.Offset())
+
+	rows, err := database.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []model.AchievementReference{}
+
+	for rows.Next() {
+		var ref model.AchievementReference
+		if err := rows.Scan(
+			&ref.ID,
+			&ref.StudentID,
+			&ref.MongoAchievementID,
+			&ref.Status,
+			&ref.SubmittedAt,
+			&ref.CreatedAt,
+			&ref.UpdatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, ref)
+	}
+
+	return list, total, rows.Err()
+}
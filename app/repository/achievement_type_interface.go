@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"backenduas/app/model"
+)
+
+type IAchievementTypeRepository interface {
+	GetAll(ctx context.Context, includeInactive bool) ([]model.AchievementType, error)
+	GetByCode(ctx context.Context, codeOrAlias string) (*model.AchievementType, error)
+	Create(ctx context.Context, t *model.AchievementType) error
+	Update(ctx context.Context, t *model.AchievementType) error
+	Deactivate(ctx context.Context, code string) error
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"backenduas/app/model"
+	"backenduas/database"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrAchievementTypeNotFound = errors.New("achievement type not found")
+
+type AchievementTypeRepository struct{}
+
+func NewAchievementTypeRepository() *AchievementTypeRepository {
+	return &AchievementTypeRepository{}
+}
+
+const achievementTypeColumns = `
+	id, code, name, description, aliases, fields, allow_additional, is_active, created_at, updated_at`
+
+func scanAchievementType(row pgx.Row) (*model.AchievementType, error) {
+	var t model.AchievementType
+	var fields []byte
+
+	if err := row.Scan(
+		&t.ID,
+		&t.Code,
+		&t.Name,
+		&t.Description,
+		&t.Aliases,
+		&fields,
+		&t.AllowAdditional,
+		&t.IsActive,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(fields, &t.Fields); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *AchievementTypeRepository) GetAll(ctx context.Context, includeInactive bool) ([]model.AchievementType, error) {
+	rows, err := database.DB.Query(ctx, `
+		SELECT `+achievementTypeColumns+`
+		FROM achievement_types
+		WHERE is_active OR $1
+		ORDER BY code
+	`, includeInactive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []model.AchievementType{}
+	for rows.Next() {
+		t, err := scanAchievementType(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *t)
+	}
+
+	return list, rows.Err()
+}
+
+// GetByCode mencari berdasarkan code atau salah satu alias (case-insensitive)
+func (r *AchievementTypeRepository) GetByCode(ctx context.Context, codeOrAlias string) (*model.AchievementType, error) {
+	row := database.DB.QueryRow(ctx, `
+		SELECT `+achievementTypeColumns+`
+		FROM achievement_types
+		WHERE code = LOWER($1) OR LOWER($1) = ANY(aliases)
+		LIMIT 1
+	`, codeOrAlias)
+
+	t, err := scanAchievementType(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAchievementTypeNotFound
+	}
+	return t, err
+}
+
+func (r *AchievementTypeRepository) Create(ctx context.Context, t *model.AchievementType) error {
+	fields, err := json.Marshal(t.Fields)
+	if err != nil {
+		return err
+	}
+
+	return database.DB.QueryRow(ctx, `
+		INSERT INTO achievement_types (code, name, description, aliases, fields, allow_additional, is_active)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)
+		RETURNING id, created_at, updated_at
+	`,
+		t.Code, t.Name, t.Description, t.Aliases, fields, t.AllowAdditional, t.IsActive,
+	).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+}
+
+func (r *AchievementTypeRepository) Update(ctx context.Context, t *model.AchievementType) error {
+	fields, err := json.Marshal(t.Fields)
+	if err != nil {
+		return err
+	}
+
+	err = database.DB.QueryRow(ctx, `
+		UPDATE achievement_types
+		SET name = $2, description = $3, aliases = $4, fields = $5,
+		    allow_additional = $6, is_active = $7, updated_at = NOW()
+		WHERE code = $1
+		RETURNING updated_at
+	`,
+		t.Code, t.Name, t.Description, t.Aliases, fields, t.AllowAdditional, t.IsActive,
+	).Scan(&t.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrAchievementTypeNotFound
+	}
+	return err
+}
+
+// Deactivate: soft delete — prestasi lama tetap merujuk code ini
+func (r *AchievementTypeRepository) Deactivate(ctx context.Context, code string) error {
+	tag, err := database.DB.Exec(ctx, `
+		UPDATE achievement_types SET is_active = FALSE, updated_at = NOW()
+		WHERE code = $1
+	`, code)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAchievementTypeNotFound
+	}
+	return nil
+}
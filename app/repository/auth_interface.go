@@ -12,7 +12,14 @@ type IAuthRepository interface {
 	FindByID(ctx context.Context, id string) (*model.User, error)
 	GetPermissionsByRole(ctx context.Context, roleID string) ([]string, error)
 
-	SaveRefreshToken(ctx context.Context, userID, token string, exp time.Time) error
-	IsRefreshTokenValid(ctx context.Context, token string) (bool, error)
-	DeleteRefreshToken(ctx context.Context, token string) error
+	// Sesi login + rotasi refresh token (token disimpan sebagai hash)
+	CreateSession(ctx context.Context, s *model.AuthSession, tokenHash string) error
+	// RotateRefreshToken menandai token lama terpakai dan menyimpan token baru
+	// dalam sesi yang sama. Token yang sudah pernah dipakai → ErrRefreshTokenReused
+	// (sesi pemilik token dikembalikan).
+	RotateRefreshToken(ctx context.Context, sessionID, oldHash, newHash string, exp time.Time) (*model.AuthSession, error)
+	RevokeSessionByToken(ctx context.Context, tokenHash, reason string) error
+	ListSessions(ctx context.Context, userID string) ([]model.AuthSession, error)
+	RevokeSession(ctx context.Context, userID, sessionID, reason string) error
+	RevokeUserSessions(ctx context.Context, userID, reason string) (int, error)
 }
@@ -7,6 +7,14 @@ import (
 	"backenduas/app/model"
 	"backenduas/database"
 	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token invalid")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+	ErrSessionNotFound     = errors.New("session not found")
 )
 
 type AuthRepository struct{}
@@ -127,33 +135,144 @@ func (r *AuthRepository) GetPermissionsByRole(ctx context.Context, roleID string
 	return permissions, nil
 }
 
-func (r *AuthRepository) SaveRefreshToken(ctx context.Context, userID, token string, exp time.Time) error {
-	_, err := database.DB.Exec(ctx, `
-		INSERT INTO refresh_tokens (user_id, token, expired_at)
-		VALUES ($1, $2, $3)
-	`, userID, token, exp)
-	return err
+// =====================================================
+//  SESI & REFRESH TOKEN (rotasi per sesi)
+// =====================================================
+
+func (r *AuthRepository) CreateSession(ctx context.Context, s *model.AuthSession, tokenHash string) error {
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO auth_sessions (id, user_id, user_agent, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, last_used_at
+	`, s.ID, s.UserID, s.UserAgent, s.IPAddress, s.ExpiresAt).Scan(&s.CreatedAt, &s.LastUsedAt)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO refresh_tokens (user_id, token, expired_at, session_id)
+		VALUES ($1, $2, $3, $4)
+	`, s.UserID, tokenHash, s.ExpiresAt, s.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *AuthRepository) RotateRefreshToken(ctx context.Context, sessionID, oldHash, newHash string, exp time.Time) (*model.AuthSession, error) {
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		s       model.AuthSession
+		usedAt  *time.Time
+		expired bool
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT s.id::text, s.user_id::text, s.user_agent, s.ip_address, s.created_at,
+		       s.last_used_at, s.expires_at, s.revoked_at,
+		       rt.used_at, rt.expired_at <= NOW() OR s.expires_at <= NOW()
+		FROM refresh_tokens rt
+		JOIN auth_sessions s ON s.id = rt.session_id
+		WHERE rt.token = $1
+		FOR UPDATE OF rt
+	`, oldHash).Scan(&s.ID, &s.UserID, &s.UserAgent, &s.IPAddress, &s.CreatedAt,
+		&s.LastUsedAt, &s.ExpiresAt, &s.RevokedAt, &usedAt, &expired)
+	if err == pgx.ErrNoRows {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.ID != sessionID || s.RevokedAt != nil || expired {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if usedAt != nil {
+		return &s, ErrRefreshTokenReused
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE refresh_tokens SET used_at = NOW() WHERE token = $1`, oldHash); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO refresh_tokens (user_id, token, expired_at, session_id)
+		VALUES ($1, $2, $3, $4)
+	`, s.UserID, newHash, exp, s.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.QueryRow(ctx, `
+		UPDATE auth_sessions SET last_used_at = NOW(), expires_at = $2
+		WHERE id = $1
+		RETURNING last_used_at, expires_at
+	`, s.ID, exp).Scan(&s.LastUsedAt, &s.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	return &s, tx.Commit(ctx)
 }
 
-func (r *AuthRepository) DeleteRefreshToken(ctx context.Context, token string) error {
+func (r *AuthRepository) RevokeSessionByToken(ctx context.Context, tokenHash, reason string) error {
 	_, err := database.DB.Exec(ctx, `
-		DELETE FROM refresh_tokens WHERE token = $1
-	`, token)
+		UPDATE auth_sessions SET revoked_at = NOW(), revoked_reason = $2
+		WHERE revoked_at IS NULL
+		  AND id = (SELECT session_id FROM refresh_tokens WHERE token = $1)
+	`, tokenHash, reason)
 	return err
 }
 
-func (r *AuthRepository) IsRefreshTokenValid(ctx context.Context, token string) (bool, error) {
-	row := database.DB.QueryRow(ctx, `
-		SELECT COUNT(*) FROM refresh_tokens
-		WHERE token = $1 AND expired_at > NOW()
-	`, token)
-
-	var count int
-	if err := row.Scan(&count); err != nil {
-		return false, err
+func (r *AuthRepository) ListSessions(ctx context.Context, userID string) ([]model.AuthSession, error) {
+	rows, err := database.DB.Query(ctx, `
+		SELECT id::text, user_id::text, user_agent, ip_address, created_at, last_used_at, expires_at
+		FROM auth_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	return count > 0, nil
+	list := []model.AuthSession{}
+	for rows.Next() {
+		var s model.AuthSession
+		if err := rows.Scan(&s.ID, &s.UserID, &s.UserAgent, &s.IPAddress, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt); err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
 }
 
+func (r *AuthRepository) RevokeSession(ctx context.Context, userID, sessionID, reason string) error {
+	tag, err := database.DB.Exec(ctx, `
+		UPDATE auth_sessions SET revoked_at = NOW(), revoked_reason = $3
+		WHERE id::text = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, sessionID, userID, reason)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
 
+func (r *AuthRepository) RevokeUserSessions(ctx context.Context, userID, reason string) (int, error) {
+	tag, err := database.DB.Exec(ctx, `
+		UPDATE auth_sessions SET revoked_at = NOW(), revoked_reason = $2
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID, reason)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
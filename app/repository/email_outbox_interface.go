@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backenduas/app/model"
+)
+
+type IEmailOutboxRepository interface {
+	Enqueue(ctx context.Context, job *model.EmailJob) error
+	// ClaimDue mengambil email yang jatuh tempo dan menundanya selama lease
+	// supaya tidak diambil worker lain selama sedang dikirim
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]model.EmailJob, error)
+	MarkSent(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id string, errMsg string, nextAttempt time.Time, dead bool) error
+}
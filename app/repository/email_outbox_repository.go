@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/database"
+)
+
+type EmailOutboxRepository struct{}
+
+func NewEmailOutboxRepository() *EmailOutboxRepository {
+	return &EmailOutboxRepository{}
+}
+
+func (r *EmailOutboxRepository) Enqueue(ctx context.Context, job *model.EmailJob) error {
+	var recipient *string
+	if job.RecipientUserID != "" {
+		recipient = &job.RecipientUserID
+	}
+
+	return database.DB.QueryRow(ctx, `
+		INSERT INTO email_outbox (recipient_user_id, to_address, template, subject, text_body, html_body)
+		VALUES ($1,$2,$3,$4,$5,$6)
+		RETURNING id, status, next_attempt_at, created_at
+	`,
+		recipient, job.ToAddress, job.Template, job.Subject, job.TextBody, job.HTMLBody,
+	).Scan(&job.ID, &job.Status, &job.NextAttemptAt, &job.CreatedAt)
+}
+
+// ClaimDue: SKIP LOCKED + geser next_attempt_at (lease) dalam satu statement
+func (r *EmailOutboxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]model.EmailJob, error) {
+	rows, err := database.DB.Query(ctx, `
+		UPDATE email_outbox SET next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, COALESCE(recipient_user_id::text, ''), to_address, template, subject,
+		          text_body, html_body, status, attempts, last_error, next_attempt_at, created_at, sent_at
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []model.EmailJob{}
+	for rows.Next() {
+		var j model.EmailJob
+		if err := rows.Scan(
+			&j.ID,
+			&j.RecipientUserID,
+			&j.ToAddress,
+			&j.Template,
+			&j.Subject,
+			&j.TextBody,
+			&j.HTMLBody,
+			&j.Status,
+			&j.Attempts,
+			&j.LastError,
+			&j.NextAttemptAt,
+			&j.CreatedAt,
+			&j.SentAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, j)
+	}
+
+	return list, rows.Err()
+}
+
+func (r *EmailOutboxRepository) MarkSent(ctx context.Context, id string) error {
+	_, err := database.DB.Exec(ctx, `
+		UPDATE email_outbox SET status = 'sent', sent_at = NOW(), attempts = attempts + 1, last_error = ''
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+func (r *EmailOutboxRepository) MarkFailed(ctx context.Context, id string, errMsg string, nextAttempt time.Time, dead bool) error {
+	status := model.EmailPending
+	if dead {
+		status = model.EmailDead
+	}
+
+	_, err := database.DB.Exec(ctx, `
+		UPDATE email_outbox SET status = $1, attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		WHERE id = $4
+	`, status, errMsg, nextAttempt, id)
+	return err
+}
@@ -0,0 +1,8 @@
+package repository
+
+import "context"
+
+type IJobRunRepository interface {
+	// Claim = true jika eksekusi (job, runKey) belum pernah diklaim instance mana pun
+	Claim(ctx context.Context, job, runKey string) (bool, error)
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"backenduas/database"
+)
+
+type JobRunRepository struct{}
+
+func NewJobRunRepository() *JobRunRepository {
+	return &JobRunRepository{}
+}
+
+func (r *JobRunRepository) Claim(ctx context.Context, job, runKey string) (bool, error) {
+	tag, err := database.DB.Exec(ctx, `
+		INSERT INTO scheduled_job_runs (job, run_key) VALUES ($1,$2)
+		ON CONFLICT DO NOTHING
+	`, job, runKey)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
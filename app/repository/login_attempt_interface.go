@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backenduas/app/model"
+)
+
+type ILoginAttemptRepository interface {
+	// Reserve menambah penghitung key sebelum password diperiksa, kecuali key
+	// sedang diblokir (penghitung tidak berubah). Penghitung yang gagal
+	// terakhir sebelum `window` dimulai ulang dari 1. Mengembalikan keadaan
+	// terbaru; BlockedUntil di masa depan = pemesanan ditolak.
+	Reserve(ctx context.Context, key string, window time.Duration) (model.LoginThrottle, error)
+	// Release membatalkan satu pemesanan; blocked_until dicabut jika sisa
+	// gagal tidak lebih dari `free`
+	Release(ctx context.Context, key string, free int) error
+	// Block memperpanjang blocked_until (tidak pernah memperpendek)
+	Block(ctx context.Context, key string, until time.Time) error
+	ResetThrottle(ctx context.Context, key string) error
+
+	// Audit login gagal
+	RecordAttempt(ctx context.Context, a *model.LoginAttempt) error
+	// AddThrottled menambah count baris "throttled" terbaru milik username + IP
+	// yang sama sejak `since`; false jika tidak ada (catat baris baru)
+	AddThrottled(ctx context.Context, a *model.LoginAttempt, since time.Time) (bool, error)
+	ListAttempts(ctx context.Context, f model.LoginAttemptFilter) ([]model.LoginAttempt, error)
+	// Purge menghapus audit sebelum `before` dan penghitung yang tidak gagal
+	// lagi selama `window` dan tidak sedang diblokir; hasil = jumlah audit terhapus
+	Purge(ctx context.Context, before time.Time, window time.Duration) (int64, error)
+}
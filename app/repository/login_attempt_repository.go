@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/database"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type LoginAttemptRepository struct{}
+
+func NewLoginAttemptRepository() *LoginAttemptRepository {
+	return &LoginAttemptRepository{}
+}
+
+func (r *LoginAttemptRepository) Reserve(ctx context.Context, key string, window time.Duration) (model.LoginThrottle, error) {
+	// satu UPSERT atomik: request paralel mendapat nilai failures berbeda.
+	// Kolom lama (login_throttles.*) dibaca sebelum diubah.
+	var t model.LoginThrottle
+	err := database.DB.QueryRow(ctx, `
+		INSERT INTO login_throttles (key, failures, last_failed_at)
+		VALUES ($1, 1, NOW())
+		ON CONFLICT (key) DO UPDATE
+		SET failures = CASE
+		        WHEN login_throttles.blocked_until > NOW() THEN login_throttles.failures
+		        WHEN login_throttles.last_failed_at < NOW() - make_interval(secs => $2) THEN 1
+		        ELSE login_throttles.failures + 1
+		    END,
+		    blocked_until = CASE
+		        WHEN login_throttles.blocked_until > NOW() THEN login_throttles.blocked_until
+		        WHEN login_throttles.last_failed_at < NOW() - make_interval(secs => $2) THEN NULL
+		        ELSE login_throttles.blocked_until
+		    END,
+		    last_failed_at = CASE
+		        WHEN login_throttles.blocked_until > NOW() THEN login_throttles.last_failed_at
+		        ELSE NOW()
+		    END
+		RETURNING key, failures, last_failed_at, blocked_until
+	`, key, window.Seconds()).Scan(&t.Key, &t.Failures, &t.LastFailedAt, &t.BlockedUntil)
+	return t, err
+}
+
+func (r *LoginAttemptRepository) Release(ctx context.Context, key string, free int) error {
+	_, err := database.DB.Exec(ctx, `
+		UPDATE login_throttles
+		SET failures = GREATEST(failures - 1, 0),
+		    blocked_until = CASE WHEN failures - 1 <= $2 THEN NULL ELSE blocked_until END
+		WHERE key = $1
+	`, key, free)
+	return err
+}
+
+func (r *LoginAttemptRepository) Block(ctx context.Context, key string, until time.Time) error {
+	_, err := database.DB.Exec(ctx, `
+		UPDATE login_throttles
+		SET blocked_until = GREATEST(COALESCE(blocked_until, $2), $2)
+		WHERE key = $1
+	`, key, until)
+	return err
+}
+
+func (r *LoginAttemptRepository) ResetThrottle(ctx context.Context, key string) error {
+	_, err := database.DB.Exec(ctx, `DELETE FROM login_throttles WHERE key = $1`, key)
+	return err
+}
+
+func (r *LoginAttemptRepository) RecordAttempt(ctx context.Context, a *model.LoginAttempt) error {
+	if a.Count < 1 {
+		a.Count = 1
+	}
+	return database.DB.QueryRow(ctx, `
+		INSERT INTO login_attempts (username, user_id, ip_address, user_agent, reason, attempt_count)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id::text, created_at
+	`, a.Username, a.UserID, a.IPAddress, a.UserAgent, a.Reason, a.Count).Scan(&a.ID, &a.CreatedAt)
+}
+
+func (r *LoginAttemptRepository) AddThrottled(ctx context.Context, a *model.LoginAttempt, since time.Time) (bool, error) {
+	err := database.DB.QueryRow(ctx, `
+		UPDATE login_attempts
+		SET attempt_count = attempt_count + 1, user_agent = $4, created_at = NOW()
+		WHERE id = (
+		    SELECT id FROM login_attempts
+		    WHERE reason = $1 AND LOWER(username) = LOWER($2) AND ip_address = $3 AND created_at >= $5
+		    ORDER BY created_at DESC
+		    LIMIT 1
+		)
+		RETURNING id::text, attempt_count, created_at
+	`, a.Reason, a.Username, a.IPAddress, a.UserAgent, since).Scan(&a.ID, &a.Count, &a.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *LoginAttemptRepository) Purge(ctx context.Context, before time.Time, window time.Duration) (int64, error) {
+	tag, err := database.DB.Exec(ctx, `DELETE FROM login_attempts WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	// penghitung yang akan dimulai ulang pun tidak perlu disimpan
+	if _, err := database.DB.Exec(ctx, `
+		DELETE FROM login_throttles
+		WHERE last_failed_at < NOW() - make_interval(secs => $1)
+		  AND (blocked_until IS NULL OR blocked_until < NOW())
+	`, window.Seconds()); err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *LoginAttemptRepository) ListAttempts(ctx context.Context, f model.LoginAttemptFilter) ([]model.LoginAttempt, error) {
+	where := []string{}
+	args := []interface{}{}
+	if f.Username != "" {
+		args = append(args, strings.ToLower(f.Username))
+		where = append(where, fmt.Sprintf("LOWER(username) = $%d", len(args)))
+	}
+	if f.IP != "" {
+		args = append(args, f.IP)
+		where = append(where, fmt.Sprintf("ip_address = $%d", len(args)))
+	}
+
+	query := `SELECT id::text, username, user_id::text, ip_address, user_agent, reason, attempt_count, created_at FROM login_attempts`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	args = append(args, f.Limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d", len(args))
+
+	rows, err := database.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []model.LoginAttempt{}
+	for rows.Next() {
+		var a model.LoginAttempt
+		if err := rows.Scan(&a.ID, &a.Username, &a.UserID, &a.IPAddress, &a.UserAgent, &a.Reason, &a.Count, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"backenduas/app/model"
+)
+
+type INotificationRepository interface {
+	Create(ctx context.Context, n *model.Notification) error
+	List(ctx context.Context, recipientID string, unreadOnly bool, limit, offset int) ([]model.Notification, int, error)
+	CountUnread(ctx context.Context, recipientID string) (int, error)
+	MarkRead(ctx context.Context, id, recipientID string) error
+	MarkAllRead(ctx context.Context, recipientID string) (int, error)
+}
+
+type INotificationPreferenceRepository interface {
+	// Get mengembalikan preferensi default jika user belum pernah menyimpan
+	Get(ctx context.Context, userID string) (model.NotificationPreference, error)
+	Upsert(ctx context.Context, p *model.NotificationPreference) error
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"backenduas/app/model"
+	"backenduas/database"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type NotificationPreferenceRepository struct{}
+
+func NewNotificationPreferenceRepository() *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{}
+}
+
+func (r *NotificationPreferenceRepository) Get(ctx context.Context, userID string) (model.NotificationPreference, error) {
+	p := model.DefaultNotificationPreference(userID)
+
+	err := database.DB.QueryRow(ctx, `
+		SELECT email_enabled, language, muted_types, updated_at
+		FROM notification_preferences
+		WHERE user_id = $1
+	`, userID).Scan(&p.EmailEnabled, &p.Language, &p.MutedTypes, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p, nil
+	}
+	return p, err
+}
+
+func (r *NotificationPreferenceRepository) Upsert(ctx context.Context, p *model.NotificationPreference) error {
+	return database.DB.QueryRow(ctx, `
+		INSERT INTO notification_preferences (user_id, email_enabled, language, muted_types, updated_at)
+		VALUES ($1,$2,$3,$4,NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET email_enabled = EXCLUDED.email_enabled,
+		    language = EXCLUDED.language,
+		    muted_types = EXCLUDED.muted_types,
+		    updated_at = NOW()
+		RETURNING updated_at
+	`, p.UserID, p.EmailEnabled, p.Language, p.MutedTypes).Scan(&p.UpdatedAt)
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"backenduas/app/model"
+	"backenduas/database"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+type NotificationRepository struct{}
+
+func NewNotificationRepository() *NotificationRepository {
+	return &NotificationRepository{}
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, n *model.Notification) error {
+	return database.DB.QueryRow(ctx, `
+		INSERT INTO notifications (recipient_user_id, type, title, message, achievement_id, link)
+		VALUES ($1,$2,$3,$4,$5,$6)
+		RETURNING id, created_at
+	`,
+		n.RecipientID, n.Type, n.Title, n.Message, n.AchievementID, n.Link,
+	).Scan(&n.ID, &n.CreatedAt)
+}
+
+// List: terbaru dulu, total = jumlah seluruh baris yang cocok (untuk paginasi)
+func (r *NotificationRepository) List(
+	ctx context.Context,
+	recipientID string,
+	unreadOnly bool,
+	limit, offset int,
+) ([]model.Notification, int, error) {
+
+	var total int
+	if err := database.DB.QueryRow(ctx, `
+		SELECT COUNT(*) FROM notifications
+		WHERE recipient_user_id = $1 AND (NOT $2 OR NOT is_read)
+	`, recipientID, unreadOnly).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := database.DB.Query(ctx, `
+		SELECT id, recipient_user_id, type, title, message, achievement_id::text,
+		       link, is_read, read_at, created_at
+		FROM notifications
+		WHERE recipient_user_id = $1 AND (NOT $2 OR NOT is_read)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`, recipientID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []model.Notification{}
+	for rows.Next() {
+		var n model.Notification
+		if err := rows.Scan(
+			&n.ID,
+			&n.RecipientID,
+			&n.Type,
+			&n.Title,
+			&n.Message,
+			&n.AchievementID,
+			&n.Link,
+			&n.IsRead,
+			&n.ReadAt,
+			&n.CreatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, n)
+	}
+
+	return list, total, rows.Err()
+}
+
+func (r *NotificationRepository) CountUnread(ctx context.Context, recipientID string) (int, error) {
+	var n int
+	err := database.DB.QueryRow(ctx, `
+		SELECT COUNT(*) FROM notifications
+		WHERE recipient_user_id = $1 AND NOT is_read
+	`, recipientID).Scan(&n)
+	return n, err
+}
+
+// MarkRead hanya untuk notifikasi milik penerima sendiri
+func (r *NotificationRepository) MarkRead(ctx context.Context, id, recipientID string) error {
+	tag, err := database.DB.Exec(ctx, `
+		UPDATE notifications
+		SET is_read = TRUE, read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND recipient_user_id = $2
+	`, id, recipientID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, recipientID string) (int, error) {
+	tag, err := database.DB.Exec(ctx, `
+		UPDATE notifications
+		SET is_read = TRUE, read_at = NOW()
+		WHERE recipient_user_id = $1 AND NOT is_read
+	`, recipientID)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"backenduas/app/model"
+)
+
+type IPointRuleRepository interface {
+	GetAll(ctx context.Context, includeInactive bool) ([]model.PointRule, error)
+	GetByID(ctx context.Context, id string) (*model.PointRule, error)
+	Create(ctx context.Context, r *model.PointRule) error
+	Update(ctx context.Context, r *model.PointRule) error
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"backenduas/app/model"
+	"backenduas/database"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrPointRuleNotFound = errors.New("point rule not found")
+
+type PointRuleRepository struct{}
+
+func NewPointRuleRepository() *PointRuleRepository {
+	return &PointRuleRepository{}
+}
+
+const pointRuleColumns = `
+	id, name, achievement_type, kind, conditions, points, factor, priority, is_active, created_at, updated_at`
+
+func scanPointRule(row pgx.Row) (*model.PointRule, error) {
+	var r model.PointRule
+	var conditions []byte
+
+	if err := row.Scan(
+		&r.ID,
+		&r.Name,
+		&r.AchievementType,
+		&r.Kind,
+		&conditions,
+		&r.Points,
+		&r.Factor,
+		&r.Priority,
+		&r.IsActive,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(conditions, &r.Conditions); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (r *PointRuleRepository) GetAll(ctx context.Context, includeInactive bool) ([]model.PointRule, error) {
+	rows, err := database.DB.Query(ctx, `
+		SELECT `+pointRuleColumns+`
+		FROM point_rules
+		WHERE is_active OR $1
+		ORDER BY achievement_type, kind, priority DESC, name
+	`, includeInactive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []model.PointRule{}
+	for rows.Next() {
+		rule, err := scanPointRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *rule)
+	}
+
+	return list, rows.Err()
+}
+
+func (r *PointRuleRepository) GetByID(ctx context.Context, id string) (*model.PointRule, error) {
+	row := database.DB.QueryRow(ctx, `
+		SELECT `+pointRuleColumns+`
+		FROM point_rules
+		WHERE id = $1
+	`, id)
+
+	rule, err := scanPointRule(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPointRuleNotFound
+	}
+	return rule, err
+}
+
+func (r *PointRuleRepository) Create(ctx context.Context, rule *model.PointRule) error {
+	conditions, err := json.Marshal(rule.Conditions)
+	if err != nil {
+		return err
+	}
+
+	return database.DB.QueryRow(ctx, `
+		INSERT INTO point_rules (name, achievement_type, kind, conditions, points, factor, priority, is_active)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+		RETURNING id, created_at, updated_at
+	`,
+		rule.Name, rule.AchievementType, rule.Kind, conditions,
+		rule.Points, rule.Factor, rule.Priority, rule.IsActive,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+}
+
+func (r *PointRuleRepository) Update(ctx context.Context, rule *model.PointRule) error {
+	conditions, err := json.Marshal(rule.Conditions)
+	if err != nil {
+		return err
+	}
+
+	err = database.DB.QueryRow(ctx, `
+		UPDATE point_rules
+		SET name = $2, achievement_type = $3, kind = $4, conditions = $5,
+		    points = $6, factor = $7, priority = $8, is_active = $9, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`,
+		rule.ID, rule.Name, rule.AchievementType, rule.Kind, conditions,
+		rule.Points, rule.Factor, rule.Priority, rule.IsActive,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrPointRuleNotFound
+	}
+	return err
+}
+
+func (r *PointRuleRepository) Delete(ctx context.Context, id string) error {
+	tag, err := database.DB.Exec(ctx, `DELETE FROM point_rules WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPointRuleNotFound
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"backenduas/app/model"
+)
+
+type IRBACRepository interface {
+	ListRoles(ctx context.Context) ([]model.Role, error)
+	GetRole(ctx context.Context, id string) (model.Role, error)
+	CreateRole(ctx context.Context, r *model.Role) error
+	UpdateRole(ctx context.Context, r *model.Role) error
+	DeleteRole(ctx context.Context, id string) error
+
+	ListPermissions(ctx context.Context) ([]model.Permission, error)
+	GetPermission(ctx context.Context, id string) (model.Permission, error)
+	CreatePermission(ctx context.Context, p *model.Permission) error
+	UpdatePermission(ctx context.Context, p *model.Permission) error
+	DeletePermission(ctx context.Context, id string) error
+
+	// SetRolePermissions mengganti seluruh permission role (nama permission)
+	SetRolePermissions(ctx context.Context, roleID string, names []string) error
+	PermissionsByRole(ctx context.Context, roleID string) ([]string, error)
+
+	// SeedPermissions menambah permission yang belum ada dan memberikannya ke
+	// role bawaan (grants: nama permission → nama role). Permission yang sudah
+	// ada tidak diubah, sehingga perubahan Admin tidak tertimpa saat restart.
+	SeedPermissions(ctx context.Context, perms []model.Permission, grants map[string][]string) ([]string, error)
+}
@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"backenduas/app/model"
+	"backenduas/database"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrRoleNotFound       = errors.New("role not found")
+	ErrPermissionNotFound = errors.New("permission not found")
+)
+
+type RBACRepository struct{}
+
+func NewRBACRepository() *RBACRepository {
+	return &RBACRepository{}
+}
+
+// splitPermission: "achievement:verify" → ("achievement", "verify")
+func splitPermission(name string) (string, string) {
+	resource, action, _ := strings.Cut(name, ":")
+	return resource, action
+}
+
+const roleSelect = `
+	SELECT r.id::text, r.name, COALESCE(r.description, ''),
+	       COALESCE(ARRAY(
+	           SELECT p.name FROM role_permissions rp
+	           JOIN permissions p ON p.id = rp.permission_id
+	           WHERE rp.role_id = r.id ORDER BY p.name
+	       ), '{}'),
+	       (SELECT COUNT(*) FROM users u WHERE u.role_id = r.id)::int,
+	       r.created_at
+	FROM roles r
+`
+
+func scanRole(row pgx.Row) (model.Role, error) {
+	var role model.Role
+	err := row.Scan(&role.ID, &role.Name, &role.Description, &role.Permissions, &role.Users, &role.CreatedAt)
+	return role, err
+}
+
+func (r *RBACRepository) ListRoles(ctx context.Context) ([]model.Role, error) {
+	rows, err := database.DB.Query(ctx, roleSelect+` ORDER BY r.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []model.Role{}
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, role)
+	}
+	return list, rows.Err()
+}
+
+func (r *RBACRepository) GetRole(ctx context.Context, id string) (model.Role, error) {
+	role, err := scanRole(database.DB.QueryRow(ctx, roleSelect+` WHERE r.id::text = $1`, id))
+	if err == pgx.ErrNoRows {
+		return role, ErrRoleNotFound
+	}
+	return role, err
+}
+
+func (r *RBACRepository) CreateRole(ctx context.Context, role *model.Role) error {
+	return database.DB.QueryRow(ctx, `
+		INSERT INTO roles (id, name, description) VALUES (gen_random_uuid(), $1, $2)
+		RETURNING id::text
+	`, role.Name, role.Description).Scan(&role.ID)
+}
+
+func (r *RBACRepository) UpdateRole(ctx context.Context, role *model.Role) error {
+	tag, err := database.DB.Exec(ctx, `
+		UPDATE roles SET name = $1, description = $2 WHERE id::text = $3
+	`, role.Name, role.Description, role.ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRoleNotFound
+	}
+	return nil
+}
+
+func (r *RBACRepository) DeleteRole(ctx context.Context, id string) error {
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM role_permissions WHERE role_id::text = $1`, id); err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM roles WHERE id::text = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRoleNotFound
+	}
+	return tx.Commit(ctx)
+}
+
+const permissionSelect = `
+	SELECT id::text, name, COALESCE(resource, ''), COALESCE(action, ''), COALESCE(description, '')
+	FROM permissions
+`
+
+func scanPermission(row pgx.Row) (model.Permission, error) {
+	var p model.Permission
+	err := row.Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description)
+	return p, err
+}
+
+func (r *RBACRepository) ListPermissions(ctx context.Context) ([]model.Permission, error) {
+	rows, err := database.DB.Query(ctx, permissionSelect+` ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []model.Permission{}
+	for rows.Next() {
+		p, err := scanPermission(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+func (r *RBACRepository) GetPermission(ctx context.Context, id string) (model.Permission, error) {
+	p, err := scanPermission(database.DB.QueryRow(ctx, permissionSelect+` WHERE id::text = $1`, id))
+	if err == pgx.ErrNoRows {
+		return p, ErrPermissionNotFound
+	}
+	return p, err
+}
+
+func (r *RBACRepository) CreatePermission(ctx context.Context, p *model.Permission) error {
+	p.Resource, p.Action = splitPermission(p.Name)
+	return database.DB.QueryRow(ctx, `
+		INSERT INTO permissions (id, name, resource, action, description)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4)
+		RETURNING id::text
+	`, p.Name, p.Resource, p.Action, p.Description).Scan(&p.ID)
+}
+
+func (r *RBACRepository) UpdatePermission(ctx context.Context, p *model.Permission) error {
+	p.Resource, p.Action = splitPermission(p.Name)
+	tag, err := database.DB.Exec(ctx, `
+		UPDATE permissions SET name = $1, resource = $2, action = $3, description = $4
+		WHERE id::text = $5
+	`, p.Name, p.Resource, p.Action, p.Description, p.ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPermissionNotFound
+	}
+	return nil
+}
+
+func (r *RBACRepository) DeletePermission(ctx context.Context, id string) error {
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM role_permissions WHERE permission_id::text = $1`, id); err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM permissions WHERE id::text = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPermissionNotFound
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *RBACRepository) SetRolePermissions(ctx context.Context, roleID string, names []string) error {
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM roles WHERE id::text = $1)`, roleID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrRoleNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM role_permissions WHERE role_id::text = $1`, roleID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO role_permissions (role_id, permission_id)
+		SELECT r.id, p.id FROM roles r, permissions p
+		WHERE r.id::text = $1 AND p.name = ANY($2)
+	`, roleID, names); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *RBACRepository) PermissionsByRole(ctx context.Context, roleID string) ([]string, error) {
+	rows, err := database.DB.Query(ctx, `
+		SELECT p.name
+		FROM role_permissions rp
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE rp.role_id::text = $1
+		ORDER BY p.name
+	`, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	perms := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		perms = append(perms, name)
+	}
+	return perms, rows.Err()
+}
+
+func (r *RBACRepository) SeedPermissions(ctx context.Context, perms []model.Permission, grants map[string][]string) ([]string, error) {
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	inserted := []string{}
+	for _, p := range perms {
+		resource, action := splitPermission(p.Name)
+
+		var id string
+		err := tx.QueryRow(ctx, `
+			INSERT INTO permissions (id, name, resource, action, description)
+			SELECT gen_random_uuid(), $1, $2, $3, $4
+			WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE name = $1)
+			RETURNING id::text
+		`, p.Name, resource, action, p.Description).Scan(&id)
+		if err == pgx.ErrNoRows {
+			continue // sudah ada → dibiarkan seperti yang diatur Admin
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO role_permissions (role_id, permission_id)
+			SELECT r.id, $1::uuid FROM roles r WHERE r.name = ANY($2)
+		`, id, grants[p.Name]); err != nil {
+			return nil, err
+		}
+		inserted = append(inserted, p.Name)
+	}
+
+	return inserted, tx.Commit(ctx)
+}
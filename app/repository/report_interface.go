@@ -14,6 +14,7 @@ type IReportPGRepository interface {
 
 type IReportMongoRepository interface {
 	FindById(ctx context.Context, id primitive.ObjectID) (model.AchievementMongo, error)
+	FindStatsByIDs(ctx context.Context, ids []primitive.ObjectID) (map[string]model.AchievementMongo, error)
 }
 
 type IReportStudentRepository interface {
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backenduas/app/model"
+)
+
+type ISLARepository interface {
+	GetSettings(ctx context.Context) (model.SLASettings, error)
+	UpdateSettings(ctx context.Context, s *model.SLASettings) error
+
+	// ListOverdue = prestasi submitted dengan submitted_at < cutoff, terlama dulu
+	ListOverdue(ctx context.Context, cutoff time.Time) ([]model.SLABreachItem, error)
+	// RecordBreach mengembalikan false jika putaran submit ini sudah pernah dicatat
+	RecordBreach(ctx context.Context, b model.SLABreach) (bool, error)
+
+	// GetBackupLecturer mengembalikan "" jika dosen belum punya cadangan
+	GetBackupLecturer(ctx context.Context, lecturerID string) (string, error)
+	SetBackupLecturer(ctx context.Context, lecturerID string, backupID *string) error
+	AssignReviewer(ctx context.Context, achievementID, lecturerID string) error
+
+	// ListTurnarounds = review (verified / rejected) oleh dosen dalam [from, to)
+	ListTurnarounds(ctx context.Context, from, to time.Time) ([]model.ReviewTurnaround, error)
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/database"
+)
+
+type SLARepository struct{}
+
+func NewSLARepository() *SLARepository {
+	return &SLARepository{}
+}
+
+func (r *SLARepository) GetSettings(ctx context.Context) (model.SLASettings, error) {
+	var s model.SLASettings
+	err := database.DB.QueryRow(ctx, `
+		SELECT review_days, action, updated_at FROM sla_settings WHERE id
+	`).Scan(&s.ReviewDays, &s.Action, &s.UpdatedAt)
+	return s, err
+}
+
+func (r *SLARepository) UpdateSettings(ctx context.Context, s *model.SLASettings) error {
+	return database.DB.QueryRow(ctx, `
+		INSERT INTO sla_settings (id, review_days, action, updated_at)
+		VALUES (TRUE, $1, $2, NOW())
+		ON CONFLICT (id) DO UPDATE
+		SET review_days = EXCLUDED.review_days, action = EXCLUDED.action, updated_at = NOW()
+		RETURNING updated_at
+	`, s.ReviewDays, s.Action).Scan(&s.UpdatedAt)
+}
+
+func (r *SLARepository) ListOverdue(ctx context.Context, cutoff time.Time) ([]model.SLABreachItem, error) {
+	rows, err := database.DB.Query(ctx, `
+		SELECT ar.id, s.id, s.student_id, su.full_name,
+		       COALESCE(l.id::text, ''), COALESCE(lu.full_name, ''),
+		       ar.assigned_reviewer_id::text, ar.submitted_at,
+		       COALESCE(b.action, ''), b.breached_at
+		FROM achievement_references ar
+		JOIN students s ON s.id = ar.student_id
+		JOIN users su ON su.id = s.user_id
+		LEFT JOIN lecturers l ON l.id = s.advisor_id
+		LEFT JOIN users lu ON lu.id = l.user_id
+		LEFT JOIN sla_breaches b ON b.achievement_id = ar.id AND b.submitted_at = ar.submitted_at
+		WHERE ar.status = 'submitted' AND ar.submitted_at < $1
+		ORDER BY ar.submitted_at ASC
+	`, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.SLABreachItem{}
+	for rows.Next() {
+		var it model.SLABreachItem
+		if err := rows.Scan(
+			&it.AchievementID, &it.StudentID, &it.StudentNIM, &it.StudentName,
+			&it.LecturerID, &it.LecturerName,
+			&it.ReviewerID, &it.SubmittedAt,
+			&it.Action, &it.BreachedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+func (r *SLARepository) RecordBreach(ctx context.Context, b model.SLABreach) (bool, error) {
+	var lecturer, reassigned interface{}
+	if b.LecturerID != "" {
+		lecturer = b.LecturerID
+	}
+	if b.ReassignedTo != "" {
+		reassigned = b.ReassignedTo
+	}
+
+	tag, err := database.DB.Exec(ctx, `
+		INSERT INTO sla_breaches (achievement_id, submitted_at, lecturer_id, action, reassigned_to)
+		VALUES ($1,$2,$3,$4,$5)
+		ON CONFLICT DO NOTHING
+	`, b.AchievementID, b.SubmittedAt, lecturer, b.Action, reassigned)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *SLARepository) GetBackupLecturer(ctx context.Context, lecturerID string) (string, error) {
+	var backup string
+	err := database.DB.QueryRow(ctx, `
+		SELECT COALESCE(backup_lecturer_id::text, '') FROM lecturers WHERE id = $1
+	`, lecturerID).Scan(&backup)
+	return backup, err
+}
+
+func (r *SLARepository) SetBackupLecturer(ctx context.Context, lecturerID string, backupID *string) error {
+	tag, err := database.DB.Exec(ctx, `
+		UPDATE lecturers SET backup_lecturer_id = $2 WHERE id = $1
+	`, lecturerID, backupID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("lecturer not found")
+	}
+	return nil
+}
+
+func (r *SLARepository) AssignReviewer(ctx context.Context, achievementID, lecturerID string) error {
+	_, err := database.DB.Exec(ctx, `
+		UPDATE achievement_references
+		SET assigned_reviewer_id = $2, updated_at = NOW()
+		WHERE id = $1 AND status = 'submitted'
+	`, achievementID, lecturerID)
+	return err
+}
+
+// Waktu review = event verified / rejected dikurangi event submitted terakhir sebelumnya.
+// Review oleh Admin (bukan dosen) tidak dihitung.
+func (r *SLARepository) ListTurnarounds(ctx context.Context, from, to time.Time) ([]model.ReviewTurnaround, error) {
+	rows, err := database.DB.Query(ctx, `
+		SELECT l.id, u.full_name, h.to_status, sub.created_at, h.created_at
+		FROM achievement_histories h
+		JOIN lecturers l ON l.user_id = h.actor_user_id
+		JOIN users u ON u.id = l.user_id
+		JOIN LATERAL (
+			SELECT s.created_at
+			FROM achievement_histories s
+			WHERE s.achievement_id = h.achievement_id
+			  AND s.to_status = 'submitted'
+			  AND s.created_at <= h.created_at
+			ORDER BY s.created_at DESC
+			LIMIT 1
+		) sub ON TRUE
+		WHERE h.to_status IN ('verified', 'rejected')
+		  AND h.created_at >= $1 AND h.created_at < $2
+	`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []model.ReviewTurnaround{}
+	for rows.Next() {
+		var t model.ReviewTurnaround
+		if err := rows.Scan(&t.LecturerID, &t.LecturerName, &t.ToStatus, &t.SubmittedAt, &t.ReviewedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+	}
+	return result, rows.Err()
+}
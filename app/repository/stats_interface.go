@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backenduas/app/model"
+)
+
+type IStatsRepository interface {
+	// ApplyFact mengganti kontribusi satu prestasi (nil = hapus) dan
+	// menyesuaikan counter dengan selisih fakta lama → baru dalam satu transaksi
+	ApplyFact(ctx context.Context, achievementID string, fact *model.StatsFact) error
+	// Rebuild mengunci tabel statistik, mengambil snapshot fakta, lalu
+	// mengosongkan dan mengisi ulang seluruh fakta & counter dalam satu
+	// transaksi. ApplyFact yang datang selama rebuild menunggu kunci.
+	Rebuild(ctx context.Context, snapshot func(ctx context.Context) ([]model.StatsFact, error)) (time.Time, error)
+	// RebuiltAt = nil jika tabel belum pernah dibangun
+	RebuiltAt(ctx context.Context) (*time.Time, error)
+
+	CountTypes(ctx context.Context, q model.StatsQuery) ([]model.StatsCount, error)
+	CountTags(ctx context.Context, q model.StatsQuery) ([]model.StatsCount, error)
+}
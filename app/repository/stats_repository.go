@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/database"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type StatsRepository struct{}
+
+func NewStatsRepository() *StatsRepository {
+	return &StatsRepository{}
+}
+
+// addCounts menambah (delta +1) atau mengurangi (delta -1) counter milik satu fakta
+func addCounts(ctx context.Context, tx pgx.Tx, f model.StatsFact, delta int) error {
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO achievement_stat_types (student_id, status, achievement_type, month, total)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (student_id, status, achievement_type, month)
+		DO UPDATE SET total = achievement_stat_types.total + EXCLUDED.total
+	`, f.StudentID, f.Status, f.AchievementType, f.Month, delta); err != nil {
+		return err
+	}
+
+	for _, tag := range f.Tags {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO achievement_stat_tags (student_id, status, tag, month, total)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (student_id, status, tag, month)
+			DO UPDATE SET total = achievement_stat_tags.total + EXCLUDED.total
+		`, f.StudentID, f.Status, tag, f.Month, delta); err != nil {
+			return err
+		}
+	}
+
+	if delta < 0 {
+		if _, err := tx.Exec(ctx, `DELETE FROM achievement_stat_types WHERE student_id = $1 AND total <= 0`, f.StudentID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM achievement_stat_tags WHERE student_id = $1 AND total <= 0`, f.StudentID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *StatsRepository) ApplyFact(ctx context.Context, achievementID string, fact *model.StatsFact) error {
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// dua ApplyFact untuk prestasi baru sama-sama tidak menemukan baris fakta
+	// (FOR UPDATE tidak mengunci baris yang belum ada) → serialkan per prestasi
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, achievementID); err != nil {
+		return err
+	}
+
+	old := model.StatsFact{AchievementID: achievementID}
+	err = tx.QueryRow(ctx, `
+		SELECT student_id, status, achievement_type, tags, month
+		FROM achievement_stat_facts WHERE achievement_id = $1
+		FOR UPDATE
+	`, achievementID).Scan(&old.StudentID, &old.Status, &old.AchievementType, &old.Tags, &old.Month)
+	switch {
+	case err == nil:
+		if err := addCounts(ctx, tx, old, -1); err != nil {
+			return err
+		}
+	case err != pgx.ErrNoRows:
+		return err
+	}
+
+	if fact == nil {
+		if _, err := tx.Exec(ctx, `DELETE FROM achievement_stat_facts WHERE achievement_id = $1`, achievementID); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO achievement_stat_facts (achievement_id, student_id, status, achievement_type, tags, month)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (achievement_id) DO UPDATE
+		SET student_id = EXCLUDED.student_id, status = EXCLUDED.status,
+		    achievement_type = EXCLUDED.achievement_type, tags = EXCLUDED.tags, month = EXCLUDED.month
+	`, achievementID, fact.StudentID, fact.Status, fact.AchievementType, fact.Tags, fact.Month); err != nil {
+		return err
+	}
+	if err := addCounts(ctx, tx, *fact, 1); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *StatsRepository) Rebuild(ctx context.Context, snapshot func(ctx context.Context) ([]model.StatsFact, error)) (time.Time, error) {
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// kunci sebelum snapshot: ApplyFact yang terjadi setelah snapshot dibaca
+	// menunggu sampai rebuild selesai, bukan tertimpa TRUNCATE. Laporan tetap
+	// bisa membaca tabel lama sampai TRUNCATE.
+	if _, err := tx.Exec(ctx, `LOCK TABLE achievement_stat_facts, achievement_stat_types, achievement_stat_tags IN EXCLUSIVE MODE`); err != nil {
+		return time.Time{}, err
+	}
+
+	facts, err := snapshot(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if _, err := tx.Exec(ctx, `TRUNCATE achievement_stat_facts, achievement_stat_types, achievement_stat_tags`); err != nil {
+		return time.Time{}, err
+	}
+
+	rows := make([][]interface{}, len(facts))
+	for i, f := range facts {
+		rows[i] = []interface{}{f.AchievementID, f.StudentID, f.Status, f.AchievementType, f.Tags, f.Month}
+	}
+	if _, err := tx.CopyFrom(ctx,
+		pgx.Identifier{"achievement_stat_facts"},
+		[]string{"achievement_id", "student_id", "status", "achievement_type", "tags", "month"},
+		pgx.CopyFromRows(rows),
+	); err != nil {
+		return time.Time{}, err
+	}
+
+	// counter dihitung ulang dari fakta dengan GROUP BY
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO achievement_stat_types (student_id, status, achievement_type, month, total)
+		SELECT student_id, status, achievement_type, month, COUNT(*)
+		FROM achievement_stat_facts
+		GROUP BY student_id, status, achievement_type, month
+	`); err != nil {
+		return time.Time{}, err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO achievement_stat_tags (student_id, status, tag, month, total)
+		SELECT student_id, status, tag, month, COUNT(*)
+		FROM achievement_stat_facts, UNNEST(tags) AS tag
+		GROUP BY student_id, status, tag, month
+	`); err != nil {
+		return time.Time{}, err
+	}
+
+	var rebuiltAt time.Time
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO achievement_stat_meta (id, rebuilt_at) VALUES (TRUE, NOW())
+		ON CONFLICT (id) DO UPDATE SET rebuilt_at = NOW()
+		RETURNING rebuilt_at
+	`).Scan(&rebuiltAt); err != nil {
+		return time.Time{}, err
+	}
+
+	return rebuiltAt, tx.Commit(ctx)
+}
+
+func (r *StatsRepository) RebuiltAt(ctx context.Context) (*time.Time, error) {
+	var at *time.Time
+	err := database.DB.QueryRow(ctx, `SELECT rebuilt_at FROM achievement_stat_meta WHERE id`).Scan(&at)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return at, err
+}
+
+// statsWhere menyusun WHERE untuk tabel counter / fakta
+func statsWhere(q model.StatsQuery) (string, []interface{}) {
+	args := []interface{}{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	where := []string{"student_id = ANY(" + arg(q.StudentIDs) + ")"}
+	if q.Statuses != nil {
+		where = append(where, "status = ANY("+arg(q.Statuses)+")")
+	} else {
+		where = append(where, "status <> 'deleted'")
+	}
+	if q.AchievementType != "" {
+		where = append(where, "LOWER(achievement_type) = LOWER("+arg(q.AchievementType)+")")
+	}
+	if q.From != nil {
+		where = append(where, "month >= "+arg(*q.From))
+	}
+	if q.To != nil {
+		where = append(where, "month < "+arg(*q.To))
+	}
+	return strings.Join(where, " AND "), args
+}
+
+func scanCounts(rows pgx.Rows) ([]model.StatsCount, error) {
+	defer rows.Close()
+
+	list := []model.StatsCount{}
+	for rows.Next() {
+		var c model.StatsCount
+		if err := rows.Scan(&c.StudentID, &c.Key, &c.Month, &c.Total); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+func (r *StatsRepository) CountTypes(ctx context.Context, q model.StatsQuery) ([]model.StatsCount, error) {
+	where, args := statsWhere(q)
+	rows, err := database.DB.Query(ctx, `
+		SELECT student_id::text, achievement_type, month, SUM(total)::int
+		FROM achievement_stat_types
+		WHERE `+where+`
+		GROUP BY student_id, achievement_type, month
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanCounts(rows)
+}
+
+// CountTags: tabel counter tag tidak menyimpan jenis prestasi, jadi filter jenis dibaca dari fakta
+func (r *StatsRepository) CountTags(ctx context.Context, q model.StatsQuery) ([]model.StatsCount, error) {
+	where, args := statsWhere(q)
+
+	query := `
+		SELECT student_id::text, tag, month, SUM(total)::int
+		FROM achievement_stat_tags
+		WHERE ` + where + `
+		GROUP BY student_id, tag, month
+	`
+	if q.AchievementType != "" {
+		query = `
+			SELECT student_id::text, tag, month, COUNT(*)::int
+			FROM achievement_stat_facts, UNNEST(tags) AS tag
+			WHERE ` + where + `
+			GROUP BY student_id, tag, month
+		`
+	}
+
+	rows, err := database.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanCounts(rows)
+}
@@ -12,6 +12,7 @@ type IStudentRepository interface {
 	UpdateAdvisor(ctx context.Context, studentID string, advisorID *string) error
 	GetStudentIDByUserID(ctx context.Context, userID string) (string, error)
 	GetLecturerIDByUserID(ctx context.Context, userID string) (string, error)
+	GetUserIDByLecturerID(ctx context.Context, lecturerID string) (string, error)
 	IsStudentUnderAdvisor(ctx context.Context, studentID, lecturerID string) (bool, error)
 
 	GetAllStudentIDs(ctx context.Context) ([]string, error)
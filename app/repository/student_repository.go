@@ -169,6 +169,21 @@ func (r *StudentRepository) GetLecturerIDByUserID(ctx context.Context, userID st
 	return lecturerID, err
 }
 
+// ===============================
+// GET USER ID BY LECTURER ID (penerima notifikasi dosen)
+// ===============================
+func (r *StudentRepository) GetUserIDByLecturerID(ctx context.Context, lecturerID string) (string, error) {
+	row := database.DB.QueryRow(ctx, `
+        SELECT user_id
+        FROM lecturers
+        WHERE id = $1
+    `, lecturerID)
+
+	var userID string
+	err := row.Scan(&userID)
+	return userID, err
+}
+
 // ===============================
 // GET STUDENTS BY ADVISOR (Mahasiswa Bimbingan Dosen)
 // ===============================
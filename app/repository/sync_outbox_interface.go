@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"backenduas/app/model"
+)
+
+type ISyncOutboxRepository interface {
+	Enqueue(ctx context.Context, e model.SyncOutboxEntry) error
+	// GetPending: entry yang belum selesai dan belum dead, paling lama duluan
+	GetPending(ctx context.Context, limit int) ([]model.SyncOutboxEntry, error)
+	PendingFor(ctx context.Context, mongoID string) ([]model.SyncOutboxEntry, error)
+	MarkDone(ctx context.Context, ids ...string) error
+	// MarkFailed menambah attempts; dead = true jika jatah maxAttempts habis
+	MarkFailed(ctx context.Context, id string, errMsg string, maxAttempts int) (dead bool, err error)
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+
+	"backenduas/app/model"
+	"backenduas/database"
+
+	"github.com/google/uuid"
+)
+
+type SyncOutboxRepository struct{}
+
+func NewSyncOutboxRepository() *SyncOutboxRepository {
+	return &SyncOutboxRepository{}
+}
+
+func (r *SyncOutboxRepository) Enqueue(ctx context.Context, e model.SyncOutboxEntry) error {
+	return enqueueOutbox(ctx, database.DB, e)
+}
+
+// enqueueOutbox dipakai Enqueue dan ChangeStatus (di dalam transaksi)
+func enqueueOutbox(ctx context.Context, db execer, e model.SyncOutboxEntry) error {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+
+	_, err := db.Exec(ctx, `
+		INSERT INTO sync_outbox (id, operation, mongo_id, status, version, attempts, last_error, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,NOW())
+	`, e.ID, e.Operation, e.MongoID, e.Status, e.Version, e.Attempts, e.LastError)
+	return err
+}
+
+const outboxColumns = `id, operation, mongo_id, status, version, attempts, last_error, created_at`
+
+// entry yang belum selesai, paling lama duluan
+func (r *SyncOutboxRepository) GetPending(ctx context.Context, limit int) ([]model.SyncOutboxEntry, error) {
+	return r.query(ctx, `
+		SELECT `+outboxColumns+`
+		FROM sync_outbox
+		WHERE processed_at IS NULL AND dead_at IS NULL
+		ORDER BY created_at ASC
+		LIMIT $1
+	`, limit)
+}
+
+// entry tertunda untuk satu dokumen Mongo (flush setelah commit)
+func (r *SyncOutboxRepository) PendingFor(ctx context.Context, mongoID string) ([]model.SyncOutboxEntry, error) {
+	return r.query(ctx, `
+		SELECT `+outboxColumns+`
+		FROM sync_outbox
+		WHERE mongo_id = $1 AND processed_at IS NULL AND dead_at IS NULL
+		ORDER BY created_at ASC
+	`, mongoID)
+}
+
+func (r *SyncOutboxRepository) query(ctx context.Context, sql string, args ...interface{}) ([]model.SyncOutboxEntry, error) {
+	rows, err := database.DB.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []model.SyncOutboxEntry{}
+
+	for rows.Next() {
+		var e model.SyncOutboxEntry
+		if err := rows.Scan(&e.ID, &e.Operation, &e.MongoID, &e.Status, &e.Version, &e.Attempts, &e.LastError, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+
+	return list, rows.Err()
+}
+
+func (r *SyncOutboxRepository) MarkDone(ctx context.Context, ids ...string) error {
+	_, err := database.DB.Exec(ctx, `
+		UPDATE sync_outbox SET processed_at = NOW(), attempts = attempts + 1, last_error = ''
+		WHERE id = ANY($1) AND processed_at IS NULL
+	`, ids)
+	return err
+}
+
+func (r *SyncOutboxRepository) MarkFailed(ctx context.Context, id string, errMsg string, maxAttempts int) (bool, error) {
+	var dead bool
+	err := database.DB.QueryRow(ctx, `
+		UPDATE sync_outbox
+		SET attempts = attempts + 1,
+		    last_error = $1,
+		    dead_at = CASE WHEN attempts + 1 >= $3 THEN NOW() END
+		WHERE id = $2
+		RETURNING dead_at IS NOT NULL
+	`, errMsg, id, maxAttempts).Scan(&dead)
+	return dead, err
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backenduas/app/model"
+)
+
+type ITokenDenylistRepository interface {
+	RevokeToken(ctx context.Context, jti, userID string, exp time.Time, reason string) error
+	// RevokeUserTokens mencabut semua access token user yang terbit sebelum `before`
+	RevokeUserTokens(ctx context.Context, userID string, before time.Time, reason string) error
+	// LoadDenylist: jti yang belum kedaluwarsa + pencabutan per user / sesi sejak `since`
+	LoadDenylist(ctx context.Context, since time.Time) (model.TokenDenylist, error)
+	// PurgeDenylist menghapus entri yang tokennya pasti sudah kedaluwarsa
+	PurgeDenylist(ctx context.Context, since time.Time) error
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/database"
+)
+
+type TokenDenylistRepository struct{}
+
+func NewTokenDenylistRepository() *TokenDenylistRepository {
+	return &TokenDenylistRepository{}
+}
+
+func (r *TokenDenylistRepository) RevokeToken(ctx context.Context, jti, userID string, exp time.Time, reason string) error {
+	_, err := database.DB.Exec(ctx, `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at, reason)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (jti) DO NOTHING
+	`, jti, userID, exp, reason)
+	return err
+}
+
+func (r *TokenDenylistRepository) RevokeUserTokens(ctx context.Context, userID string, before time.Time, reason string) error {
+	_, err := database.DB.Exec(ctx, `
+		INSERT INTO user_token_revocations (user_id, revoked_before, reason)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET revoked_before = GREATEST(user_token_revocations.revoked_before, EXCLUDED.revoked_before),
+		    reason = EXCLUDED.reason
+	`, userID, before, reason)
+	return err
+}
+
+func (r *TokenDenylistRepository) LoadDenylist(ctx context.Context, since time.Time) (model.TokenDenylist, error) {
+	d := model.TokenDenylist{Tokens: map[string]time.Time{}, Users: map[string]time.Time{}, Sessions: map[string]bool{}}
+
+	rows, err := database.DB.Query(ctx, `SELECT jti, expires_at FROM revoked_tokens WHERE expires_at > NOW()`)
+	if err != nil {
+		return d, err
+	}
+	for rows.Next() {
+		var jti string
+		var exp time.Time
+		if err := rows.Scan(&jti, &exp); err != nil {
+			rows.Close()
+			return d, err
+		}
+		d.Tokens[jti] = exp
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return d, err
+	}
+
+	rows, err = database.DB.Query(ctx, `
+		SELECT user_id::text, revoked_before FROM user_token_revocations WHERE revoked_before > $1
+	`, since)
+	if err != nil {
+		return d, err
+	}
+	for rows.Next() {
+		var uid string
+		var before time.Time
+		if err := rows.Scan(&uid, &before); err != nil {
+			rows.Close()
+			return d, err
+		}
+		d.Users[uid] = before
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return d, err
+	}
+
+	rows, err = database.DB.Query(ctx, `SELECT id::text FROM auth_sessions WHERE revoked_at > $1`, since)
+	if err != nil {
+		return d, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var sid string
+		if err := rows.Scan(&sid); err != nil {
+			return d, err
+		}
+		d.Sessions[sid] = true
+	}
+	return d, rows.Err()
+}
+
+func (r *TokenDenylistRepository) PurgeDenylist(ctx context.Context, since time.Time) error {
+	if _, err := database.DB.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at <= NOW()`); err != nil {
+		return err
+	}
+	_, err := database.DB.Exec(ctx, `DELETE FROM user_token_revocations WHERE revoked_before <= $1`, since)
+	return err
+}
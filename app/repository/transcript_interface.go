@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"backenduas/app/model"
+)
+
+type ITranscriptRepository interface {
+	// ListVerified = prestasi verified milik mahasiswa beserta nama dosen verifikator, urut tanggal verifikasi
+	ListVerified(ctx context.Context, studentID string) ([]model.TranscriptEntry, error)
+	Create(ctx context.Context, t *model.Transcript) error
+	GetByHash(ctx context.Context, hash string) (model.Transcript, error)
+}
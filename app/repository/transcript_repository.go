@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"backenduas/app/model"
+	"backenduas/database"
+)
+
+type TranscriptRepository struct{}
+
+func NewTranscriptRepository() *TranscriptRepository {
+	return &TranscriptRepository{}
+}
+
+func (r *TranscriptRepository) ListVerified(ctx context.Context, studentID string) ([]model.TranscriptEntry, error) {
+	rows, err := database.DB.Query(ctx, `
+		SELECT ar.id, ar.mongo_achievement_id, COALESCE(ar.verified_at, ar.updated_at), COALESCE(u.full_name, '')
+		FROM achievement_references ar
+		LEFT JOIN lecturers l ON l.id::text = ar.verified_by::text
+		LEFT JOIN users u ON u.id = l.user_id
+		WHERE ar.student_id = $1 AND ar.status = 'verified'
+		ORDER BY ar.verified_at ASC NULLS LAST
+	`, studentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []model.TranscriptEntry{}
+	for rows.Next() {
+		var e model.TranscriptEntry
+		if err := rows.Scan(&e.AchievementID, &e.MongoAchievementID, &e.VerifiedAt, &e.VerifierName); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *TranscriptRepository) Create(ctx context.Context, t *model.Transcript) error {
+	snapshot, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	var issuedBy interface{}
+	if t.IssuedBy != "" {
+		issuedBy = t.IssuedBy
+	}
+
+	_, err = database.DB.Exec(ctx, `
+		INSERT INTO transcripts (hash, student_id, issued_by, issued_at, key_id, snapshot)
+		VALUES ($1,$2,$3,$4,$5,$6)
+	`, t.Hash, t.StudentID, issuedBy, t.IssuedAt, t.KeyID, snapshot)
+	return err
+}
+
+func (r *TranscriptRepository) GetByHash(ctx context.Context, hash string) (model.Transcript, error) {
+	var raw []byte
+	var keyID string
+	var t model.Transcript
+	if err := database.DB.QueryRow(ctx, `
+		SELECT key_id, snapshot FROM transcripts WHERE hash = $1
+	`, hash).Scan(&keyID, &raw); err != nil {
+		return t, err
+	}
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return t, err
+	}
+	t.KeyID = keyID
+	return t, nil
+}
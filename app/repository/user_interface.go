@@ -10,6 +10,7 @@ type IUserRepository interface {
 	Update(ctx context.Context, u *model.User) error
 	Delete(ctx context.Context, id string) error
 	UpdateRole(ctx context.Context, userID string, roleID string) error
+	SetActive(ctx context.Context, userID string, active bool) error
 	GetByID(ctx context.Context, id string) (*model.User, error)
 	GetAll(ctx context.Context) ([]model.User, error)
 }
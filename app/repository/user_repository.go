@@ -163,6 +163,14 @@ func (r *UserRepository) UpdateRole(ctx context.Context, userID string, roleID s
 	return err
 }
 
+func (r *UserRepository) SetActive(ctx context.Context, userID string, active bool) error {
+	_, err := database.DB.Exec(ctx, `
+		UPDATE users SET is_active=$1, updated_at=NOW()
+		WHERE id = $2
+	`, active, userID)
+	return err
+}
+
 // =======================
 // GET ROLE NAME
 // =======================
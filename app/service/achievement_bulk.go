@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	BulkSucceeded = "succeeded"
+	BulkFailed    = "failed"
+
+	maxBulkItems = 100
+)
+
+var ErrLecturerNotFound = errors.New("lecturer not found")
+
+// bulkItems menggabungkan ids + items, membuang duplikat, memakai note bersama
+func bulkItems(req model.BulkReviewRequest) ([]model.BulkReviewItem, error) {
+	items := []model.BulkReviewItem{}
+	seen := map[string]bool{}
+
+	add := func(id, note string) {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			return
+		}
+		seen[id] = true
+		if strings.TrimSpace(note) == "" {
+			note = req.Note
+		}
+		items = append(items, model.BulkReviewItem{ID: id, Note: note})
+	}
+
+	for _, id := range req.IDs {
+		add(id, "")
+	}
+	for _, it := range req.Items {
+		add(it.ID, it.Note)
+	}
+
+	if len(items) == 0 {
+		return nil, errors.New("ids or items is required")
+	}
+	if len(items) > maxBulkItems {
+		return nil, fmt.Errorf("at most %d achievements per request", maxBulkItems)
+	}
+	return items, nil
+}
+
+// =====================================================
+//  BULK VERIFY / REJECT (Dosen Wali)
+// =====================================================
+//
+// 1. validasi per item (ada, mahasiswa bimbingan, transisi valid, note)
+// 2. item yang lolos di-update (beserta history) dalam SATU transaksi PostgreSQL;
+//    item yang statusnya keburu diubah request lain gagal sendiri (SAVEPOINT)
+// 3. Mongo disinkronkan per item (retry / outbox), notifikasi & statistik dicatat
+type bulkReviewer struct {
+	pgRepo      repository.IAchievementPGRepository
+	mongoRepo   repository.IAchievementMongoRepository
+	studentRepo repository.IStudentRepository
+	points      *PointsEngine
+	notifier    *Notifier
+	sync        *AchievementSyncer
+	stats       *StatsMaterializer
+}
+
+func (b bulkReviewer) run(
+	ctx context.Context,
+	toStatus string,
+	lecturerUserID string,
+	items []model.BulkReviewItem,
+) (model.BulkReviewResponse, error) {
+
+	resp := model.BulkReviewResponse{
+		Action:  toStatus,
+		Results: make([]model.BulkItemResult, len(items)),
+	}
+
+	lecturerID, err := b.studentRepo.GetLecturerIDByUserID(ctx, lecturerUserID)
+	if err != nil {
+		return resp, ErrLecturerNotFound
+	}
+
+	refs := make([]model.AchievementReference, len(items))
+	points := make([]model.PointsBreakdown, len(items))
+	pending := []int{}
+
+	for i, item := range items {
+		resp.Results[i] = model.BulkItemResult{ID: item.ID, Result: BulkFailed}
+
+		ref, err := b.pgRepo.GetByID(ctx, item.ID)
+		if err != nil {
+			resp.Results[i].Reason = "achievement not found"
+			continue
+		}
+
+		if !canReview(ctx, b.studentRepo, ref, lecturerID) {
+			resp.Results[i].Reason = "student is not your advisee"
+			continue
+		}
+
+		if err := ValidateTransition(ref.Status, toStatus); err != nil {
+			resp.Results[i].Reason = err.Error()
+			continue
+		}
+
+		if toStatus == StatusRejected && strings.TrimSpace(item.Note) == "" {
+			resp.Results[i].Reason = "rejection note is required"
+			continue
+		}
+
+		if toStatus == StatusVerified && b.points != nil {
+			oid, _ := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+			doc, err := b.mongoRepo.FindById(ctx, oid)
+			if err != nil {
+				resp.Results[i].Reason = "achievement detail not found"
+				continue
+			}
+			if points[i], err = b.points.Calculate(ctx, doc); err != nil {
+				resp.Results[i].Reason = "points calculation failed: " + err.Error()
+				continue
+			}
+		}
+
+		refs[i] = ref
+		pending = append(pending, i)
+	}
+
+	if len(pending) > 0 {
+		results, err := b.apply(ctx, toStatus, lecturerUserID, lecturerID, items, refs, points, pending)
+		if err != nil {
+			for _, i := range pending {
+				resp.Results[i].Reason = "transaction rolled back: " + err.Error()
+			}
+			pending = nil
+		}
+
+		applied := []int{}
+		for n, i := range pending {
+			if results[n] != nil {
+				resp.Results[i].Reason = (&TransitionError{From: refs[i].Status, To: toStatus, Stale: true}).Error()
+				continue
+			}
+			applied = append(applied, i)
+		}
+		pending = applied
+	}
+
+	for _, i := range pending {
+		ref := refs[i]
+		resp.Results[i].Result = BulkSucceeded
+
+		b.sync.SyncStatus(ctx, ref.MongoAchievementID)
+		if toStatus == StatusVerified && b.points != nil {
+			if err := b.sync.SyncPoints(ctx, ref.MongoAchievementID, points[i]); err != nil {
+				resp.Results[i].Reason = "verified, but points were not saved to MongoDB: " + err.Error()
+			}
+			total := points[i].Total
+			resp.Results[i].Points = &total
+		}
+
+		if toStatus == StatusRejected {
+			b.notifier.AchievementRejected(ctx, ref, items[i].Note)
+		} else {
+			b.notifier.AchievementVerified(ctx, ref, resp.Results[i].Points)
+		}
+
+		b.stats.Refresh(ctx, ref.ID)
+	}
+
+	for _, r := range resp.Results {
+		if r.Result == BulkSucceeded {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+
+	return resp, nil
+}
+
+func (b bulkReviewer) apply(
+	ctx context.Context,
+	toStatus, lecturerUserID, lecturerID string,
+	items []model.BulkReviewItem,
+	refs []model.AchievementReference,
+	points []model.PointsBreakdown,
+	pending []int,
+) ([]error, error) {
+	changes := make([]model.StatusChange, 0, len(pending))
+	for _, i := range pending {
+		ch := model.StatusChange{AchievementID: refs[i].ID, From: refs[i].Status, To: toStatus, LecturerID: lecturerID}
+		if toStatus == StatusRejected {
+			ch.Note = items[i].Note
+		}
+		if toStatus == StatusVerified && b.points != nil {
+			ch.Points = &points[i].Total
+		}
+		ch.History = &model.AchievementHistory{
+			AchievementID: refs[i].ID,
+			ActorUserID:   lecturerUserID,
+			ActorRole:     "Dosen Wali",
+			FromStatus:    refs[i].Status,
+			ToStatus:      toStatus,
+			Note:          ch.Note,
+		}
+		changes = append(changes, ch)
+	}
+	return b.pgRepo.ChangeStatusEach(ctx, changes...)
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// seedReviewQueue mengisi tiga prestasi submitted (dua milik bimbingan
+// uLect, satu milik dosen lain) dan satu draft.
+func seedReviewQueue(pg *repository.MockAchievementPGRepository, mg *repository.MockAchievementMongoRepository, st *repository.MockStudentRepository) {
+	seed := func(id, studentID, status string) {
+		oid := primitive.NewObjectID()
+		mg.Data[oid.Hex()] = model.AchievementMongo{ID: oid, StudentID: studentID, Status: status}
+		pg.Data[id] = model.AchievementReference{ID: id, StudentID: studentID, MongoAchievementID: oid.Hex(), Status: status}
+	}
+	seed("a1", "s1", StatusSubmitted)
+	seed("a2", "s2", StatusSubmitted)
+	seed("a3", "s3", StatusSubmitted) // bukan bimbingan
+	seed("a4", "s1", StatusDraft)
+
+	st.UserToLecturer["uLect"] = "lect1"
+	st.AdvisorMap["s1"] = "lect1"
+	st.AdvisorMap["s2"] = "lect1"
+	st.AdvisorMap["s3"] = "lect2"
+}
+
+func resultsByID(resp model.BulkReviewResponse) map[string]model.BulkItemResult {
+	out := map[string]model.BulkItemResult{}
+	for _, r := range resp.Results {
+		out[r.ID] = r
+	}
+	return out
+}
+
+func TestBulkVerify_PerItemResults(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	st := repository.NewMockStudentRepository()
+	hs := repository.NewMockAchievementHistoryRepository()
+	ob := repository.NewMockSyncOutboxRepository()
+	pg.History = hs
+	pg.Outbox = ob
+	seedReviewQueue(pg, mg, st)
+
+	svc := NewAchievementLogicService(pg, mg, st, hs, nil, nil, NewAchievementSyncer(pg, mg, ob))
+
+	resp, err := svc.BulkVerify("uLect", model.BulkReviewRequest{
+		IDs: []string{"a1", "a2", "a3", "a4", "missing", "a1"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Succeeded != 2 || resp.Failed != 3 || len(resp.Results) != 5 {
+		t.Fatalf("unexpected counts: %+v", resp)
+	}
+
+	res := resultsByID(resp)
+	if res["a1"].Result != BulkSucceeded || res["a2"].Result != BulkSucceeded {
+		t.Fatalf("expected a1, a2 to succeed: %+v", res)
+	}
+	if res["a3"].Reason != "student is not your advisee" {
+		t.Errorf("unexpected reason for a3: %q", res["a3"].Reason)
+	}
+	if res["a4"].Result != BulkFailed || res["missing"].Reason != "achievement not found" {
+		t.Errorf("unexpected failures: %+v", res)
+	}
+
+	if pg.Data["a1"].Status != StatusVerified || pg.Data["a3"].Status != StatusSubmitted {
+		t.Fatal("only validated items must be verified")
+	}
+	if doc := mg.Data[pg.Data["a2"].MongoAchievementID]; doc.Status != StatusVerified || doc.StatusVersion != 1 {
+		t.Fatalf("mongo status not synced through the outbox: %s@%d", doc.Status, doc.StatusVersion)
+	}
+	if len(hs.Data) != 2 {
+		t.Fatalf("expected 2 history entries, got %d", len(hs.Data))
+	}
+}
+
+func TestBulkReject_SharedAndPerItemNotes(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	st := repository.NewMockStudentRepository()
+	hs := repository.NewMockAchievementHistoryRepository()
+	pg.History = hs
+	seedReviewQueue(pg, mg, st)
+
+	svc := NewAchievementLogicService(pg, mg, st, hs, nil, nil, nil)
+
+	resp, err := svc.BulkReject("uLect", model.BulkReviewRequest{
+		Note:  "Bukti kurang lengkap",
+		Items: []model.BulkReviewItem{{ID: "a1", Note: "Sertifikat buram"}, {ID: "a2"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Succeeded != 2 {
+		t.Fatalf("expected both rejected: %+v", resp)
+	}
+
+	if n := pg.Data["a1"].RejectionNotes; len(n) != 1 || n[0].Note != "Sertifikat buram" {
+		t.Errorf("per-item note not stored: %+v", n)
+	}
+	if n := pg.Data["a2"].RejectionNotes; len(n) != 1 || n[0].Note != "Bukti kurang lengkap" {
+		t.Errorf("shared note not stored: %+v", n)
+	}
+}
+
+func TestBulkReject_RequiresNote(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	st := repository.NewMockStudentRepository()
+	hs := repository.NewMockAchievementHistoryRepository()
+	pg.History = hs
+	seedReviewQueue(pg, mg, st)
+
+	svc := NewAchievementLogicService(pg, mg, st, hs, nil, nil, nil)
+
+	resp, _ := svc.BulkReject("uLect", model.BulkReviewRequest{IDs: []string{"a1"}})
+	if resp.Failed != 1 || resp.Results[0].Reason != "rejection note is required" {
+		t.Fatalf("expected note required failure: %+v", resp)
+	}
+}
+
+func TestBulkVerify_TransactionRollback(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	st := repository.NewMockStudentRepository()
+	hs := repository.NewMockAchievementHistoryRepository()
+	pg.History = hs
+	seedReviewQueue(pg, mg, st)
+
+	svc := NewAchievementLogicService(pg, mg, st, hs, nil, nil, nil)
+	pg.FailWith = errors.New("connection reset")
+
+	resp, err := svc.BulkVerify("uLect", model.BulkReviewRequest{IDs: []string{"a1", "a2"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Succeeded != 0 || resp.Failed != 2 {
+		t.Fatalf("expected all items failed after rollback: %+v", resp)
+	}
+	if pg.Data["a1"].Status != StatusSubmitted || len(hs.Data) != 0 {
+		t.Fatal("nothing may change when the transaction fails")
+	}
+}
+
+func TestBulkItems_Validation(t *testing.T) {
+	if _, err := bulkItems(model.BulkReviewRequest{}); err == nil {
+		t.Error("expected error for empty request")
+	}
+
+	ids := make([]string, maxBulkItems+1)
+	for i := range ids {
+		ids[i] = primitive.NewObjectID().Hex()
+	}
+	if _, err := bulkItems(model.BulkReviewRequest{IDs: ids}); err == nil {
+		t.Error("expected error above the batch limit")
+	}
+}
+
+// racingPG: status id raced diubah request lain tepat setelah dibaca validasi
+type racingPG struct {
+	*repository.MockAchievementPGRepository
+	raced string
+}
+
+func (r racingPG) GetByID(ctx context.Context, id string) (model.AchievementReference, error) {
+	ref, err := r.MockAchievementPGRepository.GetByID(ctx, id)
+	if err == nil && id == r.raced {
+		changed := ref
+		changed.Status = StatusRejected
+		r.Data[id] = changed
+	}
+	return ref, err
+}
+
+func TestBulkVerify_StaleItemFailsAlone(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	st := repository.NewMockStudentRepository()
+	hs := repository.NewMockAchievementHistoryRepository()
+	pg.History = hs
+	seedReviewQueue(pg, mg, st)
+
+	svc := NewAchievementLogicService(racingPG{pg, "a2"}, mg, st, hs, nil, nil, nil)
+
+	resp, err := svc.BulkVerify("uLect", model.BulkReviewRequest{IDs: []string{"a1", "a2"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	res := resultsByID(resp)
+	if resp.Succeeded != 1 || res["a1"].Result != BulkSucceeded {
+		t.Fatalf("non-conflicting item must still be verified: %+v", resp)
+	}
+	if res["a2"].Result != BulkFailed || !strings.Contains(res["a2"].Reason, "no longer") {
+		t.Errorf("expected a2 to fail as stale: %+v", res["a2"])
+	}
+	if pg.Data["a1"].Status != StatusVerified || pg.Data["a2"].Status != StatusRejected || len(hs.Data) != 1 {
+		t.Fatal("only the conflicting item may be skipped")
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+)
+
+// =====================================================
+//  AUDIT TRAIL HELPER (dipakai AchievementService & AchievementLogicService)
+// =====================================================
+
+// writeHistory menyimpan satu event edit konten. Repo nil → dilewati (unit test lama).
+// Gagal → error dikembalikan supaya perubahan tanpa jejak audit tidak dianggap sukses.
+func writeHistory(ctx context.Context, repo repository.IAchievementHistoryRepository, h model.AchievementHistory) error {
+	if repo == nil {
+		return nil
+	}
+	if err := repo.Create(ctx, h); err != nil {
+		log.Printf("⚠️ gagal menyimpan riwayat prestasi %s (%s → %s): %v", h.AchievementID, h.FromStatus, h.ToStatus, err)
+		return err
+	}
+	return nil
+}
+
+// changedFields membandingkan dokumen Mongo lama dengan input update
+func changedFields(old model.AchievementMongo, in model.AchievementUpdateInput) []string {
+	fields := []string{}
+
+	if old.Title != in.Title {
+		fields = append(fields, "title")
+	}
+	if old.Description != in.Description {
+		fields = append(fields, "description")
+	}
+	if old.AchievementType != in.AchievementType {
+		fields = append(fields, "achievementType")
+	}
+	if !sameJSON(old.Details, in.Details, len(old.Details) == 0 && len(in.Details) == 0) {
+		fields = append(fields, "details")
+	}
+	if !sameJSON(old.Tags, in.Tags, len(old.Tags) == 0 && len(in.Tags) == 0) {
+		fields = append(fields, "tags")
+	}
+
+	return fields
+}
+
+// sameJSON: bandingkan lewat JSON supaya int32 (Mongo) dan float64 (body) dianggap sama
+func sameJSON(a, b interface{}, bothEmpty bool) bool {
+	if bothEmpty {
+		return true
+	}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return string(ja) == string(jb)
+}
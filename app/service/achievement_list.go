@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// parseAchievementListQuery membaca & memvalidasi query string GET /achievements
+func parseAchievementListQuery(c *fiber.Ctx) (model.AchievementListQuery, error) {
+	q := model.AchievementListQuery{
+		Page:            c.QueryInt("page", 1),
+		Limit:           c.QueryInt("limit", defaultListLimit),
+		Sort:            c.Query("sort", "created_at"),
+		Order:           strings.ToLower(c.Query("order", "desc")),
+		AchievementType: c.Query("achievementType"),
+		StudentID:       c.Query("student_id"),
+		ProgramStudy:    c.Query("program_study"),
+		Status:          splitCSV(c.Query("status")),
+		Tags:            splitCSV(c.Query("tags")),
+	}
+
+	if q.Page < 1 {
+		return q, fmt.Errorf("page must be >= 1")
+	}
+	if q.Limit < 1 || q.Limit > maxListLimit {
+		return q, fmt.Errorf("limit must be between 1 and %d", maxListLimit)
+	}
+
+	switch q.Sort {
+	case "created_at", "submitted_at", "points":
+	default:
+		return q, fmt.Errorf("sort must be one of created_at, submitted_at, points")
+	}
+
+	if q.Order != "asc" && q.Order != "desc" {
+		return q, fmt.Errorf("order must be asc or desc")
+	}
+
+	var err error
+	if q.From, err = parseDateParam(c.Query("from"), false); err != nil {
+		return q, err
+	}
+	if q.To, err = parseDateParam(c.Query("to"), true); err != nil {
+		return q, err
+	}
+
+	return q, nil
+}
+
+// parseDateParam: "2006-01-02"; endOfDay → batas eksklusif hari berikutnya
+func parseDateParam(v string, endOfDay bool) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", v)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+func splitCSV(v string) []string {
+	out := []string{}
+	for _, part := range strings.Split(v, ",") {
+		if p := strings.TrimSpace(part); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// achievementScope: student_id yang boleh dilihat pemanggil (nil = semua).
+// Error berupa *fiber.Error supaya handler bisa langsung memakai status-nya.
+func achievementScope(ctx context.Context, c *fiber.Ctx, st repository.IStudentRepository) ([]string, error) {
+	scope, err := callerScope(ctx, c, st)
+	if err != nil {
+		return nil, err
+	}
+	return scope.studentIDs(ctx, st)
+}
+
+func scopeError(c *fiber.Ctx, err error) error {
+	if fe, ok := err.(*fiber.Error); ok {
+		return c.Status(fe.Code).JSON(fiber.Map{"error": fe.Message})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
+
+// listAchievements: filter Mongo dulu (jika perlu) → SQL (filter, sort, LIMIT) → join detail halaman ini
+func listAchievements(
+	ctx context.Context,
+	pg repository.IAchievementPGRepository,
+	mg repository.IAchievementMongoRepository,
+	q model.AchievementListQuery,
+) (model.AchievementListResponse, error) {
+
+	resp := model.AchievementListResponse{
+		Data: []model.AchievementListItem{},
+		Meta: model.PageMeta{Page: q.Page, Limit: q.Limit},
+	}
+
+	if q.ScopeStudentIDs != nil && len(q.ScopeStudentIDs) == 0 {
+		return resp, nil
+	}
+
+	if q.NeedsMongoFilter() {
+		ids, err := mg.FindIDs(ctx, q)
+		if err != nil {
+			return resp, err
+		}
+		if len(ids) == 0 {
+			return resp, nil
+		}
+		q.MongoIDs = ids
+	}
+
+	refs, total, err := pg.List(ctx, q)
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Meta.Total = total
+	resp.Meta.TotalPages = (total + q.Limit - 1) / q.Limit
+
+	mongoIDs := []primitive.ObjectID{}
+	for _, r := range refs {
+		if oid, err := primitive.ObjectIDFromHex(r.MongoAchievementID); err == nil {
+			mongoIDs = append(mongoIDs, oid)
+		}
+	}
+
+	mongoMap, err := mg.FindManyByIDs(ctx, mongoIDs)
+	if err != nil {
+		return resp, err
+	}
+
+	for _, ref := range refs {
+		resp.Data = append(resp.Data, model.AchievementListItem{
+			ID:          ref.ID,
+			StudentID:   ref.StudentID,
+			Status:      ref.Status,
+			SubmittedAt: ref.SubmittedAt,
+			CreatedAt:   ref.CreatedAt,
+			Detail:      mongoMap[ref.MongoAchievementID],
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func seedList(pg *repository.MockAchievementPGRepository, mg *repository.MockAchievementMongoRepository, id, studentID, status, achType string, points int, tags []string, created time.Time) {
+	oid := primitive.NewObjectID()
+	pg.Data[id] = model.AchievementReference{
+		ID:                 id,
+		StudentID:          studentID,
+		MongoAchievementID: oid.Hex(),
+		Status:             status,
+		Points:             points,
+		CreatedAt:          created,
+	}
+	mg.Data[oid.Hex()] = model.AchievementMongo{
+		ID:              oid,
+		StudentID:       studentID,
+		Title:           id,
+		AchievementType: achType,
+		Points:          points,
+		Tags:            tags,
+		Status:          status,
+	}
+}
+
+func seedListCatalog(pg *repository.MockAchievementPGRepository, mg *repository.MockAchievementMongoRepository, st *repository.MockStudentRepository) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	seedList(pg, mg, "a1", "s1", StatusVerified, "competition", 50, []string{"Gemastik"}, base)
+	seedList(pg, mg, "a2", "s1", StatusDraft, "publication", 10, nil, base.AddDate(0, 1, 0))
+	seedList(pg, mg, "a3", "s2", StatusVerified, "competition", 80, []string{"Gemastik", "Nasional"}, base.AddDate(0, 2, 0))
+	seedList(pg, mg, "a4", "s3", StatusSubmitted, "competition", 30, nil, base.AddDate(0, 3, 0))
+
+	st.UserToLecturer["uLect"] = "lect1"
+	st.AdvisorMap["s1"] = "lect1"
+	st.AdvisorMap["s2"] = "lect1"
+}
+
+func TestListAchievements_AdminPagination(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	st := repository.NewMockStudentRepository()
+	seedListCatalog(pg, mg, st)
+
+	svc := NewAchievementLogicService(pg, mg, st, nil, nil, nil, nil)
+
+	resp, err := svc.List("Admin", "admin", model.AchievementListQuery{Page: 2, Limit: 3, Sort: "created_at", Order: "desc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Meta.Total != 4 || resp.Meta.TotalPages != 2 {
+		t.Fatalf("unexpected meta: %+v", resp.Meta)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].ID != "a1" {
+		t.Fatalf("expected oldest item on page 2, got %+v", resp.Data)
+	}
+	if resp.Data[0].Detail.Title != "a1" {
+		t.Fatalf("mongo detail not joined")
+	}
+}
+
+func TestListAchievements_SortByPointsWithMongoFilter(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	st := repository.NewMockStudentRepository()
+	seedListCatalog(pg, mg, st)
+
+	svc := NewAchievementLogicService(pg, mg, st, nil, nil, nil, nil)
+
+	resp, err := svc.List("Admin", "admin", model.AchievementListQuery{
+		Page: 1, Limit: 10, Sort: "points", Order: "desc",
+		AchievementType: "competition",
+		Status:          []string{StatusVerified, StatusSubmitted},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := []string{}
+	for _, item := range resp.Data {
+		got = append(got, item.ID)
+	}
+	if len(got) != 3 || got[0] != "a3" || got[1] != "a1" || got[2] != "a4" {
+		t.Fatalf("expected [a3 a1 a4] by points, got %v", got)
+	}
+}
+
+func TestListAchievements_DosenWaliScopeAndTags(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	st := repository.NewMockStudentRepository()
+	seedListCatalog(pg, mg, st)
+
+	svc := NewAchievementLogicService(pg, mg, st, nil, nil, nil, nil)
+
+	resp, err := svc.List("Dosen Wali", "uLect", model.AchievementListQuery{
+		Page: 1, Limit: 10, Sort: "created_at", Order: "desc",
+		Tags: []string{"Gemastik"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Meta.Total != 2 {
+		t.Fatalf("expected 2 advisee achievements tagged Gemastik, got %d", resp.Meta.Total)
+	}
+	for _, item := range resp.Data {
+		if item.StudentID == "s3" {
+			t.Fatalf("achievement of non-advisee leaked")
+		}
+	}
+}
+
+func TestListAchievements_DateRange(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	st := repository.NewMockStudentRepository()
+	seedListCatalog(pg, mg, st)
+
+	svc := NewAchievementLogicService(pg, mg, st, nil, nil, nil, nil)
+
+	from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	resp, err := svc.List("Admin", "admin", model.AchievementListQuery{
+		Page: 1, Limit: 10, Sort: "created_at", Order: "asc", From: &from, To: &to,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Meta.Total != 2 || resp.Data[0].ID != "a2" || resp.Data[1].ID != "a3" {
+		t.Fatalf("unexpected date range result: %+v", resp.Data)
+	}
+}
+
+func TestListAchievements_StatusFilteredInSQLOnly(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	seedList(pg, mg, "a1", "s1", StatusVerified, "competition", 50, nil, base)
+	seedList(pg, mg, "a2", "s1", StatusDeleted, "competition", 90, nil, base)
+	seedList(pg, mg, "a3", "s2", StatusVerified, "competition", 70, nil, base)
+
+	// status Mongo tertinggal dari PostgreSQL (sync belum jalan)
+	stale := mg.Data[pg.Data["a1"].MongoAchievementID]
+	stale.Status = StatusSubmitted
+	mg.Data[pg.Data["a1"].MongoAchievementID] = stale
+
+	ids, err := mg.FindIDs(context.Background(), model.AchievementListQuery{
+		AchievementType: "competition", ScopeStudentIDs: []string{"s1"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("mongo prefilter must only apply type & scope, got %v", ids)
+	}
+
+	resp, err := listAchievements(context.Background(), pg, mg, model.AchievementListQuery{
+		Page: 1, Limit: 10, Sort: "points", Order: "desc",
+		AchievementType: "competition", ScopeStudentIDs: []string{"s1"},
+		Status: []string{StatusVerified},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Meta.Total != 1 || resp.Data[0].ID != "a1" {
+		t.Fatalf("status must come from PostgreSQL, got %+v", resp.Data)
+	}
+
+	resp, err = listAchievements(context.Background(), pg, mg, model.AchievementListQuery{
+		Page: 1, Limit: 10, Sort: "created_at", Order: "desc", ScopeStudentIDs: []string{"s1"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Meta.Total != 1 || resp.Data[0].ID != "a1" {
+		t.Fatalf("deleted achievements must be hidden by default, got %+v", resp.Data)
+	}
+}
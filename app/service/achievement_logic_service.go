@@ -17,14 +17,22 @@ type AchievementLogicService struct {
 	pgRepo      repository.IAchievementPGRepository
 	mongoRepo   repository.IAchievementMongoRepository
 	studentRepo repository.IStudentRepository
+	historyRepo repository.IAchievementHistoryRepository
+	typeRepo    repository.IAchievementTypeRepository
+	points      *PointsEngine
+	sync        *AchievementSyncer
 }
 
 func NewAchievementLogicService(
 	pg repository.IAchievementPGRepository,
 	mg repository.IAchievementMongoRepository,
 	st repository.IStudentRepository,
+	hs repository.IAchievementHistoryRepository,
+	ts repository.IAchievementTypeRepository,
+	points *PointsEngine,
+	sync *AchievementSyncer,
 ) *AchievementLogicService {
-	return &AchievementLogicService{pgRepo: pg, mongoRepo: mg, studentRepo: st}
+	return &AchievementLogicService{pgRepo: pg, mongoRepo: mg, studentRepo: st, historyRepo: hs, typeRepo: ts, points: points, sync: sync}
 }
 
 // ================= CREATE =================
@@ -48,30 +56,110 @@ func (s *AchievementLogicService) Create(
 		return errors.New("student tidak ditemukan")
 	}
 
+	achType, err := resolveAchievementType(ctx, s.typeRepo, req.AchievementType, req.Details)
+	if err != nil {
+		return err
+	}
+
 	ach := model.AchievementMongo{
 		StudentID:       studentID,
 		Title:           req.Title,
-		AchievementType: req.AchievementType,
+		AchievementType: achType,
 		Description:     req.Description,
-		Status:          "draft",
+		Details:         req.Details,
+		Tags:            req.Tags,
+		Status:          StatusDraft,
 		CreatedAt:       time.Now().Unix(),
 		UpdatedAt:       time.Now().Unix(),
 	}
 
-	oid, _ := s.mongoRepo.Create(ctx, ach)
+	oid, err := s.mongoRepo.Create(ctx, ach)
+	if err != nil {
+		return err
+	}
 
 	ref := model.AchievementReference{
 		ID:                 uuid.New().String(),
 		StudentID:          studentID,
 		MongoAchievementID: oid.Hex(),
-		Status:             "draft",
+		Status:             StatusDraft,
 	}
 
-	return s.pgRepo.CreateReference(ref)
+	// reference + riwayat "dibuat" dalam satu transaksi
+	if err := s.pgRepo.CreateReference(ref, &model.AchievementHistory{
+		AchievementID: ref.ID,
+		ActorUserID:   userID,
+		ActorRole:     role,
+		ToStatus:      StatusDraft,
+	}); err != nil {
+		// kompensasi: hapus dokumen Mongo yang terlanjur dibuat
+		s.mongoRepo.Delete(ctx, oid)
+		return err
+	}
+	return nil
+}
+
+// ================= LIST =================
+func (s *AchievementLogicService) List(
+	role string,
+	userID string,
+	q model.AchievementListQuery,
+) (model.AchievementListResponse, error) {
+	ctx := context.Background()
+
+	scope, err := s.scope(ctx, role, userID)
+	if err != nil {
+		return model.AchievementListResponse{}, err
+	}
+	q.ScopeStudentIDs = scope
+
+	return listAchievements(ctx, s.pgRepo, s.mongoRepo, q)
+}
+
+// ================= SEARCH =================
+func (s *AchievementLogicService) Search(
+	role string,
+	userID string,
+	text string,
+	page, limit int,
+) (model.AchievementSearchResponse, error) {
+	ctx := context.Background()
+
+	scope, err := s.scope(ctx, role, userID)
+	if err != nil {
+		return model.AchievementSearchResponse{}, err
+	}
+
+	return searchAchievements(ctx, s.pgRepo, s.mongoRepo, text, scope, page, limit)
+}
+
+// scope: student_id yang boleh dilihat role ini (nil = semua)
+func (s *AchievementLogicService) scope(ctx context.Context, role, userID string) ([]string, error) {
+	switch role {
+	case "Mahasiswa":
+		studentID, err := s.studentRepo.GetStudentIDByUserID(ctx, userID)
+		if err != nil {
+			return nil, errors.New("student tidak ditemukan")
+		}
+		return []string{studentID}, nil
+
+	case "Dosen Wali":
+		lecturerID, err := s.studentRepo.GetLecturerIDByUserID(ctx, userID)
+		if err != nil {
+			return nil, errors.New("dosen tidak ditemukan")
+		}
+		return s.studentRepo.GetStudentsByAdvisor(ctx, lecturerID)
+
+	case "Admin":
+		return nil, nil
+
+	default:
+		return nil, errors.New("akses ditolak")
+	}
 }
 
 // ================= SUBMIT =================
-func (s *AchievementLogicService) Submit(id string) error {
+func (s *AchievementLogicService) Submit(id string, studentUserID string) error {
 	ctx := context.Background()
 
 	ref, err := s.pgRepo.GetByID(ctx, id)
@@ -79,15 +167,28 @@ func (s *AchievementLogicService) Submit(id string) error {
 		return errors.New("achievement tidak ditemukan")
 	}
 
-	if ref.Status != "draft" {
-		return errors.New("hanya draft yang bisa disubmit")
+	if err := ValidateTransition(ref.Status, StatusSubmitted); err != nil {
+		return err
+	}
+
+	if err := changeStatus(ctx, s.pgRepo, model.StatusChange{
+		AchievementID: id, From: ref.Status, To: StatusSubmitted,
+		History: &model.AchievementHistory{
+			AchievementID: id,
+			ActorUserID:   studentUserID,
+			ActorRole:     "Mahasiswa",
+			FromStatus:    ref.Status,
+			ToStatus:      StatusSubmitted,
+		},
+	}); err != nil {
+		return err
 	}
 
-	return s.pgRepo.UpdateStatus(id, "submitted")
+	return nil
 }
 
 // ================= DELETE =================
-func (s *AchievementLogicService) Delete(id string) error {
+func (s *AchievementLogicService) Delete(id string, studentUserID string) error {
 	ctx := context.Background()
 
 	ref, err := s.pgRepo.GetByID(ctx, id)
@@ -95,11 +196,24 @@ func (s *AchievementLogicService) Delete(id string) error {
 		return errors.New("achievement tidak ditemukan")
 	}
 
-	if ref.Status != "draft" {
-		return errors.New("hanya draft yang bisa dihapus")
+	if err := ValidateTransition(ref.Status, StatusDeleted); err != nil {
+		return err
+	}
+
+	if err := changeStatus(ctx, s.pgRepo, model.StatusChange{
+		AchievementID: id, From: ref.Status, To: StatusDeleted,
+		History: &model.AchievementHistory{
+			AchievementID: id,
+			ActorUserID:   studentUserID,
+			ActorRole:     "Mahasiswa",
+			FromStatus:    ref.Status,
+			ToStatus:      StatusDeleted,
+		},
+	}); err != nil {
+		return err
 	}
 
-	return s.pgRepo.UpdateStatus(id, "deleted")
+	return nil
 }
 
 // ================= VERIFY =================
@@ -111,17 +225,78 @@ func (s *AchievementLogicService) Verify(id string, lecturerUserID string) error
 		return errors.New("achievement tidak ditemukan")
 	}
 
-	if ref.Status != "submitted" {
-		return errors.New("achievement belum disubmit")
+	if err := ValidateTransition(ref.Status, StatusVerified); err != nil {
+		return err
 	}
 
 	lecturerID, _ := s.studentRepo.GetLecturerIDByUserID(ctx, lecturerUserID)
-	ok, _ := s.studentRepo.IsStudentUnderAdvisor(ctx, ref.StudentID, lecturerID)
-	if !ok {
+	if !canReview(ctx, s.studentRepo, ref, lecturerID) {
 		return errors.New("bukan mahasiswa bimbingan")
 	}
 
-	return s.pgRepo.Verify(id, lecturerID)
+	// poin dihitung sebelum status berubah; nil = engine tidak dipakai
+	var points model.PointsBreakdown
+	var oid primitive.ObjectID
+	if s.points != nil {
+		oid, _ = primitive.ObjectIDFromHex(ref.MongoAchievementID)
+		doc, err := s.mongoRepo.FindById(ctx, oid)
+		if err != nil {
+			return errors.New("detail prestasi tidak ditemukan")
+		}
+		if points, err = s.points.Calculate(ctx, doc); err != nil {
+			return err
+		}
+	}
+
+	ch := model.StatusChange{
+		AchievementID: id, From: ref.Status, To: StatusVerified, LecturerID: lecturerID,
+		History: &model.AchievementHistory{
+			AchievementID: id,
+			ActorUserID:   lecturerUserID,
+			ActorRole:     "Dosen Wali",
+			FromStatus:    ref.Status,
+			ToStatus:      StatusVerified,
+		},
+	}
+	if s.points != nil {
+		ch.Points = &points.Total
+	}
+	if err := changeStatus(ctx, s.pgRepo, ch); err != nil {
+		return err
+	}
+
+	// status Mongo diterapkan syncer dari outbox (status_version); di sini hanya poin
+	if s.points != nil {
+		if err := s.mongoRepo.Update(ctx, oid, pointsUpdate(points)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ================= BULK VERIFY / REJECT =================
+func (s *AchievementLogicService) BulkVerify(lecturerUserID string, req model.BulkReviewRequest) (model.BulkReviewResponse, error) {
+	return s.bulkReview(StatusVerified, lecturerUserID, req)
+}
+
+func (s *AchievementLogicService) BulkReject(lecturerUserID string, req model.BulkReviewRequest) (model.BulkReviewResponse, error) {
+	return s.bulkReview(StatusRejected, lecturerUserID, req)
+}
+
+func (s *AchievementLogicService) bulkReview(toStatus, lecturerUserID string, req model.BulkReviewRequest) (model.BulkReviewResponse, error) {
+	items, err := bulkItems(req)
+	if err != nil {
+		return model.BulkReviewResponse{}, err
+	}
+
+	reviewer := bulkReviewer{
+		pgRepo:      s.pgRepo,
+		mongoRepo:   s.mongoRepo,
+		studentRepo: s.studentRepo,
+		points:      s.points,
+		sync:        s.sync,
+	}
+	return reviewer.run(context.Background(), toStatus, lecturerUserID, items)
 }
 
 // ================= REJECT =================
@@ -137,33 +312,79 @@ func (s *AchievementLogicService) Reject(id string, lecturerUserID string, note
 		return errors.New("achievement tidak ditemukan")
 	}
 
+	if err := ValidateTransition(ref.Status, StatusRejected); err != nil {
+		return err
+	}
+
 	lecturerID, _ := s.studentRepo.GetLecturerIDByUserID(ctx, lecturerUserID)
-	ok, _ := s.studentRepo.IsStudentUnderAdvisor(ctx, ref.StudentID, lecturerID)
-	if !ok {
+	if !canReview(ctx, s.studentRepo, ref, lecturerID) {
 		return errors.New("bukan mahasiswa bimbingan")
 	}
 
-	return s.pgRepo.Reject(id, note)
+	if err := changeStatus(ctx, s.pgRepo, model.StatusChange{
+		AchievementID: id, From: ref.Status, To: StatusRejected, LecturerID: lecturerID, Note: note,
+		History: &model.AchievementHistory{
+			AchievementID: id,
+			ActorUserID:   lecturerUserID,
+			ActorRole:     "Dosen Wali",
+			FromStatus:    ref.Status,
+			ToStatus:      StatusRejected,
+			Note:          note,
+		},
+	}); err != nil {
+		return err
+	}
+
+	return nil
 }
 
-// ================= HISTORY =================
-func (s *AchievementLogicService) History(id string) ([]map[string]any, error) {
+// ================= REVISE =================
+func (s *AchievementLogicService) Revise(id string, studentUserID string) error {
 	ctx := context.Background()
 
+	studentID, _ := s.studentRepo.GetStudentIDByUserID(ctx, studentUserID)
 	ref, err := s.pgRepo.GetByID(ctx, id)
 	if err != nil {
-		return nil, errors.New("achievement tidak ditemukan")
+		return errors.New("achievement tidak ditemukan")
 	}
 
-	oid, _ := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-	detail, _ := s.mongoRepo.FindById(ctx, oid)
+	if ref.StudentID != studentID {
+		return errors.New("bukan prestasi milik sendiri")
+	}
+
+	if err := ValidateTransition(ref.Status, StatusDraft); err != nil {
+		return err
+	}
+
+	if err := changeStatus(ctx, s.pgRepo, model.StatusChange{
+		AchievementID: id, From: ref.Status, To: StatusDraft,
+		History: &model.AchievementHistory{
+			AchievementID: id,
+			ActorUserID:   studentUserID,
+			ActorRole:     "Mahasiswa",
+			FromStatus:    ref.Status,
+			ToStatus:      StatusDraft,
+		},
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
 
-	history := []map[string]any{
-		{"status": "created", "timestamp": detail.CreatedAt},
-		{"status": ref.Status, "timestamp": detail.UpdatedAt},
+// ================= HISTORY =================
+func (s *AchievementLogicService) History(id string) ([]model.AchievementHistory, error) {
+	ctx := context.Background()
+
+	ref, err := s.pgRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("achievement tidak ditemukan")
 	}
 
-	return history, nil
+	if s.historyRepo == nil {
+		return nil, errors.New("riwayat prestasi tidak tersedia")
+	}
+	return s.historyRepo.GetByAchievementID(ctx, ref.ID)
 }
 
 // ================= UPDATE =================
@@ -180,12 +401,37 @@ func (s *AchievementLogicService) Update(id string, studentUserID string, req mo
 		return errors.New("bukan prestasi milik sendiri")
 	}
 
-	if ref.Status != "draft" {
-		return errors.New("hanya draft yang bisa diubah")
+	if err := ValidateEditable(ref.Status); err != nil {
+		return err
 	}
 
 	oid, _ := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-	return s.mongoRepo.Update(ctx, oid, bson.M{
-		"title": req.Title,
+	before, err := s.mongoRepo.FindById(ctx, oid)
+	if err != nil {
+		return errors.New("detail prestasi tidak ditemukan")
+	}
+
+	req.AchievementType, err = resolveAchievementType(ctx, s.typeRepo, req.AchievementType, req.Details)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mongoRepo.Update(ctx, oid, bson.M{
+		"title":           req.Title,
+		"description":     req.Description,
+		"achievementType": req.AchievementType,
+		"details":         req.Details,
+		"tags":            req.Tags,
+	}); err != nil {
+		return err
+	}
+
+	return writeHistory(ctx, s.historyRepo, model.AchievementHistory{
+		AchievementID: id,
+		ActorUserID:   studentUserID,
+		ActorRole:     "Mahasiswa",
+		FromStatus:    ref.Status,
+		ToStatus:      ref.Status,
+		ChangedFields: changedFields(before, req),
 	})
 }
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	minSearchLength   = 2
+	snippetRadius     = 40 // karakter sebelum kata pertama yang cocok
+	snippetLength     = 160
+	maxHighlightCount = 5
+
+	// maxSearchResults: hasil teratas (per relevansi) yang diambil dari Mongo
+	// per pencarian; total & paging dihitung dari batas ini
+	maxSearchResults = 500
+)
+
+type searchField struct {
+	name string
+	text string
+}
+
+// parseSearchQuery: q wajib, page/limit sama seperti GET /achievements
+func parseSearchQuery(c *fiber.Ctx) (text string, page, limit int, err error) {
+	text = strings.TrimSpace(c.Query("q"))
+	page = c.QueryInt("page", 1)
+	limit = c.QueryInt("limit", defaultListLimit)
+
+	if len([]rune(text)) < minSearchLength {
+		return text, page, limit, fmt.Errorf("q must be at least %d characters", minSearchLength)
+	}
+	if page < 1 {
+		return text, page, limit, fmt.Errorf("page must be >= 1")
+	}
+	if limit < 1 || limit > maxListLimit {
+		return text, page, limit, fmt.Errorf("limit must be between 1 and %d", maxListLimit)
+	}
+	return text, page, limit, nil
+}
+
+// searchAchievements: $text di Mongo (urut relevansi) → disaring PostgreSQL
+// (reference ada & tidak deleted) → total & paging dihitung dari hasil saringan
+// yang sama → detail halaman ini + snippet
+func searchAchievements(
+	ctx context.Context,
+	pg repository.IAchievementPGRepository,
+	mg repository.IAchievementMongoRepository,
+	text string,
+	scope []string,
+	page, limit int,
+) (model.AchievementSearchResponse, error) {
+
+	resp := model.AchievementSearchResponse{
+		Query: text,
+		Data:  []model.AchievementSearchHit{},
+		Meta:  model.PageMeta{Page: page, Limit: limit},
+	}
+
+	if scope != nil && len(scope) == 0 {
+		return resp, nil
+	}
+
+	matches, err := mg.SearchIDs(ctx, text, scope, maxSearchResults)
+	if err != nil || len(matches) == 0 {
+		return resp, err
+	}
+
+	mongoIDs := make([]string, 0, len(matches))
+	for _, m := range matches {
+		mongoIDs = append(mongoIDs, m.MongoID)
+	}
+
+	refs, err := pg.GetByMongoIDs(ctx, mongoIDs)
+	if err != nil {
+		return resp, err
+	}
+
+	refMap := make(map[string]model.AchievementReference, len(refs))
+	for _, ref := range refs {
+		if ref.Status != StatusDeleted {
+			refMap[ref.MongoAchievementID] = ref
+		}
+	}
+
+	// dokumen yatim / sudah dihapus di PostgreSQL → ditangani reconcile
+	visible := []model.SearchMatch{}
+	for _, m := range matches {
+		if _, ok := refMap[m.MongoID]; ok {
+			visible = append(visible, m)
+		}
+	}
+
+	resp.Meta.Total = len(visible)
+	resp.Meta.TotalPages = (len(visible) + limit - 1) / limit
+
+	start := (page - 1) * limit
+	if start >= len(visible) {
+		return resp, nil
+	}
+	end := start + limit
+	if end > len(visible) {
+		end = len(visible)
+	}
+	visible = visible[start:end]
+
+	oids := make([]primitive.ObjectID, 0, len(visible))
+	for _, m := range visible {
+		if oid, err := primitive.ObjectIDFromHex(m.MongoID); err == nil {
+			oids = append(oids, oid)
+		}
+	}
+
+	docs, err := mg.FindManyByIDs(ctx, oids)
+	if err != nil {
+		return resp, err
+	}
+
+	terms := searchTerms(text)
+
+	for _, m := range visible {
+		ref := refMap[m.MongoID]
+		doc := docs[m.MongoID]
+
+		resp.Data = append(resp.Data, model.AchievementSearchHit{
+			ID:         ref.ID,
+			StudentID:  ref.StudentID,
+			Status:     ref.Status,
+			Score:      m.Score,
+			Title:      doc.Title,
+			Type:       doc.AchievementType,
+			Highlights: highlights(doc, terms),
+		})
+	}
+
+	return resp, nil
+}
+
+// searchTerms: kata dari q (tanpa tanda kutip & kata negasi "-xxx"), lowercase, unik
+func searchTerms(q string) []string {
+	seen := map[string]bool{}
+	terms := []string{}
+
+	for _, w := range strings.Fields(q) {
+		if strings.HasPrefix(w, "-") {
+			continue
+		}
+		w = strings.ToLower(strings.Trim(w, `"`))
+		if w == "" || seen[w] {
+			continue
+		}
+		seen[w] = true
+		terms = append(terms, w)
+	}
+
+	// kata terpanjang dulu supaya "lomba" tidak memotong "perlombaan"
+	sort.SliceStable(terms, func(i, j int) bool {
+		return len([]rune(terms[i])) > len([]rune(terms[j]))
+	})
+	return terms
+}
+
+// highlights: snippet per field yang memuat salah satu kata pencarian
+func highlights(doc model.AchievementMongo, terms []string) []model.SearchHighlight {
+	fields := []searchField{
+		{"title", doc.Title},
+		{"tags", strings.Join(doc.Tags, ", ")},
+		{"description", doc.Description},
+	}
+	fields = append(fields, detailFields("details", doc.Details)...)
+
+	out := []model.SearchHighlight{}
+	for _, f := range fields {
+		if snippet, ok := highlight(f.text, terms); ok {
+			out = append(out, model.SearchHighlight{Field: f.name, Snippet: snippet})
+			if len(out) == maxHighlightCount {
+				break
+			}
+		}
+	}
+	return out
+}
+
+// detailFields meratakan nilai string di dalam details (nested map / array)
+func detailFields(prefix string, v interface{}) []searchField {
+	switch val := v.(type) {
+	case string:
+		return []searchField{{prefix, val}}
+
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		out := []searchField{}
+		for _, k := range keys {
+			out = append(out, detailFields(prefix+"."+k, val[k])...)
+		}
+		return out
+
+	case primitive.M:
+		return detailFields(prefix, map[string]interface{}(val))
+
+	case primitive.D:
+		return detailFields(prefix, map[string]interface{}(val.Map()))
+
+	case primitive.A:
+		return detailFields(prefix, []interface{}(val))
+
+	case []interface{}:
+		out := []searchField{}
+		for i, item := range val {
+			out = append(out, detailFields(fmt.Sprintf("%s[%d]", prefix, i), item)...)
+		}
+		return out
+	}
+	return nil
+}
+
+// highlight memotong teks di sekitar kata pertama yang cocok dan
+// membungkus setiap kata yang cocok dengan <mark>…</mark> (teks lain di-escape)
+func highlight(text string, terms []string) (string, bool) {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	type span struct{ start, end int }
+	matches := []span{}
+
+	for i := 0; i < len(lower); {
+		matched := 0
+		for _, t := range terms {
+			tr := []rune(t)
+			if i+len(tr) <= len(lower) && string(lower[i:i+len(tr)]) == t {
+				matched = len(tr)
+				break
+			}
+		}
+		if matched == 0 {
+			i++
+			continue
+		}
+		matches = append(matches, span{i, i + matched})
+		i += matched
+	}
+
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	start := matches[0].start - snippetRadius
+	if start < 0 {
+		start = 0
+	}
+	// mulai di awal kata
+	for start > 0 && start < matches[0].start && !unicode.IsSpace(runes[start-1]) {
+		start++
+	}
+	end := start + snippetLength
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+
+	pos := start
+	for _, m := range matches {
+		if m.start < pos || m.start >= end {
+			continue
+		}
+		mEnd := m.end
+		if mEnd > end {
+			mEnd = end
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:m.start])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[m.start:mEnd])))
+		b.WriteString("</mark>")
+		pos = mEnd
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+
+	return b.String(), true
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func seedSearch(pg *repository.MockAchievementPGRepository, mg *repository.MockAchievementMongoRepository, id, studentID, status string, doc model.AchievementMongo) {
+	oid := primitive.NewObjectID()
+	pg.Data[id] = model.AchievementReference{
+		ID:                 id,
+		StudentID:          studentID,
+		MongoAchievementID: oid.Hex(),
+		Status:             status,
+	}
+	doc.ID = oid
+	doc.StudentID = studentID
+	doc.Status = status
+	mg.Data[oid.Hex()] = doc
+}
+
+func seedSearchCatalog(pg *repository.MockAchievementPGRepository, mg *repository.MockAchievementMongoRepository, st *repository.MockStudentRepository) {
+	seedSearch(pg, mg, "a1", "s1", StatusVerified, model.AchievementMongo{
+		Title:       "Juara 1 Gemastik",
+		Description: "Kompetisi nasional bidang keamanan siber",
+		Tags:        []string{"gemastik", "nasional"},
+	})
+	seedSearch(pg, mg, "a2", "s2", StatusSubmitted, model.AchievementMongo{
+		Title:       "Publikasi jurnal",
+		Description: "Artikel tentang data mining",
+		Details:     map[string]interface{}{"journal": "Jurnal Gemastik Indonesia"},
+	})
+	seedSearch(pg, mg, "a3", "s3", StatusDeleted, model.AchievementMongo{
+		Title: "Gemastik yang dihapus",
+	})
+
+	st.UserToLecturer["uLect"] = "lect1"
+	st.AdvisorMap["s2"] = "lect1"
+}
+
+func TestSearchAchievements_RelevanceAndHighlights(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	st := repository.NewMockStudentRepository()
+	seedSearchCatalog(pg, mg, st)
+
+	svc := NewAchievementLogicService(pg, mg, st, nil, nil, nil, nil)
+
+	resp, err := svc.Search("Admin", "admin", "gemastik", 1, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(resp.Data) != 2 || resp.Data[0].ID != "a1" || resp.Data[1].ID != "a2" {
+		t.Fatalf("expected a1 (title match) before a2 (details match), got %+v", resp.Data)
+	}
+
+	if h := resp.Data[0].Highlights[0]; h.Field != "title" || h.Snippet != "Juara 1 <mark>Gemastik</mark>" {
+		t.Fatalf("unexpected title highlight: %+v", h)
+	}
+
+	found := false
+	for _, h := range resp.Data[1].Highlights {
+		if h.Field == "details.journal" && strings.Contains(h.Snippet, "<mark>Gemastik</mark>") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("details match not highlighted: %+v", resp.Data[1].Highlights)
+	}
+}
+
+func TestSearchAchievements_ScopedToAdvisor(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	st := repository.NewMockStudentRepository()
+	seedSearchCatalog(pg, mg, st)
+
+	svc := NewAchievementLogicService(pg, mg, st, nil, nil, nil, nil)
+
+	resp, err := svc.Search("Dosen Wali", "uLect", "gemastik", 1, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(resp.Data) != 1 || resp.Data[0].ID != "a2" {
+		t.Fatalf("advisor should only see advisee results, got %+v", resp.Data)
+	}
+}
+
+func TestSearchAchievements_TotalMatchesVisibleResults(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+
+	for _, id := range []string{"a1", "a2", "a3"} {
+		seedSearch(pg, mg, id, "s1", StatusVerified, model.AchievementMongo{Title: "Gemastik " + id})
+	}
+	// Mongo belum tersinkron: PostgreSQL sudah deleted
+	ref := pg.Data["a2"]
+	ref.Status = StatusDeleted
+	pg.Data["a2"] = ref
+	// dokumen yatim tanpa reference
+	orphan := primitive.NewObjectID()
+	mg.Data[orphan.Hex()] = model.AchievementMongo{ID: orphan, StudentID: "s1", Title: "Gemastik yatim"}
+
+	resp, err := searchAchievements(context.Background(), pg, mg, "gemastik", nil, 1, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Meta.Total != 2 || resp.Meta.TotalPages != 2 || len(resp.Data) != 1 {
+		t.Fatalf("total must count only visible hits, got meta=%+v data=%d", resp.Meta, len(resp.Data))
+	}
+
+	page2, _ := searchAchievements(context.Background(), pg, mg, "gemastik", nil, 2, 1)
+	if len(page2.Data) != 1 || page2.Data[0].ID == resp.Data[0].ID || page2.Data[0].ID == "a2" {
+		t.Fatalf("unexpected second page: %+v", page2.Data)
+	}
+}
+
+func TestSearchAchievements_CapsMongoResults(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+
+	for i := 0; i < maxSearchResults+5; i++ {
+		seedSearch(pg, mg, fmt.Sprintf("a%d", i), "s1", StatusVerified, model.AchievementMongo{Title: "Gemastik"})
+	}
+
+	resp, err := searchAchievements(context.Background(), pg, mg, "gemastik", nil, 1, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Meta.Total != maxSearchResults {
+		t.Fatalf("expected total capped at %d, got %d", maxSearchResults, resp.Meta.Total)
+	}
+}
+
+func TestHighlight_TrimsAndEscapes(t *testing.T) {
+	text := strings.Repeat("kata ", 30) + "<b>lomba</b> " + strings.Repeat("akhir ", 40)
+
+	snippet, ok := highlight(text, searchTerms("LOMBA"))
+	if !ok {
+		t.Fatal("expected match")
+	}
+	if !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") {
+		t.Fatalf("expected trimmed snippet, got %q", snippet)
+	}
+	if !strings.Contains(snippet, "&lt;b&gt;<mark>lomba</mark>&lt;/b&gt;") {
+		t.Fatalf("expected escaped text around mark, got %q", snippet)
+	}
+
+	if _, ok := highlight("tidak ada", []string{"lomba"}); ok {
+		t.Fatal("expected no match")
+	}
+}
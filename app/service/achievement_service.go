@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -20,14 +21,66 @@ type AchievementService struct {
 	pgRepo      *repository.AchievementPGRepository
 	mongoRepo   *repository.AchievementMongoRepository
 	studentRepo *repository.StudentRepository
+	historyRepo *repository.AchievementHistoryRepository
+	typeRepo    *repository.AchievementTypeRepository
+	points      *PointsEngine
+	notifier    *Notifier
+	sync        *AchievementSyncer
+	stats       *StatsMaterializer
 }
 
 func NewAchievementService(
 	pg *repository.AchievementPGRepository,
 	mg *repository.AchievementMongoRepository,
 	st *repository.StudentRepository,
+	hs *repository.AchievementHistoryRepository,
+	ts *repository.AchievementTypeRepository,
+	points *PointsEngine,
+	notifier *Notifier,
+	sync *AchievementSyncer,
+	stats *StatsMaterializer,
 ) *AchievementService {
-	return &AchievementService{pgRepo: pg, mongoRepo: mg, studentRepo: st}
+	return &AchievementService{
+		pgRepo:      pg,
+		mongoRepo:   mg,
+		studentRepo: st,
+		historyRepo: hs,
+		typeRepo:    ts,
+		points:      points,
+		notifier:    notifier,
+		sync:        sync,
+		stats:       stats,
+	}
+}
+
+// historyEntry = riwayat dengan actor dari JWT claims
+func historyEntry(c *fiber.Ctx, achievementID, from, to, note string) *model.AchievementHistory {
+	claims := c.Locals("user").(jwt.MapClaims)
+	userID, _ := claims["user_id"].(string)
+	role, _ := claims["role_name"].(string)
+
+	return &model.AchievementHistory{
+		AchievementID: achievementID,
+		ActorUserID:   userID,
+		ActorRole:     role,
+		FromStatus:    from,
+		ToStatus:      to,
+		Note:          note,
+	}
+}
+
+// recordHistory mencatat event non-transisi (create / edit konten),
+// lalu memperbarui statistik materialized prestasi tersebut
+func (s *AchievementService) recordHistory(
+	ctx context.Context,
+	c *fiber.Ctx,
+	achievementID, from, to, note string,
+	changed []string,
+) error {
+	h := historyEntry(c, achievementID, from, to, note)
+	h.ChangedFields = changed
+
+	return writeHistory(ctx, s.historyRepo, *h)
 }
 
 // =====================================================
@@ -44,7 +97,7 @@ func NewAchievementService(
 // @Produce json
 // @Param body body model.AchievementCreateRequest true "Achievement payload"
 // @Success 201 {object} map[string]interface{}
-// @Failure 400 {object} map[string]string
+// @Failure 400 {object} map[string]any
 // @Failure 401 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /achievements [post]
@@ -68,16 +121,22 @@ func (s *AchievementService) Create(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "title & achievementType required"})
 	}
 
+	// jenis prestasi harus terdaftar & details sesuai definisinya
+	achType, err := resolveAchievementType(ctx, s.typeRepo, req.AchievementType, req.Details)
+	if err != nil {
+		return validationFailed(c, err)
+	}
+
 	ach := model.AchievementMongo{
 		StudentID:       studentID,
 		Title:           req.Title,
 		Description:     req.Description,
-		AchievementType: req.AchievementType,
+		AchievementType: achType,
 		Details:         req.Details,
 		Tags:            req.Tags,
 		CreatedAt:       time.Now().Unix(),
 		UpdatedAt:       time.Now().Unix(),
-		Status:          "draft",
+		Status:          StatusDraft,
 	}
 
 	mongoID, err := s.mongoRepo.Create(ctx, ach)
@@ -89,12 +148,16 @@ func (s *AchievementService) Create(c *fiber.Ctx) error {
 		ID:                 uuid.New().String(),
 		StudentID:          studentID,
 		MongoAchievementID: mongoID.Hex(),
-		Status:             "draft",
+		Status:             StatusDraft,
 	}
 
-	if err := s.pgRepo.CreateReference(ref); err != nil {
+	// reference + riwayat "dibuat" dalam satu transaksi
+	if err := s.pgRepo.CreateReference(ref, historyEntry(c, ref.ID, "", StatusDraft, "")); err != nil {
+		// kompensasi: jangan tinggalkan dokumen Mongo tanpa reference
+		s.sync.CompensateCreate(ctx, mongoID)
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
+	s.stats.Refresh(ctx, ref.ID)
 
 	return c.Status(201).JSON(fiber.Map{
 		"message":     "achievement created",
@@ -116,6 +179,7 @@ func (s *AchievementService) Create(c *fiber.Ctx) error {
 // @Param id path string true "Achievement ID"
 // @Success 200 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /achievements/{id}/submit [put]
 func (s *AchievementService) Submit(c *fiber.Ctx) error {
@@ -127,12 +191,23 @@ func (s *AchievementService) Submit(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
 	}
 
-	if err := s.pgRepo.UpdateStatus(id, "submitted"); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	if err := ValidateTransition(ref.Status, StatusSubmitted); err != nil {
+		return conflict(c, err)
+	}
+
+	if err := changeStatus(ctx, s.pgRepo, model.StatusChange{
+		AchievementID: id, From: ref.Status, To: StatusSubmitted,
+		History: historyEntry(c, id, ref.Status, StatusSubmitted, ""),
+	}); err != nil {
+		return statusChangeFailed(c, err)
 	}
 
-	mongoID, _ := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-	s.mongoRepo.Update(ctx, mongoID, bson.M{"status": "submitted"})
+	s.sync.SyncStatus(ctx, ref.MongoAchievementID)
+
+	s.stats.Refresh(ctx, id)
+
+	// Notifikasi ke dosen wali
+	s.notifier.AchievementSubmitted(ctx, ref)
 
 	return c.JSON(fiber.Map{"message": "achievement submitted"})
 }
@@ -152,6 +227,7 @@ func (s *AchievementService) Submit(c *fiber.Ctx) error {
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /achievements/{id} [delete]
 func (s *AchievementService) Delete(c *fiber.Ctx) error {
@@ -163,22 +239,25 @@ func (s *AchievementService) Delete(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
 	}
 
-	if ref.Status != "draft" {
-		return c.Status(400).JSON(fiber.Map{"error": "Only draft achievements can be deleted"})
+	if err := ValidateTransition(ref.Status, StatusDeleted); err != nil {
+		return conflict(c, err)
 	}
 
-	mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-	if err != nil {
+	if _, err := primitive.ObjectIDFromHex(ref.MongoAchievementID); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid MongoID"})
 	}
 
-	if err := s.mongoRepo.SoftDelete(ctx, mongoID); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete MongoDB data"})
+	// PostgreSQL dulu (sumber kebenaran), lalu soft delete Mongo via syncer
+	if err := changeStatus(ctx, s.pgRepo, model.StatusChange{
+		AchievementID: id, From: ref.Status, To: StatusDeleted,
+		History: historyEntry(c, id, ref.Status, StatusDeleted, ""),
+	}); err != nil {
+		return statusChangeFailed(c, err)
 	}
 
-	if err := s.pgRepo.UpdateStatus(id, "deleted"); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to update PostgreSQL"})
-	}
+	s.sync.SyncStatus(ctx, ref.MongoAchievementID)
+
+	s.stats.Refresh(ctx, id)
 
 	return c.JSON(fiber.Map{"message": "Achievement deleted successfully"})
 }
@@ -190,137 +269,82 @@ func (s *AchievementService) Delete(c *fiber.Ctx) error {
 // =====================================================
 // GetAchievements godoc
 // @Summary Get achievements list
-// @Description Get achievements based on role (Mahasiswa, Dosen Wali, Admin)
+// @Description Paginated achievements within the caller's data scope (own, advisees, or all with scope:all) with sorting & filters
 // @Tags Achievements
 // @Security BearerAuth
 // @Produce json
-// @Success 200 {array} map[string]interface{}
+// @Param page query int false "Page (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param sort query string false "created_at | submitted_at | points"
+// @Param order query string false "asc | desc (default desc)"
+// @Param status query string false "Comma separated status filter"
+// @Param achievementType query string false "Achievement type"
+// @Param tags query string false "Comma separated tags (all must match)"
+// @Param student_id query string false "Student ID"
+// @Param program_study query string false "Program study"
+// @Param from query string false "Created from (YYYY-MM-DD)"
+// @Param to query string false "Created until (YYYY-MM-DD, inclusive)"
+// @Success 200 {object} model.AchievementListResponse
+// @Failure 400 {object} map[string]string
 // @Failure 403 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /achievements [get]
 func (s *AchievementService) GetAll(c *fiber.Ctx) error {
 
-	claims := c.Locals("user").(jwt.MapClaims)
-	role := claims["role_name"].(string)
-	userID := claims["user_id"].(string)
 	ctx := context.Background()
 
-	switch role {
-
-	// =====================================================
-	//  MAHASISWA → hanya prestasi miliknya (tanpa join Mongo)
-	// =====================================================
-	case "Mahasiswa":
-		studentID, err := s.studentRepo.GetStudentIDByUserID(ctx, userID)
-		if err != nil {
-			return c.Status(404).JSON(fiber.Map{"error": "student not found"})
-		}
-
-		data, err := s.pgRepo.GetByStudentID(ctx, studentID)
-		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-		}
-
-		return c.JSON(data)
-
-	// =====================================================
-	//  DOSEN WALI → ambil semua prestasi seluruh mahasiswa bimbingan
-	// =====================================================
-	case "Dosen Wali":
-
-		advisorID, err := s.studentRepo.GetLecturerIDByUserID(ctx, userID)
-		if err != nil {
-			return c.Status(404).JSON(fiber.Map{"error": "lecturer not found"})
-		}
-
-		// daftar mahasiswa bimbingan
-		studentIDs, err := s.studentRepo.GetStudentsByAdvisor(ctx, advisorID)
-		if err != nil || len(studentIDs) == 0 {
-			return c.JSON([]any{})
-		}
-
-		refs, err := s.pgRepo.GetByStudentIDs(ctx, studentIDs)
-		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-		}
-
-		// kumpulkan ObjectID Mongo
-		mongoIDs := []primitive.ObjectID{}
-		for _, r := range refs {
-			oid, err := primitive.ObjectIDFromHex(r.MongoAchievementID)
-			if err == nil {
-				mongoIDs = append(mongoIDs, oid)
-			}
-		}
-
-		// ambil detail mongo sekaligus
-		mongoMap, err := s.mongoRepo.FindManyByIDs(ctx, mongoIDs)
-		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-		}
-
-		// gabungkan PG + Mongo
-		response := []fiber.Map{}
-		for _, ref := range refs {
-			response = append(response, fiber.Map{
-				"id":         ref.ID,
-				"student_id": ref.StudentID,
-				"status":     ref.Status,
-				"detail":     mongoMap[ref.MongoAchievementID],
-			})
-		}
-
-		return c.JSON(response)
-
-	// =====================================================
-	//  ADMIN → FR-010 (list semua prestasi + join Mongo)
-	// =====================================================
-	case "Admin":
-
-		// 1. Ambil semua achievement references
-		refs, err := s.pgRepo.GetAll(ctx)
-		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-		}
-
-		if len(refs) == 0 {
-			return c.JSON([]any{})
-		}
-
-		// 2. Convert ke ObjectID list
-		mongoIDs := []primitive.ObjectID{}
-		for _, r := range refs {
-			oid, err := primitive.ObjectIDFromHex(r.MongoAchievementID)
-			if err == nil {
-				mongoIDs = append(mongoIDs, oid)
-			}
-		}
-
-		// 3. Fetch all Mongo detail
-		mongoMap, err := s.mongoRepo.FindManyByIDs(ctx, mongoIDs)
-		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-		}
-
-		// 4. Gabungkan PG + Mongo
-		response := []fiber.Map{}
-		for _, ref := range refs {
-			response = append(response, fiber.Map{
-				"id":         ref.ID,
-				"student_id": ref.StudentID,
-				"status":     ref.Status,
-				"detail":     mongoMap[ref.MongoAchievementID],
-			})
-		}
-
-		return c.JSON(response)
-
-	// =====================================================
-	//  ROLE INVALID
-	// =====================================================
-	default:
-		return c.Status(403).JSON(fiber.Map{"error": "Forbidden"})
+	q, err := parseAchievementListQuery(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	q.ScopeStudentIDs, err = achievementScope(ctx, c, s.studentRepo)
+	if err != nil {
+		return scopeError(c, err)
+	}
+
+	resp, err := listAchievements(ctx, s.pgRepo, s.mongoRepo, q)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
+
+	return c.JSON(resp)
+}
+
+// SearchAchievements godoc
+// @Summary Full-text search achievements
+// @Description Search title, description, tags and details, ordered by relevance with highlighted snippets (filtered by data scope, top 500 matches)
+// @Tags Achievements
+// @Security BearerAuth
+// @Produce json
+// @Param q query string true "Search text"
+// @Param page query int false "Page (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} model.AchievementSearchResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /achievements/search [get]
+func (s *AchievementService) Search(c *fiber.Ctx) error {
+
+	ctx := context.Background()
+
+	text, page, limit, err := parseSearchQuery(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	scope, err := achievementScope(ctx, c, s.studentRepo)
+	if err != nil {
+		return scopeError(c, err)
+	}
+
+	resp, err := searchAchievements(ctx, s.pgRepo, s.mongoRepo, text, scope, page, limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(resp)
 }
 
 // =====================================================
@@ -339,6 +363,7 @@ func (s *AchievementService) GetAll(c *fiber.Ctx) error {
 // @Failure 400 {object} map[string]string
 // @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /achievements/{id}/verify [put]
 func (s *AchievementService) Verify(c *fiber.Ctx) error {
 	id := c.Params("id")
@@ -350,11 +375,9 @@ func (s *AchievementService) Verify(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
 	}
 
-	// 2. Precondition: harus submitted
-	if ref.Status != "submitted" {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Only submitted achievements can be verified",
-		})
+	// 2. Precondition: transisi submitted → verified
+	if err := ValidateTransition(ref.Status, StatusVerified); err != nil {
+		return conflict(c, err)
 	}
 
 	// 3. Ambil user_id dari token
@@ -367,29 +390,52 @@ func (s *AchievementService) Verify(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "lecturer not found"})
 	}
 
-	// 5. Update PostgreSQL
-	if err := s.pgRepo.Verify(id, lecturerID); err != nil {
+	// pastikan student ini bimbingannya (atau review dialihkan kepadanya)
+	if !canReview(ctx, s.studentRepo, ref, lecturerID) {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Forbidden: this student is not your advisee",
+		})
+	}
+
+	// 5. Hitung poin dari detail prestasi (aturan aktif)
+	oid, _ := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+	doc, err := s.mongoRepo.FindById(ctx, oid)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "achievement detail not found"})
+	}
+
+	points, err := s.points.Calculate(ctx, doc)
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	// 6. Update MongoDB
-	mongoID, _ := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-	s.mongoRepo.Update(ctx, mongoID, bson.M{
-		"status":    "verified",
-		"updatedAt": time.Now().Unix(),
-	})
+	// 6. Update PostgreSQL
+	if err := changeStatus(ctx, s.pgRepo, model.StatusChange{
+		AchievementID: id, From: ref.Status, To: StatusVerified, LecturerID: lecturerID,
+		Points:  &points.Total,
+		History: historyEntry(c, id, ref.Status, StatusVerified, ""),
+	}); err != nil {
+		return statusChangeFailed(c, err)
+	}
 
-	// 7. Return success
+	// 7. Update MongoDB (retry / outbox) + simpan poin
+	s.sync.SyncStatus(ctx, ref.MongoAchievementID)
+	if err := s.sync.SyncPoints(ctx, ref.MongoAchievementID, points); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "achievement verified, but points were not saved: " + err.Error()})
+	}
+
+	s.stats.Refresh(ctx, id)
+
+	// 8. Notifikasi ke mahasiswa
+	s.notifier.AchievementVerified(ctx, ref, &points.Total)
+
+	// 9. Return success
 	return c.JSON(fiber.Map{
 		"message":     "achievement verified",
 		"id":          id,
 		"verified_by": lecturerID,
-		"status":      "verified",
-		"notification": model.Notification{
-			Title:   "Prestasi Diverifikasi",
-			Message: "Prestasi kamu telah diverifikasi oleh dosen wali.",
-			SentAt:  time.Now().Unix(),
-		},
+		"status":      StatusVerified,
+		"points":      points,
 	})
 }
 
@@ -411,6 +457,7 @@ func (s *AchievementService) Verify(c *fiber.Ctx) error {
 // @Failure 400 {object} map[string]string
 // @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /achievements/{id}/reject [put]
 func (s *AchievementService) Reject(c *fiber.Ctx) error {
 
@@ -423,11 +470,9 @@ func (s *AchievementService) Reject(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
 	}
 
-	// 2. Precondition: harus submitted
-	if ref.Status != "submitted" {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Only submitted achievements can be rejected",
-		})
+	// 2. Precondition: transisi submitted → rejected
+	if err := ValidateTransition(ref.Status, StatusRejected); err != nil {
+		return conflict(c, err)
 	}
 
 	// 3. Ambil dosen wali (verifier)
@@ -439,9 +484,8 @@ func (s *AchievementService) Reject(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "lecturer not found"})
 	}
 
-	// pastikan student ini adalah bimbingannya
-	isBimbingan, _ := s.studentRepo.IsStudentUnderAdvisor(ctx, ref.StudentID, advisorID)
-	if !isBimbingan {
+	// pastikan student ini adalah bimbingannya (atau review dialihkan kepadanya)
+	if !canReview(ctx, s.studentRepo, ref, advisorID) {
 		return c.Status(403).JSON(fiber.Map{
 			"error": "Forbidden: this student is not your advisee",
 		})
@@ -458,46 +502,164 @@ func (s *AchievementService) Reject(c *fiber.Ctx) error {
 	}
 
 	// 5. Update status PostgreSQL
-	if err := s.pgRepo.Reject(id, body.Note); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	if err := changeStatus(ctx, s.pgRepo, model.StatusChange{
+		AchievementID: id, From: ref.Status, To: StatusRejected, LecturerID: advisorID, Note: body.Note,
+		History: historyEntry(c, id, ref.Status, StatusRejected, body.Note),
+	}); err != nil {
+		return statusChangeFailed(c, err)
 	}
 
-	// 6. Update MongoDB
-	mongoID, _ := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-	s.mongoRepo.Update(ctx, mongoID, bson.M{
-		"status": "rejected",
-	})
+	// 6. Update MongoDB (retry / outbox)
+	s.sync.SyncStatus(ctx, ref.MongoAchievementID)
+
+	s.stats.Refresh(ctx, id)
 
-	// 7. (Optional) Simulasi Notification
-	//
-	_ = s.createNotificationDummy(ref.StudentID, "Prestasi kamu ditolak oleh dosen wali")
+	// 7. Notifikasi ke mahasiswa
+	s.notifier.AchievementRejected(ctx, ref, body.Note)
 
 	// 8. Return response
 	return c.JSON(fiber.Map{
 		"message":        "achievement rejected",
 		"id":             id,
-		"status":         "rejected",
+		"status":         StatusRejected,
 		"rejection_note": body.Note,
-		"notification": model.Notification{
-			Title:   "Prestasi Ditolak",
-			Message: "Prestasi kamu ditolak. Catatan: " + body.Note,
-			SentAt:  time.Now().Unix(),
-		},
 	})
 
 }
 
 // =====================================================
 //
-//	Dummy Notification — tidak disimpan, hanya simulasi
+//	BULK VERIFY / REJECT (Dosen Wali)
 //
 // =====================================================
-func (s *AchievementService) createNotificationDummy(studentID string, msg string) model.Notification {
-	return model.Notification{
-		Title:   "Notifikasi Prestasi",
-		Message: msg,
-		SentAt:  time.Now().Unix(),
+// BulkVerifyAchievements godoc
+// @Summary Bulk verify achievements
+// @Description Verify many submitted achievements in one PostgreSQL transaction; returns a result per item (Dosen Wali)
+// @Tags Achievements
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body model.BulkReviewRequest true "Achievement IDs"
+// @Success 200 {object} model.BulkReviewResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /achievements/bulk/verify [post]
+func (s *AchievementService) BulkVerify(c *fiber.Ctx) error {
+	return s.bulkReview(c, StatusVerified)
+}
+
+// BulkRejectAchievements godoc
+// @Summary Bulk reject achievements
+// @Description Reject many submitted achievements with a shared or per-item note in one PostgreSQL transaction; returns a result per item (Dosen Wali)
+// @Tags Achievements
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body model.BulkReviewRequest true "Achievement IDs and notes"
+// @Success 200 {object} model.BulkReviewResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /achievements/bulk/reject [post]
+func (s *AchievementService) BulkReject(c *fiber.Ctx) error {
+	return s.bulkReview(c, StatusRejected)
+}
+
+func (s *AchievementService) bulkReview(c *fiber.Ctx, toStatus string) error {
+	claims := c.Locals("user").(jwt.MapClaims)
+	userID := claims["user_id"].(string)
+
+	var req model.BulkReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	items, err := bulkItems(req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	reviewer := bulkReviewer{
+		pgRepo:      s.pgRepo,
+		mongoRepo:   s.mongoRepo,
+		studentRepo: s.studentRepo,
+		points:      s.points,
+		notifier:    s.notifier,
+		sync:        s.sync,
+		stats:       s.stats,
+	}
+
+	resp, err := reviewer.run(context.Background(), toStatus, userID, items)
+	if errors.Is(err, ErrLecturerNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(resp)
+}
+
+// =====================================================
+//
+//	FR-008b — REVISI PRESTASI DITOLAK (Mahasiswa)
+//
+// =====================================================
+// ReviseAchievement godoc
+// @Summary Revise rejected achievement
+// @Description Move rejected achievement back to draft so it can be edited and resubmitted (rejection notes are kept)
+// @Tags Achievements
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Achievement ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /achievements/{id}/revise [post]
+func (s *AchievementService) Revise(c *fiber.Ctx) error {
+	id := c.Params("id")
+	ctx := context.Background()
+
+	claims := c.Locals("user").(jwt.MapClaims)
+	userID := claims["user_id"].(string)
+
+	sid, err := s.studentRepo.GetStudentIDByUserID(ctx, userID)
+	if err != nil {
+		return c.Status(403).JSON(fiber.Map{"error": "student not found"})
+	}
+
+	ref, err := s.pgRepo.GetByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
+	}
+
+	if ref.StudentID != sid {
+		return c.Status(403).JSON(fiber.Map{"error": "cannot revise other student's achievement"})
 	}
+
+	// rejected → draft
+	if err := ValidateTransition(ref.Status, StatusDraft); err != nil {
+		return conflict(c, err)
+	}
+
+	if err := changeStatus(ctx, s.pgRepo, model.StatusChange{
+		AchievementID: id, From: ref.Status, To: StatusDraft,
+		History: historyEntry(c, id, ref.Status, StatusDraft, ""),
+	}); err != nil {
+		return statusChangeFailed(c, err)
+	}
+
+	s.sync.SyncStatus(ctx, ref.MongoAchievementID)
+
+	s.stats.Refresh(ctx, id)
+
+	return c.JSON(fiber.Map{
+		"message":         "achievement moved back to draft for revision",
+		"id":              id,
+		"status":          StatusDraft,
+		"rejection_notes": ref.RejectionNotes,
+	})
 }
 
 // =====================================================
@@ -527,46 +689,15 @@ func (s *AchievementService) GetByID(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
 	}
 
-	// 2. Ambil claims user
-	claims := c.Locals("user").(jwt.MapClaims)
-	role := claims["role_name"].(string)
-	userID := claims["user_id"].(string)
-
 	// ----------------------------
-	// 3. VALIDASI AKSES BERDASARKAN ROLE
+	// 2-3. VALIDASI AKSES: milik sendiri / mahasiswa bimbingan / scope:all
 	// ----------------------------
-
-	switch role {
-
-	// ======== MAHASISWA ========
-	case "Mahasiswa":
-		studentID, err := s.studentRepo.GetStudentIDByUserID(ctx, userID)
-		if err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": "student not found"})
-		}
-
-		if ref.StudentID != studentID {
-			return c.Status(403).JSON(fiber.Map{"error": "Forbidden: not your achievement"})
-		}
-
-	// ======== DOSEN WALI ========
-	case "Dosen Wali":
-		advisorID, err := s.studentRepo.GetLecturerIDByUserID(ctx, userID)
-		if err != nil {
-			return c.Status(404).JSON(fiber.Map{"error": "lecturer not found"})
-		}
-
-		// cek apakah student ini benar dibimbing dosen ini
-		isBimbingan, err := s.studentRepo.IsStudentUnderAdvisor(ctx, ref.StudentID, advisorID)
-		if err != nil || !isBimbingan {
-			return c.Status(403).JSON(fiber.Map{"error": "Forbidden: not your student"})
-		}
-
-	// ======== ADMIN ========
-	case "Admin":
-		// bebas
-	default:
-		return c.Status(403).JSON(fiber.Map{"error": "Forbidden"})
+	scope, err := callerScope(ctx, c, s.studentRepo)
+	if err != nil {
+		return scopeError(c, err)
+	}
+	if !scope.canSeeAchievement(ctx, s.studentRepo, ref) {
+		return c.Status(403).JSON(fiber.Map{"error": "Forbidden: not your achievement"})
 	}
 
 	// ----------------------------
@@ -588,16 +719,16 @@ func (s *AchievementService) GetByID(c *fiber.Ctx) error {
 	// ----------------------------
 
 	return c.JSON(fiber.Map{
-		"id":             ref.ID,
-		"student_id":     ref.StudentID,
-		"status":         ref.Status,
-		"submitted_at":   ref.SubmittedAt,
-		"verified_at":    ref.VerifiedAt,
-		"verified_by":    ref.VerifiedBy,
-		"rejection_note": ref.RejectionNote,
-		"created_at":     ref.CreatedAt,
-		"updated_at":     ref.UpdatedAt,
-		"detail":         mongoData,
+		"id":              ref.ID,
+		"student_id":      ref.StudentID,
+		"status":          ref.Status,
+		"submitted_at":    ref.SubmittedAt,
+		"verified_at":     ref.VerifiedAt,
+		"verified_by":     ref.VerifiedBy,
+		"rejection_notes": ref.RejectionNotes,
+		"created_at":      ref.CreatedAt,
+		"updated_at":      ref.UpdatedAt,
+		"detail":          mongoData,
 	})
 }
 // =====================================================
@@ -616,6 +747,7 @@ func (s *AchievementService) GetByID(c *fiber.Ctx) error {
 // @Failure 400 {object} map[string]string
 // @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /achievements/{id} [put]
 func (s *AchievementService) Update(c *fiber.Ctx) error {
 	ctx := context.Background()
@@ -642,9 +774,9 @@ func (s *AchievementService) Update(c *fiber.Ctx) error {
 		return c.Status(403).JSON(fiber.Map{"error": "cannot edit other student's achievement"})
 	}
 
-	// Pastikan status = DRAFT
-	if ref.Status != "draft" {
-		return c.Status(400).JSON(fiber.Map{"error": "only DRAFT achievements can be updated"})
+	// Pastikan status masih boleh diedit
+	if err := ValidateEditable(ref.Status); err != nil {
+		return conflict(c, err)
 	}
 
 	// Parse body
@@ -654,6 +786,12 @@ func (s *AchievementService) Update(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	// Validasi jenis prestasi & details terhadap registry
+	body.AchievementType, err = resolveAchievementType(ctx, s.typeRepo, body.AchievementType, body.Details)
+	if err != nil {
+		return validationFailed(c, err)
+	}
+
 	// Build bson update sesuai field Mongo
 	update := bson.M{
 		"title":           body.Title,
@@ -663,12 +801,23 @@ func (s *AchievementService) Update(c *fiber.Ctx) error {
 		"tags":            body.Tags,
 	}
 
-	// Update Mongo
+	// Snapshot lama untuk audit field yang berubah
 	oid, _ := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+	before, err := s.mongoRepo.FindById(ctx, oid)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "achievement detail not found"})
+	}
+
+	// Update Mongo
 	if err := s.mongoRepo.Update(ctx, oid, update); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	s.stats.Refresh(ctx, id)
+	if err := s.recordHistory(ctx, c, id, ref.Status, ref.Status, "", changedFields(before, body)); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "achievement updated, but its history could not be saved: " + err.Error()})
+	}
+
 	return c.JSON(fiber.Map{"message": "achievement updated"})
 }
 // =====================================================
@@ -686,6 +835,7 @@ func (s *AchievementService) Update(c *fiber.Ctx) error {
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /achievements/{id}/attachments [post]
 
@@ -710,8 +860,8 @@ func (s *AchievementService) UploadAttachment(c *fiber.Ctx) error {
         return c.Status(403).JSON(fiber.Map{"error": "cannot upload to other's achievement"})
     }
 
-    if ref.Status != "draft" {
-        return c.Status(400).JSON(fiber.Map{"error": "cannot upload attachment when not in DRAFT status"})
+    if err := ValidateEditable(ref.Status); err != nil {
+        return conflict(c, err)
     }
 
     // Ambil file
@@ -755,7 +905,7 @@ func (s *AchievementService) UploadAttachment(c *fiber.Ctx) error {
 // =====================================================
 // GetAchievementHistory godoc
 // @Summary Get achievement history
-// @Description Get status history of achievement
+// @Description Get full status timeline of achievement (audit trail)
 // @Tags Achievements
 // @Security BearerAuth
 // @Produce json
@@ -769,53 +919,25 @@ func (s *AchievementService) History(c *fiber.Ctx) error {
     ctx := context.Background()
     id := c.Params("id")
 
-    // Ambil user claims
-    claims := c.Locals("user").(jwt.MapClaims)
-    role := claims["role_name"].(string)
-    userID := claims["user_id"].(string)
-
     // --- Ambil Postgre reference ---
     ref, err := s.pgRepo.GetByID(ctx, id)
     if err != nil {
         return c.Status(404).JSON(fiber.Map{"error": "achievement not found"})
     }
 
-    // --- Authorization sesuai role ---
-    switch role {
-    case "Mahasiswa":
-        sid, _ := s.studentRepo.GetStudentIDByUserID(ctx, userID)
-        if ref.StudentID != sid {
-            return c.Status(403).JSON(fiber.Map{"error": "forbidden"})
-        }
-
-    case "Dosen Wali":
-        lecID, _ := s.studentRepo.GetLecturerIDByUserID(ctx, userID)
-        ok, _ := s.studentRepo.IsStudentUnderAdvisor(ctx, ref.StudentID, lecID)
-        if !ok {
-            return c.Status(403).JSON(fiber.Map{"error": "not your advisee"})
-        }
-
-    case "Admin":
-        // admin bebas akses
-    }
-
-    // --- Ambil detail dari Mongo ---
-    oid, _ := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-    detail, err := s.mongoRepo.FindById(ctx, oid)
+    // --- Authorization: milik sendiri / bimbingan / scope:all ---
+    scope, err := callerScope(ctx, c, s.studentRepo)
     if err != nil {
-        return c.Status(500).JSON(fiber.Map{"error": "mongo data not found"})
+        return scopeError(c, err)
+    }
+    if !scope.canSeeAchievement(ctx, s.studentRepo, ref) {
+        return c.Status(403).JSON(fiber.Map{"error": "forbidden"})
     }
 
-    // --- Bentuk "history" sederhana sesuai SRS ---
-    history := []fiber.Map{
-        {
-            "status":    "created",
-            "timestamp": detail.CreatedAt,
-        },
-        {
-            "status":    ref.Status,
-            "timestamp": detail.UpdatedAt,
-        },
+    // --- Ambil timeline dari audit trail ---
+    history, err := s.historyRepo.GetByAchievementID(ctx, ref.ID)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
 
     return c.JSON(fiber.Map{
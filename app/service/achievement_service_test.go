@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"testing"
 
 	"backenduas/app/model"
@@ -16,7 +17,7 @@ func TestCreateAchievementLogic(t *testing.T) {
 
 	st.UserToStudent["u1"] = "s1"
 
-	svc := NewAchievementLogicService(pg, mg, st)
+	svc := NewAchievementLogicService(pg, mg, st, nil, nil, nil, nil)
 
 	err := svc.Create("Mahasiswa", "u1", model.AchievementCreateRequest{
 		Title:           "Juara 1",
@@ -29,7 +30,7 @@ func TestCreateAchievementLogic(t *testing.T) {
 }
 
 func TestCreateAchievementLogic_NotMahasiswa(t *testing.T) {
-	svc := NewAchievementLogicService(nil, nil, nil)
+	svc := NewAchievementLogicService(nil, nil, nil, nil, nil, nil, nil)
 
 	err := svc.Create("Admin", "u1", model.AchievementCreateRequest{})
 	if err == nil {
@@ -42,9 +43,9 @@ func TestSubmitAchievementLogic(t *testing.T) {
 	pg := repository.NewMockAchievementPGRepository()
 	refID := pg.SeedDraft("s1")
 
-	svc := NewAchievementLogicService(pg, nil, nil)
+	svc := NewAchievementLogicService(pg, nil, nil, nil, nil, nil, nil)
 
-	err := svc.Submit(refID)
+	err := svc.Submit(refID, "u1")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -55,9 +56,9 @@ func TestDeleteAchievementLogic(t *testing.T) {
 	pg := repository.NewMockAchievementPGRepository()
 	refID := pg.SeedDraft("s1")
 
-	svc := NewAchievementLogicService(pg, nil, nil)
+	svc := NewAchievementLogicService(pg, nil, nil, nil, nil, nil, nil)
 
-	err := svc.Delete(refID)
+	err := svc.Delete(refID, "u1")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -72,7 +73,7 @@ func TestVerifyAchievementLogic(t *testing.T) {
 	st.UserToLecturer["uLect"] = "lect1"
 	st.AdvisorMap["s1"] = "lect1"
 
-	svc := NewAchievementLogicService(pg, nil, st)
+	svc := NewAchievementLogicService(pg, nil, st, nil, nil, nil, nil)
 
 	err := svc.Verify(refID, "uLect")
 	if err != nil {
@@ -89,7 +90,7 @@ func TestRejectAchievementLogic(t *testing.T) {
 	st.UserToLecturer["uLect"] = "lect1"
 	st.AdvisorMap["s1"] = "lect1"
 
-	svc := NewAchievementLogicService(pg, nil, st)
+	svc := NewAchievementLogicService(pg, nil, st, nil, nil, nil, nil)
 
 	err := svc.Reject(refID, "uLect", "Kurang bukti")
 	if err != nil {
@@ -112,7 +113,7 @@ func TestUpdateAchievementLogic(t *testing.T) {
 	// 🔥 WAJIB: seed Mongo data
 	mg.Seed(oid)
 
-	svc := NewAchievementLogicService(pg, mg, st)
+	svc := NewAchievementLogicService(pg, mg, st, nil, nil, nil, nil)
 
 	err := svc.Update(refID, "u1", model.AchievementUpdateInput{
 		Title: "Updated Title",
@@ -128,18 +129,184 @@ func TestUpdateAchievementLogic(t *testing.T) {
 func TestHistoryAchievementLogic(t *testing.T) {
 	pg := repository.NewMockAchievementPGRepository()
 	mg := repository.NewMockAchievementMongoRepository()
+	st := repository.NewMockStudentRepository()
+	hs := repository.NewMockAchievementHistoryRepository()
+	pg.History = hs
+
+	st.UserToStudent["u1"] = "s1"
+	st.UserToLecturer["uLect"] = "lect1"
+	st.AdvisorMap["s1"] = "lect1"
 
 	refID, oid := pg.SeedWithMongo("s1")
 	mg.Seed(oid)
 
-	svc := NewAchievementLogicService(pg, mg, nil)
+	svc := NewAchievementLogicService(pg, mg, st, hs, nil, nil, nil)
+
+	if err := svc.Update(refID, "u1", model.AchievementUpdateInput{Title: "Revisi"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := svc.Submit(refID, "u1"); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if err := svc.Reject(refID, "uLect", "Kurang bukti"); err != nil {
+		t.Fatalf("reject: %v", err)
+	}
 
 	history, err := svc.History(refID)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if len(history) != 2 {
-		t.Fatalf("expected 2 history items, got %d", len(history))
+	if len(history) != 3 {
+		t.Fatalf("expected 3 history items, got %d", len(history))
+	}
+
+	if len(history[0].ChangedFields) != 1 || history[0].ChangedFields[0] != "title" {
+		t.Fatalf("expected changed field title, got %v", history[0].ChangedFields)
+	}
+
+	if history[1].FromStatus != "draft" || history[1].ToStatus != "submitted" || history[1].ActorUserID != "u1" {
+		t.Fatalf("unexpected submit event: %+v", history[1])
+	}
+
+	if history[2].ToStatus != "rejected" || history[2].Note != "Kurang bukti" || history[2].ActorRole != "Dosen Wali" {
+		t.Fatalf("unexpected reject event: %+v", history[2])
+	}
+}
+
+func TestHistoryAchievementLogic_NoRepo(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	refID := pg.SeedDraft("a1")
+
+	svc := NewAchievementLogicService(pg, nil, nil, nil, nil, nil, nil)
+
+	if _, err := svc.History(refID); err == nil {
+		t.Fatal("expected error without history repository")
+	}
+}
+
+func TestRejectAchievementLogic_HistoryRolledBack(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	st := repository.NewMockStudentRepository()
+	hs := repository.NewMockAchievementHistoryRepository()
+	pg.History = hs
+
+	st.UserToLecturer["uLect"] = "lect1"
+	st.AdvisorMap["s1"] = "lect1"
+
+	refID := pg.SeedSubmitted("a1")
+	pg.FailWith = errors.New("db down")
+
+	svc := NewAchievementLogicService(pg, nil, st, hs, nil, nil, nil)
+
+	if err := svc.Reject(refID, "uLect", "Kurang bukti"); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(hs.Data) != 0 {
+		t.Fatalf("history must not be written when the status update fails, got %+v", hs.Data)
+	}
+}
+
+func TestCreateAchievementLogic_RecordsHistory(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	st := repository.NewMockStudentRepository()
+	hs := repository.NewMockAchievementHistoryRepository()
+	pg.History = hs
+
+	st.UserToStudent["u1"] = "s1"
+
+	svc := NewAchievementLogicService(pg, mg, st, hs, nil, nil, nil)
+
+	err := svc.Create("Mahasiswa", "u1", model.AchievementCreateRequest{
+		Title:           "Juara 1",
+		AchievementType: "Lomba",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(hs.Data) != 1 || hs.Data[0].ToStatus != "draft" || hs.Data[0].FromStatus != "" {
+		t.Fatalf("expected single created event, got %+v", hs.Data)
+	}
+
+	// INSERT reference gagal → riwayat ikut batal, dokumen Mongo dihapus
+	pg.FailWith = errors.New("db down")
+	if err := svc.Create("Mahasiswa", "u1", model.AchievementCreateRequest{Title: "Juara 2", AchievementType: "Lomba"}); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(hs.Data) != 1 || len(mg.Data) != 1 {
+		t.Fatalf("failed create must leave no history or orphan document, got %d history, %d docs", len(hs.Data), len(mg.Data))
+	}
+}
+
+func TestUpdateAchievementLogic_HistoryFailureReturnsError(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	st := repository.NewMockStudentRepository()
+	hs := repository.NewMockAchievementHistoryRepository()
+	hs.FailWith = errors.New("db down")
+
+	st.UserToStudent["u1"] = "s1"
+	refID, oid := pg.SeedWithMongo("s1")
+	mg.Seed(oid)
+
+	svc := NewAchievementLogicService(pg, mg, st, hs, nil, nil, nil)
+
+	if err := svc.Update(refID, "u1", model.AchievementUpdateInput{Title: "Revisi"}); err == nil {
+		t.Fatal("a lost history row must fail the update")
+	}
+}
+
+// ================= REVISE =================
+func TestReviseAndResubmitAchievementLogic(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	st := repository.NewMockStudentRepository()
+	hs := repository.NewMockAchievementHistoryRepository()
+
+	st.UserToStudent["u1"] = "s1"
+	st.UserToLecturer["uLect"] = "lect1"
+	st.AdvisorMap["s1"] = "lect1"
+
+	refID := pg.SeedSubmitted("a1")
+
+	svc := NewAchievementLogicService(pg, nil, st, hs, nil, nil, nil)
+
+	if err := svc.Reject(refID, "uLect", "Kurang bukti"); err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+	if err := svc.Revise(refID, "u1"); err != nil {
+		t.Fatalf("revise: %v", err)
+	}
+	if pg.Data[refID].Status != StatusDraft {
+		t.Fatalf("expected draft after revise, got %s", pg.Data[refID].Status)
+	}
+	if err := svc.Submit(refID, "u1"); err != nil {
+		t.Fatalf("resubmit: %v", err)
+	}
+	if err := svc.Reject(refID, "uLect", "Sertifikat buram"); err != nil {
+		t.Fatalf("second reject: %v", err)
+	}
+
+	notes := pg.Data[refID].RejectionNotes
+	if len(notes) != 2 {
+		t.Fatalf("expected 2 review rounds, got %d", len(notes))
+	}
+	if notes[0].Round != 1 || notes[0].Note != "Kurang bukti" || notes[1].Round != 2 || notes[1].Note != "Sertifikat buram" {
+		t.Fatalf("unexpected review rounds: %+v", notes)
+	}
+}
+
+func TestReviseAchievementLogic_NotRejected(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	st := repository.NewMockStudentRepository()
+
+	st.UserToStudent["u1"] = "s1"
+	refID := pg.SeedSubmitted("a1")
+
+	svc := NewAchievementLogicService(pg, nil, st, nil, nil, nil, nil)
+
+	if err := svc.Revise(refID, "u1"); err == nil {
+		t.Fatalf("expected error when revising submitted achievement")
 	}
 }
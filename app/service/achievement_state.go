@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// =====================================================
+//  STATE MACHINE STATUS PRESTASI
+// =====================================================
+//
+//	draft ──submit──▶ submitted ──verify──▶ verified
+//	  │                   │
+//	  │                   └──reject──▶ rejected ──revise──▶ draft
+//	  └──delete──▶ deleted
+const (
+	StatusDraft     = "draft"
+	StatusSubmitted = "submitted"
+	StatusVerified  = "verified"
+	StatusRejected  = "rejected"
+	StatusDeleted   = "deleted"
+)
+
+// satu-satunya tabel transisi yang sah (from → to)
+var achievementTransitions = map[string][]string{
+	StatusDraft:     {StatusSubmitted, StatusDeleted},
+	StatusSubmitted: {StatusVerified, StatusRejected},
+	StatusRejected:  {StatusDraft},
+}
+
+// status yang isinya (detail & lampiran) masih boleh diubah mahasiswa
+var editableStatuses = map[string]bool{
+	StatusDraft: true,
+}
+
+// ErrInvalidTransition dipakai untuk errors.Is di handler / test
+var ErrInvalidTransition = errors.New("invalid achievement status transition")
+
+// TransitionError = transisi ditolak oleh state machine (→ HTTP 409)
+type TransitionError struct {
+	From  string
+	To    string
+	Stale bool // status sudah diubah request lain setelah divalidasi
+}
+
+func (e *TransitionError) Error() string {
+	if e.Stale {
+		return fmt.Sprintf("achievement status is no longer %q; cannot change it to %q", e.From, e.To)
+	}
+	if e.From == e.To {
+		return fmt.Sprintf("achievement with status %q cannot be modified", e.From)
+	}
+	return fmt.Sprintf("cannot change achievement status from %q to %q", e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+// CanTransition → true jika from → to ada di tabel transisi
+func CanTransition(from, to string) bool {
+	for _, next := range achievementTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition → *TransitionError jika transisi tidak sah
+func ValidateTransition(from, to string) error {
+	if !CanTransition(from, to) {
+		return &TransitionError{From: from, To: to}
+	}
+	return nil
+}
+
+// ValidateEditable → *TransitionError jika konten prestasi tidak boleh diubah
+func ValidateEditable(status string) error {
+	if !editableStatuses[status] {
+		return &TransitionError{From: status, To: status}
+	}
+	return nil
+}
+
+// changeStatus menjalankan UPDATE bersyarat; kalah balapan dengan request
+// lain → *TransitionError (Stale)
+func changeStatus(ctx context.Context, repo repository.IAchievementPGRepository, ch model.StatusChange) error {
+	err := repo.ChangeStatus(ctx, ch)
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return &TransitionError{From: ch.From, To: ch.To, Stale: true}
+	}
+	return err
+}
+
+// statusChangeFailed: 409 jika transisi kalah balapan, selain itu 500
+func statusChangeFailed(c *fiber.Ctx, err error) error {
+	if errors.Is(err, ErrInvalidTransition) {
+		return conflict(c, err)
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
+
+// conflict memetakan error state machine ke 409 Conflict
+func conflict(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+}
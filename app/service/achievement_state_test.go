@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+)
+
+var allStatuses = []string{StatusDraft, StatusSubmitted, StatusVerified, StatusRejected, StatusDeleted}
+
+func TestAchievementTransitions_AllEdges(t *testing.T) {
+	legal := map[[2]string]bool{
+		{StatusDraft, StatusSubmitted}:    true,
+		{StatusDraft, StatusDeleted}:      true,
+		{StatusSubmitted, StatusVerified}: true,
+		{StatusSubmitted, StatusRejected}: true,
+		{StatusRejected, StatusDraft}:     true,
+	}
+
+	for _, from := range allStatuses {
+		for _, to := range allStatuses {
+			err := ValidateTransition(from, to)
+
+			if legal[[2]string{from, to}] {
+				if err != nil {
+					t.Errorf("%s → %s should be allowed, got %v", from, to, err)
+				}
+				continue
+			}
+
+			if err == nil {
+				t.Errorf("%s → %s should be rejected", from, to)
+				continue
+			}
+
+			var te *TransitionError
+			if !errors.As(err, &te) || te.From != from || te.To != to {
+				t.Errorf("%s → %s: expected *TransitionError, got %#v", from, to, err)
+			}
+			if !errors.Is(err, ErrInvalidTransition) {
+				t.Errorf("%s → %s: expected errors.Is ErrInvalidTransition", from, to)
+			}
+		}
+	}
+}
+
+func TestValidateEditable(t *testing.T) {
+	for _, status := range allStatuses {
+		err := ValidateEditable(status)
+		if status == StatusDraft && err != nil {
+			t.Errorf("draft should be editable, got %v", err)
+		}
+		if status != StatusDraft && !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("%s should not be editable", status)
+		}
+	}
+}
+
+func TestSubmitAchievementLogic_AlreadyVerified(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	refID := pg.SeedSubmitted("a1")
+	pg.ChangeStatus(context.Background(), model.StatusChange{AchievementID: refID, From: StatusSubmitted, To: StatusVerified, LecturerID: "lect1"})
+
+	svc := NewAchievementLogicService(pg, nil, nil, nil, nil, nil, nil)
+
+	err := svc.Submit(refID, "u1")
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected invalid transition, got %v", err)
+	}
+
+	if pg.Data[refID].Status != StatusVerified {
+		t.Fatalf("status must stay verified, got %s", pg.Data[refID].Status)
+	}
+}
+
+func TestRejectAchievementLogic_NotSubmitted(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	st := repository.NewMockStudentRepository()
+
+	refID := pg.SeedDraft("a1")
+	st.UserToLecturer["uLect"] = "lect1"
+	st.AdvisorMap["s1"] = "lect1"
+
+	svc := NewAchievementLogicService(pg, nil, st, nil, nil, nil, nil)
+
+	err := svc.Reject(refID, "uLect", "Kurang bukti")
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected invalid transition, got %v", err)
+	}
+}
+
+func TestChangeStatus_LostRace(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	refID := pg.SeedSubmitted("a1")
+
+	// request lain sudah menolak prestasi setelah kita memvalidasi "submitted"
+	pg.ChangeStatus(context.Background(), model.StatusChange{AchievementID: refID, From: StatusSubmitted, To: StatusRejected, LecturerID: "lect2", Note: "x"})
+
+	err := changeStatus(context.Background(), pg, model.StatusChange{
+		AchievementID: refID, From: StatusSubmitted, To: StatusVerified, LecturerID: "lect1",
+	})
+	var te *TransitionError
+	if !errors.As(err, &te) || !te.Stale {
+		t.Fatalf("expected stale transition error, got %v", err)
+	}
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("stale transition must map to 409, got %v", err)
+	}
+	if pg.Data[refID].Status != StatusRejected {
+		t.Fatalf("status must stay rejected, got %s", pg.Data[refID].Status)
+	}
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// =====================================================
+//  DUAL-WRITE POSTGRESQL ↔ MONGODB
+// =====================================================
+//
+// PostgreSQL (achievement_references) = sumber kebenaran status.
+// Setiap UPDATE status menulis entry sync_outbox dalam transaksi yang sama
+// (lihat ChangeStatus). Setelah commit, handler langsung mencoba flush satu
+// kali; yang gagal diulang worker sampai MaxAttempts lalu ditandai dead.
+// Saat diterapkan, status dibaca ulang dari PostgreSQL dan hanya ditulis ke
+// Mongo jika status_version-nya lebih baru, jadi urutan tidak bisa mundur.
+//
+// Poin: SyncPoints menulis langsung; jika Mongo gagal, entry set_points
+// diantrikan dan worker menghitung ulang poin dari aturan aktif (Points).
+const (
+	OutboxSetStatus = "set_status"
+	OutboxSetPoints = "set_points"
+	OutboxDelete    = "delete"
+)
+
+type AchievementSyncer struct {
+	pgRepo    repository.IAchievementPGRepository
+	mongoRepo repository.IAchievementMongoRepository
+	outbox    repository.ISyncOutboxRepository
+
+	// Points dipakai untuk menerapkan entry set_points (nil → entry gagal & diulang)
+	Points *PointsEngine
+
+	MaxAttempts int
+	BatchSize   int
+}
+
+func NewAchievementSyncer(
+	pg repository.IAchievementPGRepository,
+	mg repository.IAchievementMongoRepository,
+	outbox repository.ISyncOutboxRepository,
+) *AchievementSyncer {
+	return &AchievementSyncer{
+		pgRepo:      pg,
+		mongoRepo:   mg,
+		outbox:      outbox,
+		MaxAttempts: 10,
+		BatchSize:   100,
+	}
+}
+
+// SyncStatus dipanggil setelah ChangeStatus commit: menerapkan entry outbox
+// dokumen tersebut sekarang juga (tanpa sleep / retry di dalam request).
+// Status dibaca ulang dari PostgreSQL; yang gagal tetap di outbox untuk worker.
+// nil-safe: tanpa syncer, Mongo hanya disusul lewat reconcile.
+func (s *AchievementSyncer) SyncStatus(ctx context.Context, mongoHex string) {
+	if s == nil {
+		return
+	}
+	entries, err := s.outbox.PendingFor(ctx, mongoHex)
+	if err != nil {
+		log.Printf("⚠️ sync MongoDB %s ditunda ke worker: %v", mongoHex, err)
+		return
+	}
+	s.process(ctx, entries)
+}
+
+// CompensateCreate menghapus dokumen Mongo yang sudah terlanjur dibuat
+// ketika insert achievement_references gagal (supaya tidak yatim).
+func (s *AchievementSyncer) CompensateCreate(ctx context.Context, oid primitive.ObjectID) error {
+	err := s.mongoRepo.Delete(ctx, oid)
+	if err == nil {
+		return nil
+	}
+
+	if qErr := s.outbox.Enqueue(ctx, model.SyncOutboxEntry{
+		Operation: OutboxDelete,
+		MongoID:   oid.Hex(),
+		LastError: err.Error(),
+	}); qErr != nil {
+		log.Printf("❌ sync outbox gagal (%s %s): %v", OutboxDelete, oid.Hex(), qErr)
+		return fmt.Errorf("mongo delete failed and could not be queued: %w", qErr)
+	}
+	log.Printf("⚠️ hapus dokumen MongoDB %s dijadwalkan ulang: %v", oid.Hex(), err)
+	return nil
+}
+
+// SyncPoints menyimpan hasil perhitungan poin ke dokumen Mongo. Jika gagal,
+// entry set_points diantrikan untuk worker; error hanya jika antrean juga gagal.
+func (s *AchievementSyncer) SyncPoints(ctx context.Context, mongoHex string, pb model.PointsBreakdown) error {
+	if s == nil {
+		return nil
+	}
+	oid, err := primitive.ObjectIDFromHex(mongoHex)
+	if err != nil {
+		return fmt.Errorf("invalid mongo id %q: %w", mongoHex, err)
+	}
+
+	err = s.mongoRepo.Update(ctx, oid, pointsUpdate(pb))
+	if err == nil {
+		return nil
+	}
+
+	if qErr := s.outbox.Enqueue(ctx, model.SyncOutboxEntry{
+		Operation: OutboxSetPoints,
+		MongoID:   mongoHex,
+		LastError: err.Error(),
+	}); qErr != nil {
+		log.Printf("❌ sync outbox gagal (%s %s): %v", OutboxSetPoints, mongoHex, qErr)
+		return fmt.Errorf("mongo points update failed and could not be queued: %w", qErr)
+	}
+	log.Printf("⚠️ poin MongoDB %s dijadwalkan ulang: %v", mongoHex, err)
+	return nil
+}
+
+// ProcessOutbox menerapkan ulang entry yang tertunda (dipanggil worker)
+func (s *AchievementSyncer) ProcessOutbox(ctx context.Context) (done int, failed int, err error) {
+	entries, err := s.outbox.GetPending(ctx, s.BatchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	done, failed = s.process(ctx, entries)
+	return done, failed, nil
+}
+
+// process mengelompokkan entry per dokumen: beberapa set_status untuk
+// dokumen yang sama cukup diterapkan sekali (status terbaru dari PostgreSQL)
+func (s *AchievementSyncer) process(ctx context.Context, entries []model.SyncOutboxEntry) (done int, failed int) {
+	groups := map[string][]model.SyncOutboxEntry{}
+	order := []string{}
+	for _, e := range entries {
+		key := e.Operation + ":" + e.MongoID
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], e)
+	}
+
+	for _, key := range order {
+		group := groups[key]
+		ids := make([]string, len(group))
+		for i, e := range group {
+			ids[i] = e.ID
+		}
+
+		if err := s.apply(ctx, group[0]); err != nil {
+			failed += len(group)
+			for _, e := range group {
+				dead, _ := s.outbox.MarkFailed(ctx, e.ID, err.Error(), s.MaxAttempts)
+				if dead {
+					log.Printf("☠️ sync outbox %s %s berhenti setelah %d percobaan: %v", e.Operation, e.MongoID, s.MaxAttempts, err)
+				}
+			}
+			continue
+		}
+
+		done += len(group)
+		s.outbox.MarkDone(ctx, ids...)
+	}
+
+	return done, failed
+}
+
+func (s *AchievementSyncer) apply(ctx context.Context, e model.SyncOutboxEntry) error {
+	oid, err := primitive.ObjectIDFromHex(e.MongoID)
+	if err != nil {
+		return err
+	}
+
+	switch e.Operation {
+	case OutboxSetStatus:
+		// baca ulang: entry bisa sudah didahului perubahan lain
+		refs, err := s.pgRepo.GetByMongoIDs(ctx, []string{e.MongoID})
+		if err != nil {
+			return err
+		}
+		if len(refs) == 0 {
+			return nil // reference hilang → ditangani reconcile
+		}
+		return s.mongoRepo.SetStatus(ctx, oid, refs[0].Status, refs[0].StatusVersion)
+	case OutboxSetPoints:
+		return s.applyPoints(ctx, oid)
+	case OutboxDelete:
+		return s.mongoRepo.Delete(ctx, oid)
+	default:
+		return fmt.Errorf("unknown outbox operation %q", e.Operation)
+	}
+}
+
+// applyPoints menghitung ulang poin dokumen (aturan aktif saat ini) dan
+// menyimpannya ke Mongo & PostgreSQL, sama seperti recalculate
+func (s *AchievementSyncer) applyPoints(ctx context.Context, oid primitive.ObjectID) error {
+	if s.Points == nil {
+		return fmt.Errorf("points engine not configured")
+	}
+	doc, err := s.mongoRepo.FindById(ctx, oid)
+	if err != nil {
+		return err
+	}
+	pb, err := s.Points.Calculate(ctx, doc)
+	if err != nil {
+		return err
+	}
+	if err := s.mongoRepo.Update(ctx, oid, pointsUpdate(pb)); err != nil {
+		return err
+	}
+	return s.pgRepo.SetPoints(ctx, oid.Hex(), pb.Total)
+}
+
+// Reconcile membandingkan status kedua store (laporan saja; perbaikan
+// dilakukan lewat ReconcileService / outbox)
+func (s *AchievementSyncer) Reconcile(ctx context.Context) (model.DriftReport, error) {
+	report := model.DriftReport{Drifts: []model.StatusDrift{}}
+
+	refs, err := s.pgRepo.GetAll(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	mongoIDs := []primitive.ObjectID{}
+	for _, r := range refs {
+		if oid, err := primitive.ObjectIDFromHex(r.MongoAchievementID); err == nil {
+			mongoIDs = append(mongoIDs, oid)
+		}
+	}
+
+	mongoMap, err := s.mongoRepo.FindManyByIDs(ctx, mongoIDs)
+	if err != nil {
+		return report, err
+	}
+
+	for _, ref := range refs {
+		doc, ok := mongoMap[ref.MongoAchievementID]
+		if !ok {
+			continue // dokumen hilang → ditangani reconcile lengkap
+		}
+		report.Checked++
+
+		if doc.Status == ref.Status {
+			continue
+		}
+
+		report.Drifts = append(report.Drifts, model.StatusDrift{
+			AchievementID: ref.ID,
+			MongoID:       ref.MongoAchievementID,
+			PGStatus:      ref.Status,
+			MongoStatus:   doc.Status,
+		})
+	}
+
+	return report, nil
+}
+
+// Run = background worker: outbox tiap syncEvery, laporan drift tiap reconcileEvery
+func (s *AchievementSyncer) Run(ctx context.Context, syncEvery, reconcileEvery time.Duration) {
+	syncTicker := time.NewTicker(syncEvery)
+	reconcileTicker := time.NewTicker(reconcileEvery)
+	defer syncTicker.Stop()
+	defer reconcileTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-syncTicker.C:
+			done, failed, err := s.ProcessOutbox(ctx)
+			if err != nil {
+				log.Printf("❌ sync outbox worker: %v", err)
+			} else if done+failed > 0 {
+				log.Printf("🔄 sync outbox: %d berhasil, %d gagal", done, failed)
+			}
+
+		case <-reconcileTicker.C:
+			report, err := s.Reconcile(ctx)
+			if err != nil {
+				log.Printf("❌ reconcile job: %v", err)
+			} else if len(report.Drifts) > 0 {
+				log.Printf("🩹 reconcile: %d dicek, %d drift (perbaiki: backenduas reconcile --fix --source=pg)", report.Checked, len(report.Drifts))
+			}
+		}
+	}
+}
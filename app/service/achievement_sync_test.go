@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSyncStatus_QueuedThenReplayed(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	ob := repository.NewMockSyncOutboxRepository()
+	pg.Outbox = ob
+
+	refID, oid := pg.SeedWithMongo("s1")
+	mg.Seed(oid)
+	pg.ChangeStatus(context.Background(), model.StatusChange{AchievementID: refID, From: StatusDraft, To: StatusSubmitted})
+
+	// outbox ditulis bersama UPDATE status, sebelum Mongo disentuh
+	if len(ob.Data) != 1 || ob.Data[0].Operation != OutboxSetStatus || ob.Data[0].Version != 1 {
+		t.Fatalf("unexpected outbox: %+v", ob.Data)
+	}
+
+	mg.FailWith = errors.New("mongo down")
+	syncer := NewAchievementSyncer(pg, mg, ob)
+
+	syncer.SyncStatus(context.Background(), oid.Hex())
+	if ob.Data[0].ProcessedAt != nil || ob.Data[0].Attempts != 1 {
+		t.Fatalf("entry must stay pending after failed flush: %+v", ob.Data[0])
+	}
+
+	// Mongo kembali normal → worker menerapkan ulang
+	mg.FailWith = nil
+
+	done, failed, err := syncer.ProcessOutbox(context.Background())
+	if err != nil || done != 1 || failed != 0 {
+		t.Fatalf("expected 1 done, got done=%d failed=%d err=%v", done, failed, err)
+	}
+
+	if mg.Data[oid.Hex()].Status != StatusSubmitted {
+		t.Fatalf("mongo status not replayed, got %q", mg.Data[oid.Hex()].Status)
+	}
+
+	if pending, _ := ob.GetPending(context.Background(), 10); len(pending) != 0 {
+		t.Fatalf("expected empty outbox, got %d", len(pending))
+	}
+}
+
+func TestSyncPoints_QueuedThenRecalculated(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	ob := repository.NewMockSyncOutboxRepository()
+	rules := repository.NewMockPointRuleRepository()
+	for _, r := range facultyRules() {
+		rules.Data[r.ID] = r
+	}
+
+	_, oid := pg.SeedWithMongo("s1")
+	mg.Data[oid.Hex()] = model.AchievementMongo{
+		ID:              oid,
+		AchievementType: "competition",
+		Details:         map[string]interface{}{"competitionLevel": "international", "rank": 1},
+	}
+
+	syncer := NewAchievementSyncer(pg, mg, ob)
+	syncer.Points = NewPointsEngine(rules, pg, mg)
+	ctx := context.Background()
+
+	mg.FailWith = errors.New("mongo down")
+	if err := syncer.SyncPoints(ctx, oid.Hex(), model.PointsBreakdown{Total: 130}); err != nil {
+		t.Fatalf("failed points write must be queued, got %v", err)
+	}
+	if len(ob.Data) != 1 || ob.Data[0].Operation != OutboxSetPoints {
+		t.Fatalf("expected a set_points entry, got %+v", ob.Data)
+	}
+
+	mg.FailWith = nil
+	done, failed, err := syncer.ProcessOutbox(ctx)
+	if err != nil || done != 1 || failed != 0 {
+		t.Fatalf("expected 1 done, got done=%d failed=%d err=%v", done, failed, err)
+	}
+	if doc := mg.Data[oid.Hex()]; doc.Points != 130 || doc.PointsBreakdown == nil {
+		t.Fatalf("points not replayed: %d %+v", doc.Points, doc.PointsBreakdown)
+	}
+	if pg.Data["ref-s1"].Points != 130 {
+		t.Fatalf("postgres points not updated: %d", pg.Data["ref-s1"].Points)
+	}
+
+	// antrean juga gagal → error dikembalikan ke pemanggil
+	mg.FailWith = errors.New("mongo down")
+	ob.FailWith = errors.New("postgres down")
+	if err := syncer.SyncPoints(ctx, oid.Hex(), model.PointsBreakdown{Total: 130}); err == nil {
+		t.Fatal("expected an error when the outbox is unavailable")
+	}
+}
+
+func TestProcessOutbox_AppliesLatestStatus(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	ob := repository.NewMockSyncOutboxRepository()
+	pg.Outbox = ob
+
+	refID, oid := pg.SeedWithMongo("s1")
+	mg.Seed(oid)
+	ctx := context.Background()
+	pg.ChangeStatus(ctx, model.StatusChange{AchievementID: refID, From: StatusDraft, To: StatusSubmitted})
+	pg.ChangeStatus(ctx, model.StatusChange{AchievementID: refID, From: StatusSubmitted, To: StatusRejected, Note: "x"})
+
+	syncer := NewAchievementSyncer(pg, mg, ob)
+
+	done, failed, err := syncer.ProcessOutbox(ctx)
+	if err != nil || done != 2 || failed != 0 {
+		t.Fatalf("expected both entries done, got done=%d failed=%d err=%v", done, failed, err)
+	}
+	if doc := mg.Data[oid.Hex()]; doc.Status != StatusRejected || doc.StatusVersion != 2 {
+		t.Fatalf("expected latest status rejected@2, got %s@%d", doc.Status, doc.StatusVersion)
+	}
+
+	// entry lama yang terlambat tidak boleh memundurkan status Mongo
+	if err := mg.SetStatus(ctx, oid, StatusSubmitted, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mg.Data[oid.Hex()].Status != StatusRejected {
+		t.Fatalf("stale version overwrote mongo status: %s", mg.Data[oid.Hex()].Status)
+	}
+}
+
+func TestProcessOutbox_DeadAfterMaxAttempts(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	ob := repository.NewMockSyncOutboxRepository()
+	pg.Outbox = ob
+
+	refID, oid := pg.SeedWithMongo("s1")
+	mg.Seed(oid)
+	pg.ChangeStatus(context.Background(), model.StatusChange{AchievementID: refID, From: StatusDraft, To: StatusSubmitted})
+	mg.FailWith = errors.New("mongo down")
+
+	syncer := NewAchievementSyncer(pg, mg, ob)
+	syncer.MaxAttempts = 3
+
+	for i := 0; i < 5; i++ {
+		syncer.ProcessOutbox(context.Background())
+	}
+
+	if ob.Data[0].Attempts != 3 || ob.Data[0].DeadAt == nil {
+		t.Fatalf("expected dead entry after 3 attempts, got %+v", ob.Data[0])
+	}
+	if pending, _ := ob.GetPending(context.Background(), 10); len(pending) != 0 {
+		t.Fatalf("dead entries must not be retried, got %d pending", len(pending))
+	}
+}
+
+func TestCompensateCreate_RemovesOrphan(t *testing.T) {
+	mg := repository.NewMockAchievementMongoRepository()
+	ob := repository.NewMockSyncOutboxRepository()
+
+	oid := primitive.NewObjectID()
+	mg.Seed(oid)
+
+	syncer := NewAchievementSyncer(nil, mg, ob)
+
+	if err := syncer.CompensateCreate(context.Background(), oid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := mg.Data[oid.Hex()]; ok {
+		t.Fatalf("orphan mongo document must be removed")
+	}
+	if len(ob.Data) != 0 {
+		t.Fatalf("outbox should stay empty on success")
+	}
+}
+
+func TestReconcile_ReportsStatusDrift(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	ob := repository.NewMockSyncOutboxRepository()
+
+	oid := primitive.NewObjectID()
+	pg.Data["a1"] = model.AchievementReference{ID: "a1", StudentID: "s1", MongoAchievementID: oid.Hex(), Status: StatusVerified}
+	mg.Data[oid.Hex()] = model.AchievementMongo{ID: oid, Status: StatusSubmitted}
+
+	syncer := NewAchievementSyncer(pg, mg, ob)
+
+	report, err := syncer.Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Checked != 1 || len(report.Drifts) != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	// laporan saja — perbaikan lewat ReconcileService
+	if mg.Data[oid.Hex()].Status != StatusSubmitted {
+		t.Fatalf("drift must not be auto-repaired, mongo status %q", mg.Data[oid.Hex()].Status)
+	}
+}
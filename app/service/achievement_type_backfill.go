@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+)
+
+// BackfillAchievementTypes memetakan achievementType lama di Mongo (mis.
+// "Lomba", "kompetisi ") ke code kanonik registry lewat code / alias.
+// Idempotent: nilai yang sudah kanonik dilewati, jadi aman dijalankan ulang.
+func BackfillAchievementTypes(
+	ctx context.Context,
+	types repository.IAchievementTypeRepository,
+	mg repository.IAchievementMongoRepository,
+	dryRun bool,
+) (model.TypeBackfillReport, error) {
+
+	report := model.TypeBackfillReport{
+		DryRun:  dryRun,
+		Mapped:  []model.TypeMapping{},
+		Unknown: []string{},
+	}
+
+	values, err := mg.DistinctTypes(ctx)
+	if err != nil {
+		return report, err
+	}
+	report.Checked = len(values)
+
+	for _, v := range values {
+		t, err := types.GetByCode(ctx, strings.TrimSpace(v))
+		if errors.Is(err, repository.ErrAchievementTypeNotFound) {
+			report.Unknown = append(report.Unknown, v)
+			continue
+		}
+		if err != nil {
+			return report, err
+		}
+		if t.Code == v {
+			continue
+		}
+
+		n, err := mg.RenameType(ctx, v, t.Code, dryRun)
+		if err != nil {
+			return report, err
+		}
+		report.Mapped = append(report.Mapped, model.TypeMapping{From: v, To: t.Code, Documents: n})
+		report.Updated += n
+	}
+
+	return report, nil
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+	"backenduas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AchievementTypeService struct {
+	repo  *repository.AchievementTypeRepository
+	stats *StatsMaterializer
+}
+
+func NewAchievementTypeService(repo *repository.AchievementTypeRepository, stats *StatsMaterializer) *AchievementTypeService {
+	return &AchievementTypeService{repo: repo, stats: stats}
+}
+
+// ===============================
+// GET ALL
+// ===============================
+// GetAll godoc
+// @Summary List achievement types
+// @Description Registry jenis prestasi beserta definisi field details (pemilik achievement_type:manage dapat melihat yang nonaktif dengan include_inactive=true)
+// @Tags Achievement Types
+// @Security BearerAuth
+// @Produce json
+// @Param include_inactive query bool false "Include inactive types (achievement_type:manage only)"
+// @Success 200 {array} model.AchievementType
+// @Failure 500 {object} map[string]string
+// @Router /achievement-types [get]
+func (s *AchievementTypeService) GetAll(c *fiber.Ctx) error {
+	// jenis nonaktif hanya untuk pengelola registry
+	includeInactive := c.QueryBool("include_inactive") && containsString(middleware.Permissions(c), "achievement_type:manage")
+
+	types, err := s.repo.GetAll(context.Background(), includeInactive)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(types)
+}
+
+// ===============================
+// GET BY CODE
+// ===============================
+// GetByCode godoc
+// @Summary Get achievement type
+// @Description Detail jenis prestasi berdasarkan code atau alias
+// @Tags Achievement Types
+// @Security BearerAuth
+// @Produce json
+// @Param code path string true "Type code or alias"
+// @Success 200 {object} model.AchievementType
+// @Failure 404 {object} map[string]string
+// @Router /achievement-types/{code} [get]
+func (s *AchievementTypeService) GetByCode(c *fiber.Ctx) error {
+	t, err := s.repo.GetByCode(context.Background(), c.Params("code"))
+	if errors.Is(err, repository.ErrAchievementTypeNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	// nama jenis ikut tampil di laporan statistik yang di-cache
+	s.stats.InvalidateCache()
+	return c.JSON(t)
+}
+
+// ===============================
+// CREATE (Admin)
+// ===============================
+// Create godoc
+// @Summary Create achievement type
+// @Description Menambah jenis prestasi baru beserta definisi field details (Admin)
+// @Tags Achievement Types
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body model.AchievementTypeRequest true "Achievement type"
+// @Success 201 {object} model.AchievementType
+// @Failure 400 {object} map[string]any
+// @Failure 500 {object} map[string]string
+// @Router /achievement-types [post]
+func (s *AchievementTypeService) Create(c *fiber.Ctx) error {
+	var req model.AchievementTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	t := typeFromRequest(req)
+	t.Code = strings.ToLower(strings.TrimSpace(req.Code))
+
+	ctx := context.Background()
+	if err := validateTypeDefinition(ctx, s.repo, t, true); err != nil {
+		return validationFailed(c, err)
+	}
+
+	if err := s.repo.Create(ctx, &t); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(t)
+}
+
+// ===============================
+// UPDATE (Admin)
+// ===============================
+// Update godoc
+// @Summary Update achievement type
+// @Description Mengubah nama, alias dan definisi field details (code tidak dapat diubah) (Admin)
+// @Tags Achievement Types
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param code path string true "Type code"
+// @Param request body model.AchievementTypeRequest true "Achievement type"
+// @Success 200 {object} model.AchievementType
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]string
+// @Router /achievement-types/{code} [put]
+func (s *AchievementTypeService) Update(c *fiber.Ctx) error {
+	var req model.AchievementTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	t := typeFromRequest(req)
+	t.Code = c.Params("code")
+
+	ctx := context.Background()
+	if err := validateTypeDefinition(ctx, s.repo, t, false); err != nil {
+		return validationFailed(c, err)
+	}
+
+	err := s.repo.Update(ctx, &t)
+	if errors.Is(err, repository.ErrAchievementTypeNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	// nama jenis ikut tampil di laporan statistik yang di-cache
+	s.stats.InvalidateCache()
+	return c.JSON(t)
+}
+
+// ===============================
+// DELETE (Admin) → nonaktifkan
+// ===============================
+// Delete godoc
+// @Summary Deactivate achievement type
+// @Description Menonaktifkan jenis prestasi; prestasi lama tetap tersimpan, prestasi baru tidak dapat memakai jenis ini (Admin)
+// @Tags Achievement Types
+// @Security BearerAuth
+// @Produce json
+// @Param code path string true "Type code"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /achievement-types/{code} [delete]
+func (s *AchievementTypeService) Delete(c *fiber.Ctx) error {
+	err := s.repo.Deactivate(context.Background(), c.Params("code"))
+	if errors.Is(err, repository.ErrAchievementTypeNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "achievement type deactivated"})
+}
+
+func typeFromRequest(req model.AchievementTypeRequest) model.AchievementType {
+	t := model.AchievementType{
+		Name:            strings.TrimSpace(req.Name),
+		Description:     req.Description,
+		Aliases:         []string{},
+		Fields:          req.Fields,
+		AllowAdditional: req.AllowAdditional,
+		IsActive:        req.IsActive == nil || *req.IsActive,
+	}
+	for _, a := range req.Aliases {
+		t.Aliases = append(t.Aliases, strings.ToLower(strings.TrimSpace(a)))
+	}
+	if t.Fields == nil {
+		t.Fields = []model.DetailField{}
+	}
+	return t
+}
+
+// validateTypeDefinition = ValidateTypeDefinition + code/alias tidak bentrok dengan jenis lain
+func validateTypeDefinition(ctx context.Context, repo repository.IAchievementTypeRepository, t model.AchievementType, creating bool) error {
+	errs := ValidateTypeDefinition(t)
+
+	existing, err := repo.GetAll(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	taken := map[string]string{} // code/alias → code pemilik
+	for _, other := range existing {
+		if other.Code == t.Code && !creating {
+			continue
+		}
+		taken[other.Code] = other.Code
+		for _, a := range other.Aliases {
+			taken[a] = other.Code
+		}
+	}
+
+	if owner, ok := taken[t.Code]; ok {
+		errs = append(errs, model.FieldError{Field: "code", Message: fmt.Sprintf("already used by %q", owner)})
+	}
+	for i, a := range t.Aliases {
+		if owner, ok := taken[a]; ok {
+			errs = append(errs, model.FieldError{Field: fmt.Sprintf("aliases[%d]", i), Message: fmt.Sprintf("already used by %q", owner)})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Fields: errs}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func competitionType() model.AchievementType {
+	min := 1.0
+	return model.AchievementType{
+		Code:     "competition",
+		Name:     "Kompetisi",
+		Aliases:  []string{"lomba"},
+		IsActive: true,
+		Fields: []model.DetailField{
+			{Name: "competitionName", Type: model.FieldString, Required: true},
+			{Name: "competitionLevel", Type: model.FieldEnum, Required: true, Enum: []string{"international", "national"}},
+			{Name: "rank", Type: model.FieldInteger, Min: &min},
+			{Name: "eventDate", Type: model.FieldDate},
+			{Name: "members", Type: model.FieldArray, Items: model.FieldString},
+		},
+	}
+}
+
+func fieldMessages(errs []model.FieldError) map[string]string {
+	out := map[string]string{}
+	for _, e := range errs {
+		out[e.Field] = e.Message
+	}
+	return out
+}
+
+func TestValidateDetails_Valid(t *testing.T) {
+	errs := ValidateDetails(competitionType(), map[string]interface{}{
+		"competitionName":  "Gemastik",
+		"competitionLevel": "national",
+		"rank":             float64(1),
+		"eventDate":        "2025-10-01",
+		"members":          []interface{}{"Ani", "Budi"},
+	})
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors, got %+v", errs)
+	}
+}
+
+func TestValidateDetails_FieldErrors(t *testing.T) {
+	errs := fieldMessages(ValidateDetails(competitionType(), map[string]interface{}{
+		"competitionLevel": "galaxy",
+		"rank":             1.5,
+		"eventDate":        "01/10/2025",
+		"members":          []interface{}{"Ani", 2},
+		"unknown":          "x",
+	}))
+
+	for _, field := range []string{
+		"details.competitionName",
+		"details.competitionLevel",
+		"details.rank",
+		"details.eventDate",
+		"details.members",
+		"details.unknown",
+	} {
+		if _, ok := errs[field]; !ok {
+			t.Errorf("expected error for %s, got %+v", field, errs)
+		}
+	}
+	if errs["details.competitionName"] != "is required" {
+		t.Errorf("unexpected message: %q", errs["details.competitionName"])
+	}
+}
+
+func TestResolveAchievementType_AliasAndUnknown(t *testing.T) {
+	repo := repository.NewMockAchievementTypeRepository()
+	repo.Data["competition"] = competitionType()
+	ctx := context.Background()
+
+	code, err := resolveAchievementType(ctx, repo, "Lomba", map[string]interface{}{
+		"competitionName":  "Gemastik",
+		"competitionLevel": "national",
+	})
+	if err != nil || code != "competition" {
+		t.Fatalf("expected alias to resolve to competition, got %q, %v", code, err)
+	}
+
+	_, err = resolveAchievementType(ctx, repo, "hobby", nil)
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Fields[0].Field != "achievementType" {
+		t.Fatalf("expected unknown type validation error, got %v", err)
+	}
+
+	repo.Deactivate(ctx, "competition")
+	if _, err := resolveAchievementType(ctx, repo, "competition", nil); !errors.As(err, &ve) {
+		t.Fatalf("inactive type must be rejected, got %v", err)
+	}
+}
+
+func TestCreateAchievement_RejectsInvalidDetails(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	st := repository.NewMockStudentRepository()
+	types := repository.NewMockAchievementTypeRepository()
+	types.Data["competition"] = competitionType()
+	st.UserToStudent["u1"] = "s1"
+
+	svc := NewAchievementLogicService(pg, mg, st, nil, types, nil, nil)
+
+	err := svc.Create("Mahasiswa", "u1", model.AchievementCreateRequest{
+		Title:           "Juara",
+		AchievementType: "competition",
+		Details:         map[string]interface{}{"competitionLevel": "national"},
+	})
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if len(pg.Data) != 0 || len(mg.Data) != 0 {
+		t.Fatal("invalid achievement must not be stored")
+	}
+
+	err = svc.Create("Mahasiswa", "u1", model.AchievementCreateRequest{
+		Title:           "Juara",
+		AchievementType: "LOMBA",
+		Details:         map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, doc := range mg.Data {
+		if doc.AchievementType != "competition" {
+			t.Fatalf("expected canonical type code, got %q", doc.AchievementType)
+		}
+	}
+}
+
+func TestValidateTypeDefinition(t *testing.T) {
+	errs := fieldMessages(ValidateTypeDefinition(model.AchievementType{
+		Code: "Bad Code",
+		Fields: []model.DetailField{
+			{Name: "level", Type: model.FieldEnum},
+			{Name: "level", Type: "color"},
+		},
+	}))
+
+	for _, field := range []string{"code", "name", "fields[0].enum", "fields[1].name", "fields[1].type"} {
+		if _, ok := errs[field]; !ok {
+			t.Errorf("expected error for %s, got %+v", field, errs)
+		}
+	}
+}
+
+func TestBackfillAchievementTypes_MapsAliasesIdempotently(t *testing.T) {
+	types := repository.NewMockAchievementTypeRepository()
+	types.Data["competition"] = competitionType()
+	mg := repository.NewMockAchievementMongoRepository()
+
+	for i, v := range []string{"Lomba", "lomba", "competition", "Lomba", "hobi"} {
+		oid := primitive.NewObjectID()
+		mg.Data[oid.Hex()] = model.AchievementMongo{ID: oid, Title: fmt.Sprint(i), AchievementType: v}
+	}
+
+	countType := func(code string) int {
+		n := 0
+		for _, ach := range mg.Data {
+			if ach.AchievementType == code {
+				n++
+			}
+		}
+		return n
+	}
+
+	dry, err := BackfillAchievementTypes(context.Background(), types, mg, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dry.Updated != 3 || countType("competition") != 1 {
+		t.Fatalf("dry run must only count, got %+v", dry)
+	}
+
+	report, err := BackfillAchievementTypes(context.Background(), types, mg, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Checked != 4 || report.Updated != 3 || len(report.Mapped) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if len(report.Unknown) != 1 || report.Unknown[0] != "hobi" {
+		t.Fatalf("expected hobi as unknown, got %v", report.Unknown)
+	}
+	if countType("competition") != 4 {
+		t.Fatalf("expected 4 competition documents, got %d", countType("competition"))
+	}
+
+	again, _ := BackfillAchievementTypes(context.Background(), types, mg, false)
+	if again.Updated != 0 || len(again.Mapped) != 0 {
+		t.Fatalf("second run must be a no-op, got %+v", again)
+	}
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var typeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// ValidationError membawa daftar error per field (dikirim apa adanya ke client)
+type ValidationError struct {
+	Fields []model.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, f.Field+": "+f.Message)
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// validationFailed: 400 + field-level errors, selain itu 500
+func validationFailed(c *fiber.Ctx, err error) error {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return c.Status(400).JSON(fiber.Map{"error": "validation failed", "fields": ve.Fields})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
+
+// resolveAchievementType mencari jenis prestasi aktif (code / alias) lalu
+// memvalidasi details terhadap definisinya. Mengembalikan code kanonik.
+// repo nil = registry tidak dipakai (input diteruskan apa adanya).
+func resolveAchievementType(
+	ctx context.Context,
+	repo repository.IAchievementTypeRepository,
+	achievementType string,
+	details map[string]interface{},
+) (string, error) {
+
+	if repo == nil {
+		return achievementType, nil
+	}
+
+	code := strings.TrimSpace(achievementType)
+	if code == "" {
+		return "", &ValidationError{Fields: []model.FieldError{
+			{Field: "achievementType", Message: "is required"},
+		}}
+	}
+
+	t, err := repo.GetByCode(ctx, code)
+	if errors.Is(err, repository.ErrAchievementTypeNotFound) || (err == nil && !t.IsActive) {
+		return "", &ValidationError{Fields: []model.FieldError{
+			{Field: "achievementType", Message: fmt.Sprintf("unknown achievement type %q", achievementType)},
+		}}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if errs := ValidateDetails(*t, details); len(errs) > 0 {
+		return "", &ValidationError{Fields: errs}
+	}
+
+	return t.Code, nil
+}
+
+// ValidateDetails memeriksa details terhadap definisi field jenis prestasi
+func ValidateDetails(t model.AchievementType, details map[string]interface{}) []model.FieldError {
+	errs := []model.FieldError{}
+	known := map[string]bool{}
+
+	for _, f := range t.Fields {
+		known[f.Name] = true
+		path := "details." + f.Name
+
+		v, ok := details[f.Name]
+		if !ok || v == nil || v == "" {
+			if f.Required {
+				errs = append(errs, model.FieldError{Field: path, Message: "is required"})
+			}
+			continue
+		}
+
+		if msg := validateValue(f, f.Type, v); msg != "" {
+			errs = append(errs, model.FieldError{Field: path, Message: msg})
+		}
+	}
+
+	if !t.AllowAdditional {
+		extra := []string{}
+		for k := range details {
+			if !known[k] {
+				extra = append(extra, k)
+			}
+		}
+		sort.Strings(extra)
+		for _, k := range extra {
+			errs = append(errs, model.FieldError{
+				Field:   "details." + k,
+				Message: fmt.Sprintf("is not allowed for achievement type %q", t.Code),
+			})
+		}
+	}
+
+	return errs
+}
+
+func validateValue(f model.DetailField, typ string, v interface{}) string {
+	switch typ {
+	case model.FieldString:
+		s, ok := v.(string)
+		if !ok {
+			return "must be a string"
+		}
+		return checkString(f, s)
+
+	case model.FieldEnum:
+		s, ok := v.(string)
+		if !ok {
+			return "must be a string"
+		}
+		for _, e := range f.Enum {
+			if s == e {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(f.Enum, ", ")
+
+	case model.FieldDate:
+		s, ok := v.(string)
+		if !ok {
+			return "must be a date string (YYYY-MM-DD)"
+		}
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return "must be a date (YYYY-MM-DD)"
+		}
+
+	case model.FieldBoolean:
+		if _, ok := v.(bool); !ok {
+			return "must be a boolean"
+		}
+
+	case model.FieldNumber, model.FieldInteger:
+		n, ok := toFloat(v)
+		if !ok {
+			return "must be a number"
+		}
+		if typ == model.FieldInteger && n != float64(int64(n)) {
+			return "must be an integer"
+		}
+		if f.Min != nil && n < *f.Min {
+			return fmt.Sprintf("must be >= %v", *f.Min)
+		}
+		if f.Max != nil && n > *f.Max {
+			return fmt.Sprintf("must be <= %v", *f.Max)
+		}
+
+	case model.FieldArray:
+		items, ok := toSlice(v)
+		if !ok {
+			return "must be an array"
+		}
+		if f.MinLength != nil && len(items) < *f.MinLength {
+			return fmt.Sprintf("must have at least %d items", *f.MinLength)
+		}
+		if f.MaxLength != nil && len(items) > *f.MaxLength {
+			return fmt.Sprintf("must have at most %d items", *f.MaxLength)
+		}
+		if f.Items == "" {
+			return ""
+		}
+		elem := model.DetailField{Name: f.Name, Enum: f.Enum, Min: f.Min, Max: f.Max, Pattern: f.Pattern}
+		for i, item := range items {
+			if msg := validateValue(elem, f.Items, item); msg != "" {
+				return fmt.Sprintf("item %d %s", i, msg)
+			}
+		}
+	}
+
+	return ""
+}
+
+func checkString(f model.DetailField, s string) string {
+	n := len([]rune(s))
+	if f.MinLength != nil && n < *f.MinLength {
+		return fmt.Sprintf("must be at least %d characters", *f.MinLength)
+	}
+	if f.MaxLength != nil && n > *f.MaxLength {
+		return fmt.Sprintf("must be at most %d characters", *f.MaxLength)
+	}
+	if f.Pattern != "" {
+		if re, err := regexp.Compile(f.Pattern); err == nil && !re.MatchString(s) {
+			return "has an invalid format"
+		}
+	}
+	return ""
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func toSlice(v interface{}) ([]interface{}, bool) {
+	switch s := v.(type) {
+	case []interface{}:
+		return s, true
+	case []string:
+		out := make([]interface{}, len(s))
+		for i, item := range s {
+			out[i] = item
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// ValidateTypeDefinition memeriksa definisi jenis prestasi dari Admin
+func ValidateTypeDefinition(t model.AchievementType) []model.FieldError {
+	errs := []model.FieldError{}
+
+	if !typeCodePattern.MatchString(t.Code) {
+		errs = append(errs, model.FieldError{Field: "code", Message: "must be lowercase letters, digits or underscore"})
+	}
+	if strings.TrimSpace(t.Name) == "" {
+		errs = append(errs, model.FieldError{Field: "name", Message: "is required"})
+	}
+	for i, a := range t.Aliases {
+		if a == "" || a != strings.ToLower(a) {
+			errs = append(errs, model.FieldError{Field: fmt.Sprintf("aliases[%d]", i), Message: "must be a non-empty lowercase string"})
+		}
+	}
+
+	seen := map[string]bool{}
+	for i, f := range t.Fields {
+		path := fmt.Sprintf("fields[%d]", i)
+
+		if f.Name == "" {
+			errs = append(errs, model.FieldError{Field: path + ".name", Message: "is required"})
+		} else if seen[f.Name] {
+			errs = append(errs, model.FieldError{Field: path + ".name", Message: fmt.Sprintf("duplicate field %q", f.Name)})
+		}
+		seen[f.Name] = true
+
+		if !isFieldType(f.Type) {
+			errs = append(errs, model.FieldError{Field: path + ".type", Message: "unsupported type " + fmt.Sprintf("%q", f.Type)})
+		}
+		if (f.Type == model.FieldEnum || f.Items == model.FieldEnum) && len(f.Enum) == 0 {
+			errs = append(errs, model.FieldError{Field: path + ".enum", Message: "is required for enum fields"})
+		}
+		if f.Type == model.FieldArray && f.Items != "" && (f.Items == model.FieldArray || !isFieldType(f.Items)) {
+			errs = append(errs, model.FieldError{Field: path + ".items", Message: "unsupported item type " + fmt.Sprintf("%q", f.Items)})
+		}
+		if f.Pattern != "" {
+			if _, err := regexp.Compile(f.Pattern); err != nil {
+				errs = append(errs, model.FieldError{Field: path + ".pattern", Message: "is not a valid regular expression"})
+			}
+		}
+	}
+
+	return errs
+}
+
+func isFieldType(t string) bool {
+	switch t {
+	case model.FieldString, model.FieldNumber, model.FieldInteger, model.FieldBoolean,
+		model.FieldDate, model.FieldEnum, model.FieldArray:
+		return true
+	}
+	return false
+}
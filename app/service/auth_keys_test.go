@@ -0,0 +1,159 @@
+package service
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"backenduas/app/jwtkeys"
+	"backenduas/app/repository"
+	"backenduas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writeKey(t *testing.T, dir string, key *jwtkeys.Key, public bool) {
+	t.Helper()
+	pem, name := key.PrivatePEM, key.ID+".pem"
+	if public {
+		pem, name = key.PublicPEM, key.ID+".pub.pem"
+	}
+	raw, err := pem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyRing_RotationKeepsOldTokensValid(t *testing.T) {
+	dir := t.TempDir()
+	old, _ := jwtkeys.Generate("2026-01", jwtkeys.AlgRS256)
+	next, _ := jwtkeys.Generate("2026-07", jwtkeys.AlgEdDSA)
+	writeKey(t, dir, old, false)
+	writeKey(t, dir, old, true)
+
+	before, err := jwtkeys.Load(dir, "iss", "aud", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, _ := before.Sign(jwt.MapClaims{"user_id": "u1", "exp": time.Now().Add(time.Hour).Unix()}, "aud")
+
+	// kunci baru dipasang, kunci lama hanya tersisa kunci publiknya
+	os.Remove(filepath.Join(dir, "2026-01.pem"))
+	writeKey(t, dir, next, false)
+	after, err := jwtkeys.Load(dir, "iss", "aud", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.SigningKID() != "2026-07" {
+		t.Fatalf("newest private key must sign, got %s", after.SigningKID())
+	}
+	if _, err := after.Verify(oldToken, "aud"); err != nil {
+		t.Errorf("token of the previous key must still verify: %v", err)
+	}
+
+	newToken, _ := after.Sign(jwt.MapClaims{"user_id": "u1", "exp": time.Now().Add(time.Hour).Unix()}, "aud")
+	parsed, _, _ := jwt.NewParser().ParseUnverified(newToken, jwt.MapClaims{})
+	if parsed.Header["kid"] != "2026-07" || parsed.Header["alg"] != jwtkeys.AlgEdDSA {
+		t.Errorf("unexpected header %v", parsed.Header)
+	}
+	if _, err := before.Verify(newToken, "aud"); err == nil {
+		t.Error("unknown kid must be rejected")
+	}
+
+	if _, err := jwtkeys.Load(dir, "iss", "aud", "2026-01"); err == nil {
+		t.Error("public-only key cannot be the signing key")
+	}
+}
+
+func TestKeyRing_RejectsForgedAndForeignTokens(t *testing.T) {
+	rsaKey, _ := jwtkeys.Generate("rsa", jwtkeys.AlgRS256)
+	edKey, _ := jwtkeys.Generate("ed", jwtkeys.AlgEdDSA)
+	keys, err := jwtkeys.New("backenduas", "sistem-prestasi", "rsa", rsaKey, edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{"user_id": "u1", "iss": "backenduas", "aud": "sistem-prestasi", "exp": time.Now().Add(time.Hour).Unix()}
+	}
+	sign := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		token := jwt.NewWithClaims(method, claims())
+		token.Header["kid"] = kid
+		raw, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+	pub, _ := rsaKey.PublicPEM()
+
+	forged := map[string]string{
+		// HS256 dengan kunci publik RSA sebagai secret (algorithm confusion)
+		"hs256 with public key": sign(jwt.SigningMethodHS256, "rsa", pub),
+		// alg tidak sesuai kunci pada kid
+		"eddsa under rsa kid": sign(jwt.SigningMethodEdDSA, "rsa", edKey.Private),
+		"none":                sign(jwt.SigningMethodNone, "rsa", jwt.UnsafeAllowNoneSignatureType),
+		"missing kid":         sign(jwt.SigningMethodRS256, "", rsaKey.Private),
+	}
+	for name, raw := range forged {
+		if _, err := keys.Verify(raw, "sistem-prestasi"); err == nil {
+			t.Errorf("%s: token must be rejected", name)
+		}
+	}
+
+	valid, _ := keys.Sign(claims(), "sistem-prestasi")
+	if _, err := keys.Verify(valid, "sistem-prestasi"); err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+	if _, err := keys.Verify(valid, "layanan-lain"); err == nil {
+		t.Error("wrong audience must be rejected")
+	}
+	foreign, _ := jwtkeys.New("kampus-lain", "sistem-prestasi", "rsa", rsaKey)
+	if _, err := foreign.Verify(valid, "sistem-prestasi"); err == nil {
+		t.Error("wrong issuer must be rejected")
+	}
+	noExp, _ := keys.Sign(jwt.MapClaims{"user_id": "u1"}, "sistem-prestasi")
+	if _, err := keys.Verify(noExp, "sistem-prestasi"); err == nil {
+		t.Error("token without exp must be rejected")
+	}
+}
+
+func TestRefreshTokenIsNotAnAccessToken(t *testing.T) {
+	repo := repository.NewMockAuthRepository()
+	repo.SeedUser("u1", "andi", "andi123", "Mahasiswa")
+	_, refresh, _, err := NewAuthLogicService(repo, testKeys).LoginLogic("andi", "andi123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// aud refresh token = issuer, bukan audience layanan
+	if _, err := testKeys.Verify(refresh, testKeys.Audience); err == nil {
+		t.Error("refresh token must not verify as an access token")
+	}
+
+	middleware.UseKeyRing(testKeys)
+	defer middleware.UseKeyRing(nil)
+	app := fiber.New()
+	app.Get("/profile", middleware.JWTProtected(), func(c *fiber.Ctx) error { return c.SendStatus(204) })
+	app.Get("/.well-known/jwks.json", NewAuthService(repo, nil, testKeys, nil).JWKS)
+
+	req := httptest.NewRequest("GET", "/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+refresh)
+	if resp, _ := app.Test(req); resp.StatusCode != 401 {
+		t.Errorf("refresh token must not authenticate, got %d", resp.StatusCode)
+	}
+
+	code, body := call(t, app, "GET", "/.well-known/jwks.json", "")
+	list, _ := body["keys"].([]interface{})
+	if code != 200 || len(list) != 1 {
+		t.Fatalf("unexpected JWKS %d %v", code, body)
+	}
+	jwk := list[0].(map[string]interface{})
+	if jwk["kty"] != "OKP" || jwk["crv"] != "Ed25519" || jwk["kid"] != testKeys.SigningKID() || jwk["x"] == "" || jwk["d"] != nil {
+		t.Errorf("unexpected JWK %v", jwk)
+	}
+}
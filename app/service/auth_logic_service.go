@@ -3,22 +3,21 @@ package service
 import (
 	"context"
 	"errors"
-	"os"
-	"time"
 
+	"backenduas/app/jwtkeys"
 	"backenduas/app/model"
 	"backenduas/app/repository"
 
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthLogicService struct {
 	repo repository.IAuthRepository
+	keys *jwtkeys.KeyRing
 }
 
-func NewAuthLogicService(repo repository.IAuthRepository) *AuthLogicService {
-	return &AuthLogicService{repo}
+func NewAuthLogicService(repo repository.IAuthRepository, keys *jwtkeys.KeyRing) *AuthLogicService {
+	return &AuthLogicService{repo, keys}
 }
 
 func (s *AuthLogicService) LoginLogic(
@@ -35,22 +34,16 @@ func (s *AuthLogicService) LoginLogic(
 		return "", "", nil, errors.New("username atau password salah")
 	}
 
-	claims := jwt.MapClaims{
-		"user_id":   user.ID,
-		"role_name": user.RoleName,
-		"exp":       time.Now().Add(24 * time.Hour).Unix(),
+	if !user.IsActive {
+		return "", "", nil, errors.New("akun tidak aktif")
 	}
-	access := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	accessToken, _ := access.SignedString([]byte(os.Getenv("API_KEY")))
 
-	refreshClaims := jwt.MapClaims{
-		"user_id": user.ID,
-		"exp":     time.Now().Add(7 * 24 * time.Hour).Unix(),
+	ctx := context.Background()
+	perms, _ := s.repo.GetPermissionsByRole(ctx, user.RoleID)
+	pair, err := startSession(ctx, s.repo, s.keys, user, perms, "", "")
+	if err != nil {
+		return "", "", nil, err
 	}
-	refresh := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshToken, _ := refresh.SignedString([]byte(os.Getenv("API_KEY")))
-
-	s.repo.SaveRefreshToken(context.Background(), user.ID, refreshToken, time.Now().Add(7*24*time.Hour))
 
-	return accessToken, refreshToken, user, nil
+	return pair.AccessToken, pair.RefreshToken, user, nil
 }
@@ -2,9 +2,15 @@ package service
 
 import (
 	"context"
-	"os"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
+	"backenduas/app/jwtkeys"
 	"backenduas/app/model"
 	"backenduas/app/repository"
 
@@ -14,11 +20,41 @@ import (
 )
 
 type AuthService struct {
-	repo *repository.AuthRepository
+	repo     repository.IAuthRepository
+	denylist *TokenDenylist // nil = access token tidak dapat dicabut sebelum kedaluwarsa
+	keys     *jwtkeys.KeyRing
+	guard    *LoginGuard // nil = percobaan login tidak dibatasi
 }
 
-func NewAuthService(repo *repository.AuthRepository) *AuthService {
-	return &AuthService{repo}
+func NewAuthService(repo repository.IAuthRepository, denylist *TokenDenylist, keys *jwtkeys.KeyRing, guard *LoginGuard) *AuthService {
+	return &AuthService{repo: repo, denylist: denylist, keys: keys, guard: guard}
+}
+
+// tooManyAttempts = 429 + Retry-After (detik, dibulatkan ke atas)
+func tooManyAttempts(c *fiber.Ctx, wait time.Duration) error {
+	secs := int(math.Ceil(wait.Seconds()))
+	c.Set("Retry-After", strconv.Itoa(secs))
+	return c.Status(429).JSON(fiber.Map{
+		"error":       fmt.Sprintf("Terlalu banyak percobaan login. Coba lagi dalam %d detik", secs),
+		"retry_after": secs,
+	})
+}
+
+// loginClient = IP & User-Agent yang disalin (string fiber dipakai ulang setelah request selesai)
+func loginClient(c *fiber.Ctx) (ip, userAgent string) {
+	return strings.Clone(c.IP()), strings.Clone(c.Get("User-Agent"))
+}
+
+// loginFailed mencatat kegagalan lalu membalas 401 (Retry-After jika kini diblokir)
+func (s *AuthService) loginFailed(c *fiber.Ctx, username string, user *model.User, reason string, penalty time.Duration) error {
+	ip, userAgent := loginClient(c)
+	if err := s.guard.Fail(context.Background(), username, ip, userAgent, user, reason); err != nil {
+		log.Printf("⚠️ gagal mencatat login gagal %q: %v", username, err)
+	}
+	if penalty > 0 {
+		c.Set("Retry-After", strconv.Itoa(int(math.Ceil(penalty.Seconds()))))
+	}
+	return c.Status(401).JSON(fiber.Map{"error": "username atau password salah"})
 }
 
 /* =============================
@@ -26,7 +62,7 @@ func NewAuthService(repo *repository.AuthRepository) *AuthService {
 ============================= */
 // Login godoc
 // @Summary Login user
-// @Description Login user dan mengembalikan access token & refresh token
+// @Description Login user dan mengembalikan access token & refresh token. Login gagal berulang per akun / IP diberi jeda bertahap lalu dikunci sementara (429 + Retry-After).
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -34,6 +70,8 @@ func NewAuthService(repo *repository.AuthRepository) *AuthService {
 // @Success 200 {object} map[string]any
 // @Failure 400 {object} map[string]any
 // @Failure 401 {object} map[string]any
+// @Failure 403 {object} map[string]any
+// @Failure 429 {object} map[string]any
 // @Router /auth/login [post]
 func (s *AuthService) Login(c *fiber.Ctx) error {
 	var req model.LoginRequest
@@ -42,44 +80,53 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Bad Request"})
 	}
 
-	user, err := s.repo.FindByUsername(context.Background(), req.Username)
+	ctx := context.Background()
+	ip, userAgent := loginClient(c)
+
+	// percobaan dipesan (dihitung sebagai gagal) sebelum password diperiksa;
+	// akun / IP yang sedang diblokir ditolak tanpa dihitung
+	blocked, penalty, err := s.guard.Reserve(ctx, req.Username, ip)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if blocked > 0 {
+		s.guard.Fail(ctx, req.Username, ip, userAgent, nil, model.LoginThrottled)
+		return tooManyAttempts(c, blocked)
+	}
+
+	user, err := s.repo.FindByUsername(ctx, req.Username)
 	if err != nil {
-		return c.Status(401).JSON(fiber.Map{"error": "username atau password salah"})
+		return s.loginFailed(c, req.Username, nil, model.LoginUnknownUser, penalty)
 	}
 
 	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)) != nil {
-		return c.Status(401).JSON(fiber.Map{"error": "username atau password salah"})
+		return s.loginFailed(c, req.Username, user, model.LoginFailedPassword, penalty)
 	}
 
-	perms, _ := s.repo.GetPermissionsByRole(context.Background(), user.RoleID)
-
-	// ACCESS TOKEN
-	claims := jwt.MapClaims{
-		"user_id":   user.ID,
-		"role_id":   user.RoleID,
-		"role_name": user.RoleName,
-		"full_name": user.FullName,
-		"exp":       time.Now().Add(24 * time.Hour).Unix(),
+	// diperiksa setelah password benar agar status akun tidak bocor ke penebak
+	if !user.IsActive {
+		s.guard.Release(ctx, req.Username, ip)
+		s.guard.Fail(ctx, req.Username, ip, userAgent, user, model.LoginInactiveUser)
+		return c.Status(403).JSON(fiber.Map{"error": "Akun tidak aktif"})
 	}
-	access := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	accessToken, _ := access.SignedString([]byte(os.Getenv("API_KEY")))
-
-	// REFRESH TOKEN
-	refreshClaims := jwt.MapClaims{
-		"user_id": user.ID,
-		"exp":     time.Now().Add(7 * 24 * time.Hour).Unix(),
+	if err := s.guard.Succeed(ctx, req.Username, ip); err != nil {
+		log.Printf("⚠️ gagal mereset penghitung login %q: %v", req.Username, err)
 	}
-	refreshJWT := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshToken, _ := refreshJWT.SignedString([]byte(os.Getenv("API_KEY")))
 
-	// SAVE REFRESH TOKEN → DB
-	s.repo.SaveRefreshToken(context.Background(), user.ID, refreshToken, time.Now().Add(7*24*time.Hour))
+	perms, _ := s.repo.GetPermissionsByRole(ctx, user.RoleID)
+
+	// ACCESS + REFRESH TOKEN dalam sesi baru
+	pair, err := startSession(ctx, s.repo, s.keys, user, perms, userAgent, ip)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
 
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"token":        accessToken,
-			"refreshToken": refreshToken,
+			"token":        pair.AccessToken,
+			"refreshToken": pair.RefreshToken,
+			"sessionId":    pair.Session.ID,
 			"user": fiber.Map{
 				"id":          user.ID,
 				"username":    user.Username,
@@ -119,11 +166,11 @@ func (s *AuthService) Profile(c *fiber.Ctx) error {
 }
 
 /* =============================
-   LOGOUT (REVOKE REFRESH TOKEN)
+   LOGOUT (REVOKE SESSION + ACCESS TOKEN)
 ============================= */
 // Logout godoc
 // @Summary Logout user
-// @Description Logout user dan mencabut refresh token
+// @Description Logout user: mencabut sesi milik refresh token dan access token yang sedang dipakai
 // @Tags Auth
 // @Security BearerAuth
 // @Produce json
@@ -137,7 +184,18 @@ func (s *AuthService) Logout(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Refresh token tidak ditemukan"})
 	}
 
-	s.repo.DeleteRefreshToken(context.Background(), refreshToken)
+	ctx := context.Background()
+	if err := s.repo.RevokeSessionByToken(ctx, hashToken(refreshToken), model.RevokedLogout); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if _, sid, err := parseRefreshToken(s.keys, refreshToken); err == nil {
+		s.denylist.RevokeSession(sid)
+	}
+
+	claims := c.Locals("user").(jwt.MapClaims)
+	if err := s.denylist.RevokeToken(ctx, claims, model.RevokedLogout); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
 
 	return c.JSON(fiber.Map{
 		"status":  "success",
@@ -145,12 +203,41 @@ func (s *AuthService) Logout(c *fiber.Ctx) error {
 	})
 }
 
+/* =============================
+   LOGOUT SEMUA PERANGKAT
+============================= */
+// LogoutAll godoc
+// @Summary Logout from all devices
+// @Description Mencabut semua sesi dan semua access token user yang sedang login
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]any
+// @Router /auth/logout-all [post]
+func (s *AuthService) LogoutAll(c *fiber.Ctx) error {
+	claims := c.Locals("user").(jwt.MapClaims)
+	userID, _ := claims["user_id"].(string)
+
+	ctx := context.Background()
+	if _, err := s.repo.RevokeUserSessions(ctx, userID, model.RevokedLogoutAll); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := s.denylist.RevokeUser(ctx, userID, model.RevokedLogoutAll, false); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Logout dari semua perangkat berhasil.",
+	})
+}
+
 /* =============================
    REFRESH TOKEN
 ============================= */
 // RefreshToken godoc
 // @Summary Refresh access token
-// @Description Generate access token baru menggunakan refresh token
+// @Description Menukar refresh token dengan access token + refresh token baru (rotasi). Refresh token lama tidak dapat dipakai lagi; jika dipakai ulang, seluruh sesi user dicabut.
 // @Tags Auth
 // @Produce json
 // @Param X-Refresh-Token header string true "Refresh Token"
@@ -164,33 +251,98 @@ func (s *AuthService) Refresh(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Refresh token tidak ditemukan"})
 	}
 
-	valid, _ := s.repo.IsRefreshTokenValid(context.Background(), refreshToken)
-	if !valid {
-		return c.Status(401).JSON(fiber.Map{"error": "Refresh token sudah dicabut"})
+	ctx := context.Background()
+	pair, err := rotateSession(ctx, s.repo, s.keys, refreshToken)
+	switch {
+	case errors.Is(err, repository.ErrRefreshTokenReused):
+		// access token yang sudah terbit ikut dicabut (sesi sudah dicabut rotateSession)
+		if rerr := s.denylist.RevokeUser(ctx, pair.Session.UserID, model.RevokedTokenReuse, false); rerr != nil {
+			return c.Status(500).JSON(fiber.Map{"error": rerr.Error()})
+		}
+		return c.Status(401).JSON(fiber.Map{"error": "Refresh token sudah pernah dipakai; semua sesi dicabut, silakan login ulang"})
+	case errors.Is(err, repository.ErrRefreshTokenInvalid):
+		return c.Status(401).JSON(fiber.Map{"error": "Refresh token invalid atau sudah dicabut"})
+	case err != nil:
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	// Parse refresh token
-	token, err := jwt.Parse(refreshToken, func(t *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("API_KEY")), nil
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"token":        pair.AccessToken,
+			"refreshToken": pair.RefreshToken,
+			"sessionId":    pair.Session.ID,
+		},
 	})
-	if err != nil || !token.Valid {
-		return c.Status(401).JSON(fiber.Map{"error": "Refresh token invalid"})
-	}
+}
 
-	claims := token.Claims.(jwt.MapClaims)
+/* =============================
+   SESSIONS (PERANGKAT LOGIN)
+============================= */
+// Sessions godoc
+// @Summary List active sessions
+// @Description Sesi login aktif milik user (satu per perangkat); current = sesi token ini
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]any
+// @Router /auth/sessions [get]
+func (s *AuthService) Sessions(c *fiber.Ctx) error {
+	claims := c.Locals("user").(jwt.MapClaims)
+	userID, _ := claims["user_id"].(string)
 
-	newClaims := jwt.MapClaims{
-		"user_id":   claims["user_id"],
-		"exp":       time.Now().Add(24 * time.Hour).Unix(),
+	sessions, err := s.repo.ListSessions(context.Background(), userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == claims["sid"]
+	}
+
+	return c.JSON(fiber.Map{"data": sessions})
+}
+
+// RevokeSession godoc
+// @Summary Revoke session
+// @Description Mencabut satu sesi milik user; refresh token dan access token sesi itu tidak dapat dipakai lagi
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /auth/sessions/{id} [delete]
+func (s *AuthService) RevokeSession(c *fiber.Ctx) error {
+	claims := c.Locals("user").(jwt.MapClaims)
+	userID, _ := claims["user_id"].(string)
 
-	newToken := jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims)
-	signed, _ := newToken.SignedString([]byte(os.Getenv("API_KEY")))
+	err := s.repo.RevokeSession(context.Background(), userID, c.Params("id"), model.RevokedSession)
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "Sesi tidak ditemukan"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	s.denylist.RevokeSession(c.Params("id"))
 
 	return c.JSON(fiber.Map{
-		"status": "success",
-		"data": fiber.Map{
-			"token": signed,
-		},
+		"status":  "success",
+		"message": "Sesi dicabut",
 	})
 }
+
+/* =============================
+   JWKS (KUNCI PUBLIK)
+============================= */
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description Kunci publik untuk memverifikasi access token (RS256 / EdDSA, dipilih lewat header kid). Layanan lain wajib memeriksa iss dan aud.
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} jwtkeys.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (s *AuthService) JWKS(c *fiber.Ctx) error {
+	// kunci baru harus dipublikasikan minimal 5 menit sebelum dipakai menandatangani
+	c.Set("Cache-Control", "public, max-age=300")
+	return c.JSON(s.keys.JWKS())
+}
@@ -1,16 +1,24 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"net/http/httptest"
 	"testing"
 
+	"backenduas/app/jwtkeys"
+	"backenduas/app/model"
 	"backenduas/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 func TestLoginLogic_Success(t *testing.T) {
 	repo := repository.NewMockAuthRepository()
 	repo.SeedUser("u1", "admin", "admin123", "Admin")
 
-	svc := NewAuthLogicService(repo)
+	svc := NewAuthLogicService(repo, testKeys)
 
 	_, _, user, err := svc.LoginLogic("admin", "admin123")
 	if err != nil {
@@ -26,7 +34,7 @@ func TestLoginLogic_WrongPassword(t *testing.T) {
 	repo := repository.NewMockAuthRepository()
 	repo.SeedUser("u1", "admin", "admin123", "Admin")
 
-	svc := NewAuthLogicService(repo)
+	svc := NewAuthLogicService(repo, testKeys)
 
 	_, _, _, err := svc.LoginLogic("admin", "salah")
 	if err == nil {
@@ -36,10 +44,148 @@ func TestLoginLogic_WrongPassword(t *testing.T) {
 
 func TestLoginLogic_UserNotFound(t *testing.T) {
 	repo := repository.NewMockAuthRepository()
-	svc := NewAuthLogicService(repo)
+	svc := NewAuthLogicService(repo, testKeys)
 
 	_, _, _, err := svc.LoginLogic("unknown", "123")
 	if err == nil {
 		t.Fatalf("expected error")
 	}
 }
+
+// testKeys = kunci Ed25519 sementara untuk semua test yang menerbitkan token
+var testKeys = func() *jwtkeys.KeyRing {
+	keys, err := jwtkeys.Ephemeral("backenduas-test", "sistem-prestasi")
+	if err != nil {
+		panic(err)
+	}
+	return keys
+}()
+
+func parseClaims(t *testing.T, token string) jwt.MapClaims {
+	t.Helper()
+	claims, err := testKeys.Verify(token, testKeys.Audience)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return claims
+}
+
+func TestRotateSession_RotatesWithFullClaims(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMockAuthRepository()
+	repo.SeedUser("u1", "dosen", "dosen123", "Dosen Wali")
+
+	_, refresh, _, err := NewAuthLogicService(repo, testKeys).LoginLogic("dosen", "dosen123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pair, err := rotateSession(ctx, repo, testKeys, refresh)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pair.RefreshToken == refresh {
+		t.Fatal("refresh token must be rotated")
+	}
+	claims := parseClaims(t, pair.AccessToken)
+	if claims["role_name"] != "Dosen Wali" || claims["role_id"] != "role-1" || claims["sid"] != pair.Session.ID {
+		t.Errorf("refreshed access token lacks claims: %v", claims)
+	}
+	if len(claims["permissions"].([]interface{})) != 2 {
+		t.Errorf("refreshed access token lacks permissions: %v", claims)
+	}
+
+	// token baru tetap bisa dirotasi lagi
+	if _, err := rotateSession(ctx, repo, testKeys, pair.RefreshToken); err != nil {
+		t.Errorf("rotated token must be usable once: %v", err)
+	}
+
+	// access token tidak dapat dipakai sebagai refresh token
+	if _, err := rotateSession(ctx, repo, testKeys, pair.AccessToken); !errors.Is(err, repository.ErrRefreshTokenInvalid) {
+		t.Errorf("access token must not refresh, got %v", err)
+	}
+}
+
+func TestRotateSession_ReuseRevokesAllSessions(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMockAuthRepository()
+	repo.SeedUser("u1", "andi", "andi123", "Mahasiswa")
+	svc := NewAuthLogicService(repo, testKeys)
+
+	_, stolen, _, _ := svc.LoginLogic("andi", "andi123")
+	_, laptop, _, _ := svc.LoginLogic("andi", "andi123")
+
+	pair, err := rotateSession(ctx, repo, testKeys, stolen)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// penyerang memutar ulang token lama
+	if _, err := rotateSession(ctx, repo, testKeys, stolen); !errors.Is(err, repository.ErrRefreshTokenReused) {
+		t.Fatalf("expected reuse detection, got %v", err)
+	}
+	for _, s := range repo.Sessions {
+		if s.RevokedAt == nil || s.RevokedReason != model.RevokedTokenReuse {
+			t.Errorf("session %s must be revoked on reuse: %+v", s.ID, s)
+		}
+	}
+	for _, tok := range []string{pair.RefreshToken, laptop} {
+		if _, err := rotateSession(ctx, repo, testKeys, tok); !errors.Is(err, repository.ErrRefreshTokenInvalid) {
+			t.Errorf("tokens of revoked sessions must be rejected, got %v", err)
+		}
+	}
+}
+
+func TestSessionsEndpoints(t *testing.T) {
+	repo := repository.NewMockAuthRepository()
+	repo.SeedUser("u1", "andi", "andi123", "Mahasiswa")
+	repo.SeedUser("u2", "citra", "citra123", "Mahasiswa")
+	logic := NewAuthLogicService(repo, testKeys)
+
+	access, _, _, _ := logic.LoginLogic("andi", "andi123")
+	_, phone, _, _ := logic.LoginLogic("andi", "andi123")
+	_, other, _, _ := logic.LoginLogic("citra", "citra123")
+
+	svc := NewAuthService(repo, nil, testKeys, nil)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user", parseClaims(t, access))
+		return c.Next()
+	})
+	app.Get("/sessions", svc.Sessions)
+	app.Delete("/sessions/:id", svc.RevokeSession)
+	app.Post("/refresh", svc.Refresh)
+
+	code, body := call(t, app, "GET", "/sessions", "")
+	list, _ := body["data"].([]interface{})
+	if code != 200 || len(list) != 2 {
+		t.Fatalf("expected two sessions, got %d %v", code, body)
+	}
+	current := 0
+	for _, s := range list {
+		if s.(map[string]interface{})["current"] == true {
+			current++
+		}
+	}
+	if current != 1 {
+		t.Errorf("exactly one session must be current: %v", list)
+	}
+
+	// sesi user lain tidak terlihat / tidak dapat dicabut
+	_, otherSession, _ := parseRefreshToken(testKeys, other)
+	if code, _ := call(t, app, "DELETE", "/sessions/"+otherSession, ""); code != 404 {
+		t.Errorf("foreign session must be 404, got %d", code)
+	}
+
+	_, phoneSession, _ := parseRefreshToken(testKeys, phone)
+	if code, _ := call(t, app, "DELETE", "/sessions/"+phoneSession, ""); code != 200 {
+		t.Errorf("expected session revoked, got %d", code)
+	}
+
+	req := httptest.NewRequest("POST", "/refresh", nil)
+	req.Header.Set("X-Refresh-Token", phone)
+	resp, _ := app.Test(req)
+	if resp.StatusCode != 401 {
+		t.Errorf("refresh of a revoked session must be 401, got %d", resp.StatusCode)
+	}
+}
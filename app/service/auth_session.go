@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"backenduas/app/jwtkeys"
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// =====================================================
+//  TOKEN & SESI LOGIN
+// =====================================================
+//
+// Setiap login membuka satu sesi. Refresh token berisi sid (id sesi) dan
+// hanya boleh dipakai sekali: /auth/refresh menandainya terpakai lalu
+// menerbitkan pasangan token baru di sesi yang sama. Jika token yang sudah
+// terpakai muncul lagi, token itu kemungkinan dicuri → semua sesi user dicabut.
+
+const (
+	accessTokenTTL  = 24 * time.Hour
+	refreshTokenTTL = 7 * 24 * time.Hour
+	tokenTypRefresh = "refresh"
+)
+
+type tokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	Session      *model.AuthSession
+}
+
+// hashToken: refresh token hanya disimpan sebagai SHA-256
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// signAccessToken: aud = layanan kampus (boleh diverifikasi lewat JWKS)
+func signAccessToken(keys *jwtkeys.KeyRing, claims jwt.MapClaims) (string, error) {
+	return keys.Sign(claims, keys.Audience)
+}
+
+// accessClaims = klaim lengkap access token (login maupun refresh)
+func accessClaims(user *model.User, perms []string, sessionID string) jwt.MapClaims {
+	if perms == nil {
+		perms = []string{}
+	}
+	// iat milidetik: token baru tetap sah walau terbit pada detik yang sama
+	// dengan logout-all (lihat TokenDenylist.RevokeUser)
+	now := time.Now()
+	return jwt.MapClaims{
+		"user_id":     user.ID,
+		"role_id":     user.RoleID,
+		"role_name":   user.RoleName,
+		"full_name":   user.FullName,
+		"permissions": perms, // fallback jika resolver permission tidak dipasang
+		"sid":         sessionID,
+		"jti":         uuid.New().String(),
+		"iat":         float64(now.UnixMilli()) / 1000,
+		"exp":         now.Add(accessTokenTTL).Unix(),
+	}
+}
+
+// newRefreshToken: aud = issuer sendiri, sehingga refresh token tidak pernah
+// diterima layanan lain yang memverifikasi access token
+func newRefreshToken(keys *jwtkeys.KeyRing, userID, sessionID string, exp time.Time) (string, error) {
+	return keys.Sign(jwt.MapClaims{
+		"user_id": userID,
+		"sid":     sessionID,
+		"jti":     uuid.New().String(), // token unik walau diterbitkan pada detik yang sama
+		"typ":     tokenTypRefresh,
+		"exp":     exp.Unix(),
+	}, keys.Issuer)
+}
+
+// startSession membuka sesi baru untuk user yang berhasil login
+func startSession(ctx context.Context, repo repository.IAuthRepository, keys *jwtkeys.KeyRing, user *model.User, perms []string, userAgent, ip string) (tokenPair, error) {
+	exp := time.Now().Add(refreshTokenTTL)
+	sess := &model.AuthSession{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		UserAgent: userAgent,
+		IPAddress: ip,
+		ExpiresAt: exp,
+	}
+
+	refresh, err := newRefreshToken(keys, user.ID, sess.ID, exp)
+	if err != nil {
+		return tokenPair{}, err
+	}
+	if err := repo.CreateSession(ctx, sess, hashToken(refresh)); err != nil {
+		return tokenPair{}, err
+	}
+
+	access, err := signAccessToken(keys, accessClaims(user, perms, sess.ID))
+	if err != nil {
+		return tokenPair{}, err
+	}
+	return tokenPair{AccessToken: access, RefreshToken: refresh, Session: sess}, nil
+}
+
+// parseRefreshToken memeriksa tanda tangan, masa berlaku, iss/aud dan jenis token
+func parseRefreshToken(keys *jwtkeys.KeyRing, raw string) (userID, sessionID string, err error) {
+	claims, err := keys.Verify(raw, keys.Issuer)
+	if err != nil {
+		return "", "", repository.ErrRefreshTokenInvalid
+	}
+
+	userID, _ = claims["user_id"].(string)
+	sessionID, _ = claims["sid"].(string)
+	if claims["typ"] != tokenTypRefresh || userID == "" || sessionID == "" {
+		return "", "", repository.ErrRefreshTokenInvalid
+	}
+	return userID, sessionID, nil
+}
+
+// rotateSession menukar refresh token dengan pasangan token baru.
+// Token yang dipakai ulang mencabut seluruh sesi pemiliknya (Session pada
+// hasil = sesi token tersebut).
+func rotateSession(ctx context.Context, repo repository.IAuthRepository, keys *jwtkeys.KeyRing, raw string) (tokenPair, error) {
+	userID, sessionID, err := parseRefreshToken(keys, raw)
+	if err != nil {
+		return tokenPair{}, err
+	}
+
+	exp := time.Now().Add(refreshTokenTTL)
+	refresh, err := newRefreshToken(keys, userID, sessionID, exp)
+	if err != nil {
+		return tokenPair{}, err
+	}
+
+	sess, err := repo.RotateRefreshToken(ctx, sessionID, hashToken(raw), hashToken(refresh), exp)
+	if errors.Is(err, repository.ErrRefreshTokenReused) {
+		n, rerr := repo.RevokeUserSessions(ctx, sess.UserID, model.RevokedTokenReuse)
+		if rerr != nil {
+			return tokenPair{}, rerr
+		}
+		log.Printf("🚨 Refresh token sesi %s dipakai ulang; %d sesi user %s dicabut", sessionID, n, sess.UserID)
+		return tokenPair{Session: sess}, err
+	}
+	if err != nil {
+		return tokenPair{}, err
+	}
+
+	user, err := repo.FindByID(ctx, sess.UserID)
+	if err != nil || !user.IsActive {
+		return tokenPair{}, repository.ErrRefreshTokenInvalid
+	}
+	// klaim dibaca ulang: role / permission bisa berubah sejak login
+	perms, _ := repo.GetPermissionsByRole(ctx, user.RoleID)
+	access, err := signAccessToken(keys, accessClaims(user, perms, sess.ID))
+	if err != nil {
+		return tokenPair{}, err
+	}
+	return tokenPair{AccessToken: access, RefreshToken: refresh, Session: sess}, nil
+}
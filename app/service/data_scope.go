@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+	"backenduas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// =====================================================
+//  CAKUPAN DATA PEMANGGIL
+// =====================================================
+//
+// Route hanya memeriksa permission (boleh memanggil endpoint). Data mana yang
+// terlihat ditentukan di sini, bukan dari role_name:
+//
+//	permission scope:all        → seluruh mahasiswa
+//	user terhubung ke students  → hanya data miliknya
+//	user terhubung ke lecturers → mahasiswa bimbingan
+//
+// User yang tidak memenuhi satu pun ditolak (fail closed), sehingga role
+// buatan Admin cukup diberi permission & data dosen/mahasiswa yang sesuai.
+
+var errNoDataScope = fiber.NewError(403, "Forbidden: akun tidak terhubung ke data mahasiswa atau dosen")
+
+type dataScope struct {
+	All        bool
+	StudentID  string
+	LecturerID string
+}
+
+// callerScope menentukan cakupan data pemanggil dari permission & keterkaitan user
+func callerScope(ctx context.Context, c *fiber.Ctx, st repository.IStudentRepository) (dataScope, error) {
+	if containsString(middleware.Permissions(c), PermScopeAll) {
+		return dataScope{All: true}, nil
+	}
+
+	claims, _ := c.Locals("user").(jwt.MapClaims)
+	userID, _ := claims["user_id"].(string)
+	if userID == "" {
+		return dataScope{}, errNoDataScope
+	}
+
+	if sid, err := st.GetStudentIDByUserID(ctx, userID); err == nil && sid != "" {
+		return dataScope{StudentID: sid}, nil
+	}
+	if lid, err := st.GetLecturerIDByUserID(ctx, userID); err == nil && lid != "" {
+		return dataScope{LecturerID: lid}, nil
+	}
+	return dataScope{}, errNoDataScope
+}
+
+// studentIDs: mahasiswa dalam cakupan (nil = semua)
+func (d dataScope) studentIDs(ctx context.Context, st repository.IStudentRepository) ([]string, error) {
+	switch {
+	case d.All:
+		return nil, nil
+	case d.StudentID != "":
+		return []string{d.StudentID}, nil
+	case d.LecturerID != "":
+		ids, err := st.GetStudentsByAdvisor(ctx, d.LecturerID)
+		if err != nil {
+			return nil, fiber.NewError(500, err.Error())
+		}
+		return ids, nil
+	}
+	return nil, errNoDataScope
+}
+
+// canSeeStudent: mahasiswa ini milik pemanggil / bimbingannya
+func (d dataScope) canSeeStudent(ctx context.Context, st repository.IStudentRepository, studentID string) bool {
+	switch {
+	case d.All:
+		return true
+	case d.StudentID != "":
+		return d.StudentID == studentID
+	case d.LecturerID != "":
+		ok, err := st.IsStudentUnderAdvisor(ctx, studentID, d.LecturerID)
+		return err == nil && ok
+	}
+	return false
+}
+
+// canSeeAchievement: seperti canSeeStudent, dosen juga melihat prestasi yang
+// review-nya dialihkan kepadanya
+func (d dataScope) canSeeAchievement(ctx context.Context, st repository.IStudentRepository, ref model.AchievementReference) bool {
+	if d.LecturerID != "" {
+		return canReview(ctx, st, ref, d.LecturerID)
+	}
+	return d.canSeeStudent(ctx, st, ref.StudentID)
+}
+
+// cacheKey: kunci cache laporan; tiap cakupan punya kunci sendiri
+func (d dataScope) cacheKey() string {
+	switch {
+	case d.All:
+		return "all"
+	case d.StudentID != "":
+		return "student:" + d.StudentID
+	case d.LecturerID != "":
+		return "advisor:" + d.LecturerID
+	}
+	return ""
+}
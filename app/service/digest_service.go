@@ -0,0 +1,466 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+	"backenduas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const jobDailyDigest = "daily_digest"
+
+// =====================================================
+//  DELIVERY — tujuan pengiriman digest & eskalasi
+// =====================================================
+type DigestDelivery interface {
+	DeliverDigest(ctx context.Context, d model.LecturerDigest) error
+	DeliverEscalation(ctx context.Context, r model.EscalationReport) error
+}
+
+// FileDigestSink menulis digest sebagai file teks ke Dir (Dir kosong = hanya log)
+type FileDigestSink struct {
+	Dir string
+}
+
+func NewFileDigestSink(dir string) *FileDigestSink {
+	return &FileDigestSink{Dir: dir}
+}
+
+func (f *FileDigestSink) DeliverDigest(ctx context.Context, d model.LecturerDigest) error {
+	msg, err := renderList(digestTemplates, model.LangID, digestView(d, d.Name, ""))
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("digest-%s-%s.txt", d.GeneratedAt.Format("2006-01-02"), d.LecturerID)
+	return f.write(name, msg)
+}
+
+func (f *FileDigestSink) DeliverEscalation(ctx context.Context, r model.EscalationReport) error {
+	msg, err := renderList(escalationTemplates, model.LangID, escalationView(r, "Admin"))
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("escalation-%s.txt", r.GeneratedAt.Format("2006-01-02"))
+	return f.write(name, msg)
+}
+
+func (f *FileDigestSink) write(name string, msg model.EmailMessage) error {
+	content := msg.Subject + "\n\n" + msg.Text
+	if f.Dir == "" {
+		log.Printf("📋 %s\n%s", name, content)
+		return nil
+	}
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(f.Dir, name), []byte(content), 0o644)
+}
+
+// EmailDigestDelivery mengirim lewat antrean email (mengikuti preferensi & bahasa user)
+type EmailDigestDelivery struct {
+	mail  *EmailNotifier
+	users repository.IUserRepository
+	perms middleware.PermissionResolver
+}
+
+func NewEmailDigestDelivery(mail *EmailNotifier, users repository.IUserRepository, perms middleware.PermissionResolver) *EmailDigestDelivery {
+	return &EmailDigestDelivery{mail: mail, users: users, perms: perms}
+}
+
+func (e *EmailDigestDelivery) DeliverDigest(ctx context.Context, d model.LecturerDigest) error {
+	link := e.mail.baseURL + "/achievements?status=" + StatusSubmitted
+	e.mail.enqueue(ctx, d.UserID, model.NotifPendingDigest, func(lang, recipientName string) (model.EmailMessage, error) {
+		return renderList(digestTemplates, lang, digestView(d, recipientName, link))
+	})
+	return nil
+}
+
+// DeliverEscalation → semua user aktif dengan permission system:manage
+func (e *EmailDigestDelivery) DeliverEscalation(ctx context.Context, r model.EscalationReport) error {
+	users, err := e.users.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, u := range usersWithPermission(ctx, users, e.perms, "system:manage") {
+		e.mail.enqueue(ctx, u.ID, model.NotifPendingEscalation, func(lang, recipientName string) (model.EmailMessage, error) {
+			return renderList(escalationTemplates, lang, escalationView(r, recipientName))
+		})
+	}
+	return nil
+}
+
+func digestView(d model.LecturerDigest, recipientName, link string) map[string]interface{} {
+	return map[string]interface{}{
+		"RecipientName": recipientName,
+		"Count":         len(d.Items),
+		"Items":         d.Items,
+		"Link":          link,
+	}
+}
+
+func escalationView(r model.EscalationReport, recipientName string) map[string]interface{} {
+	return map[string]interface{}{
+		"RecipientName": recipientName,
+		"Count":         len(r.Items),
+		"Items":         r.Items,
+		"Threshold":     r.ThresholdDays,
+	}
+}
+
+// =====================================================
+//  DIGEST SERVICE
+// =====================================================
+//
+// Sekali sehari (jam DIGEST_TIME) setiap dosen wali menerima daftar prestasi
+// bimbingan yang masih submitted; Admin menerima eskalasi untuk prestasi yang
+// menunggu lebih dari EscalationDays hari.
+type DigestService struct {
+	lecturerRepo repository.ILecturerRepository
+	studentRepo  repository.IStudentRepository
+	pgRepo       repository.IAchievementPGRepository
+	mongoRepo    repository.IAchievementMongoRepository
+	jobRuns      repository.IJobRunRepository
+	delivery     DigestDelivery
+
+	EscalationDays int
+	now            func() time.Time
+}
+
+func NewDigestService(
+	lecturerRepo repository.ILecturerRepository,
+	st repository.IStudentRepository,
+	pg repository.IAchievementPGRepository,
+	mg repository.IAchievementMongoRepository,
+	jobRuns repository.IJobRunRepository,
+	delivery DigestDelivery,
+	escalationDays int,
+) *DigestService {
+	return &DigestService{
+		lecturerRepo:   lecturerRepo,
+		studentRepo:    st,
+		pgRepo:         pg,
+		mongoRepo:      mg,
+		jobRuns:        jobRuns,
+		delivery:       delivery,
+		EscalationDays: escalationDays,
+		now:            time.Now,
+	}
+}
+
+// pending = prestasi submitted milik studentIDs, terlama dulu
+func (s *DigestService) pending(ctx context.Context, studentIDs []string) ([]model.EscalationItem, error) {
+	out := []model.EscalationItem{}
+	if len(studentIDs) == 0 {
+		return out, nil
+	}
+
+	refs, err := s.pgRepo.GetByStudentIDs(ctx, studentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	submitted := []model.AchievementReference{}
+	mongoIDs := []primitive.ObjectID{}
+	for _, ref := range refs {
+		if ref.Status != StatusSubmitted {
+			continue
+		}
+		submitted = append(submitted, ref)
+		if oid, err := primitive.ObjectIDFromHex(ref.MongoAchievementID); err == nil {
+			mongoIDs = append(mongoIDs, oid)
+		}
+	}
+	if len(submitted) == 0 {
+		return out, nil
+	}
+
+	students, err := s.studentRepo.GetStudentsByIDs(ctx, studentIDs)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := s.mongoRepo.FindManyByIDs(ctx, mongoIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	for _, ref := range submitted {
+		submittedAt := ref.UpdatedAt
+		if ref.SubmittedAt != nil {
+			submittedAt = *ref.SubmittedAt
+		}
+
+		st := students[ref.StudentID]
+		item := model.EscalationItem{
+			PendingItem: model.PendingItem{
+				AchievementID: ref.ID,
+				StudentID:     ref.StudentID,
+				StudentNIM:    st.StudentID,
+				StudentName:   st.FullName,
+				Title:         docs[ref.MongoAchievementID].Title,
+				SubmittedAt:   submittedAt,
+				AgeDays:       int(now.Sub(submittedAt).Hours() / 24),
+			},
+		}
+		if st.AdvisorID != nil {
+			item.AdvisorID = *st.AdvisorID
+		}
+		if st.AdvisorName != nil {
+			item.AdvisorName = *st.AdvisorName
+		}
+		if ref.ReviewerID != nil {
+			item.ReviewerID = *ref.ReviewerID
+		}
+		out = append(out, item)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].SubmittedAt.Before(out[j].SubmittedAt)
+	})
+	return out, nil
+}
+
+// BuildDigests = digest per dosen yang memiliki prestasi menunggu. Prestasi
+// yang sudah dialihkan SLA masuk ke digest dosen pengganti, bukan dosen wali.
+func (s *DigestService) BuildDigests(ctx context.Context) ([]model.LecturerDigest, error) {
+	lecturers, err := s.lecturerRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(lecturers, func(i, j int) bool { return lecturers[i].LecturerID < lecturers[j].LecturerID })
+
+	studentIDs, err := s.studentRepo.GetAllStudentIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.pending(ctx, studentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byReviewer := map[string][]model.PendingItem{}
+	for _, it := range items {
+		if reviewer := effectiveReviewer(it); reviewer != "" {
+			byReviewer[reviewer] = append(byReviewer[reviewer], it.PendingItem)
+		}
+	}
+
+	digests := []model.LecturerDigest{}
+	for _, l := range lecturers {
+		pending := byReviewer[l.ID]
+		if len(pending) == 0 {
+			continue
+		}
+		digests = append(digests, model.LecturerDigest{
+			LecturerID:  l.ID,
+			UserID:      l.UserID,
+			Name:        l.FullName,
+			Email:       l.Email,
+			GeneratedAt: s.now(),
+			Items:       pending,
+		})
+	}
+
+	return digests, nil
+}
+
+// effectiveReviewer = COALESCE(assigned_reviewer_id, advisor_id)
+func effectiveReviewer(it model.EscalationItem) string {
+	if it.ReviewerID != "" {
+		return it.ReviewerID
+	}
+	return it.AdvisorID
+}
+
+// BuildEscalation = semua prestasi submitted lebih dari days hari
+// (termasuk milik mahasiswa yang belum punya dosen wali)
+func (s *DigestService) BuildEscalation(ctx context.Context, days int) (model.EscalationReport, error) {
+	report := model.EscalationReport{
+		GeneratedAt:   s.now(),
+		ThresholdDays: days,
+		Items:         []model.EscalationItem{},
+	}
+
+	studentIDs, err := s.studentRepo.GetAllStudentIDs(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	items, err := s.pending(ctx, studentIDs)
+	if err != nil {
+		return report, err
+	}
+
+	threshold := time.Duration(days) * 24 * time.Hour
+	for _, it := range items {
+		if report.GeneratedAt.Sub(it.SubmittedAt) > threshold {
+			report.Items = append(report.Items, it)
+		}
+	}
+	return report, nil
+}
+
+// RunDaily membangun dan mengirim semua digest + eskalasi
+func (s *DigestService) RunDaily(ctx context.Context) (model.DigestRunSummary, error) {
+	summary := model.DigestRunSummary{RunAt: s.now(), Errors: []string{}}
+
+	digests, err := s.BuildDigests(ctx)
+	if err != nil {
+		return summary, err
+	}
+	summary.Lecturers = len(digests)
+
+	for _, d := range digests {
+		if err := s.delivery.DeliverDigest(ctx, d); err != nil {
+			summary.Errors = append(summary.Errors, fmt.Sprintf("digest %s: %v", d.LecturerID, err))
+			continue
+		}
+		summary.DigestsDelivered++
+	}
+
+	report, err := s.BuildEscalation(ctx, s.EscalationDays)
+	if err != nil {
+		return summary, err
+	}
+	summary.Escalations = len(report.Items)
+	if len(report.Items) > 0 {
+		if err := s.delivery.DeliverEscalation(ctx, report); err != nil {
+			summary.Errors = append(summary.Errors, fmt.Sprintf("escalation: %v", err))
+		}
+	}
+
+	return summary, nil
+}
+
+// parseClock: "HH:MM" → jam, menit
+func parseClock(at string) (int, int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(at))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time %q, expected HH:MM", at)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// nextDailyRun = jam:menit berikutnya setelah now (zona waktu now)
+func nextDailyRun(now time.Time, hour, minute int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// RunScheduler = background worker: RunDaily setiap hari pada jam `at` di zona loc.
+// Setiap tanggal hanya dijalankan oleh satu instance (scheduled_job_runs).
+func (s *DigestService) RunScheduler(ctx context.Context, at string, loc *time.Location) {
+	hour, minute, err := parseClock(at)
+	if err != nil {
+		log.Printf("❌ digest scheduler tidak dijalankan: %v", err)
+		return
+	}
+
+	for {
+		next := nextDailyRun(s.now().In(loc), hour, minute)
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if s.jobRuns != nil {
+			claimed, err := s.jobRuns.Claim(ctx, jobDailyDigest, next.Format("2006-01-02"))
+			if err != nil {
+				log.Printf("❌ digest scheduler: %v", err)
+				continue
+			}
+			if !claimed {
+				continue // sudah dijalankan instance lain
+			}
+		}
+
+		summary, err := s.RunDaily(ctx)
+		if err != nil {
+			log.Printf("❌ digest harian: %v", err)
+			continue
+		}
+		log.Printf("📬 digest harian: %d dosen wali, %d eskalasi, %d error",
+			summary.DigestsDelivered, summary.Escalations, len(summary.Errors))
+	}
+}
+
+// =====================================================
+//
+//	ADMIN ENDPOINT
+//
+// =====================================================
+// Preview godoc
+// @Summary Preview daily digests
+// @Description Digest per dosen wali (prestasi bimbingan yang masih submitted) tanpa mengirim (Admin only)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} model.LecturerDigest
+// @Failure 500 {object} map[string]string
+// @Router /admin/digests [get]
+func (s *DigestService) Preview(c *fiber.Ctx) error {
+	digests, err := s.BuildDigests(context.Background())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(digests)
+}
+
+// Run godoc
+// @Summary Send daily digests now
+// @Description Membangun dan mengirim digest + eskalasi sekarang juga, di luar jadwal (Admin only)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} model.DigestRunSummary
+// @Failure 500 {object} map[string]string
+// @Router /admin/digests/run [post]
+func (s *DigestService) Run(c *fiber.Ctx) error {
+	summary, err := s.RunDaily(context.Background())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(summary)
+}
+
+// Escalations godoc
+// @Summary Escalation list
+// @Description Prestasi berstatus submitted lebih dari N hari beserta dosen walinya (Admin only)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param days query int false "Threshold in days (default ESCALATION_DAYS)"
+// @Success 200 {object} model.EscalationReport
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/escalations [get]
+func (s *DigestService) Escalations(c *fiber.Ctx) error {
+	days := c.QueryInt("days", s.EscalationDays)
+	if days < 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "days must be >= 0"})
+	}
+
+	report, err := s.BuildEscalation(context.Background(), days)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(report)
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type recordingDelivery struct {
+	digests     []model.LecturerDigest
+	escalations []model.EscalationReport
+}
+
+func (r *recordingDelivery) DeliverDigest(ctx context.Context, d model.LecturerDigest) error {
+	r.digests = append(r.digests, d)
+	return nil
+}
+
+func (r *recordingDelivery) DeliverEscalation(ctx context.Context, rep model.EscalationReport) error {
+	r.escalations = append(r.escalations, rep)
+	return nil
+}
+
+var digestNow = time.Date(2026, 3, 10, 7, 0, 0, 0, time.UTC)
+
+// seedDigestQueue mengisi dua dosen, mahasiswa s1 (bimbingan lect1) dan s2
+// (tanpa dosen wali), serta prestasi dengan umur pengajuan yang berbeda.
+func seedDigestQueue(lr *repository.MockLecturerRepository, st *repository.MockStudentRepository, pg *repository.MockAchievementPGRepository, mg *repository.MockAchievementMongoRepository) {
+	lr.Lecturers["lect1"] = model.LecturerDetail{ID: "lect1", UserID: "uLect", FullName: "Dr. Budi", LecturerID: "L001"}
+	lr.Lecturers["lect2"] = model.LecturerDetail{ID: "lect2", UserID: "uLect2", FullName: "Dr. Sari", LecturerID: "L002"}
+
+	lect1, budi := "lect1", "Dr. Budi"
+	st.Students["s1"] = model.StudentDetail{ID: "s1", StudentID: "2021001", FullName: "Andi", AdvisorID: &lect1, AdvisorName: &budi}
+	st.Students["s2"] = model.StudentDetail{ID: "s2", StudentID: "2021002", FullName: "Citra"} // tanpa dosen wali
+	st.AdvisorMap["s1"] = "lect1"
+
+	seed := func(id, studentID, status, title string, ageDays int) {
+		oid := primitive.NewObjectID()
+		mg.Data[oid.Hex()] = model.AchievementMongo{ID: oid, StudentID: studentID, Title: title, Status: status}
+		submitted := digestNow.Add(-time.Duration(ageDays) * 24 * time.Hour)
+		pg.Data[id] = model.AchievementReference{
+			ID: id, StudentID: studentID, MongoAchievementID: oid.Hex(), Status: status, SubmittedAt: &submitted,
+		}
+	}
+	seed("a1", "s1", StatusSubmitted, "Juara 1 Hackathon", 2)
+	seed("a2", "s1", StatusSubmitted, "Paper SINTA 2", 9)
+	seed("a3", "s1", StatusVerified, "Sertifikat AWS", 20)
+	seed("a4", "s2", StatusSubmitted, "Magang Startup", 12)
+}
+
+func TestBuildDigests_PendingAdviseeItemsOldestFirst(t *testing.T) {
+	lr := repository.NewMockLecturerRepository()
+	st := repository.NewMockStudentRepository()
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	seedDigestQueue(lr, st, pg, mg)
+
+	delivery := &recordingDelivery{}
+	svc := NewDigestService(lr, st, pg, mg, repository.NewMockJobRunRepository(), delivery, 7)
+	svc.now = func() time.Time { return digestNow }
+
+	digests, err := svc.BuildDigests(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(digests) != 1 || digests[0].LecturerID != "lect1" {
+		t.Fatalf("expected a single digest for lect1, got %+v", digests)
+	}
+	items := digests[0].Items
+	if len(items) != 2 || items[0].AchievementID != "a2" || items[1].AchievementID != "a1" {
+		t.Fatalf("expected submitted items oldest first: %+v", items)
+	}
+	if items[0].AgeDays != 9 || items[0].Title != "Paper SINTA 2" || items[0].StudentNIM != "2021001" {
+		t.Errorf("unexpected item: %+v", items[0])
+	}
+}
+
+func TestBuildDigests_ReassignedItemsGoToBackupReviewer(t *testing.T) {
+	lr := repository.NewMockLecturerRepository()
+	st := repository.NewMockStudentRepository()
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	seedDigestQueue(lr, st, pg, mg)
+
+	backup := "lect2"
+	ref := pg.Data["a2"]
+	ref.ReviewerID = &backup
+	pg.Data["a2"] = ref
+
+	svc := NewDigestService(lr, st, pg, mg, repository.NewMockJobRunRepository(), &recordingDelivery{}, 7)
+	svc.now = func() time.Time { return digestNow }
+
+	digests, err := svc.BuildDigests(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(digests) != 2 {
+		t.Fatalf("expected digests for advisor and backup, got %+v", digests)
+	}
+	if d := digests[0]; d.LecturerID != "lect1" || len(d.Items) != 1 || d.Items[0].AchievementID != "a1" {
+		t.Errorf("advisor must no longer receive the reassigned item: %+v", d)
+	}
+	if d := digests[1]; d.LecturerID != "lect2" || len(d.Items) != 1 || d.Items[0].AchievementID != "a2" {
+		t.Errorf("backup reviewer must receive the reassigned item: %+v", d)
+	}
+}
+
+func TestBuildEscalation_IncludesUnassignedStudents(t *testing.T) {
+	lr := repository.NewMockLecturerRepository()
+	st := repository.NewMockStudentRepository()
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	seedDigestQueue(lr, st, pg, mg)
+
+	delivery := &recordingDelivery{}
+	svc := NewDigestService(lr, st, pg, mg, repository.NewMockJobRunRepository(), delivery, 7)
+	svc.now = func() time.Time { return digestNow }
+
+	report, err := svc.BuildEscalation(context.Background(), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Items) != 2 || report.Items[0].AchievementID != "a4" || report.Items[1].AchievementID != "a2" {
+		t.Fatalf("unexpected escalation items: %+v", report.Items)
+	}
+	if report.Items[0].AdvisorID != "" || report.Items[1].AdvisorName != "Dr. Budi" {
+		t.Errorf("unexpected advisor info: %+v", report.Items)
+	}
+}
+
+func TestRunDaily_DeliversDigestsAndEscalation(t *testing.T) {
+	lr := repository.NewMockLecturerRepository()
+	st := repository.NewMockStudentRepository()
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	seedDigestQueue(lr, st, pg, mg)
+
+	delivery := &recordingDelivery{}
+	svc := NewDigestService(lr, st, pg, mg, repository.NewMockJobRunRepository(), delivery, 7)
+	svc.now = func() time.Time { return digestNow }
+
+	summary, err := svc.RunDaily(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.DigestsDelivered != 1 || summary.Escalations != 2 || len(summary.Errors) != 0 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	if len(delivery.digests) != 1 || len(delivery.escalations) != 1 {
+		t.Errorf("expected one digest and one escalation delivered")
+	}
+}
+
+func TestFileDigestSink_WritesText(t *testing.T) {
+	lr := repository.NewMockLecturerRepository()
+	st := repository.NewMockStudentRepository()
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	seedDigestQueue(lr, st, pg, mg)
+
+	delivery := &recordingDelivery{}
+	svc := NewDigestService(lr, st, pg, mg, repository.NewMockJobRunRepository(), delivery, 7)
+	svc.now = func() time.Time { return digestNow }
+	dir := t.TempDir()
+	svc.delivery = NewFileDigestSink(dir)
+
+	if _, err := svc.RunDaily(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(dir + "/digest-2026-03-10-lect1.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(raw)
+	if !strings.Contains(text, "Ada 2 prestasi") || !strings.Contains(text, "Andi (2021001): Paper SINTA 2 — 9 hari") {
+		t.Errorf("unexpected digest:\n%s", text)
+	}
+
+	raw, _ = os.ReadFile(dir + "/escalation-2026-03-10.txt")
+	if !strings.Contains(string(raw), "dosen wali: belum ada") {
+		t.Errorf("unexpected escalation:\n%s", raw)
+	}
+}
+
+func TestEmailDigestDelivery_QueuesForLecturerAndAdmins(t *testing.T) {
+	lr := repository.NewMockLecturerRepository()
+	st := repository.NewMockStudentRepository()
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	seedDigestQueue(lr, st, pg, mg)
+
+	delivery := &recordingDelivery{}
+	svc := NewDigestService(lr, st, pg, mg, repository.NewMockJobRunRepository(), delivery, 7)
+	svc.now = func() time.Time { return digestNow }
+
+	users := repository.NewMockUserRepository()
+	outbox := repository.NewMockEmailOutboxRepository()
+	mail := NewEmailNotifier(users, repository.NewMockNotificationPreferenceRepository(),
+		NewMailQueue(outbox, &fakeMailer{}), "https://prestasi.kampus.ac.id/")
+
+	users.Data["uAdmin"] = model.User{ID: "uAdmin", FullName: "Admin", Email: "admin@kampus.ac.id", RoleID: "rAdmin", RoleName: "Admin", IsActive: true}
+	users.Data["uLect"] = model.User{ID: "uLect", FullName: "Dr. Budi", Email: "budi@kampus.ac.id", RoleName: "Dosen Wali", IsActive: true}
+	svc.delivery = NewEmailDigestDelivery(mail, users, slaPermissions)
+
+	if _, err := svc.RunDaily(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(outbox.Data) != 2 {
+		t.Fatalf("expected digest + escalation emails, got %d", len(outbox.Data))
+	}
+	if outbox.Data[0].ToAddress != "budi@kampus.ac.id" || outbox.Data[0].Template != model.NotifPendingDigest {
+		t.Errorf("unexpected digest email: %+v", outbox.Data[0])
+	}
+	if !strings.Contains(outbox.Data[0].TextBody, "/achievements?status=submitted") {
+		t.Errorf("digest must link to the pending list:\n%s", outbox.Data[0].TextBody)
+	}
+	if outbox.Data[1].ToAddress != "admin@kampus.ac.id" || !strings.HasPrefix(outbox.Data[1].Subject, "Eskalasi: 2 prestasi") {
+		t.Errorf("unexpected escalation email: %+v", outbox.Data[1])
+	}
+}
+
+func TestNextDailyRun(t *testing.T) {
+	loc := time.FixedZone("WIB", 7*3600)
+
+	before := time.Date(2026, 3, 10, 6, 59, 0, 0, loc)
+	if got := nextDailyRun(before, 7, 0); !got.Equal(time.Date(2026, 3, 10, 7, 0, 0, 0, loc)) {
+		t.Errorf("expected today 07:00, got %s", got)
+	}
+
+	at := time.Date(2026, 3, 10, 7, 0, 0, 0, loc)
+	if got := nextDailyRun(at, 7, 0); !got.Equal(time.Date(2026, 3, 11, 7, 0, 0, 0, loc)) {
+		t.Errorf("expected tomorrow 07:00, got %s", got)
+	}
+
+	if _, _, err := parseClock("25:00"); err == nil {
+		t.Error("expected invalid clock error")
+	}
+}
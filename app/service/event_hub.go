@@ -0,0 +1,170 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"backenduas/app/model"
+)
+
+const (
+	hubHistorySize = 100 // event terakhir per user yang disimpan untuk replay
+	hubBufferSize  = 32  // antrean per koneksi sebelum dianggap lambat
+)
+
+// =====================================================
+//  EVENT HUB — pub/sub real-time per user
+// =====================================================
+//
+// MemoryHub cukup untuk satu instance. Untuk beberapa instance di belakang
+// load balancer, ganti dengan implementasi di atas broker eksternal
+// (Redis Streams, NATS, ...) yang memenuhi interface yang sama.
+type EventHub interface {
+	// Publish mengirim event ke semua koneksi milik userID dan menyimpannya untuk replay
+	Publish(userID, eventType string, data interface{}) model.StreamEvent
+
+	// Subscribe membuka langganan. lastEventID (header Last-Event-ID) menentukan
+	// event yang dikirim ulang sebelum event live.
+	Subscribe(userID, lastEventID string) *Subscription
+}
+
+type Subscription struct {
+	Replay []model.StreamEvent
+	Events <-chan model.StreamEvent // ditutup saat Close atau saat koneksi terlalu lambat
+	Close  func()
+}
+
+type hubSubscriber struct {
+	ch     chan model.StreamEvent
+	closed bool
+}
+
+// MemoryHub: ID event = "<epoch>-<seq>". Epoch berubah setiap restart sehingga
+// Last-Event-ID dari proses sebelumnya dikenali dan dijawab dengan event resync.
+type MemoryHub struct {
+	mu      sync.Mutex
+	epoch   string
+	seq     uint64
+	history map[string][]model.StreamEvent // userID → event terakhir (urut seq)
+	trimmed map[string]uint64              // userID → seq terbesar yang sudah dibuang dari history
+	subs    map[string]map[*hubSubscriber]struct{}
+}
+
+func NewMemoryHub() *MemoryHub {
+	return &MemoryHub{
+		epoch:   strconv.FormatInt(time.Now().UnixNano(), 36),
+		history: map[string][]model.StreamEvent{},
+		trimmed: map[string]uint64{},
+		subs:    map[string]map[*hubSubscriber]struct{}{},
+	}
+}
+
+func (h *MemoryHub) eventID(seq uint64) string {
+	return fmt.Sprintf("%s-%d", h.epoch, seq)
+}
+
+// parseEventID mengembalikan seq jika ID berasal dari epoch yang sama
+func (h *MemoryHub) parseEventID(id string) (uint64, bool) {
+	epoch, seq, ok := strings.Cut(id, "-")
+	if !ok || epoch != h.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	return n, err == nil
+}
+
+func (h *MemoryHub) Publish(userID, eventType string, data interface{}) model.StreamEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	evt := model.StreamEvent{
+		ID:        h.eventID(h.seq),
+		Type:      eventType,
+		Data:      data,
+		CreatedAt: time.Now(),
+	}
+
+	hist := append(h.history[userID], evt)
+	if len(hist) > hubHistorySize {
+		dropped := hist[len(hist)-hubHistorySize-1]
+		h.trimmed[userID], _ = h.parseEventID(dropped.ID)
+		hist = append([]model.StreamEvent(nil), hist[len(hist)-hubHistorySize:]...)
+	}
+	h.history[userID] = hist
+
+	for sub := range h.subs[userID] {
+		select {
+		case sub.ch <- evt:
+		default:
+			// koneksi lambat → putus; client reconnect dengan Last-Event-ID
+			h.unsubscribe(userID, sub)
+		}
+	}
+
+	return evt
+}
+
+func (h *MemoryHub) Subscribe(userID, lastEventID string) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	replay := []model.StreamEvent{}
+	if lastEventID != "" {
+		last, ok := h.parseEventID(lastEventID)
+		if !ok || last < h.trimmed[userID] || last > h.seq {
+			replay = append(replay, model.StreamEvent{
+				ID:        h.eventID(h.seq),
+				Type:      model.EventResync,
+				Data:      map[string]string{"reason": "event history is no longer available"},
+				CreatedAt: time.Now(),
+			})
+			last = h.trimmed[userID]
+		}
+		for _, evt := range h.history[userID] {
+			if seq, _ := h.parseEventID(evt.ID); seq > last {
+				replay = append(replay, evt)
+			}
+		}
+	}
+
+	sub := &hubSubscriber{ch: make(chan model.StreamEvent, hubBufferSize)}
+	if h.subs[userID] == nil {
+		h.subs[userID] = map[*hubSubscriber]struct{}{}
+	}
+	h.subs[userID][sub] = struct{}{}
+
+	return &Subscription{
+		Replay: replay,
+		Events: sub.ch,
+		Close: func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			h.unsubscribe(userID, sub)
+		},
+	}
+}
+
+// unsubscribe dipanggil dengan h.mu terkunci
+func (h *MemoryHub) unsubscribe(userID string, sub *hubSubscriber) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.ch)
+
+	delete(h.subs[userID], sub)
+	if len(h.subs[userID]) == 0 {
+		delete(h.subs, userID)
+	}
+}
+
+// Subscribers = jumlah koneksi aktif milik user
+func (h *MemoryHub) Subscribers(userID string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs[userID])
+}
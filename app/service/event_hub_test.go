@@ -0,0 +1,178 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestMemoryHub_PublishToSubscriber(t *testing.T) {
+	hub := NewMemoryHub()
+	sub := hub.Subscribe("u1", "")
+	defer sub.Close()
+
+	hub.Publish("u2", "other", nil)
+	evt := hub.Publish("u1", model.EventNotification, "hello")
+
+	got := <-sub.Events
+	if got.ID != evt.ID || got.Data != "hello" {
+		t.Fatalf("unexpected event: %+v", got)
+	}
+	select {
+	case extra := <-sub.Events:
+		t.Fatalf("events of other users must not be delivered: %+v", extra)
+	default:
+	}
+}
+
+func TestMemoryHub_ReplayAfterLastEventID(t *testing.T) {
+	hub := NewMemoryHub()
+	first := hub.Publish("u1", "a", 1)
+	hub.Publish("u1", "b", 2)
+	hub.Publish("u2", "x", 0)
+	hub.Publish("u1", "c", 3)
+
+	sub := hub.Subscribe("u1", first.ID)
+	defer sub.Close()
+
+	if len(sub.Replay) != 2 || sub.Replay[0].Type != "b" || sub.Replay[1].Type != "c" {
+		t.Fatalf("unexpected replay: %+v", sub.Replay)
+	}
+}
+
+func TestMemoryHub_ResyncWhenHistoryIsGone(t *testing.T) {
+	hub := NewMemoryHub()
+	first := hub.Publish("u1", "a", 0)
+	for i := 0; i < hubHistorySize+5; i++ {
+		hub.Publish("u1", "b", i)
+	}
+
+	sub := hub.Subscribe("u1", first.ID)
+	defer sub.Close()
+
+	if sub.Replay[0].Type != model.EventResync {
+		t.Fatalf("expected resync first, got %+v", sub.Replay[0])
+	}
+	if len(sub.Replay) != hubHistorySize+1 {
+		t.Errorf("expected full history after resync, got %d events", len(sub.Replay))
+	}
+
+	// ID dari proses sebelumnya (epoch lain)
+	stale := hub.Subscribe("u1", "oldepoch-3")
+	defer stale.Close()
+	if stale.Replay[0].Type != model.EventResync {
+		t.Errorf("expected resync for unknown epoch, got %+v", stale.Replay[0])
+	}
+}
+
+func TestMemoryHub_SlowSubscriberIsDropped(t *testing.T) {
+	hub := NewMemoryHub()
+	sub := hub.Subscribe("u1", "")
+
+	for i := 0; i <= hubBufferSize; i++ {
+		hub.Publish("u1", "tick", i)
+	}
+
+	if hub.Subscribers("u1") != 0 {
+		t.Fatal("slow subscriber must be removed")
+	}
+	n := 0
+	for range sub.Events {
+		n++
+	}
+	if n != hubBufferSize {
+		t.Errorf("expected %d buffered events before close, got %d", hubBufferSize, n)
+	}
+	sub.Close() // tetap aman setelah ditutup hub
+}
+
+func TestNotifier_PublishesToHub(t *testing.T) {
+	st := repository.NewMockStudentRepository()
+	seedNotifierUsers(st)
+
+	hub := NewMemoryHub()
+	n := NewNotifier(repository.NewMockNotificationRepository(), st, hub, nil)
+
+	student := hub.Subscribe("uStudent", "")
+	advisor := hub.Subscribe("uLect", "")
+	defer student.Close()
+	defer advisor.Close()
+
+	ref := model.AchievementReference{ID: "a1", StudentID: "s1"}
+	n.AchievementSubmitted(context.Background(), ref)
+	points := 10
+	n.AchievementVerified(context.Background(), ref, &points)
+
+	if got := (<-advisor.Events).Type; got != model.NotifAchievementSubmitted {
+		t.Errorf("advisor: expected submitted event, got %s", got)
+	}
+	if got := (<-advisor.Events).Type; got != model.EventNotification {
+		t.Errorf("advisor: expected notification event, got %s", got)
+	}
+
+	evt := <-student.Events
+	payload, ok := evt.Data.(model.AchievementEvent)
+	if evt.Type != model.NotifAchievementVerified || !ok || payload.Points == nil || *payload.Points != 10 {
+		t.Errorf("student: unexpected event %+v", evt)
+	}
+}
+
+func TestWriteSSE(t *testing.T) {
+	var buf bytes.Buffer
+	evt := model.StreamEvent{ID: "e-7", Type: model.EventNotification, Data: map[string]string{"title": "Hai\nDunia"}}
+
+	if err := writeSSE(&buf, evt); err != nil {
+		t.Fatal(err)
+	}
+
+	want := fmt.Sprintf("id: e-7\nevent: notification\ndata: %s\n\n", `{"title":"Hai\nDunia"}`)
+	if buf.String() != want {
+		t.Errorf("unexpected frame:\n%q\nwant\n%q", buf.String(), want)
+	}
+}
+
+func TestStreamPump_ClosesOnExpiryAndRevocation(t *testing.T) {
+	hub := NewMemoryHub()
+	list := NewTokenDenylist(repository.NewMockTokenDenylistRepository(), repository.NewMockAuthRepository(), time.Minute)
+	svc := NewNotificationService(nil, nil, hub, list)
+
+	run := func(claims jwt.MapClaims, heartbeat time.Duration) string {
+		var buf bytes.Buffer
+		sub := hub.Subscribe("u1", "")
+		defer sub.Close()
+
+		done := make(chan struct{})
+		go func() {
+			svc.pump(bufio.NewWriter(&buf), sub, claims, heartbeat)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("stream must be closed")
+		}
+		return buf.String()
+	}
+
+	expiring := issuedClaims("u1", "s1", time.Now().Add(-time.Minute))
+	expiring["exp"] = float64(time.Now().Add(50*time.Millisecond).UnixMilli()) / 1000
+	if out := run(expiring, time.Hour); !strings.HasSuffix(out, "event: token_expired\ndata: {}\n\n") {
+		t.Errorf("expired token must end the stream, got %q", out)
+	}
+
+	revoked := issuedClaims("u1", "s2", time.Now().Add(-time.Minute))
+	revoked["exp"] = float64(time.Now().Add(time.Hour).Unix())
+	list.RevokeToken(context.Background(), revoked, model.RevokedLogout)
+	if out := run(revoked, 10*time.Millisecond); !strings.Contains(out, "event: token_expired") || strings.Contains(out, ": ping") {
+		t.Errorf("revoked token must end the stream at the next heartbeat, got %q", out)
+	}
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+)
+
+// =====================================================
+//  PROTEKSI BRUTE-FORCE LOGIN
+// =====================================================
+//
+// Setiap percobaan login dipesan lebih dulu di dua penghitung: per akun
+// (username, walau tidak terdaftar, agar tidak membocorkan username yang ada)
+// dan per IP. Pemesanan menambah penghitung dan langsung memasang jeda yang
+// berlaku jika percobaan itu gagal, sebelum bcrypt berjalan; request paralel
+// karena itu tidak bisa lolos bersamaan. Password benar membatalkan pemesanan.
+//
+// Setelah FreeAttempts kali gagal, percobaan berikutnya harus menunggu
+// DelayBase, 2×DelayBase, 4×DelayBase, ... ; setelah MaxAttempts (akun) atau
+// IPMaxAttempts (IP) kali gagal, akun / IP dikunci selama Lockout. Penghitung
+// dimulai ulang jika tidak ada kegagalan selama Window, dan penghitung akun
+// dihapus saat login berhasil atau dibuka Admin.
+
+type LoginPolicy struct {
+	MaxAttempts   int
+	IPMaxAttempts int
+	FreeAttempts  int
+	DelayBase     time.Duration
+	Lockout       time.Duration
+	Window        time.Duration
+}
+
+// backoff = lama blokir setelah `failures` kali gagal
+func (p LoginPolicy) backoff(failures, max int) time.Duration {
+	if failures >= max {
+		return p.Lockout
+	}
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+	shift := failures - p.FreeAttempts - 1
+	if shift > 30 {
+		return p.Lockout
+	}
+	d := p.DelayBase << shift
+	if d <= 0 || d > p.Lockout {
+		return p.Lockout
+	}
+	return d
+}
+
+type LoginGuard struct {
+	repo   repository.ILoginAttemptRepository
+	policy LoginPolicy
+	now    func() time.Time
+}
+
+func NewLoginGuard(repo repository.ILoginAttemptRepository, policy LoginPolicy) *LoginGuard {
+	return &LoginGuard{repo: repo, policy: policy, now: time.Now}
+}
+
+func accountKey(username string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// counters = penghitung akun & IP beserta batas kuncinya
+func (g *LoginGuard) counters(username, ip string) []loginCounter {
+	return []loginCounter{
+		{accountKey(username), g.policy.MaxAttempts},
+		{ipKey(ip), g.policy.IPMaxAttempts},
+	}
+}
+
+type loginCounter struct {
+	key string
+	max int
+}
+
+// Reserve memesan satu percobaan sebelum password diperiksa.
+// blocked > 0: akun / IP sedang diblokir, percobaan ditolak dan tidak dihitung.
+// penalty: jeda yang sudah dipasang dan berlaku jika percobaan ini gagal.
+func (g *LoginGuard) Reserve(ctx context.Context, username, ip string) (blocked, penalty time.Duration, err error) {
+	if g == nil {
+		return 0, 0, nil
+	}
+
+	now := g.now()
+	reserved := []loginCounter{}
+	failures := map[string]int{}
+	for _, c := range g.counters(username, ip) {
+		t, err := g.repo.Reserve(ctx, c.key, g.policy.Window)
+		if err != nil {
+			g.release(ctx, reserved)
+			return 0, 0, err
+		}
+		if t.BlockedUntil != nil && t.BlockedUntil.After(now) {
+			if d := t.BlockedUntil.Sub(now); d > blocked {
+				blocked = d
+			}
+			continue
+		}
+		reserved = append(reserved, c)
+		failures[c.key] = t.Failures
+	}
+	if blocked > 0 {
+		g.release(ctx, reserved)
+		return blocked, 0, nil
+	}
+
+	for _, c := range reserved {
+		d := g.policy.backoff(failures[c.key], c.max)
+		if d == 0 {
+			continue
+		}
+		if err := g.repo.Block(ctx, c.key, now.Add(d)); err != nil {
+			return 0, 0, err
+		}
+		if failures[c.key] == c.max {
+			log.Printf("🚨 %s dikunci %s setelah %d kali gagal login", c.key, d, failures[c.key])
+		}
+		if d > penalty {
+			penalty = d
+		}
+	}
+	return 0, penalty, nil
+}
+
+// release membatalkan pemesanan; jeda dicabut jika sisa gagal masih gratis
+func (g *LoginGuard) release(ctx context.Context, counters []loginCounter) {
+	for _, c := range counters {
+		if err := g.repo.Release(ctx, c.key, g.policy.FreeAttempts); err != nil {
+			log.Printf("⚠️ gagal membatalkan pemesanan login %s: %v", c.key, err)
+		}
+	}
+}
+
+// Fail mencatat login gagal di audit (penghitung sudah ditambah oleh Reserve).
+// Percobaan yang ditolak karena diblokir digabung per username + IP dalam
+// satu Window agar banjir request tidak memenuhi tabel audit.
+func (g *LoginGuard) Fail(ctx context.Context, username, ip, userAgent string, user *model.User, reason string) error {
+	if g == nil {
+		return nil
+	}
+
+	attempt := &model.LoginAttempt{Username: username, IPAddress: ip, UserAgent: userAgent, Reason: reason, Count: 1}
+	if user != nil {
+		attempt.UserID = &user.ID
+	}
+	if reason == model.LoginThrottled {
+		merged, err := g.repo.AddThrottled(ctx, attempt, g.now().Add(-g.policy.Window))
+		if err != nil || merged {
+			return err
+		}
+	}
+	return g.repo.RecordAttempt(ctx, attempt)
+}
+
+// Succeed: password benar. Penghitung akun dihapus, pemesanan IP dibatalkan
+// (login ke akun sendiri tidak boleh menghapus jejak tebakan ke akun lain)
+func (g *LoginGuard) Succeed(ctx context.Context, username, ip string) error {
+	if g == nil {
+		return nil
+	}
+	g.release(ctx, []loginCounter{{ipKey(ip), g.policy.IPMaxAttempts}})
+	return g.repo.ResetThrottle(ctx, accountKey(username))
+}
+
+// Release: password benar tetapi login tetap ditolak (mis. akun nonaktif);
+// percobaan ini tidak dihitung sebagai tebakan
+func (g *LoginGuard) Release(ctx context.Context, username, ip string) {
+	if g == nil {
+		return
+	}
+	g.release(ctx, g.counters(username, ip))
+}
+
+// Unlock membuka kunci akun (Admin)
+func (g *LoginGuard) Unlock(ctx context.Context, username string) error {
+	if g == nil {
+		return nil
+	}
+	return g.repo.ResetThrottle(ctx, accountKey(username))
+}
+
+// Attempts = audit login gagal terbaru
+func (g *LoginGuard) Attempts(ctx context.Context, f model.LoginAttemptFilter) ([]model.LoginAttempt, error) {
+	if g == nil {
+		return []model.LoginAttempt{}, nil
+	}
+	return g.repo.ListAttempts(ctx, f)
+}
+
+// Run = background worker: hapus audit login yang lebih tua dari retention
+// dan penghitung yang sudah tidak aktif, setiap interval
+func (g *LoginGuard) Run(ctx context.Context, every, retention time.Duration) {
+	if g == nil {
+		return
+	}
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		n, err := g.repo.Purge(ctx, g.now().Add(-retention), g.policy.Window)
+		if err != nil {
+			log.Printf("❌ purge audit login: %v", err)
+			continue
+		}
+		if n > 0 {
+			log.Printf("🧹 %d audit login lama dihapus", n)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// loginApp memasang route login dan admin dengan guard yang memakai jam repo.
+func loginApp(auth *repository.MockAuthRepository, attempts *repository.MockLoginAttemptRepository, policy LoginPolicy) *fiber.App {
+	guard := NewLoginGuard(attempts, policy)
+	guard.now = attempts.Now
+
+	users := repository.NewMockUserRepository()
+	users.Data["u1"] = model.User{ID: "u1", Username: "andi", IsActive: true}
+
+	svc := NewAuthService(auth, nil, testKeys, guard)
+	admin := NewUserService(users, nil, guard)
+	app := fiber.New(fiber.Config{ProxyHeader: "X-Forwarded-For"})
+	app.Post("/login", svc.Login)
+	app.Post("/users/:id/unlock", admin.Unlock)
+	app.Get("/users/login-attempts", admin.LoginAttempts)
+	return app
+}
+
+func defaultLoginPolicy() LoginPolicy {
+	return LoginPolicy{MaxAttempts: 5, IPMaxAttempts: 20, FreeAttempts: 2, DelayBase: time.Second, Lockout: 15 * time.Minute, Window: 15 * time.Minute}
+}
+
+// postLogin mengembalikan status dan header Retry-After
+func postLogin(t *testing.T, app *fiber.App, ip, username, password string) (int, string) {
+	t.Helper()
+	body := fmt.Sprintf(`{"username":%q,"password":%q}`, username, password)
+	req := httptest.NewRequest("POST", "/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forwarded-For", ip)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, resp.Header.Get("Retry-After")
+}
+
+func TestLogin_ProgressiveDelayThenLockout(t *testing.T) {
+	auth := repository.NewMockAuthRepository()
+	attempts := repository.NewMockLoginAttemptRepository()
+	auth.SeedUser("u1", "andi", "andi123", "Mahasiswa")
+	clock := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	attempts.Now = func() time.Time { return clock }
+	app := loginApp(auth, attempts, defaultLoginPolicy())
+
+	// dua kali gagal tanpa jeda, lalu 1s, 2s, dan kunci 15 menit
+	for i, want := range []string{"", "", "1", "2", "900"} {
+		code, retry := postLogin(t, app, "10.0.0.1", "andi", "salah")
+		if code != 401 || retry != want {
+			t.Fatalf("attempt %d: got %d Retry-After=%q, want 401 %q", i+1, code, retry, want)
+		}
+		if want != "" && want != "900" {
+			// selama jeda berlaku, password benar pun ditolak
+			if code, _ := postLogin(t, app, "10.0.0.1", "andi", "andi123"); code != 429 {
+				t.Fatalf("attempt %d: expected 429 during delay, got %d", i+1, code)
+			}
+			clock = clock.Add(time.Duration(i) * time.Second)
+		}
+	}
+
+	// akun terkunci walau dari IP lain
+	clock = clock.Add(10 * time.Minute)
+	if code, retry := postLogin(t, app, "10.0.0.2", "andi", "andi123"); code != 429 || retry != "300" {
+		t.Fatalf("locked account must be 429 with remaining time, got %d %q", code, retry)
+	}
+
+	if code, body := call(t, app, "POST", "/users/u1/unlock", ""); code != 200 {
+		t.Fatalf("unlock failed: %d %v", code, body)
+	}
+	if code, _ := postLogin(t, app, "10.0.0.2", "andi", "andi123"); code != 200 {
+		t.Fatalf("unlocked account must log in, got %d", code)
+	}
+	if code, _ := call(t, app, "POST", "/users/missing/unlock", ""); code != 404 {
+		t.Errorf("unknown user unlock must be 404, got %d", code)
+	}
+
+	// audit: 5 password salah + 3 ditolak karena diblokir; yang ditolak dari
+	// username + IP yang sama digabung menjadi satu baris
+	reasons := map[string]int{}
+	throttledRows := 0
+	for _, a := range attempts.Attempts {
+		reasons[a.Reason] += a.Count
+		if a.Reason == model.LoginThrottled {
+			throttledRows++
+		}
+		if a.Reason == model.LoginFailedPassword && (a.UserID == nil || *a.UserID != "u1") {
+			t.Errorf("failed attempt must reference the user: %+v", a)
+		}
+	}
+	if reasons[model.LoginFailedPassword] != 5 || reasons[model.LoginThrottled] != 3 || throttledRows != 2 {
+		t.Errorf("unexpected audit %v in %d throttled rows", reasons, throttledRows)
+	}
+	code, body := call(t, app, "GET", "/users/login-attempts?username=ANDI&ip=10.0.0.2", "")
+	if list, _ := body["data"].([]interface{}); code != 200 || len(list) != 1 {
+		t.Errorf("expected one filtered attempt, got %d %v", code, body)
+	}
+}
+
+func TestLogin_SuccessResetsAccountCounter(t *testing.T) {
+	auth := repository.NewMockAuthRepository()
+	attempts := repository.NewMockLoginAttemptRepository()
+	auth.SeedUser("u1", "andi", "andi123", "Mahasiswa")
+	clock := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	attempts.Now = func() time.Time { return clock }
+	app := loginApp(auth, attempts, defaultLoginPolicy())
+
+	for i := 0; i < 2; i++ {
+		postLogin(t, app, "10.0.0.1", "andi", "salah")
+	}
+	if code, _ := postLogin(t, app, "10.0.0.1", "andi", "andi123"); code != 200 {
+		t.Fatalf("expected login, got %d", code)
+	}
+	if _, ok := attempts.Throttle[accountKey("andi")]; ok {
+		t.Error("successful login must reset the account counter")
+	}
+	if attempts.Throttle[ipKey("10.0.0.1")].Failures != 2 {
+		t.Error("successful login must not reset the IP counter")
+	}
+
+	// penghitung juga dimulai ulang setelah window lewat
+	for i := 0; i < 2; i++ {
+		postLogin(t, app, "10.0.0.1", "andi", "salah")
+	}
+	clock = clock.Add(16 * time.Minute)
+	if code, retry := postLogin(t, app, "10.0.0.1", "andi", "salah"); code != 401 || retry != "" {
+		t.Errorf("counter must restart after the window, got %d %q", code, retry)
+	}
+}
+
+func TestLogin_BlocksIPAcrossUsernames(t *testing.T) {
+	policy := defaultLoginPolicy()
+	policy.IPMaxAttempts = 3
+	policy.FreeAttempts = 5
+	auth := repository.NewMockAuthRepository()
+	attempts := repository.NewMockLoginAttemptRepository()
+	auth.SeedUser("u1", "andi", "andi123", "Mahasiswa")
+	clock := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	attempts.Now = func() time.Time { return clock }
+	app := loginApp(auth, attempts, policy)
+
+	// username tidak terdaftar dihitung sama seperti password salah
+	for i := 0; i < 3; i++ {
+		if code, _ := postLogin(t, app, "10.0.0.9", fmt.Sprintf("tebak%d", i), "x"); code != 401 {
+			t.Fatalf("expected 401, got %d", code)
+		}
+	}
+	if code, retry := postLogin(t, app, "10.0.0.9", "andi", "andi123"); code != 429 || retry != "900" {
+		t.Fatalf("blocked IP must be rejected, got %d %q", code, retry)
+	}
+	if code, _ := postLogin(t, app, "10.0.0.10", "andi", "andi123"); code != 200 {
+		t.Errorf("other IPs must not be blocked, got %d", code)
+	}
+	if attempts.Attempts[0].Reason != model.LoginUnknownUser || attempts.Attempts[0].UserID != nil {
+		t.Errorf("unknown username must be audited without user id: %+v", attempts.Attempts[0])
+	}
+}
+
+func TestLogin_RejectsInactiveUser(t *testing.T) {
+	auth := repository.NewMockAuthRepository()
+	attempts := repository.NewMockLoginAttemptRepository()
+	auth.SeedUser("u1", "andi", "andi123", "Mahasiswa")
+	clock := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	attempts.Now = func() time.Time { return clock }
+	app := loginApp(auth, attempts, defaultLoginPolicy())
+	u := auth.Users["andi"]
+	u.IsActive = false
+	auth.Users["andi"] = u
+
+	if code, _ := postLogin(t, app, "10.0.0.1", "andi", "salah"); code != 401 {
+		t.Errorf("wrong password of inactive user must stay 401, got %d", code)
+	}
+	if code, _ := postLogin(t, app, "10.0.0.1", "andi", "andi123"); code != 403 {
+		t.Errorf("inactive user must be rejected, got %d", code)
+	}
+	if len(auth.Sessions) != 0 {
+		t.Error("no session may be opened for an inactive user")
+	}
+	last := attempts.Attempts[len(attempts.Attempts)-1]
+	if last.Reason != model.LoginInactiveUser {
+		t.Errorf("inactive login must be audited, got %+v", last)
+	}
+	if attempts.Throttle[accountKey("andi")].Failures != 1 {
+		t.Error("correct password of inactive user must not count as a failure")
+	}
+
+	if _, _, _, err := NewAuthLogicService(auth, testKeys).LoginLogic("andi", "andi123"); err == nil {
+		t.Error("LoginLogic must reject inactive users")
+	}
+}
+
+func TestLoginGuard_ReservesBeforePasswordCheck(t *testing.T) {
+	attempts := repository.NewMockLoginAttemptRepository()
+	clock := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	attempts.Now = func() time.Time { return clock }
+	guard := NewLoginGuard(attempts, defaultLoginPolicy())
+	guard.now = attempts.Now
+	ctx := context.Background()
+
+	// request paralel: semuanya memesan sebelum ada yang selesai memeriksa
+	// password → hanya FreeAttempts+1 yang lolos, sisanya langsung diblokir
+	passed := 0
+	for i := 0; i < 10; i++ {
+		blocked, _, err := guard.Reserve(ctx, "andi", "10.0.0.1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if blocked == 0 {
+			passed++
+		}
+	}
+	if passed != 3 {
+		t.Fatalf("expected 3 in-flight attempts before the delay, got %d", passed)
+	}
+	if attempts.Throttle[accountKey("andi")].Failures != 3 {
+		t.Errorf("blocked attempts must not be counted, got %+v", attempts.Throttle[accountKey("andi")])
+	}
+
+	// password benar membatalkan pemesanan IP dan jedanya
+	if err := guard.Succeed(ctx, "andi", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	ip := attempts.Throttle[ipKey("10.0.0.1")]
+	if ip.Failures != 2 || ip.BlockedUntil != nil {
+		t.Errorf("successful login must release its IP reservation, got %+v", ip)
+	}
+}
+
+func TestLoginGuard_PurgesOldAudit(t *testing.T) {
+	attempts := repository.NewMockLoginAttemptRepository()
+	clock := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	attempts.Now = func() time.Time { return clock }
+	guard := NewLoginGuard(attempts, defaultLoginPolicy())
+	guard.now = attempts.Now
+	ctx := context.Background()
+
+	guard.Reserve(ctx, "andi", "10.0.0.1")
+	guard.Fail(ctx, "andi", "10.0.0.1", "", nil, model.LoginUnknownUser)
+	clock = clock.Add(100 * 24 * time.Hour)
+	guard.Fail(ctx, "budi", "10.0.0.2", "", nil, model.LoginUnknownUser)
+
+	n, err := attempts.Purge(ctx, clock.Add(-90*24*time.Hour), guard.policy.Window)
+	if err != nil || n != 1 || len(attempts.Attempts) != 1 || attempts.Attempts[0].Username != "budi" {
+		t.Fatalf("expected the old attempt purged, got %d %v %+v", n, err, attempts.Attempts)
+	}
+	if len(attempts.Throttle) != 0 {
+		t.Errorf("idle counters must be purged, got %+v", attempts.Throttle)
+	}
+}
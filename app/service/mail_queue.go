@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+)
+
+// =====================================================
+//  MAIL QUEUE — email_outbox + worker dengan retry
+// =====================================================
+//
+// Enqueue hanya menulis ke email_outbox lalu membangunkan worker, sehingga
+// Verify / Reject tidak menunggu SMTP. Gagal kirim dijadwalkan ulang dengan
+// backoff eksponensial sampai MaxAttempts, setelah itu status = dead.
+type MailQueue struct {
+	outbox repository.IEmailOutboxRepository
+	mailer Mailer
+	wake   chan struct{}
+
+	MaxAttempts int
+	Backoff     time.Duration // jeda retry pertama, berlipat dua setiap gagal
+	BatchSize   int
+	Lease       time.Duration // lama email "dipegang" satu worker sebelum boleh diambil lagi
+}
+
+func NewMailQueue(outbox repository.IEmailOutboxRepository, mailer Mailer) *MailQueue {
+	return &MailQueue{
+		outbox:      outbox,
+		mailer:      mailer,
+		wake:        make(chan struct{}, 1),
+		MaxAttempts: 5,
+		Backoff:     time.Minute,
+		BatchSize:   20,
+		Lease:       5 * time.Minute,
+	}
+}
+
+func (q *MailQueue) Enqueue(ctx context.Context, template, recipientUserID string, msg model.EmailMessage) error {
+	job := model.EmailJob{
+		RecipientUserID: recipientUserID,
+		ToAddress:       msg.To,
+		Template:        template,
+		Subject:         msg.Subject,
+		TextBody:        msg.Text,
+		HTMLBody:        msg.HTML,
+	}
+	if err := q.outbox.Enqueue(ctx, &job); err != nil {
+		return err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// retryDelay = Backoff × 2^(attempts-1)
+func (q *MailQueue) retryDelay(attempts int) time.Duration {
+	d := q.Backoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+	}
+	return d
+}
+
+// ProcessDue mengirim satu batch email yang sudah jatuh tempo
+func (q *MailQueue) ProcessDue(ctx context.Context) (sent, failed int, err error) {
+	jobs, err := q.outbox.ClaimDue(ctx, q.BatchSize, q.Lease)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, job := range jobs {
+		sendErr := q.mailer.Send(ctx, model.EmailMessage{
+			To:      job.ToAddress,
+			Subject: job.Subject,
+			Text:    job.TextBody,
+			HTML:    job.HTMLBody,
+		})
+		if sendErr == nil {
+			sent++
+			q.outbox.MarkSent(ctx, job.ID)
+			continue
+		}
+
+		failed++
+		attempts := job.Attempts + 1
+		dead := attempts >= q.MaxAttempts
+		if dead {
+			log.Printf("❌ email %s ke %s gagal %d kali, berhenti: %v", job.Template, job.ToAddress, attempts, sendErr)
+		}
+		q.outbox.MarkFailed(ctx, job.ID, sendErr.Error(), time.Now().Add(q.retryDelay(attempts)), dead)
+	}
+
+	return sent, failed, nil
+}
+
+// Run = background worker: kirim saat ada email baru atau tiap interval (untuk retry)
+func (q *MailQueue) Run(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-ticker.C:
+		}
+
+		sent, failed, err := q.ProcessDue(ctx)
+		if err != nil {
+			log.Printf("❌ mail queue worker: %v", err)
+		} else if failed > 0 {
+			log.Printf("📧 mail queue: %d terkirim, %d gagal (dijadwalkan ulang)", sent, failed)
+		}
+	}
+}
+
+// =====================================================
+//  EMAIL NOTIFIER — preferensi user + template → antrean
+// =====================================================
+type EmailNotifier struct {
+	users   repository.IUserRepository
+	prefs   repository.INotificationPreferenceRepository
+	queue   *MailQueue
+	baseURL string // URL frontend, dipakai untuk link absolut di email
+}
+
+func NewEmailNotifier(
+	users repository.IUserRepository,
+	prefs repository.INotificationPreferenceRepository,
+	queue *MailQueue,
+	baseURL string,
+) *EmailNotifier {
+	return &EmailNotifier{
+		users:   users,
+		prefs:   prefs,
+		queue:   queue,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Notify mengantrekan email jenis notifType ke user, kecuali user opt-out.
+// Receiver nil aman dipakai (email dimatikan).
+func (e *EmailNotifier) Notify(ctx context.Context, userID, notifType string, data MailData) {
+	if e == nil {
+		return
+	}
+
+	data.Link = e.baseURL + data.Link
+	e.enqueue(ctx, userID, notifType, func(lang, recipientName string) (model.EmailMessage, error) {
+		data.RecipientName = recipientName
+		return renderMail(notifType, lang, data)
+	})
+}
+
+// enqueue: cek preferensi & alamat user, render sesuai bahasa user, lalu masuk antrean
+func (e *EmailNotifier) enqueue(
+	ctx context.Context,
+	userID, notifType string,
+	render func(lang, recipientName string) (model.EmailMessage, error),
+) {
+	if e == nil || userID == "" {
+		return
+	}
+
+	pref, err := e.prefs.Get(ctx, userID)
+	if err != nil {
+		log.Printf("⚠️ email %s: preferensi user %s tidak terbaca: %v", notifType, userID, err)
+		return
+	}
+	if !pref.EmailEnabled || containsString(pref.MutedTypes, notifType) {
+		return
+	}
+
+	user, err := e.users.GetByID(ctx, userID)
+	if err != nil || user.Email == "" || !user.IsActive {
+		return
+	}
+
+	name := user.FullName
+	if name == "" {
+		name = user.Username
+	}
+
+	msg, err := render(pref.Language, name)
+	if err != nil {
+		log.Printf("❌ email %s: %v", notifType, err)
+		return
+	}
+	msg.To = user.Email
+
+	if err := e.queue.Enqueue(ctx, notifType, userID, msg); err != nil {
+		log.Printf("❌ email %s ke %s gagal masuk antrean: %v", notifType, user.Email, err)
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+)
+
+type fakeMailer struct {
+	sent  []model.EmailMessage
+	fails int // jumlah kegagalan sebelum berhasil
+}
+
+func (m *fakeMailer) Send(ctx context.Context, msg model.EmailMessage) error {
+	if m.fails > 0 {
+		m.fails--
+		return errors.New("smtp: connection refused")
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func seedMailUsers(users *repository.MockUserRepository) {
+	users.Data["uStudent"] = model.User{ID: "uStudent", Username: "andi", FullName: "Andi", Email: "andi@kampus.ac.id", IsActive: true}
+	users.Data["uLect"] = model.User{ID: "uLect", Username: "budi", FullName: "Dr. Budi", Email: "budi@kampus.ac.id", IsActive: true}
+}
+
+func TestEmailNotifier_QueuesPerRecipient(t *testing.T) {
+	st := repository.NewMockStudentRepository()
+	users := repository.NewMockUserRepository()
+	seedNotifierUsers(st)
+	seedMailUsers(users)
+
+	outbox := repository.NewMockEmailOutboxRepository()
+	prefs := repository.NewMockNotificationPreferenceRepository()
+	mailer := &fakeMailer{}
+	queue := NewMailQueue(outbox, mailer)
+	notifier := NewNotifier(repository.NewMockNotificationRepository(), st, nil,
+		NewEmailNotifier(users, prefs, queue, "https://prestasi.kampus.ac.id/"))
+	ctx := context.Background()
+	ref := model.AchievementReference{ID: "a1", StudentID: "s1"}
+
+	notifier.AchievementSubmitted(ctx, ref)
+	notifier.AchievementRejected(ctx, ref, "Sertifikat <buram>")
+
+	if len(outbox.Data) != 2 {
+		t.Fatalf("expected 2 queued emails, got %d", len(outbox.Data))
+	}
+	if len(mailer.sent) != 0 {
+		t.Fatal("enqueue must not send synchronously")
+	}
+
+	submitted := outbox.Data[0]
+	if submitted.ToAddress != "budi@kampus.ac.id" || !strings.Contains(submitted.Subject, "Andi") {
+		t.Errorf("unexpected advisor email: %+v", submitted)
+	}
+	if !strings.Contains(submitted.TextBody, "https://prestasi.kampus.ac.id/achievements/a1") {
+		t.Errorf("expected absolute link in body:\n%s", submitted.TextBody)
+	}
+
+	rejected := outbox.Data[1]
+	if rejected.ToAddress != "andi@kampus.ac.id" || !strings.Contains(rejected.TextBody, "Catatan: Sertifikat <buram>") {
+		t.Errorf("unexpected student email: %+v", rejected)
+	}
+	if !strings.Contains(rejected.HTMLBody, "Sertifikat &lt;buram&gt;") {
+		t.Errorf("note must be escaped in HTML:\n%s", rejected.HTMLBody)
+	}
+}
+
+func TestEmailNotifier_RespectsPreferences(t *testing.T) {
+	st := repository.NewMockStudentRepository()
+	users := repository.NewMockUserRepository()
+	seedNotifierUsers(st)
+	seedMailUsers(users)
+
+	outbox := repository.NewMockEmailOutboxRepository()
+	prefs := repository.NewMockNotificationPreferenceRepository()
+	mailer := &fakeMailer{}
+	queue := NewMailQueue(outbox, mailer)
+	notifier := NewNotifier(repository.NewMockNotificationRepository(), st, nil,
+		NewEmailNotifier(users, prefs, queue, "https://prestasi.kampus.ac.id/"))
+	ctx := context.Background()
+	ref := model.AchievementReference{ID: "a1", StudentID: "s1"}
+
+	prefs.Data["uLect"] = model.NotificationPreference{UserID: "uLect", EmailEnabled: false, Language: model.LangID}
+	prefs.Data["uStudent"] = model.NotificationPreference{
+		UserID: "uStudent", EmailEnabled: true, Language: model.LangEN,
+		MutedTypes: []string{model.NotifAchievementRejected},
+	}
+
+	points := 30
+	notifier.AchievementSubmitted(ctx, ref)
+	notifier.AchievementRejected(ctx, ref, "x")
+	notifier.AchievementVerified(ctx, ref, &points)
+
+	if len(outbox.Data) != 1 {
+		t.Fatalf("expected only the verify email, got %d", len(outbox.Data))
+	}
+	job := outbox.Data[0]
+	if job.Subject != "Your achievement has been verified" || !strings.Contains(job.TextBody, "You earned 30 points.") {
+		t.Errorf("expected English template: %q\n%s", job.Subject, job.TextBody)
+	}
+}
+
+func TestMailQueue_RetryWithBackoff(t *testing.T) {
+	outbox := repository.NewMockEmailOutboxRepository()
+	mailer := &fakeMailer{}
+	queue := NewMailQueue(outbox, mailer)
+	ctx := context.Background()
+	queue.Backoff = 0
+	mailer.fails = 2
+
+	queue.Enqueue(ctx, model.NotifAchievementVerified, "uStudent", model.EmailMessage{To: "andi@kampus.ac.id", Subject: "Hai"})
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := queue.ProcessDue(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	job := outbox.Data[0]
+	if job.Status != model.EmailSent || job.Attempts != 3 || len(mailer.sent) != 1 {
+		t.Fatalf("expected sent on third attempt: %+v", job)
+	}
+}
+
+func TestMailQueue_GivesUpAfterMaxAttempts(t *testing.T) {
+	outbox := repository.NewMockEmailOutboxRepository()
+	mailer := &fakeMailer{}
+	queue := NewMailQueue(outbox, mailer)
+	ctx := context.Background()
+	queue.Backoff = 0
+	queue.MaxAttempts = 2
+	mailer.fails = 10
+
+	queue.Enqueue(ctx, "test", "", model.EmailMessage{To: "x@kampus.ac.id"})
+	queue.ProcessDue(ctx)
+	queue.ProcessDue(ctx)
+	queue.ProcessDue(ctx)
+
+	job := outbox.Data[0]
+	if job.Status != model.EmailDead || job.Attempts != 2 || job.LastError == "" {
+		t.Fatalf("expected dead job after 2 attempts: %+v", job)
+	}
+}
+
+func TestMailQueue_RetryDelay(t *testing.T) {
+	q := NewMailQueue(nil, nil)
+	if q.retryDelay(1) != time.Minute || q.retryDelay(3) != 4*time.Minute {
+		t.Errorf("unexpected backoff: %s, %s", q.retryDelay(1), q.retryDelay(3))
+	}
+}
+
+func TestFileMailer_WritesMultipartEML(t *testing.T) {
+	dir := t.TempDir()
+	m := NewFileMailer(dir, "Sistem Prestasi <no-reply@kampus.ac.id>")
+
+	err := m.Send(context.Background(), model.EmailMessage{
+		To:      "andi@kampus.ac.id",
+		Subject: "Prestasi kamu ditolak",
+		Text:    "Halo Andi",
+		HTML:    "<p>Halo Andi</p>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || !strings.HasSuffix(entries[0].Name(), "-andi@kampus.ac.id.eml") {
+		t.Fatalf("unexpected files: %v", entries)
+	}
+
+	raw, _ := os.ReadFile(dir + "/" + entries[0].Name())
+	eml := string(raw)
+	for _, want := range []string{"To: andi@kampus.ac.id", "multipart/alternative", "text/plain; charset=UTF-8", "<p>Halo Andi</p>"} {
+		if !strings.Contains(eml, want) {
+			t.Errorf("eml missing %q", want)
+		}
+	}
+}
+
+func TestApplyPreferenceRequest_Validation(t *testing.T) {
+	pref := model.DefaultNotificationPreference("u1")
+	lang := "fr"
+	muted := []string{model.NotifAchievementSubmitted, "unknown"}
+
+	err := applyPreferenceRequest(&pref, model.NotificationPreferenceRequest{Language: &lang, MutedTypes: &muted})
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) || len(ve.Fields) != 2 {
+		t.Fatalf("expected 2 field errors, got %v", err)
+	}
+	if ve.Fields[0].Field != "language" || ve.Fields[1].Field != "muted_types[1]" {
+		t.Errorf("unexpected fields: %+v", ve.Fields)
+	}
+}
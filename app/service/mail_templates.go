@@ -0,0 +1,248 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+
+	"backenduas/app/model"
+)
+
+// MailData = nilai yang dapat dipakai template email
+type MailData struct {
+	RecipientName string
+	StudentName   string
+	Points        *int
+	Note          string
+	Link          string // URL absolut ke prestasi
+}
+
+type mailTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+func (t mailTemplate) execute(view interface{}) (model.EmailMessage, error) {
+	var subject, text, html bytes.Buffer
+	if err := t.subject.Execute(&subject, view); err != nil {
+		return model.EmailMessage{}, err
+	}
+	if err := t.text.Execute(&text, view); err != nil {
+		return model.EmailMessage{}, err
+	}
+	if err := t.html.Execute(&html, view); err != nil {
+		return model.EmailMessage{}, err
+	}
+
+	return model.EmailMessage{Subject: subject.String(), Text: text.String(), HTML: html.String()}, nil
+}
+
+// kerangka HTML bersama; %s = isi, %s = footer
+const mailHTMLLayout = `<!DOCTYPE html>
+<html>
+<body style="font-family:Arial,Helvetica,sans-serif;color:#222;line-height:1.5">
+%s
+<hr style="border:none;border-top:1px solid #ddd">
+<p style="color:#888;font-size:12px">%s</p>
+</body>
+</html>`
+
+var mailFooters = map[string]string{
+	model.LangID: "Email ini dikirim otomatis oleh Sistem Prestasi Mahasiswa. Atur preferensi notifikasi di menu Notifikasi.",
+	model.LangEN: "This email was sent automatically by the Student Achievement System. Manage your notification preferences in the Notifications menu.",
+}
+
+func newMailTemplate(lang, subject, text, html string) mailTemplate {
+	return mailTemplate{
+		subject: texttemplate.Must(texttemplate.New("subject").Parse(subject)),
+		text:    texttemplate.Must(texttemplate.New("text").Parse(text + "\n\n-- \n" + mailFooters[lang] + "\n")),
+		html:    htmltemplate.Must(htmltemplate.New("html").Parse(fmt.Sprintf(mailHTMLLayout, html, mailFooters[lang]))),
+	}
+}
+
+// mailTemplates: jenis notifikasi → bahasa → template
+var mailTemplates = map[string]map[string]mailTemplate{
+	model.NotifAchievementSubmitted: {
+		model.LangID: newMailTemplate(model.LangID,
+			`Prestasi {{.StudentName}} menunggu verifikasi`,
+			`Halo {{.RecipientName}},
+
+{{.StudentName}} mengajukan prestasi baru untuk diverifikasi.
+
+Tinjau prestasi: {{.Link}}`,
+			`<p>Halo {{.RecipientName}},</p>
+<p><strong>{{.StudentName}}</strong> mengajukan prestasi baru untuk diverifikasi.</p>
+<p><a href="{{.Link}}">Tinjau prestasi</a></p>`),
+		model.LangEN: newMailTemplate(model.LangEN,
+			`Achievement from {{.StudentName}} awaits verification`,
+			`Hello {{.RecipientName}},
+
+{{.StudentName}} submitted a new achievement for verification.
+
+Review it: {{.Link}}`,
+			`<p>Hello {{.RecipientName}},</p>
+<p><strong>{{.StudentName}}</strong> submitted a new achievement for verification.</p>
+<p><a href="{{.Link}}">Review achievement</a></p>`),
+	},
+
+	model.NotifAchievementVerified: {
+		model.LangID: newMailTemplate(model.LangID,
+			`Prestasi kamu telah diverifikasi`,
+			`Halo {{.RecipientName}},
+
+Prestasi kamu telah diverifikasi oleh dosen wali.{{if .Points}} Kamu mendapatkan {{.Points}} poin.{{end}}
+
+Lihat prestasi: {{.Link}}`,
+			`<p>Halo {{.RecipientName}},</p>
+<p>Prestasi kamu telah <strong>diverifikasi</strong> oleh dosen wali.{{if .Points}} Kamu mendapatkan <strong>{{.Points}} poin</strong>.{{end}}</p>
+<p><a href="{{.Link}}">Lihat prestasi</a></p>`),
+		model.LangEN: newMailTemplate(model.LangEN,
+			`Your achievement has been verified`,
+			`Hello {{.RecipientName}},
+
+Your achievement has been verified by your academic advisor.{{if .Points}} You earned {{.Points}} points.{{end}}
+
+View achievement: {{.Link}}`,
+			`<p>Hello {{.RecipientName}},</p>
+<p>Your achievement has been <strong>verified</strong> by your academic advisor.{{if .Points}} You earned <strong>{{.Points}} points</strong>.{{end}}</p>
+<p><a href="{{.Link}}">View achievement</a></p>`),
+	},
+
+	model.NotifAchievementRejected: {
+		model.LangID: newMailTemplate(model.LangID,
+			`Prestasi kamu ditolak`,
+			`Halo {{.RecipientName}},
+
+Prestasi kamu ditolak oleh dosen wali.
+
+Catatan: {{.Note}}
+
+Perbaiki prestasi: {{.Link}}`,
+			`<p>Halo {{.RecipientName}},</p>
+<p>Prestasi kamu <strong>ditolak</strong> oleh dosen wali.</p>
+<blockquote style="border-left:3px solid #c33;margin:0;padding-left:12px">{{.Note}}</blockquote>
+<p><a href="{{.Link}}">Perbaiki prestasi</a></p>`),
+		model.LangEN: newMailTemplate(model.LangEN,
+			`Your achievement was rejected`,
+			`Hello {{.RecipientName}},
+
+Your achievement was rejected by your academic advisor.
+
+Note: {{.Note}}
+
+Update your achievement: {{.Link}}`,
+			`<p>Hello {{.RecipientName}},</p>
+<p>Your achievement was <strong>rejected</strong> by your academic advisor.</p>
+<blockquote style="border-left:3px solid #c33;margin:0;padding-left:12px">{{.Note}}</blockquote>
+<p><a href="{{.Link}}">Update achievement</a></p>`),
+	},
+}
+
+// renderMail: bahasa yang tidak dikenal → bahasa Indonesia
+func renderMail(notifType, lang string, data MailData) (model.EmailMessage, error) {
+	byLang, ok := mailTemplates[notifType]
+	if !ok {
+		return model.EmailMessage{}, fmt.Errorf("no email template for %q", notifType)
+	}
+	t, ok := byLang[lang]
+	if !ok {
+		t = byLang[model.LangID]
+	}
+
+	// Points dicetak sebagai angka, bukan alamat pointer
+	view := map[string]interface{}{
+		"RecipientName": data.RecipientName,
+		"StudentName":   data.StudentName,
+		"Note":          data.Note,
+		"Link":          data.Link,
+		"Points":        nil,
+	}
+	if data.Points != nil {
+		view["Points"] = *data.Points
+	}
+
+	return t.execute(view)
+}
+
+// =====================================================
+//  DIGEST & ESKALASI (data: daftar prestasi submitted)
+// =====================================================
+var digestTemplates = map[string]mailTemplate{
+	model.LangID: newMailTemplate(model.LangID,
+		`{{.Count}} prestasi mahasiswa bimbingan menunggu verifikasi`,
+		`Halo {{.RecipientName}},
+
+Ada {{.Count}} prestasi mahasiswa bimbingan yang menunggu verifikasi:
+{{range .Items}}
+- {{.StudentName}} ({{.StudentNIM}}): {{.Title}} — {{.AgeDays}} hari
+{{- end}}
+
+Tinjau sekarang: {{.Link}}`,
+		`<p>Halo {{.RecipientName}},</p>
+<p>Ada <strong>{{.Count}}</strong> prestasi mahasiswa bimbingan yang menunggu verifikasi:</p>
+<table cellpadding="6" style="border-collapse:collapse">
+<tr style="background:#f3f3f3"><th align="left">Mahasiswa</th><th align="left">Prestasi</th><th align="right">Menunggu</th></tr>
+{{range .Items}}<tr><td>{{.StudentName}} ({{.StudentNIM}})</td><td>{{.Title}}</td><td align="right">{{.AgeDays}} hari</td></tr>
+{{end}}</table>
+<p><a href="{{.Link}}">Tinjau sekarang</a></p>`),
+	model.LangEN: newMailTemplate(model.LangEN,
+		`{{.Count}} advisee achievements await verification`,
+		`Hello {{.RecipientName}},
+
+{{.Count}} advisee achievements are waiting for your verification:
+{{range .Items}}
+- {{.StudentName}} ({{.StudentNIM}}): {{.Title}} — {{.AgeDays}} days
+{{- end}}
+
+Review now: {{.Link}}`,
+		`<p>Hello {{.RecipientName}},</p>
+<p><strong>{{.Count}}</strong> advisee achievements are waiting for your verification:</p>
+<table cellpadding="6" style="border-collapse:collapse">
+<tr style="background:#f3f3f3"><th align="left">Student</th><th align="left">Achievement</th><th align="right">Waiting</th></tr>
+{{range .Items}}<tr><td>{{.StudentName}} ({{.StudentNIM}})</td><td>{{.Title}}</td><td align="right">{{.AgeDays}} days</td></tr>
+{{end}}</table>
+<p><a href="{{.Link}}">Review now</a></p>`),
+}
+
+var escalationTemplates = map[string]mailTemplate{
+	model.LangID: newMailTemplate(model.LangID,
+		`Eskalasi: {{.Count}} prestasi menunggu lebih dari {{.Threshold}} hari`,
+		`Halo {{.RecipientName}},
+
+{{.Count}} prestasi berstatus submitted lebih dari {{.Threshold}} hari:
+{{range .Items}}
+- {{.StudentName}} ({{.StudentNIM}}): {{.Title}} — {{.AgeDays}} hari, dosen wali: {{if .AdvisorName}}{{.AdvisorName}}{{else}}belum ada{{end}}
+{{- end}}`,
+		`<p>Halo {{.RecipientName}},</p>
+<p><strong>{{.Count}}</strong> prestasi berstatus submitted lebih dari {{.Threshold}} hari:</p>
+<table cellpadding="6" style="border-collapse:collapse">
+<tr style="background:#f3f3f3"><th align="left">Mahasiswa</th><th align="left">Prestasi</th><th align="left">Dosen Wali</th><th align="right">Menunggu</th></tr>
+{{range .Items}}<tr><td>{{.StudentName}} ({{.StudentNIM}})</td><td>{{.Title}}</td><td>{{if .AdvisorName}}{{.AdvisorName}}{{else}}<em>belum ada</em>{{end}}</td><td align="right">{{.AgeDays}} hari</td></tr>
+{{end}}</table>`),
+	model.LangEN: newMailTemplate(model.LangEN,
+		`Escalation: {{.Count}} achievements pending for more than {{.Threshold}} days`,
+		`Hello {{.RecipientName}},
+
+{{.Count}} achievements have been in submitted status for more than {{.Threshold}} days:
+{{range .Items}}
+- {{.StudentName}} ({{.StudentNIM}}): {{.Title}} — {{.AgeDays}} days, advisor: {{if .AdvisorName}}{{.AdvisorName}}{{else}}none{{end}}
+{{- end}}`,
+		`<p>Hello {{.RecipientName}},</p>
+<p><strong>{{.Count}}</strong> achievements have been in submitted status for more than {{.Threshold}} days:</p>
+<table cellpadding="6" style="border-collapse:collapse">
+<tr style="background:#f3f3f3"><th align="left">Student</th><th align="left">Achievement</th><th align="left">Advisor</th><th align="right">Waiting</th></tr>
+{{range .Items}}<tr><td>{{.StudentName}} ({{.StudentNIM}})</td><td>{{.Title}}</td><td>{{if .AdvisorName}}{{.AdvisorName}}{{else}}<em>none</em>{{end}}</td><td align="right">{{.AgeDays}} days</td></tr>
+{{end}}</table>`),
+}
+
+// renderList merender template digest / eskalasi; view berisi Items, Count, dst.
+func renderList(templates map[string]mailTemplate, lang string, view map[string]interface{}) (model.EmailMessage, error) {
+	t, ok := templates[lang]
+	if !ok {
+		t = templates[model.LangID]
+	}
+
+	return t.execute(view)
+}
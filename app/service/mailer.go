@@ -0,0 +1,164 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"backenduas/app/model"
+)
+
+// =====================================================
+//  MAILER — backend pengiriman email
+// =====================================================
+//
+// SMTPMailer untuk production, FileMailer menulis file .eml ke folder lokal
+// (dev / test). Pemanggil tidak pernah memakai Mailer langsung; semua email
+// lewat MailQueue supaya request tidak menunggu SMTP.
+type Mailer interface {
+	Send(ctx context.Context, msg model.EmailMessage) error
+}
+
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string // "Nama <alamat@domain>" atau alamat saja
+	Timeout  time.Duration
+}
+
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		From:     from,
+		Timeout:  30 * time.Second,
+	}
+}
+
+// Send: port 465 = TLS langsung, selain itu STARTTLS jika server mendukung
+func (m *SMTPMailer) Send(ctx context.Context, msg model.EmailMessage) error {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+
+	dialer := net.Dialer{Timeout: m.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.Host, strconv.Itoa(m.Port)))
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(m.Timeout))
+
+	tlsConfig := &tls.Config{ServerName: m.Host}
+	if m.Port == 465 {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	c, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && m.Port != 465 {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if m.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(msg.To); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(buildMIME(m.From, msg, time.Now())); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// FileMailer menyimpan setiap email sebagai <waktu>-<penerima>.eml di Dir
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{Dir: dir, From: from}
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._@-]+`)
+
+func (m *FileMailer) Send(ctx context.Context, msg model.EmailMessage) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%d-%s.eml", now.UnixNano(), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+	return os.WriteFile(filepath.Join(m.Dir, name), buildMIME(m.From, msg, now), 0o644)
+}
+
+// buildMIME menyusun email multipart/alternative (text/plain + text/html, UTF-8)
+func buildMIME(from string, msg model.EmailMessage, date time.Time) []byte {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	writePart := func(contentType, content string) {
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Type", contentType+"; charset=UTF-8")
+		h.Set("Content-Transfer-Encoding", "quoted-printable")
+		part, _ := mw.CreatePart(h)
+		qp := quotedprintable.NewWriter(part)
+		qp.Write([]byte(content))
+		qp.Close()
+	}
+	writePart("text/plain", msg.Text)
+	writePart("text/html", msg.HTML)
+	mw.Close()
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "From: %s\r\n", from)
+	fmt.Fprintf(&out, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&out, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&out, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&out, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&out, "Content-Type: multipart/alternative; boundary=%q\r\n", mw.Boundary())
+	out.WriteString("\r\n")
+	out.Write(body.Bytes())
+
+	return out.Bytes()
+}
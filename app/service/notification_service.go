@@ -0,0 +1,365 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// interval komentar keep-alive supaya proxy tidak menutup koneksi SSE yang diam
+const streamHeartbeat = 25 * time.Second
+
+type NotificationService struct {
+	repo     *repository.NotificationRepository
+	prefs    *repository.NotificationPreferenceRepository
+	hub      EventHub
+	denylist *TokenDenylist
+}
+
+func NewNotificationService(
+	repo *repository.NotificationRepository,
+	prefs *repository.NotificationPreferenceRepository,
+	hub EventHub,
+	denylist *TokenDenylist,
+) *NotificationService {
+	return &NotificationService{repo: repo, prefs: prefs, hub: hub, denylist: denylist}
+}
+
+// ===============================
+// LIST
+// ===============================
+// GetAll godoc
+// @Summary List my notifications
+// @Description Notifikasi milik user login, terbaru dulu
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Param unread query bool false "Only unread notifications"
+// @Param page query int false "Page (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} model.NotificationListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notifications [get]
+func (s *NotificationService) GetAll(c *fiber.Ctx) error {
+	claims := c.Locals("user").(jwt.MapClaims)
+	userID := claims["user_id"].(string)
+	ctx := context.Background()
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", defaultListLimit)
+	if page < 1 || limit < 1 || limit > maxListLimit {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid page or limit"})
+	}
+
+	list, total, err := s.repo.List(ctx, userID, c.QueryBool("unread"), limit, (page-1)*limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	unread, err := s.repo.CountUnread(ctx, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(model.NotificationListResponse{
+		Data: list,
+		Meta: model.PageMeta{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: (total + limit - 1) / limit,
+		},
+		Unread: unread,
+	})
+}
+
+// ===============================
+// MARK READ
+// ===============================
+// MarkRead godoc
+// @Summary Mark notification as read
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Notification ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /notifications/{id}/read [post]
+func (s *NotificationService) MarkRead(c *fiber.Ctx) error {
+	claims := c.Locals("user").(jwt.MapClaims)
+	userID := claims["user_id"].(string)
+
+	err := s.repo.MarkRead(context.Background(), c.Params("id"), userID)
+	if errors.Is(err, repository.ErrNotificationNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "notification marked as read"})
+}
+
+// ===============================
+// MARK ALL READ
+// ===============================
+// MarkAllRead godoc
+// @Summary Mark all notifications as read
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /notifications/read-all [post]
+func (s *NotificationService) MarkAllRead(c *fiber.Ctx) error {
+	claims := c.Locals("user").(jwt.MapClaims)
+	userID := claims["user_id"].(string)
+
+	n, err := s.repo.MarkAllRead(context.Background(), userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "all notifications marked as read", "updated": n})
+}
+
+// ===============================
+// PREFERENCES
+// ===============================
+// GetPreferences godoc
+// @Summary Get my notification preferences
+// @Description Preferensi email (aktif/nonaktif, bahasa id/en, jenis notifikasi yang tidak dikirim lewat email)
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} model.NotificationPreference
+// @Failure 500 {object} map[string]string
+// @Router /notifications/preferences [get]
+func (s *NotificationService) GetPreferences(c *fiber.Ctx) error {
+	claims := c.Locals("user").(jwt.MapClaims)
+	userID := claims["user_id"].(string)
+
+	pref, err := s.prefs.Get(context.Background(), userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(pref)
+}
+
+// UpdatePreferences godoc
+// @Summary Update my notification preferences
+// @Description Field yang tidak dikirim tidak diubah
+// @Tags Notifications
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body model.NotificationPreferenceRequest true "Preferences"
+// @Success 200 {object} model.NotificationPreference
+// @Failure 400 {object} map[string]any
+// @Failure 500 {object} map[string]string
+// @Router /notifications/preferences [put]
+func (s *NotificationService) UpdatePreferences(c *fiber.Ctx) error {
+	claims := c.Locals("user").(jwt.MapClaims)
+	userID := claims["user_id"].(string)
+
+	var req model.NotificationPreferenceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	ctx := context.Background()
+	pref, err := s.prefs.Get(ctx, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := applyPreferenceRequest(&pref, req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	if err := s.prefs.Upsert(ctx, &pref); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(pref)
+}
+
+// jenis notifikasi yang dapat dimatikan email-nya
+var emailNotificationTypes = []string{
+	model.NotifAchievementSubmitted,
+	model.NotifAchievementVerified,
+	model.NotifAchievementRejected,
+	model.NotifPendingDigest,
+	model.NotifPendingEscalation,
+}
+
+func applyPreferenceRequest(pref *model.NotificationPreference, req model.NotificationPreferenceRequest) error {
+	errs := []model.FieldError{}
+
+	if req.EmailEnabled != nil {
+		pref.EmailEnabled = *req.EmailEnabled
+	}
+
+	if req.Language != nil {
+		switch *req.Language {
+		case model.LangID, model.LangEN:
+			pref.Language = *req.Language
+		default:
+			errs = append(errs, model.FieldError{Field: "language", Message: "must be one of id, en"})
+		}
+	}
+
+	if req.MutedTypes != nil {
+		muted := []string{}
+		for i, t := range *req.MutedTypes {
+			if !containsString(emailNotificationTypes, t) {
+				errs = append(errs, model.FieldError{
+					Field:   fmt.Sprintf("muted_types[%d]", i),
+					Message: "must be one of " + strings.Join(emailNotificationTypes, ", "),
+				})
+				continue
+			}
+			if !containsString(muted, t) {
+				muted = append(muted, t)
+			}
+		}
+		pref.MutedTypes = muted
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Fields: errs}
+	}
+	return nil
+}
+
+// ===============================
+// STREAM (Server-Sent Events)
+// ===============================
+// Stream godoc
+// @Summary Real-time event stream
+// @Description Server-Sent Events milik user login: notification, achievement_submitted (dosen wali), achievement_verified / achievement_rejected (mahasiswa) dan resync. Stream ditutup dengan event token_expired saat token habis atau dicabut. Kirim header Last-Event-ID (otomatis oleh EventSource) untuk mengulang event yang terlewat. Token boleh dikirim lewat query access_token karena EventSource tidak dapat mengirim header Authorization.
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce text/event-stream
+// @Param access_token query string false "JWT (alternatif header Authorization)"
+// @Param last_event_id query string false "Alternatif header Last-Event-ID"
+// @Success 200 {string} string "event stream"
+// @Failure 401 {object} map[string]string
+// @Router /notifications/stream [get]
+func (s *NotificationService) Stream(c *fiber.Ctx) error {
+	claims := c.Locals("user").(jwt.MapClaims)
+	userID := claims["user_id"].(string)
+
+	lastEventID := c.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	sub := s.hub.Subscribe(userID, lastEventID)
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer sub.Close()
+		s.pump(w, sub, claims, streamHeartbeat)
+	})
+
+	return nil
+}
+
+// pump menulis event ke koneksi sampai client menutup koneksi, subscriber
+// dilepas hub, atau token tidak lagi berlaku. Koneksi SSE bisa hidup jauh
+// lebih lama dari access token, jadi exp dipasang sebagai timer dan denylist
+// diperiksa ulang setiap heartbeat.
+func (s *NotificationService) pump(w *bufio.Writer, sub *Subscription, claims jwt.MapClaims, heartbeat time.Duration) {
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	var expired <-chan time.Time
+	if exp, ok := claimTime(claims, "exp"); ok {
+		timer := time.NewTimer(time.Until(exp))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	fmt.Fprint(w, "retry: 3000\n\n")
+	for _, evt := range sub.Replay {
+		if err := writeSSE(w, evt); err != nil {
+			return
+		}
+	}
+	if w.Flush() != nil {
+		return
+	}
+
+	for {
+		select {
+		case evt, ok := <-sub.Events:
+			if !ok {
+				return // koneksi terlalu lambat → client reconnect dengan Last-Event-ID
+			}
+			if err := writeSSE(w, evt); err != nil {
+				return
+			}
+		case <-expired:
+			endStream(w)
+			return
+		case <-ticker.C:
+			if s.streamRevoked(claims) {
+				endStream(w)
+				return
+			}
+			fmt.Fprint(w, ": ping\n\n")
+		}
+		if w.Flush() != nil {
+			return // client menutup koneksi
+		}
+	}
+}
+
+// streamRevoked = token sudah dicabut (logout, user dinonaktifkan, ...).
+// Denylist yang gagal diperiksa juga menutup stream; saat reconnect
+// middleware memeriksa token dari awal.
+func (s *NotificationService) streamRevoked(claims jwt.MapClaims) bool {
+	if s.denylist == nil {
+		return false
+	}
+	revoked, err := s.denylist.IsRevoked(context.Background(), claims)
+	if err != nil {
+		log.Printf("⚠️ gagal memeriksa denylist token stream: %v", err)
+		return true
+	}
+	return revoked
+}
+
+// endStream memberi tahu client bahwa token tidak berlaku lagi sehingga client
+// perlu refresh token sebelum membuka stream baru. Tanpa field id agar
+// Last-Event-ID di client tidak ikut direset.
+func endStream(w *bufio.Writer) {
+	fmt.Fprintf(w, "event: %s\ndata: {}\n\n", model.EventTokenExpired)
+	w.Flush()
+}
+
+// writeSSE menulis satu event dalam format text/event-stream (data = JSON satu baris)
+func writeSSE(w io.Writer, evt model.StreamEvent) error {
+	data, err := json.Marshal(evt.Data)
+	if err != nil {
+		log.Printf("❌ event %s tidak dapat di-encode: %v", evt.Type, err)
+		return nil
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", evt.ID, evt.Type, data)
+	return err
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+)
+
+// =====================================================
+//  NOTIFIER — membuat notifikasi in-app
+// =====================================================
+//
+// Kegagalan menulis notifikasi hanya dicatat di log; tidak pernah
+// menggagalkan operasi utama (submit / verify / reject / ganti dosen wali).
+// Receiver nil aman dipakai (notifikasi dimatikan). hub nil = tanpa push
+// real-time, mail nil = tanpa email.
+type Notifier struct {
+	repo        repository.INotificationRepository
+	studentRepo repository.IStudentRepository
+	hub         EventHub
+	mail        *EmailNotifier
+}
+
+func NewNotifier(
+	repo repository.INotificationRepository,
+	st repository.IStudentRepository,
+	hub EventHub,
+	mail *EmailNotifier,
+) *Notifier {
+	return &Notifier{repo: repo, studentRepo: st, hub: hub, mail: mail}
+}
+
+func achievementLink(id string) string {
+	return "/achievements/" + id
+}
+
+func (n *Notifier) send(ctx context.Context, notif model.Notification) {
+	if notif.RecipientID == "" {
+		return
+	}
+	if err := n.repo.Create(ctx, &notif); err != nil {
+		log.Printf("❌ notifikasi %s ke %s gagal disimpan: %v", notif.Type, notif.RecipientID, err)
+		return
+	}
+	n.publish(notif.RecipientID, model.EventNotification, notif)
+}
+
+// publish mengirim event ke stream SSE milik user (tanpa efek jika hub nil)
+func (n *Notifier) publish(userID, eventType string, data interface{}) {
+	if n.hub == nil || userID == "" {
+		return
+	}
+	n.hub.Publish(userID, eventType, data)
+}
+
+func achievementEvent(ref model.AchievementReference, status string) model.AchievementEvent {
+	return model.AchievementEvent{AchievementID: ref.ID, StudentID: ref.StudentID, Status: status}
+}
+
+func (n *Notifier) studentUserID(ctx context.Context, studentID string) string {
+	st, err := n.studentRepo.GetByID(ctx, studentID)
+	if err != nil {
+		log.Printf("⚠️ penerima notifikasi: student %s tidak ditemukan: %v", studentID, err)
+		return ""
+	}
+	return st.UserID
+}
+
+func (n *Notifier) lecturerUserID(ctx context.Context, lecturerID string) string {
+	uid, err := n.studentRepo.GetUserIDByLecturerID(ctx, lecturerID)
+	if err != nil {
+		log.Printf("⚠️ penerima notifikasi: dosen %s tidak ditemukan: %v", lecturerID, err)
+		return ""
+	}
+	return uid
+}
+
+// AchievementSubmitted → dosen wali mahasiswa pemilik prestasi
+func (n *Notifier) AchievementSubmitted(ctx context.Context, ref model.AchievementReference) {
+	if n == nil {
+		return
+	}
+
+	st, err := n.studentRepo.GetByID(ctx, ref.StudentID)
+	if err != nil || st.AdvisorID == nil {
+		log.Printf("⚠️ notifikasi submit %s: mahasiswa belum punya dosen wali", ref.ID)
+		return
+	}
+
+	name := st.FullName
+	if name == "" {
+		name = st.StudentID
+	}
+
+	recipient := n.lecturerUserID(ctx, *st.AdvisorID)
+	n.publish(recipient, model.NotifAchievementSubmitted, achievementEvent(ref, StatusSubmitted))
+	n.mail.Notify(ctx, recipient, model.NotifAchievementSubmitted, MailData{
+		StudentName: name,
+		Link:        achievementLink(ref.ID),
+	})
+
+	n.send(ctx, model.Notification{
+		RecipientID:   recipient,
+		Type:          model.NotifAchievementSubmitted,
+		Title:         "Prestasi Menunggu Verifikasi",
+		Message:       fmt.Sprintf("%s mengajukan prestasi untuk diverifikasi.", name),
+		AchievementID: &ref.ID,
+		Link:          achievementLink(ref.ID),
+	})
+}
+
+// AchievementVerified → mahasiswa
+func (n *Notifier) AchievementVerified(ctx context.Context, ref model.AchievementReference, points *int) {
+	if n == nil {
+		return
+	}
+
+	msg := "Prestasi kamu telah diverifikasi oleh dosen wali."
+	if points != nil {
+		msg = fmt.Sprintf("Prestasi kamu telah diverifikasi oleh dosen wali (+%d poin).", *points)
+	}
+
+	recipient := n.studentUserID(ctx, ref.StudentID)
+	evt := achievementEvent(ref, StatusVerified)
+	evt.Points = points
+	n.publish(recipient, model.NotifAchievementVerified, evt)
+	n.mail.Notify(ctx, recipient, model.NotifAchievementVerified, MailData{
+		Points: points,
+		Link:   achievementLink(ref.ID),
+	})
+
+	n.send(ctx, model.Notification{
+		RecipientID:   recipient,
+		Type:          model.NotifAchievementVerified,
+		Title:         "Prestasi Diverifikasi",
+		Message:       msg,
+		AchievementID: &ref.ID,
+		Link:          achievementLink(ref.ID),
+	})
+}
+
+// AchievementRejected → mahasiswa, beserta catatan penolakan
+func (n *Notifier) AchievementRejected(ctx context.Context, ref model.AchievementReference, note string) {
+	if n == nil {
+		return
+	}
+
+	recipient := n.studentUserID(ctx, ref.StudentID)
+	evt := achievementEvent(ref, StatusRejected)
+	evt.Note = note
+	n.publish(recipient, model.NotifAchievementRejected, evt)
+	n.mail.Notify(ctx, recipient, model.NotifAchievementRejected, MailData{
+		Note: note,
+		Link: achievementLink(ref.ID),
+	})
+
+	n.send(ctx, model.Notification{
+		RecipientID:   recipient,
+		Type:          model.NotifAchievementRejected,
+		Title:         "Prestasi Ditolak",
+		Message:       "Prestasi kamu ditolak. Catatan: " + note,
+		AchievementID: &ref.ID,
+		Link:          achievementLink(ref.ID),
+	})
+}
+
+// AdvisorAssigned → mahasiswa dan dosen wali baru (advisorID nil = dosen wali dilepas)
+func (n *Notifier) AdvisorAssigned(ctx context.Context, studentID string, advisorID *string) {
+	if n == nil {
+		return
+	}
+
+	st, err := n.studentRepo.GetByID(ctx, studentID)
+	if err != nil {
+		log.Printf("⚠️ notifikasi dosen wali: student %s tidak ditemukan: %v", studentID, err)
+		return
+	}
+
+	if advisorID == nil {
+		n.send(ctx, model.Notification{
+			RecipientID: st.UserID,
+			Type:        model.NotifAdvisorAssigned,
+			Title:       "Dosen Wali Dilepas",
+			Message:     "Kamu saat ini belum memiliki dosen wali.",
+		})
+		return
+	}
+
+	advisorName := "dosen wali baru"
+	if st.AdvisorName != nil && *st.AdvisorName != "" {
+		advisorName = *st.AdvisorName
+	}
+
+	n.send(ctx, model.Notification{
+		RecipientID: st.UserID,
+		Type:        model.NotifAdvisorAssigned,
+		Title:       "Dosen Wali Diperbarui",
+		Message:     fmt.Sprintf("Dosen wali kamu sekarang %s.", advisorName),
+	})
+
+	name := st.FullName
+	if name == "" {
+		name = st.StudentID
+	}
+
+	n.send(ctx, model.Notification{
+		RecipientID: n.lecturerUserID(ctx, *advisorID),
+		Type:        model.NotifAdviseeAssigned,
+		Title:       "Mahasiswa Bimbingan Baru",
+		Message:     fmt.Sprintf("%s sekarang menjadi mahasiswa bimbingan Anda.", name),
+	})
+}
+
+// SLABreached → Admin (adminUserIDs): prestasi melewati batas review
+func (n *Notifier) SLABreached(ctx context.Context, adminUserIDs []string, item model.SLABreachItem, reviewDays int) {
+	if n == nil {
+		return
+	}
+
+	advisor := "belum punya dosen wali"
+	if item.LecturerName != "" {
+		advisor = "dosen wali " + item.LecturerName
+	}
+
+	for _, uid := range adminUserIDs {
+		n.send(ctx, model.Notification{
+			RecipientID:   uid,
+			Type:          model.NotifSLABreached,
+			Title:         "SLA Verifikasi Terlewati",
+			Message:       fmt.Sprintf("Prestasi %s menunggu %d hari (batas %d hari, %s).", item.StudentName, item.AgeDays, reviewDays, advisor),
+			AchievementID: &item.AchievementID,
+			Link:          achievementLink(item.AchievementID),
+		})
+	}
+}
+
+// ReviewReassigned → dosen cadangan yang sekarang boleh memverifikasi
+func (n *Notifier) ReviewReassigned(ctx context.Context, item model.SLABreachItem, backupLecturerID string) {
+	if n == nil {
+		return
+	}
+
+	advisor := "dosen wali"
+	if item.LecturerName != "" {
+		advisor = item.LecturerName
+	}
+
+	n.send(ctx, model.Notification{
+		RecipientID:   n.lecturerUserID(ctx, backupLecturerID),
+		Type:          model.NotifReviewReassigned,
+		Title:         "Verifikasi Dialihkan ke Anda",
+		Message:       fmt.Sprintf("Prestasi %s melewati batas verifikasi %s dan dialihkan kepada Anda.", item.StudentName, advisor),
+		AchievementID: &item.AchievementID,
+		Link:          achievementLink(item.AchievementID),
+	})
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// seedNotifierUsers mengisi mahasiswa s1 (dengan dosen wali lect1), s2 (tanpa
+// dosen wali), serta akun dosen uLect dan uLect2.
+func seedNotifierUsers(st *repository.MockStudentRepository) {
+	advisor := "lect1"
+	advisorName := "Dr. Budi"
+	st.Students["s1"] = model.StudentDetail{
+		ID: "s1", UserID: "uStudent", StudentID: "2021001", FullName: "Andi",
+		AdvisorID: &advisor, AdvisorName: &advisorName,
+	}
+	st.Students["s2"] = model.StudentDetail{ID: "s2", UserID: "uStudent2", StudentID: "2021002"}
+	st.UserToLecturer["uLect"] = "lect1"
+	st.UserToLecturer["uLect2"] = "lect2"
+}
+
+func TestNotifier_SubmitGoesToAdvisor(t *testing.T) {
+	nr := repository.NewMockNotificationRepository()
+	st := repository.NewMockStudentRepository()
+	seedNotifierUsers(st)
+
+	n := NewNotifier(nr, st, nil, nil)
+
+	n.AchievementSubmitted(context.Background(), model.AchievementReference{ID: "a1", StudentID: "s1"})
+
+	if len(nr.Data) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(nr.Data))
+	}
+	got := nr.Data[0]
+	if got.RecipientID != "uLect" || got.Type != model.NotifAchievementSubmitted {
+		t.Errorf("unexpected notification: %+v", got)
+	}
+	if got.AchievementID == nil || *got.AchievementID != "a1" || got.Link != "/achievements/a1" {
+		t.Errorf("missing achievement link: %+v", got)
+	}
+}
+
+func TestNotifier_SubmitWithoutAdvisorIsSkipped(t *testing.T) {
+	nr := repository.NewMockNotificationRepository()
+	st := repository.NewMockStudentRepository()
+	seedNotifierUsers(st)
+
+	n := NewNotifier(nr, st, nil, nil)
+
+	n.AchievementSubmitted(context.Background(), model.AchievementReference{ID: "a2", StudentID: "s2"})
+
+	if len(nr.Data) != 0 {
+		t.Fatalf("expected no notification, got %+v", nr.Data)
+	}
+}
+
+func TestNotifier_ReviewGoesToStudent(t *testing.T) {
+	nr := repository.NewMockNotificationRepository()
+	st := repository.NewMockStudentRepository()
+	seedNotifierUsers(st)
+
+	n := NewNotifier(nr, st, nil, nil)
+	ctx := context.Background()
+	ref := model.AchievementReference{ID: "a1", StudentID: "s1"}
+
+	points := 25
+	n.AchievementVerified(ctx, ref, &points)
+	n.AchievementRejected(ctx, ref, "Sertifikat buram")
+
+	if len(nr.Data) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(nr.Data))
+	}
+	if nr.Data[0].RecipientID != "uStudent" || nr.Data[0].Message != "Prestasi kamu telah diverifikasi oleh dosen wali (+25 poin)." {
+		t.Errorf("unexpected verify notification: %+v", nr.Data[0])
+	}
+	if nr.Data[1].Type != model.NotifAchievementRejected || nr.Data[1].Message != "Prestasi kamu ditolak. Catatan: Sertifikat buram" {
+		t.Errorf("unexpected reject notification: %+v", nr.Data[1])
+	}
+}
+
+func TestNotifier_AdvisorAssignedNotifiesBoth(t *testing.T) {
+	nr := repository.NewMockNotificationRepository()
+	st := repository.NewMockStudentRepository()
+	seedNotifierUsers(st)
+
+	n := NewNotifier(nr, st, nil, nil)
+
+	lect2 := "lect2"
+	n.AdvisorAssigned(context.Background(), "s2", &lect2)
+
+	if len(nr.Data) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(nr.Data))
+	}
+	if nr.Data[0].RecipientID != "uStudent2" || nr.Data[0].Type != model.NotifAdvisorAssigned {
+		t.Errorf("unexpected student notification: %+v", nr.Data[0])
+	}
+	if nr.Data[1].RecipientID != "uLect2" || nr.Data[1].Type != model.NotifAdviseeAssigned {
+		t.Errorf("unexpected advisor notification: %+v", nr.Data[1])
+	}
+}
+
+func TestNotifier_NilIsNoop(t *testing.T) {
+	var n *Notifier
+	n.AchievementSubmitted(context.Background(), model.AchievementReference{ID: "a1", StudentID: "s1"})
+	n.AdvisorAssigned(context.Background(), "s1", nil)
+}
+
+func TestBulkReview_NotifiesStudents(t *testing.T) {
+	nr := repository.NewMockNotificationRepository()
+	st := repository.NewMockStudentRepository()
+	seedNotifierUsers(st)
+
+	n := NewNotifier(nr, st, nil, nil)
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	st.AdvisorMap["s1"] = "lect1"
+
+	for _, id := range []string{"a1", "a2"} {
+		oid := primitive.NewObjectID()
+		mg.Data[oid.Hex()] = model.AchievementMongo{ID: oid, StudentID: "s1", Status: StatusSubmitted}
+		pg.Data[id] = model.AchievementReference{ID: id, StudentID: "s1", MongoAchievementID: oid.Hex(), Status: StatusSubmitted}
+	}
+
+	reviewer := bulkReviewer{
+		pgRepo:      pg,
+		mongoRepo:   mg,
+		studentRepo: st,
+		notifier:    n,
+	}
+	items := []model.BulkReviewItem{{ID: "a1", Note: "Kurang bukti"}, {ID: "a2", Note: "Kurang bukti"}}
+
+	resp, err := reviewer.run(context.Background(), StatusRejected, "uLect", items)
+	if err != nil || resp.Succeeded != 2 {
+		t.Fatalf("unexpected result: %+v, %v", resp, err)
+	}
+
+	if len(nr.Data) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(nr.Data))
+	}
+	for _, notif := range nr.Data {
+		if notif.RecipientID != "uStudent" || notif.Type != model.NotifAchievementRejected {
+			t.Errorf("unexpected notification: %+v", notif)
+		}
+	}
+}
+
+func TestMockNotificationRepository_ReadState(t *testing.T) {
+	nr := repository.NewMockNotificationRepository()
+	st := repository.NewMockStudentRepository()
+	seedNotifierUsers(st)
+
+	n := NewNotifier(nr, st, nil, nil)
+	ctx := context.Background()
+	ref := model.AchievementReference{ID: "a1", StudentID: "s1"}
+
+	n.AchievementVerified(ctx, ref, nil)
+	n.AchievementRejected(ctx, ref, "x")
+	n.AchievementSubmitted(ctx, ref)
+
+	if c, _ := nr.CountUnread(ctx, "uStudent"); c != 2 {
+		t.Fatalf("expected 2 unread, got %d", c)
+	}
+
+	if err := nr.MarkRead(ctx, nr.Data[0].ID, "uLect"); err != repository.ErrNotificationNotFound {
+		t.Errorf("other users must not mark notifications read, got %v", err)
+	}
+	if err := nr.MarkRead(ctx, nr.Data[0].ID, "uStudent"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	unread, total, _ := nr.List(ctx, "uStudent", true, 10, 0)
+	if total != 1 || len(unread) != 1 || unread[0].Type != model.NotifAchievementRejected {
+		t.Errorf("unexpected unread list: %+v", unread)
+	}
+
+	if c, _ := nr.MarkAllRead(ctx, "uStudent"); c != 1 {
+		t.Errorf("expected 1 marked read, got %d", c)
+	}
+	if c, _ := nr.CountUnread(ctx, "uLect"); c != 1 {
+		t.Errorf("advisor notifications must stay unread, got %d", c)
+	}
+}
@@ -0,0 +1,371 @@
+package service
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// =====================================================
+//  PDF WRITER — dokumen PDF sederhana tanpa library luar
+// =====================================================
+//
+// Hanya memakai font standar Helvetica / Helvetica-Bold (tidak perlu
+// di-embed) dengan WinAnsiEncoding, sehingga karakter di luar Latin-1
+// diganti "?". Cukup untuk laporan tabel dan transkrip.
+
+const (
+	pdfPageWidth  = 595.0 // A4 portrait, satuan point
+	pdfPageHeight = 842.0
+	pdfMargin     = 50.0
+)
+
+// lebar glyph Helvetica (1/1000 em) untuk karakter 32..126
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// pdfTextWidth = lebar teks dalam point
+func pdfTextWidth(s string, size float64, bold bool) float64 {
+	widths := &helveticaWidths
+	if bold {
+		widths = &helveticaBoldWidths
+	}
+	total := 0
+	for _, r := range s {
+		if r >= 32 && r <= 126 {
+			total += widths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// pdfFit memotong teks (dengan "...") agar muat dalam lebar max
+func pdfFit(s string, size float64, bold bool, max float64) string {
+	if pdfTextWidth(s, size, bold) <= max {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if t := string(runes) + "..."; pdfTextWidth(t, size, bold) <= max {
+			return t
+		}
+	}
+	return ""
+}
+
+// pdfWrap memecah teks per kata agar tiap baris muat dalam lebar max
+func pdfWrap(s string, size float64, bold bool, max float64) []string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if line != "" && pdfTextWidth(candidate, size, bold) > max {
+			lines = append(lines, line)
+			candidate = word
+		}
+		line = candidate
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// pdfEscape: UTF-8 → WinAnsi + escape karakter khusus string PDF
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r <= 126:
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		case r == '—':
+			b.WriteString("\\227")
+		case r == '–':
+			b.WriteString("\\226")
+		case r == '•':
+			b.WriteString("\\225")
+		case r == '‘' || r == '’':
+			b.WriteByte('\'')
+		case r == '“' || r == '”':
+			b.WriteByte('"')
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+type pdfDocument struct {
+	Title   string
+	Created time.Time
+	Footer  string // teks kiri bawah setiap halaman; nomor halaman otomatis di kanan
+
+	pages []*bytes.Buffer
+}
+
+func newPDFDocument(title string, created time.Time) *pdfDocument {
+	return &pdfDocument{Title: title, Created: created}
+}
+
+func (d *pdfDocument) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *pdfDocument) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// Text menulis satu baris teks; (x, y) = garis dasar dari kiri bawah halaman
+func (d *pdfDocument) Text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.page(), "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfEscape(s))
+}
+
+// TextRight = Text rata kanan terhadap x
+func (d *pdfDocument) TextRight(x, y, size float64, bold bool, s string) {
+	d.Text(x-pdfTextWidth(s, size, bold), y, size, bold, s)
+}
+
+func (d *pdfDocument) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.page(), "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, y1, x2, y2)
+}
+
+// Rect = kotak berisi warna abu-abu (gray 0 = hitam, 1 = putih)
+func (d *pdfDocument) Rect(x, y, w, h, gray float64) {
+	fmt.Fprintf(d.page(), "q %.2f g %.2f %.2f %.2f %.2f re f Q\n", gray, x, y, w, h)
+}
+
+// Modules menggambar matriks n×n (mis. QR code) berukuran size point dengan
+// sudut kiri bawah (x, y), termasuk quiet zone 4 modul di setiap sisi.
+// Modul hitam yang berdampingan dalam satu baris digabung jadi satu kotak.
+func (d *pdfDocument) Modules(x, y, size float64, n int, black func(col, row int) bool) {
+	module := size / float64(n+8)
+	b := d.page()
+	b.WriteString("q 0 g\n")
+	for row := 0; row < n; row++ {
+		bottom := y + size - float64(row+5)*module
+		for col := 0; col < n; {
+			if !black(col, row) {
+				col++
+				continue
+			}
+			start := col
+			for col < n && black(col, row) {
+				col++
+			}
+			fmt.Fprintf(b, "%.2f %.2f %.2f %.2f re\n", x+float64(start+4)*module, bottom, float64(col-start)*module, module)
+		}
+	}
+	b.WriteString("f Q\n")
+}
+
+func (d *pdfDocument) footer(i int) []byte {
+	var b bytes.Buffer
+	y := pdfMargin / 2
+	if d.Footer != "" {
+		fmt.Fprintf(&b, "BT /F1 8 Tf %.2f %.2f Td (%s) Tj ET\n", pdfMargin, y, pdfEscape(d.Footer))
+	}
+	label := fmt.Sprintf("Halaman %d / %d", i+1, len(d.pages))
+	fmt.Fprintf(&b, "BT /F1 8 Tf %.2f %.2f Td (%s) Tj ET\n", pdfPageWidth-pdfMargin-pdfTextWidth(label, 8, false), y, label)
+	return b.Bytes()
+}
+
+// WriteTo menyusun objek PDF 1.4 beserta tabel xref
+func (d *pdfDocument) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var out bytes.Buffer
+	offsets := []int{}
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 catalog, 2 pages, 3-4 font, 5 info, lalu (page, content) per halaman
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+i*2)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	obj(fmt.Sprintf("<< /Title (%s) /Producer (Sistem Prestasi Mahasiswa) /CreationDate (D:%s) >>",
+		pdfEscape(d.Title), d.Created.UTC().Format("20060102150405Z")))
+
+	for i, content := range d.pages {
+		var raw bytes.Buffer
+		raw.Write(content.Bytes())
+		raw.Write(d.footer(i))
+
+		var packed bytes.Buffer
+		zw := zlib.NewWriter(&packed)
+		zw.Write(raw.Bytes())
+		zw.Close()
+
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 7+i*2))
+		obj(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", packed.Len(), packed.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	n, err := w.Write(out.Bytes())
+	return int64(n), err
+}
+
+// =====================================================
+//  PDF LAYOUT — kursor vertikal, judul, paragraf, tabel
+// =====================================================
+type pdfLayout struct {
+	doc *pdfDocument
+	y   float64
+}
+
+func newPDFLayout(doc *pdfDocument) *pdfLayout {
+	l := &pdfLayout{doc: doc}
+	l.newPage()
+	return l
+}
+
+func (l *pdfLayout) newPage() {
+	l.doc.AddPage()
+	l.y = pdfPageHeight - pdfMargin
+}
+
+// ensure pindah halaman jika sisa ruang kurang dari h
+func (l *pdfLayout) ensure(h float64) {
+	if l.y-h < pdfMargin {
+		l.newPage()
+	}
+}
+
+func (l *pdfLayout) Space(h float64) {
+	l.y -= h
+}
+
+func (l *pdfLayout) Heading(s string, size float64) {
+	l.ensure(size * 2)
+	l.y -= size
+	l.doc.Text(pdfMargin, l.y, size, true, s)
+	l.y -= size * 0.6
+}
+
+func (l *pdfLayout) Paragraph(s string, size float64) {
+	for _, line := range pdfWrap(s, size, false, pdfPageWidth-2*pdfMargin) {
+		l.ensure(size * 1.4)
+		l.y -= size * 1.4
+		l.doc.Text(pdfMargin, l.y, size, false, line)
+	}
+}
+
+// KeyValue = baris "label : nilai" (untuk identitas / ringkasan)
+func (l *pdfLayout) KeyValue(label, value string, size, labelWidth float64) {
+	l.ensure(size * 1.5)
+	l.y -= size * 1.5
+	l.doc.Text(pdfMargin, l.y, size, false, label)
+	l.doc.Text(pdfMargin+labelWidth, l.y, size, false, ": "+value)
+}
+
+// pdfColumn: Width = proporsi terhadap lebar tabel
+type pdfColumn struct {
+	Header string
+	Width  float64
+	Right  bool
+}
+
+// Table menggambar tabel; header diulang di setiap halaman baru
+func (l *pdfLayout) Table(cols []pdfColumn, rows [][]string, size float64) {
+	tableWidth := pdfPageWidth - 2*pdfMargin
+	rowHeight := size * 1.9
+	pad := 4.0
+
+	total := 0.0
+	for _, c := range cols {
+		total += c.Width
+	}
+	xs := make([]float64, len(cols)+1)
+	xs[0] = pdfMargin
+	for i, c := range cols {
+		xs[i+1] = xs[i] + c.Width/total*tableWidth
+	}
+
+	drawRow := func(cells []string, bold bool) {
+		l.y -= rowHeight
+		if bold {
+			l.doc.Rect(pdfMargin, l.y, tableWidth, rowHeight, 0.9)
+		}
+		base := l.y + (rowHeight-size)/2 + size*0.2
+		for i, c := range cols {
+			if i >= len(cells) {
+				break
+			}
+			text := pdfFit(cells[i], size, bold, xs[i+1]-xs[i]-2*pad)
+			if c.Right {
+				l.doc.TextRight(xs[i+1]-pad, base, size, bold, text)
+			} else {
+				l.doc.Text(xs[i]+pad, base, size, bold, text)
+			}
+		}
+		l.doc.Line(pdfMargin, l.y, pdfMargin+tableWidth, l.y, 0.3)
+	}
+
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = c.Header
+	}
+
+	l.ensure(rowHeight * 2)
+	drawRow(header, true)
+	for _, r := range rows {
+		if l.y-rowHeight < pdfMargin {
+			l.newPage()
+			drawRow(header, true)
+		}
+		drawRow(r, false)
+	}
+}
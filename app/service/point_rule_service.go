@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PointRuleService struct {
+	repo     *repository.PointRuleRepository
+	typeRepo *repository.AchievementTypeRepository
+	engine   *PointsEngine
+}
+
+func NewPointRuleService(
+	repo *repository.PointRuleRepository,
+	typeRepo *repository.AchievementTypeRepository,
+	engine *PointsEngine,
+) *PointRuleService {
+	return &PointRuleService{repo: repo, typeRepo: typeRepo, engine: engine}
+}
+
+// ===============================
+// GET ALL
+// ===============================
+// GetAll godoc
+// @Summary List point rules
+// @Description Daftar aturan perhitungan poin prestasi (Admin)
+// @Tags Point Rules
+// @Security BearerAuth
+// @Produce json
+// @Param include_inactive query bool false "Include inactive rules"
+// @Success 200 {array} model.PointRule
+// @Failure 500 {object} map[string]string
+// @Router /point-rules [get]
+func (s *PointRuleService) GetAll(c *fiber.Ctx) error {
+	rules, err := s.repo.GetAll(context.Background(), c.QueryBool("include_inactive"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(rules)
+}
+
+// ===============================
+// CREATE
+// ===============================
+// Create godoc
+// @Summary Create point rule
+// @Description Menambah aturan poin (base / bonus / multiplier) (Admin)
+// @Tags Point Rules
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body model.PointRuleRequest true "Point rule"
+// @Success 201 {object} model.PointRule
+// @Failure 400 {object} map[string]any
+// @Failure 500 {object} map[string]string
+// @Router /point-rules [post]
+func (s *PointRuleService) Create(c *fiber.Ctx) error {
+	var req model.PointRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+	rule := ruleFromRequest(req)
+
+	ctx := context.Background()
+	if err := s.validate(ctx, &rule); err != nil {
+		return validationFailed(c, err)
+	}
+
+	if err := s.repo.Create(ctx, &rule); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(rule)
+}
+
+// ===============================
+// UPDATE
+// ===============================
+// Update godoc
+// @Summary Update point rule
+// @Description Mengubah aturan poin; jalankan recalculate untuk menerapkan ke prestasi yang sudah diverifikasi (Admin)
+// @Tags Point Rules
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param request body model.PointRuleRequest true "Point rule"
+// @Success 200 {object} model.PointRule
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]string
+// @Router /point-rules/{id} [put]
+func (s *PointRuleService) Update(c *fiber.Ctx) error {
+	var req model.PointRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+	rule := ruleFromRequest(req)
+	rule.ID = c.Params("id")
+
+	ctx := context.Background()
+	if err := s.validate(ctx, &rule); err != nil {
+		return validationFailed(c, err)
+	}
+
+	err := s.repo.Update(ctx, &rule)
+	if errors.Is(err, repository.ErrPointRuleNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(rule)
+}
+
+// ===============================
+// DELETE
+// ===============================
+// Delete godoc
+// @Summary Delete point rule
+// @Description Menghapus aturan poin (Admin)
+// @Tags Point Rules
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /point-rules/{id} [delete]
+func (s *PointRuleService) Delete(c *fiber.Ctx) error {
+	err := s.repo.Delete(context.Background(), c.Params("id"))
+	if errors.Is(err, repository.ErrPointRuleNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "point rule deleted"})
+}
+
+// ===============================
+// RECALCULATE
+// ===============================
+// Recalculate godoc
+// @Summary Recalculate points
+// @Description Menghitung ulang poin seluruh prestasi verified dengan aturan aktif saat ini (Admin)
+// @Tags Point Rules
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} model.PointsRecalculateSummary
+// @Failure 500 {object} map[string]string
+// @Router /point-rules/recalculate [post]
+func (s *PointRuleService) Recalculate(c *fiber.Ctx) error {
+	summary, err := s.engine.RecalculateVerified(context.Background())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(summary)
+}
+
+func ruleFromRequest(req model.PointRuleRequest) model.PointRule {
+	return model.PointRule{
+		Name:            req.Name,
+		AchievementType: req.AchievementType,
+		Kind:            req.Kind,
+		Conditions:      req.Conditions,
+		Points:          req.Points,
+		Factor:          req.Factor,
+		Priority:        req.Priority,
+		IsActive:        req.IsActive == nil || *req.IsActive,
+	}
+}
+
+// validate: bentuk aturan + jenis prestasi harus terdaftar
+func (s *PointRuleService) validate(ctx context.Context, rule *model.PointRule) error {
+	if rule.Conditions == nil {
+		rule.Conditions = []model.RuleCondition{}
+	}
+	if rule.Kind != model.RuleMultiplier {
+		rule.Factor = 1
+	}
+
+	errs := ValidatePointRule(*rule)
+
+	if rule.AchievementType != "" {
+		t, err := s.typeRepo.GetByCode(ctx, rule.AchievementType)
+		if errors.Is(err, repository.ErrAchievementTypeNotFound) {
+			errs = append(errs, model.FieldError{
+				Field:   "achievement_type",
+				Message: fmt.Sprintf("unknown achievement type %q", rule.AchievementType),
+			})
+		} else if err != nil {
+			return err
+		} else {
+			rule.AchievementType = t.Code
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Fields: errs}
+	}
+	return nil
+}
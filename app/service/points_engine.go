@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// =====================================================
+//  POINTS ENGINE
+// =====================================================
+//
+// total = round((base + Σ bonus) × Π multiplier)
+//
+// base       : aturan base yang cocok dengan prioritas tertinggi (seri → paling spesifik)
+// bonus      : semua aturan bonus yang cocok
+// multiplier : semua aturan multiplier yang cocok
+type PointsEngine struct {
+	rules     repository.IPointRuleRepository
+	pgRepo    repository.IAchievementPGRepository
+	mongoRepo repository.IAchievementMongoRepository
+}
+
+func NewPointsEngine(
+	rules repository.IPointRuleRepository,
+	pg repository.IAchievementPGRepository,
+	mg repository.IAchievementMongoRepository,
+) *PointsEngine {
+	return &PointsEngine{rules: rules, pgRepo: pg, mongoRepo: mg}
+}
+
+// Calculate menghitung poin dokumen dengan aturan aktif saat ini
+func (e *PointsEngine) Calculate(ctx context.Context, doc model.AchievementMongo) (model.PointsBreakdown, error) {
+	rules, err := e.rules.GetAll(ctx, false)
+	if err != nil {
+		return model.PointsBreakdown{}, err
+	}
+	return CalculatePoints(rules, doc.AchievementType, doc.Details), nil
+}
+
+// pointsUpdate = field Mongo yang ditulis setelah perhitungan
+func pointsUpdate(pb model.PointsBreakdown) bson.M {
+	return bson.M{"points": pb.Total, "pointsBreakdown": pb}
+}
+
+// RecalculateVerified menghitung ulang seluruh prestasi verified (setelah aturan diubah)
+func (e *PointsEngine) RecalculateVerified(ctx context.Context) (model.PointsRecalculateSummary, error) {
+	summary := model.PointsRecalculateSummary{Errors: []string{}}
+
+	rules, err := e.rules.GetAll(ctx, false)
+	if err != nil {
+		return summary, err
+	}
+
+	refs, err := e.pgRepo.GetAll(ctx)
+	if err != nil {
+		return summary, err
+	}
+
+	mongoIDs := []primitive.ObjectID{}
+	for _, ref := range refs {
+		if ref.Status != StatusVerified {
+			continue
+		}
+		if oid, err := primitive.ObjectIDFromHex(ref.MongoAchievementID); err == nil {
+			mongoIDs = append(mongoIDs, oid)
+		}
+	}
+
+	docs, err := e.mongoRepo.FindManyByIDs(ctx, mongoIDs)
+	if err != nil {
+		return summary, err
+	}
+
+	for _, oid := range mongoIDs {
+		doc, ok := docs[oid.Hex()]
+		if !ok {
+			continue // dokumen hilang → ditangani reconcile
+		}
+		summary.Checked++
+
+		pb := CalculatePoints(rules, doc.AchievementType, doc.Details)
+		// total di PostgreSQL dipakai sort GET /achievements (no-op jika sama)
+		if err := e.pgRepo.SetPoints(ctx, oid.Hex(), pb.Total); err != nil {
+			summary.Failed++
+			summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", oid.Hex(), err))
+			continue
+		}
+		if doc.PointsBreakdown != nil && doc.Points == pb.Total && sameItems(doc.PointsBreakdown.Items, pb.Items) {
+			summary.Unchanged++
+			continue
+		}
+
+		if err := e.mongoRepo.Update(ctx, oid, pointsUpdate(pb)); err != nil {
+			summary.Failed++
+			summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", oid.Hex(), err))
+			continue
+		}
+		summary.Updated++
+	}
+
+	return summary, nil
+}
+
+func sameItems(a, b []model.PointsBreakdownItem) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// CalculatePoints = inti engine (tanpa I/O)
+func CalculatePoints(rules []model.PointRule, achievementType string, details map[string]interface{}) model.PointsBreakdown {
+	pb := model.PointsBreakdown{
+		Items:        []model.PointsBreakdownItem{},
+		CalculatedAt: time.Now().Unix(),
+	}
+
+	matching := []model.PointRule{}
+	for _, r := range rules {
+		if !r.IsActive {
+			continue
+		}
+		if r.AchievementType != "" && r.AchievementType != achievementType {
+			continue
+		}
+		if ruleMatches(r, details) {
+			matching = append(matching, r)
+		}
+	}
+
+	// urutan stabil supaya breakdown bisa dibandingkan antar perhitungan
+	sort.SliceStable(matching, func(i, j int) bool {
+		if matching[i].Priority != matching[j].Priority {
+			return matching[i].Priority > matching[j].Priority
+		}
+		if len(matching[i].Conditions) != len(matching[j].Conditions) {
+			return len(matching[i].Conditions) > len(matching[j].Conditions)
+		}
+		return matching[i].ID < matching[j].ID
+	})
+
+	sum := 0.0
+	factor := 1.0
+	var base, bonus, multiplier []model.PointsBreakdownItem
+
+	for _, r := range matching {
+		switch r.Kind {
+		case model.RuleBase:
+			if base != nil {
+				continue
+			}
+			sum += r.Points
+			base = []model.PointsBreakdownItem{{RuleID: r.ID, RuleName: r.Name, Kind: r.Kind, Points: r.Points}}
+
+		case model.RuleBonus:
+			sum += r.Points
+			bonus = append(bonus, model.PointsBreakdownItem{RuleID: r.ID, RuleName: r.Name, Kind: r.Kind, Points: r.Points})
+
+		case model.RuleMultiplier:
+			factor *= r.Factor
+			multiplier = append(multiplier, model.PointsBreakdownItem{RuleID: r.ID, RuleName: r.Name, Kind: r.Kind, Factor: r.Factor})
+		}
+	}
+
+	// breakdown: base → bonus → multiplier
+	pb.Items = append(pb.Items, base...)
+	pb.Items = append(pb.Items, bonus...)
+	pb.Items = append(pb.Items, multiplier...)
+	pb.Total = int(math.Round(sum * factor))
+	return pb
+}
+
+func ruleMatches(r model.PointRule, details map[string]interface{}) bool {
+	for _, c := range r.Conditions {
+		v, ok := details[c.Field]
+		if !ok || v == nil {
+			return false
+		}
+		if !conditionMatches(c, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func conditionMatches(c model.RuleCondition, v interface{}) bool {
+	switch c.Op {
+	case model.OpEq:
+		return valuesEqual(v, c.Value)
+
+	case model.OpIn:
+		options, ok := toSlice(c.Value)
+		if !ok {
+			return false
+		}
+		for _, o := range options {
+			if valuesEqual(v, o) {
+				return true
+			}
+		}
+		return false
+
+	case model.OpGte, model.OpLte:
+		a, ok1 := toFloat(v)
+		b, ok2 := toFloat(c.Value)
+		if !ok1 || !ok2 {
+			return false
+		}
+		if c.Op == model.OpGte {
+			return a >= b
+		}
+		return a <= b
+	}
+	return false
+}
+
+// valuesEqual: angka dibandingkan sebagai angka, selain itu string case-insensitive
+func valuesEqual(a, b interface{}) bool {
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			return x == y
+		}
+	}
+	return strings.EqualFold(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// ValidatePointRule memeriksa aturan dari Admin
+func ValidatePointRule(r model.PointRule) []model.FieldError {
+	errs := []model.FieldError{}
+
+	if strings.TrimSpace(r.Name) == "" {
+		errs = append(errs, model.FieldError{Field: "name", Message: "is required"})
+	}
+
+	switch r.Kind {
+	case model.RuleBase, model.RuleBonus:
+	case model.RuleMultiplier:
+		if r.Factor <= 0 {
+			errs = append(errs, model.FieldError{Field: "factor", Message: "must be greater than 0"})
+		}
+	default:
+		errs = append(errs, model.FieldError{Field: "kind", Message: "must be one of base, bonus, multiplier"})
+	}
+
+	for i, c := range r.Conditions {
+		path := fmt.Sprintf("conditions[%d]", i)
+		if c.Field == "" {
+			errs = append(errs, model.FieldError{Field: path + ".field", Message: "is required"})
+		}
+
+		switch c.Op {
+		case model.OpEq:
+		case model.OpIn:
+			if _, ok := toSlice(c.Value); !ok {
+				errs = append(errs, model.FieldError{Field: path + ".value", Message: "must be an array for op in"})
+			}
+		case model.OpGte, model.OpLte:
+			if _, ok := toFloat(c.Value); !ok {
+				errs = append(errs, model.FieldError{Field: path + ".value", Message: "must be a number for op " + c.Op})
+			}
+		default:
+			errs = append(errs, model.FieldError{Field: path + ".op", Message: "must be one of eq, in, gte, lte"})
+		}
+	}
+
+	return errs
+}
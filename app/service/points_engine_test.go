@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func facultyRules() []model.PointRule {
+	eq := func(field string, v interface{}) []model.RuleCondition {
+		return []model.RuleCondition{{Field: field, Op: model.OpEq, Value: v}}
+	}
+	return []model.PointRule{
+		{ID: "r1", Name: "Internasional", AchievementType: "competition", Kind: model.RuleBase, Points: 100, Priority: 10, IsActive: true, Conditions: eq("competitionLevel", "international")},
+		{ID: "r2", Name: "Nasional", AchievementType: "competition", Kind: model.RuleBase, Points: 60, Priority: 10, IsActive: true, Conditions: eq("competitionLevel", "national")},
+		{ID: "r3", Name: "Kompetisi lain", AchievementType: "competition", Kind: model.RuleBase, Points: 10, IsActive: true},
+		{ID: "r4", Name: "Juara 1", AchievementType: "competition", Kind: model.RuleBonus, Points: 30, IsActive: true, Conditions: eq("rank", 1)},
+		{ID: "r5", Name: "Tim 2-5", AchievementType: "competition", Kind: model.RuleMultiplier, Factor: 0.75, IsActive: true, Conditions: []model.RuleCondition{
+			{Field: "teamSize", Op: model.OpGte, Value: 2},
+			{Field: "teamSize", Op: model.OpLte, Value: 5},
+		}},
+		{ID: "r6", Name: "Nonaktif", AchievementType: "competition", Kind: model.RuleBonus, Points: 1000, IsActive: false},
+		{ID: "r7", Name: "Publikasi", AchievementType: "publication", Kind: model.RuleBase, Points: 50, IsActive: true},
+	}
+}
+
+func TestCalculatePoints_BaseBonusMultiplier(t *testing.T) {
+	pb := CalculatePoints(facultyRules(), "competition", map[string]interface{}{
+		"competitionLevel": "National",
+		"rank":             float64(1),
+		"teamSize":         float64(3),
+	})
+
+	// (60 + 30) × 0.75 = 67.5 → 68
+	if pb.Total != 68 {
+		t.Fatalf("expected 68 points, got %d (%+v)", pb.Total, pb.Items)
+	}
+
+	got := []string{}
+	for _, it := range pb.Items {
+		got = append(got, it.RuleID)
+	}
+	if len(got) != 3 || got[0] != "r2" || got[1] != "r4" || got[2] != "r5" {
+		t.Fatalf("unexpected breakdown: %v", got)
+	}
+}
+
+func TestCalculatePoints_FallbackBaseAndNoMatch(t *testing.T) {
+	pb := CalculatePoints(facultyRules(), "competition", map[string]interface{}{
+		"competitionLevel": "campus",
+		"teamSize":         float64(8),
+	})
+	if pb.Total != 10 || len(pb.Items) != 1 {
+		t.Fatalf("expected only fallback base rule, got %+v", pb)
+	}
+
+	pb = CalculatePoints(facultyRules(), "internship", nil)
+	if pb.Total != 0 || len(pb.Items) != 0 {
+		t.Fatalf("expected zero points for type without rules, got %+v", pb)
+	}
+}
+
+func TestVerifyAchievementLogic_StoresPoints(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	st := repository.NewMockStudentRepository()
+	rules := repository.NewMockPointRuleRepository()
+	for _, r := range facultyRules() {
+		rules.Data[r.ID] = r
+	}
+
+	oid := primitive.NewObjectID()
+	mg.Data[oid.Hex()] = model.AchievementMongo{
+		ID:              oid,
+		AchievementType: "competition",
+		Status:          StatusSubmitted,
+		Details:         map[string]interface{}{"competitionLevel": "international", "rank": 1},
+	}
+	pg.Data["a1"] = model.AchievementReference{ID: "a1", StudentID: "s1", MongoAchievementID: oid.Hex(), Status: StatusSubmitted}
+	st.UserToLecturer["uLect"] = "lect1"
+	st.AdvisorMap["s1"] = "lect1"
+
+	engine := NewPointsEngine(rules, pg, mg)
+	svc := NewAchievementLogicService(pg, mg, st, nil, nil, engine, nil)
+
+	if err := svc.Verify("a1", "uLect"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	doc := mg.Data[oid.Hex()]
+	if doc.Points != 130 || doc.PointsBreakdown == nil || len(doc.PointsBreakdown.Items) != 2 {
+		t.Fatalf("expected 130 points with breakdown, got %d %+v", doc.Points, doc.PointsBreakdown)
+	}
+	// status Mongo hanya ditulis syncer (outbox + status_version)
+	if doc.Status != StatusSubmitted {
+		t.Fatalf("verify must not write the mongo status directly, got %s", doc.Status)
+	}
+	if pg.Data["a1"].Status != StatusVerified || pg.Data["a1"].Points != 130 {
+		t.Fatalf("expected verified reference with 130 points, got %+v", pg.Data["a1"])
+	}
+}
+
+func TestRecalculateVerified(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	rules := repository.NewMockPointRuleRepository()
+	for _, r := range facultyRules() {
+		rules.Data[r.ID] = r
+	}
+
+	seed := func(id, status string) string {
+		oid := primitive.NewObjectID()
+		mg.Data[oid.Hex()] = model.AchievementMongo{
+			ID:              oid,
+			AchievementType: "publication",
+			Status:          status,
+		}
+		pg.Data[id] = model.AchievementReference{ID: id, MongoAchievementID: oid.Hex(), Status: status}
+		return oid.Hex()
+	}
+	verified := seed("a1", StatusVerified)
+	draft := seed("a2", StatusDraft)
+
+	engine := NewPointsEngine(rules, pg, mg)
+	ctx := context.Background()
+
+	summary, err := engine.RecalculateVerified(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Checked != 1 || summary.Updated != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if mg.Data[verified].Points != 50 || mg.Data[draft].Points != 0 {
+		t.Fatal("only verified achievements should be recalculated")
+	}
+
+	// aturan berubah → poin ikut berubah
+	r := rules.Data["r7"]
+	r.Points = 70
+	rules.Data["r7"] = r
+
+	summary, _ = engine.RecalculateVerified(ctx)
+	if summary.Updated != 1 || mg.Data[verified].Points != 70 {
+		t.Fatalf("expected recalculated points 70, got %d (%+v)", mg.Data[verified].Points, summary)
+	}
+
+	summary, _ = engine.RecalculateVerified(ctx)
+	if summary.Unchanged != 1 || summary.Updated != 0 {
+		t.Fatalf("expected unchanged on second run, got %+v", summary)
+	}
+}
+
+func TestValidatePointRule(t *testing.T) {
+	errs := fieldMessages(ValidatePointRule(model.PointRule{
+		Kind:   model.RuleMultiplier,
+		Factor: 0,
+		Conditions: []model.RuleCondition{
+			{Field: "rank", Op: "between", Value: 1},
+			{Field: "teamSize", Op: model.OpGte, Value: "many"},
+		},
+	}))
+
+	for _, field := range []string{"name", "factor", "conditions[0].op", "conditions[1].value"} {
+		if _, ok := errs[field]; !ok {
+			t.Errorf("expected error for %s, got %+v", field, errs)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import "backenduas/app/model"
+
+// =====================================================
+//  KATALOG PERMISSION BAWAAN
+// =====================================================
+//
+// Permission yang dipakai route. Saat start, permission yang belum ada di
+// tabel permissions ditambahkan dan diberikan ke role bawaan di bawah;
+// permission yang sudah ada tidak disentuh (pengaturan Admin tetap).
+
+// PermRoleManage tidak boleh dicabut dari role pemanggil sendiri (agar tidak terkunci)
+const PermRoleManage = "role:manage"
+
+// PermScopeAll: melihat data seluruh mahasiswa; tanpa permission ini cakupan
+// data mengikuti keterkaitan user dengan mahasiswa / dosen (lihat data_scope.go)
+const PermScopeAll = "scope:all"
+
+// role bawaan (users.role_id mengacu ke sini); tidak dapat dihapus / diganti nama
+var systemRoles = []string{"Admin", "Dosen Wali", "Mahasiswa"}
+
+type permissionDefault struct {
+	Name        string
+	Description string
+	Roles       []string
+}
+
+var permissionCatalog = []permissionDefault{
+	{"achievement:read", "Melihat prestasi & riwayatnya", []string{"Admin", "Dosen Wali", "Mahasiswa"}},
+	{"achievement:create", "Membuat prestasi", []string{"Mahasiswa"}},
+	{"achievement:update", "Mengubah, merevisi & melampirkan berkas prestasi", []string{"Mahasiswa"}},
+	{"achievement:delete", "Menghapus prestasi", []string{"Mahasiswa"}},
+	{"achievement:submit", "Mengajukan prestasi untuk diverifikasi", []string{"Mahasiswa"}},
+	{"achievement:verify", "Memverifikasi / menolak prestasi", []string{"Dosen Wali"}},
+	{"achievement_type:read", "Melihat jenis prestasi", []string{"Admin", "Dosen Wali", "Mahasiswa"}},
+	{"achievement_type:manage", "Mengelola jenis prestasi", []string{"Admin"}},
+	{"point_rule:manage", "Mengelola aturan poin", []string{"Admin"}},
+	{"student:read", "Melihat detail mahasiswa", []string{"Admin", "Dosen Wali"}},
+	{"student:manage", "Mengelola data mahasiswa & dosen wali", []string{"Admin"}},
+	{"lecturer:read", "Melihat dosen & mahasiswa bimbingan", []string{"Admin", "Dosen Wali"}},
+	{"user:manage", "Mengelola user", []string{"Admin"}},
+	{PermRoleManage, "Mengelola role & permission", []string{"Admin"}},
+	{"report:read", "Melihat laporan statistik", []string{"Admin", "Dosen Wali", "Mahasiswa"}},
+	{"report:review", "Melihat waktu review dosen", []string{"Admin", "Dosen Wali"}},
+	{"report:manage", "Membangun ulang statistik laporan", []string{"Admin"}},
+	{"sla:read", "Melihat pengaturan & pelanggaran SLA", []string{"Admin", "Dosen Wali"}},
+	{"sla:manage", "Mengelola pengaturan SLA", []string{"Admin"}},
+	{"notification:read", "Menerima notifikasi", []string{"Admin", "Dosen Wali", "Mahasiswa"}},
+	{"transcript:read", "Mengunduh transkrip prestasi", []string{"Admin", "Dosen Wali", "Mahasiswa"}},
+	{"system:manage", "Rekonsiliasi data & digest", []string{"Admin"}},
+	{PermScopeAll, "Melihat data seluruh mahasiswa (prestasi, laporan, SLA, transkrip)", []string{"Admin"}},
+}
+
+func isSystemPermission(name string) bool {
+	for _, p := range permissionCatalog {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isSystemRole(name string) bool {
+	return containsString(systemRoles, name)
+}
+
+func catalogSeed() ([]model.Permission, map[string][]string) {
+	perms := make([]model.Permission, 0, len(permissionCatalog))
+	grants := map[string][]string{}
+	for _, p := range permissionCatalog {
+		perms = append(perms, model.Permission{Name: p.Name, Description: p.Description})
+		grants[p.Name] = p.Roles
+	}
+	return perms, grants
+}
@@ -0,0 +1,501 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+	"backenduas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// =====================================================
+//  ROLE & PERMISSION (Admin)
+// =====================================================
+//
+// RBACService juga menjadi PermissionResolver untuk middleware
+// RequirePermission: permission per role di-cache sebentar dan cache
+// dikosongkan setiap kali Admin mengubah role / permission.
+
+const permissionCacheTTL = time.Minute
+
+var permissionNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*:[a-z][a-z0-9_]*$`)
+
+type RBACService struct {
+	repo  repository.IRBACRepository
+	now   func() time.Time
+	mu    sync.Mutex
+	cache map[string]permissionCacheEntry // key = role id
+}
+
+type permissionCacheEntry struct {
+	perms   []string
+	expires time.Time
+}
+
+func NewRBACService(repo repository.IRBACRepository) *RBACService {
+	return &RBACService{repo: repo, now: time.Now, cache: map[string]permissionCacheEntry{}}
+}
+
+// Seed menambahkan permission katalog yang belum ada (dipanggil saat start)
+func (s *RBACService) Seed(ctx context.Context) error {
+	perms, grants := catalogSeed()
+	inserted, err := s.repo.SeedPermissions(ctx, perms, grants)
+	if err != nil {
+		return err
+	}
+	if len(inserted) > 0 {
+		log.Printf("🔐 Permission baru: %s", strings.Join(inserted, ", "))
+	}
+	s.invalidate()
+	return nil
+}
+
+// PermissionsForRole memenuhi middleware.PermissionResolver
+func (s *RBACService) PermissionsForRole(ctx context.Context, roleID string) ([]string, error) {
+	s.mu.Lock()
+	e, ok := s.cache[roleID]
+	s.mu.Unlock()
+	if ok && s.now().Before(e.expires) {
+		return e.perms, nil
+	}
+
+	perms, err := s.repo.PermissionsByRole(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[roleID] = permissionCacheEntry{perms: perms, expires: s.now().Add(permissionCacheTTL)}
+	s.mu.Unlock()
+	return perms, nil
+}
+
+func (s *RBACService) invalidate() {
+	s.mu.Lock()
+	s.cache = map[string]permissionCacheEntry{}
+	s.mu.Unlock()
+}
+
+func markRole(r model.Role) model.Role {
+	r.System = isSystemRole(r.Name)
+	return r
+}
+
+func markPermission(p model.Permission) model.Permission {
+	p.System = isSystemPermission(p.Name)
+	return p
+}
+
+func rbacNotFound(c *fiber.Ctx, err error) error {
+	if errors.Is(err, repository.ErrRoleNotFound) || errors.Is(err, repository.ErrPermissionNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
+
+// ===============================
+// ROLES
+// ===============================
+
+// ListRoles godoc
+// @Summary List roles
+// @Description Daftar role beserta permission dan jumlah user (Admin)
+// @Tags RBAC
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} model.Role
+// @Router /roles [get]
+func (s *RBACService) ListRoles(c *fiber.Ctx) error {
+	roles, err := s.repo.ListRoles(context.Background())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	for i := range roles {
+		roles[i] = markRole(roles[i])
+	}
+	return c.JSON(roles)
+}
+
+// GetRole godoc
+// @Summary Get role
+// @Tags RBAC
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Role ID"
+// @Success 200 {object} model.Role
+// @Failure 404 {object} map[string]string
+// @Router /roles/{id} [get]
+func (s *RBACService) GetRole(c *fiber.Ctx) error {
+	role, err := s.repo.GetRole(context.Background(), c.Params("id"))
+	if err != nil {
+		return rbacNotFound(c, err)
+	}
+	return c.JSON(markRole(role))
+}
+
+// validateRole: nama wajib & unik (tidak peka huruf besar/kecil)
+func (s *RBACService) validateRole(ctx context.Context, id string, req model.RoleRequest) error {
+	errs := []model.FieldError{}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 50 {
+		errs = append(errs, model.FieldError{Field: "name", Message: "is required and must be at most 50 characters"})
+	}
+
+	roles, err := s.repo.ListRoles(ctx)
+	if err != nil {
+		return err
+	}
+	for _, r := range roles {
+		if r.ID != id && strings.EqualFold(r.Name, name) {
+			errs = append(errs, model.FieldError{Field: "name", Message: "role " + r.Name + " already exists"})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Fields: errs}
+	}
+	return nil
+}
+
+// CreateRole godoc
+// @Summary Create role
+// @Tags RBAC
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body model.RoleRequest true "Role"
+// @Success 201 {object} model.Role
+// @Failure 400 {object} map[string]any
+// @Router /roles [post]
+func (s *RBACService) CreateRole(c *fiber.Ctx) error {
+	var req model.RoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	ctx := context.Background()
+	if err := s.validateRole(ctx, "", req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	role := model.Role{Name: strings.TrimSpace(req.Name), Description: req.Description, Permissions: []string{}}
+	if err := s.repo.CreateRole(ctx, &role); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(markRole(role))
+}
+
+// UpdateRole godoc
+// @Summary Update role
+// @Description Mengubah nama & deskripsi role; role bawaan tidak dapat diganti nama (Admin)
+// @Tags RBAC
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID"
+// @Param request body model.RoleRequest true "Role"
+// @Success 200 {object} model.Role
+// @Failure 400 {object} map[string]any
+// @Failure 409 {object} map[string]string
+// @Router /roles/{id} [put]
+func (s *RBACService) UpdateRole(c *fiber.Ctx) error {
+	var req model.RoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	ctx := context.Background()
+	role, err := s.repo.GetRole(ctx, c.Params("id"))
+	if err != nil {
+		return rbacNotFound(c, err)
+	}
+	if isSystemRole(role.Name) && strings.TrimSpace(req.Name) != role.Name {
+		return conflict(c, fmt.Errorf("role %s is built in and cannot be renamed", role.Name))
+	}
+	if err := s.validateRole(ctx, role.ID, req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	role.Name, role.Description = strings.TrimSpace(req.Name), req.Description
+	if err := s.repo.UpdateRole(ctx, &role); err != nil {
+		return rbacNotFound(c, err)
+	}
+	return c.JSON(markRole(role))
+}
+
+// DeleteRole godoc
+// @Summary Delete role
+// @Description Menghapus role yang tidak bawaan dan tidak dipakai user (Admin)
+// @Tags RBAC
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Role ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /roles/{id} [delete]
+func (s *RBACService) DeleteRole(c *fiber.Ctx) error {
+	ctx := context.Background()
+	role, err := s.repo.GetRole(ctx, c.Params("id"))
+	if err != nil {
+		return rbacNotFound(c, err)
+	}
+	if isSystemRole(role.Name) {
+		return conflict(c, fmt.Errorf("role %s is built in and cannot be deleted", role.Name))
+	}
+	if role.Users > 0 {
+		return conflict(c, fmt.Errorf("role %s is still assigned to %d user(s)", role.Name, role.Users))
+	}
+
+	if err := s.repo.DeleteRole(ctx, role.ID); err != nil {
+		return rbacNotFound(c, err)
+	}
+	s.invalidate()
+	return c.JSON(fiber.Map{"message": "role deleted"})
+}
+
+// SetRolePermissions godoc
+// @Summary Replace role permissions
+// @Description Mengganti seluruh permission role. role:manage tidak dapat dicabut dari role sendiri (Admin)
+// @Tags RBAC
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID"
+// @Param request body model.RolePermissionsRequest true "Permission names"
+// @Success 200 {object} model.Role
+// @Failure 400 {object} map[string]any
+// @Failure 409 {object} map[string]string
+// @Router /roles/{id}/permissions [put]
+func (s *RBACService) SetRolePermissions(c *fiber.Ctx) error {
+	var req model.RolePermissionsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	ctx := context.Background()
+	role, err := s.repo.GetRole(ctx, c.Params("id"))
+	if err != nil {
+		return rbacNotFound(c, err)
+	}
+
+	all, err := s.repo.ListPermissions(ctx)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	known := map[string]bool{}
+	for _, p := range all {
+		known[p.Name] = true
+	}
+
+	names := []string{}
+	errs := []model.FieldError{}
+	for i, n := range req.Permissions {
+		n = strings.TrimSpace(n)
+		if !known[n] {
+			errs = append(errs, model.FieldError{Field: fmt.Sprintf("permissions[%d]", i), Message: "unknown permission " + n})
+			continue
+		}
+		if !containsString(names, n) {
+			names = append(names, n)
+		}
+	}
+	if len(errs) > 0 {
+		return validationFailed(c, &ValidationError{Fields: errs})
+	}
+
+	claims := c.Locals("user").(jwt.MapClaims)
+	if claims["role_id"] == role.ID && !containsString(names, PermRoleManage) {
+		return conflict(c, fmt.Errorf("%s cannot be removed from your own role", PermRoleManage))
+	}
+
+	if err := s.repo.SetRolePermissions(ctx, role.ID, names); err != nil {
+		return rbacNotFound(c, err)
+	}
+	s.invalidate()
+
+	role, err = s.repo.GetRole(ctx, role.ID)
+	if err != nil {
+		return rbacNotFound(c, err)
+	}
+	return c.JSON(markRole(role))
+}
+
+// ===============================
+// PERMISSIONS
+// ===============================
+
+// ListPermissions godoc
+// @Summary List permissions
+// @Tags RBAC
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} model.Permission
+// @Router /permissions [get]
+func (s *RBACService) ListPermissions(c *fiber.Ctx) error {
+	perms, err := s.repo.ListPermissions(context.Background())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	for i := range perms {
+		perms[i] = markPermission(perms[i])
+	}
+	return c.JSON(perms)
+}
+
+// validatePermission: nama berbentuk resource:action dan unik
+func (s *RBACService) validatePermission(ctx context.Context, id string, req model.PermissionRequest) error {
+	errs := []model.FieldError{}
+	name := strings.TrimSpace(req.Name)
+	if !permissionNamePattern.MatchString(name) || len(name) > 100 {
+		errs = append(errs, model.FieldError{Field: "name", Message: "must be in resource:action format (lowercase letters, digits, underscore)"})
+	}
+
+	perms, err := s.repo.ListPermissions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range perms {
+		if p.ID != id && p.Name == name {
+			errs = append(errs, model.FieldError{Field: "name", Message: "permission " + name + " already exists"})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Fields: errs}
+	}
+	return nil
+}
+
+// CreatePermission godoc
+// @Summary Create permission
+// @Tags RBAC
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body model.PermissionRequest true "Permission"
+// @Success 201 {object} model.Permission
+// @Failure 400 {object} map[string]any
+// @Router /permissions [post]
+func (s *RBACService) CreatePermission(c *fiber.Ctx) error {
+	var req model.PermissionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	ctx := context.Background()
+	if err := s.validatePermission(ctx, "", req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	p := model.Permission{Name: strings.TrimSpace(req.Name), Description: req.Description}
+	if err := s.repo.CreatePermission(ctx, &p); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(markPermission(p))
+}
+
+// UpdatePermission godoc
+// @Summary Update permission
+// @Description Permission yang dipakai route tidak dapat diganti nama (Admin)
+// @Tags RBAC
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Permission ID"
+// @Param request body model.PermissionRequest true "Permission"
+// @Success 200 {object} model.Permission
+// @Failure 400 {object} map[string]any
+// @Failure 409 {object} map[string]string
+// @Router /permissions/{id} [put]
+func (s *RBACService) UpdatePermission(c *fiber.Ctx) error {
+	var req model.PermissionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	ctx := context.Background()
+	p, err := s.repo.GetPermission(ctx, c.Params("id"))
+	if err != nil {
+		return rbacNotFound(c, err)
+	}
+	if isSystemPermission(p.Name) && strings.TrimSpace(req.Name) != p.Name {
+		return conflict(c, fmt.Errorf("permission %s is used by routes and cannot be renamed", p.Name))
+	}
+	if err := s.validatePermission(ctx, p.ID, req); err != nil {
+		return validationFailed(c, err)
+	}
+
+	p.Name, p.Description = strings.TrimSpace(req.Name), req.Description
+	if err := s.repo.UpdatePermission(ctx, &p); err != nil {
+		return rbacNotFound(c, err)
+	}
+	s.invalidate()
+	return c.JSON(markPermission(p))
+}
+
+// DeletePermission godoc
+// @Summary Delete permission
+// @Description Permission yang dipakai route tidak dapat dihapus (Admin)
+// @Tags RBAC
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Permission ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /permissions/{id} [delete]
+func (s *RBACService) DeletePermission(c *fiber.Ctx) error {
+	ctx := context.Background()
+	p, err := s.repo.GetPermission(ctx, c.Params("id"))
+	if err != nil {
+		return rbacNotFound(c, err)
+	}
+	if isSystemPermission(p.Name) {
+		return conflict(c, fmt.Errorf("permission %s is used by routes and cannot be deleted", p.Name))
+	}
+
+	if err := s.repo.DeletePermission(ctx, p.ID); err != nil {
+		return rbacNotFound(c, err)
+	}
+	s.invalidate()
+	return c.JSON(fiber.Map{"message": "permission deleted"})
+}
+
+// usersWithPermission: user aktif yang role-nya memiliki permission tersebut.
+// Penerima eskalasi mengikuti pengaturan role & permission, bukan nama role.
+func usersWithPermission(ctx context.Context, users []model.User, perms middleware.PermissionResolver, permission string) []model.User {
+	out := []model.User{}
+	if perms == nil {
+		return out
+	}
+	granted := map[string]bool{} // role id → punya permission
+	for _, u := range users {
+		if !u.IsActive || u.RoleID == "" {
+			continue
+		}
+		ok, seen := granted[u.RoleID]
+		if !seen {
+			list, err := perms.PermissionsForRole(ctx, u.RoleID)
+			if err != nil {
+				log.Printf("⚠️ gagal membaca permission role %s: %v", u.RoleID, err)
+			}
+			ok = containsString(list, permission)
+			granted[u.RoleID] = ok
+		}
+		if ok {
+			out = append(out, u)
+		}
+	}
+	return out
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+	"backenduas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// seedSystemRoles membuat role bawaan dan mengembalikan peta nama → id.
+func seedSystemRoles(repo *repository.MockRBACRepository) map[string]string {
+	roles := map[string]string{}
+	for _, name := range systemRoles {
+		r := model.Role{Name: name}
+		repo.CreateRole(context.Background(), &r)
+		roles[name] = r.ID
+	}
+	return roles
+}
+
+// rbacApp memasang claims role tertentu lalu route RBAC seperti di routes/rbac_routes.go
+func rbacApp(svc *RBACService, roleID, role string) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user", jwt.MapClaims{"user_id": "u1", "role_id": roleID, "role_name": role})
+		return c.Next()
+	})
+	guard := middleware.RequirePermission(PermRoleManage)
+	app.Get("/roles", guard, svc.ListRoles)
+	app.Post("/roles", guard, svc.CreateRole)
+	app.Delete("/roles/:id", guard, svc.DeleteRole)
+	app.Put("/roles/:id/permissions", guard, svc.SetRolePermissions)
+	app.Post("/permissions", guard, svc.CreatePermission)
+	app.Put("/permissions/:id", guard, svc.UpdatePermission)
+	app.Delete("/permissions/:id", guard, svc.DeletePermission)
+	return app
+}
+
+func call(t *testing.T, app *fiber.App, method, path, body string) (int, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	out := map[string]interface{}{}
+	json.Unmarshal(raw, &out)
+	return resp.StatusCode, out
+}
+
+func permissionID(repo *repository.MockRBACRepository, name string) string {
+	for id, p := range repo.Permissions {
+		if p.Name == name {
+			return id
+		}
+	}
+	return ""
+}
+
+func TestRBACSeed_GrantsOnlyNewPermissions(t *testing.T) {
+	repo := repository.NewMockRBACRepository()
+	roles := seedSystemRoles(repo)
+	svc := NewRBACService(repo)
+	if err := svc.Seed(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if len(repo.Permissions) != len(permissionCatalog) {
+		t.Fatalf("expected %d permissions, got %d", len(permissionCatalog), len(repo.Permissions))
+	}
+	perms, _ := repo.PermissionsByRole(ctx, roles["Dosen Wali"])
+	if !containsString(perms, "achievement:verify") || containsString(perms, "achievement:create") {
+		t.Errorf("unexpected lecturer permissions: %v", perms)
+	}
+
+	// pengaturan Admin tidak tertimpa saat start berikutnya
+	repo.SetRolePermissions(ctx, roles["Dosen Wali"], []string{"achievement:read"})
+	svc.Seed(ctx)
+	if perms, _ := repo.PermissionsByRole(ctx, roles["Dosen Wali"]); len(perms) != 1 {
+		t.Errorf("seed must not re-grant existing permissions, got %v", perms)
+	}
+}
+
+func TestRequirePermission_ResolvesFromRole(t *testing.T) {
+	repo := repository.NewMockRBACRepository()
+	roles := seedSystemRoles(repo)
+	svc := NewRBACService(repo)
+	if err := svc.Seed(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	middleware.UsePermissionResolver(svc)
+	defer middleware.UsePermissionResolver(nil)
+
+	if code, _ := call(t, rbacApp(svc, roles["Admin"], "Admin"), "GET", "/roles", ""); code != 200 {
+		t.Errorf("admin must pass role:manage, got %d", code)
+	}
+	// handler membaca permission hasil resolver, bukan yang di-embed di JWT
+	app := rbacApp(svc, roles["Admin"], "Admin")
+	app.Get("/scope", middleware.RequirePermission("report:read"), func(c *fiber.Ctx) error {
+		if !containsString(middleware.Permissions(c), PermScopeAll) {
+			return c.SendStatus(403)
+		}
+		return c.SendStatus(204)
+	})
+	if code, _ := call(t, app, "GET", "/scope", ""); code != 204 {
+		t.Errorf("resolved permissions must reach the handler, got %d", code)
+	}
+
+	code, body := call(t, rbacApp(svc, roles["Mahasiswa"], "Mahasiswa"), "GET", "/roles", "")
+	if code != 403 || body["permission"] != PermRoleManage {
+		t.Errorf("student must be forbidden, got %d %v", code, body)
+	}
+
+	// tanpa resolver: permission dari JWT
+	middleware.UsePermissionResolver(nil)
+	app = fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user", jwt.MapClaims{"permissions": []interface{}{"report:read"}})
+		return c.Next()
+	})
+	app.Get("/ok", middleware.RequirePermission("report:read"), func(c *fiber.Ctx) error { return c.SendStatus(204) })
+	app.Get("/no", middleware.RequirePermission("report:manage"), func(c *fiber.Ctx) error { return c.SendStatus(204) })
+	if code, _ := call(t, app, "GET", "/ok", ""); code != 204 {
+		t.Errorf("embedded permission must be accepted, got %d", code)
+	}
+	if code, _ := call(t, app, "GET", "/no", ""); code != 403 {
+		t.Errorf("missing embedded permission must be rejected, got %d", code)
+	}
+}
+
+func TestPermissionsForRole_CacheInvalidatedOnChange(t *testing.T) {
+	repo := repository.NewMockRBACRepository()
+	roles := seedSystemRoles(repo)
+	svc := NewRBACService(repo)
+	if err := svc.Seed(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	middleware.UsePermissionResolver(svc)
+	defer middleware.UsePermissionResolver(nil)
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	student := roles["Mahasiswa"]
+	svc.PermissionsForRole(ctx, student)
+	svc.PermissionsForRole(ctx, student)
+	if repo.Lookups != 1 {
+		t.Errorf("expected cached lookup, got %d queries", repo.Lookups)
+	}
+
+	now = now.Add(permissionCacheTTL)
+	svc.PermissionsForRole(ctx, student)
+	if repo.Lookups != 2 {
+		t.Errorf("cache must expire after the TTL, got %d queries", repo.Lookups)
+	}
+
+	// perubahan Admin langsung berlaku
+	code, _ := call(t, rbacApp(svc, roles["Admin"], "Admin"), "PUT", "/roles/"+student+"/permissions", `{"permissions":["achievement:read","report:read"]}`)
+	if code != 200 {
+		t.Fatalf("unexpected status %d", code)
+	}
+	perms, _ := svc.PermissionsForRole(ctx, student)
+	if len(perms) != 2 || containsString(perms, "achievement:create") {
+		t.Errorf("expected updated permissions, got %v", perms)
+	}
+}
+
+func TestRBACAdmin_ValidationAndProtection(t *testing.T) {
+	repo := repository.NewMockRBACRepository()
+	roles := seedSystemRoles(repo)
+	svc := NewRBACService(repo)
+	if err := svc.Seed(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	middleware.UsePermissionResolver(svc)
+	defer middleware.UsePermissionResolver(nil)
+	app := rbacApp(svc, roles["Admin"], "Admin")
+
+	code, body := call(t, app, "POST", "/roles", `{"name":"mahasiswa"}`)
+	if code != 400 || body["fields"] == nil {
+		t.Errorf("duplicate role name must be a validation error, got %d %v", code, body)
+	}
+	if code, _ := call(t, app, "POST", "/permissions", `{"name":"Export Data"}`); code != 400 {
+		t.Errorf("permission name must be resource:action, got %d", code)
+	}
+	if code, _ := call(t, app, "PUT", "/roles/"+roles["Mahasiswa"]+"/permissions", `{"permissions":["achievement:fly"]}`); code != 400 {
+		t.Errorf("unknown permission must be rejected, got %d", code)
+	}
+
+	// role bawaan & role yang masih dipakai user
+	if code, _ := call(t, app, "DELETE", "/roles/"+roles["Dosen Wali"], ""); code != 409 {
+		t.Errorf("built-in role must not be deleted, got %d", code)
+	}
+	code, body = call(t, app, "POST", "/roles", `{"name":"Kaprodi","description":"Ketua program studi"}`)
+	if code != 201 {
+		t.Fatalf("unexpected status %d %v", code, body)
+	}
+	kaprodi := body["id"].(string)
+	repo.Users[kaprodi] = 2
+	if code, _ := call(t, app, "DELETE", "/roles/"+kaprodi, ""); code != 409 {
+		t.Errorf("role in use must not be deleted, got %d", code)
+	}
+	repo.Users[kaprodi] = 0
+	if code, _ := call(t, app, "DELETE", "/roles/"+kaprodi, ""); code != 200 {
+		t.Errorf("unused role must be deleted, got %d", code)
+	}
+
+	// Admin tidak dapat mengunci dirinya sendiri
+	if code, _ := call(t, app, "PUT", "/roles/"+roles["Admin"]+"/permissions", `{"permissions":["user:manage"]}`); code != 409 {
+		t.Errorf("removing role:manage from own role must conflict, got %d", code)
+	}
+
+	// permission yang dipakai route
+	verify := permissionID(repo, "achievement:verify")
+	if code, _ := call(t, app, "DELETE", "/permissions/"+verify, ""); code != 409 {
+		t.Errorf("system permission must not be deleted, got %d", code)
+	}
+	if code, _ := call(t, app, "PUT", "/permissions/"+verify, `{"name":"achievement:approve"}`); code != 409 {
+		t.Errorf("system permission must not be renamed, got %d", code)
+	}
+
+	code, body = call(t, app, "POST", "/permissions", `{"name":"report:export","description":"Ekspor laporan"}`)
+	if code != 201 || body["resource"] != "report" || body["action"] != "export" || body["system"] != false {
+		t.Fatalf("unexpected permission %d %v", code, body)
+	}
+	if code, _ := call(t, app, "DELETE", "/permissions/"+body["id"].(string), ""); code != 200 {
+		t.Errorf("custom permission must be deletable, got %d", code)
+	}
+}
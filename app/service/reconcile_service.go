@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sumber kebenaran saat memperbaiki selisih
+const (
+	SourcePG    = "pg"
+	SourceMongo = "mongo"
+)
+
+var ErrInvalidSource = errors.New("source must be 'pg' or 'mongo'")
+
+// ReconcileActor = actor_role riwayat untuk perubahan oleh reconcile
+const ReconcileActor = "reconcile"
+
+type ReconcileService struct {
+	pgRepo      repository.IAchievementPGRepository
+	mongoRepo   repository.IAchievementMongoRepository
+	historyRepo repository.IAchievementHistoryRepository
+	stats       *StatsMaterializer
+}
+
+func NewReconcileService(
+	pg repository.IAchievementPGRepository,
+	mg repository.IAchievementMongoRepository,
+	hs repository.IAchievementHistoryRepository,
+	stats *StatsMaterializer,
+) *ReconcileService {
+	return &ReconcileService{pgRepo: pg, mongoRepo: mg, historyRepo: hs, stats: stats}
+}
+
+// =====================================================
+//
+//	SCAN + (OPTIONAL) FIX
+//
+// =====================================================
+//
+// fix=false → hanya laporan. fix=true → perbaiki sesuai source:
+//
+//	orphaned_document  : pg → hapus dokumen Mongo, mongo → buat reference baru
+//	dangling_reference : pg → tidak bisa (detail hilang), mongo → soft delete reference
+//	status_mismatch    : pg → set status Mongo, mongo → set status PostgreSQL
+//	                     (hanya transisi sah; keputusan review tidak diambil dari Mongo)
+//
+// Setiap perubahan PostgreSQL dicatat di riwayat dengan actor "reconcile".
+func (s *ReconcileService) Reconcile(ctx context.Context, fix bool, source string) (model.ReconcileReport, error) {
+	report := model.ReconcileReport{
+		GeneratedAt: time.Now(),
+		Fix:         fix,
+		Items:       []model.ReconcileItem{},
+	}
+
+	if fix {
+		if source != SourcePG && source != SourceMongo {
+			return report, ErrInvalidSource
+		}
+		report.Source = source
+	}
+
+	refs, err := s.pgRepo.GetAll(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	docs, err := s.mongoRepo.FindAll(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	report.Summary.PGReferences = len(refs)
+	report.Summary.MongoDocuments = len(docs)
+
+	docMap := make(map[string]model.AchievementMongo, len(docs))
+	for _, d := range docs {
+		docMap[d.ID.Hex()] = d
+	}
+
+	referenced := make(map[string]bool, len(refs))
+
+	// ---------- dari sisi PostgreSQL ----------
+	for _, ref := range refs {
+		referenced[ref.MongoAchievementID] = true
+
+		doc, ok := docMap[ref.MongoAchievementID]
+		if !ok && ref.Status == StatusDeleted {
+			continue // dangling yang sudah di-soft delete
+		}
+		if !ok {
+			item := model.ReconcileItem{
+				Kind:          model.ReconcileDanglingRef,
+				AchievementID: ref.ID,
+				MongoID:       ref.MongoAchievementID,
+				StudentID:     ref.StudentID,
+				PGStatus:      ref.Status,
+			}
+			report.Summary.DanglingReferences++
+			if fix {
+				s.fixDangling(ctx, &item, source)
+			}
+			report.Items = append(report.Items, item)
+			continue
+		}
+
+		if doc.Status != ref.Status {
+			item := model.ReconcileItem{
+				Kind:          model.ReconcileStatusMismatch,
+				AchievementID: ref.ID,
+				MongoID:       ref.MongoAchievementID,
+				StudentID:     ref.StudentID,
+				PGStatus:      ref.Status,
+				MongoStatus:   doc.Status,
+			}
+			report.Summary.StatusMismatches++
+			if fix {
+				s.fixMismatch(ctx, &item, source)
+			}
+			report.Items = append(report.Items, item)
+		}
+	}
+
+	// ---------- dari sisi MongoDB ----------
+	for _, doc := range docs {
+		if referenced[doc.ID.Hex()] {
+			continue
+		}
+		item := model.ReconcileItem{
+			Kind:        model.ReconcileOrphanedDocument,
+			MongoID:     doc.ID.Hex(),
+			StudentID:   doc.StudentID,
+			MongoStatus: doc.Status,
+		}
+		report.Summary.OrphanedDocuments++
+		if fix {
+			s.fixOrphan(ctx, &item, doc, source)
+		}
+		report.Items = append(report.Items, item)
+	}
+
+	if fix {
+		for _, item := range report.Items {
+			if item.Fixed {
+				report.Summary.Fixed++
+			} else {
+				report.Summary.Unfixable++
+			}
+		}
+	}
+
+	return report, nil
+}
+
+func (s *ReconcileService) fixDangling(ctx context.Context, item *model.ReconcileItem, source string) {
+	if source == SourcePG {
+		item.Action = "none"
+		item.Error = "mongo detail is missing and cannot be restored from PostgreSQL"
+		return
+	}
+
+	// data rusak: soft delete dari status apa pun, riwayat tetap tersimpan
+	item.Action = "soft_delete_reference"
+	setFixResult(item, s.changeStatus(ctx, item, StatusDeleted, "mongo detail is missing"))
+}
+
+func (s *ReconcileService) fixMismatch(ctx context.Context, item *model.ReconcileItem, source string) {
+	if source == SourcePG {
+		item.Action = "set_mongo_status"
+		oid, _ := primitive.ObjectIDFromHex(item.MongoID)
+		setFixResult(item, s.mongoRepo.Update(ctx, oid, bson.M{"status": item.PGStatus}))
+		return
+	}
+
+	if !CanTransition(item.PGStatus, item.MongoStatus) {
+		item.Action = "none"
+		item.Error = (&TransitionError{From: item.PGStatus, To: item.MongoStatus}).Error()
+		return
+	}
+	// verify / reject butuh dosen penanggung jawab yang tidak ada di Mongo
+	if item.MongoStatus == StatusVerified || item.MongoStatus == StatusRejected {
+		item.Action = "none"
+		item.Error = "review decision " + item.MongoStatus + " must be made by a lecturer"
+		return
+	}
+
+	item.Action = "set_pg_status"
+	setFixResult(item, s.changeStatus(ctx, item, item.MongoStatus, "status follows MongoDB"))
+}
+
+// changeStatus: UPDATE bersyarat + riwayat actor reconcile dalam satu transaksi,
+// lalu statistik prestasi tersebut disegarkan
+func (s *ReconcileService) changeStatus(ctx context.Context, item *model.ReconcileItem, to, note string) error {
+	err := s.pgRepo.ChangeStatus(ctx, model.StatusChange{
+		AchievementID: item.AchievementID,
+		From:          item.PGStatus,
+		To:            to,
+		History: &model.AchievementHistory{
+			AchievementID: item.AchievementID,
+			ActorRole:     ReconcileActor,
+			FromStatus:    item.PGStatus,
+			ToStatus:      to,
+			Note:          note,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	s.stats.Refresh(ctx, item.AchievementID)
+	return nil
+}
+
+func (s *ReconcileService) fixOrphan(ctx context.Context, item *model.ReconcileItem, doc model.AchievementMongo, source string) {
+	if source == SourcePG {
+		item.Action = "delete_document"
+		setFixResult(item, s.mongoRepo.Delete(ctx, doc.ID))
+		return
+	}
+
+	if doc.StudentID == "" {
+		item.Action = "none"
+		item.Error = "document has no studentId"
+		return
+	}
+
+	status := doc.Status
+	if status == "" {
+		status = StatusDraft
+	}
+
+	ref := model.AchievementReference{
+		ID:                 uuid.New().String(),
+		StudentID:          doc.StudentID,
+		MongoAchievementID: doc.ID.Hex(),
+		Status:             status,
+	}
+
+	item.Action = "create_reference"
+	item.AchievementID = ref.ID
+	if err := s.pgRepo.CreateReference(ref, &model.AchievementHistory{
+		AchievementID: ref.ID,
+		ActorRole:     ReconcileActor,
+		ToStatus:      status,
+		Note:          "reference created from MongoDB document",
+	}); err != nil {
+		setFixResult(item, err)
+		return
+	}
+	s.stats.Refresh(ctx, ref.ID)
+	setFixResult(item, nil)
+}
+
+func setFixResult(item *model.ReconcileItem, err error) {
+	if err != nil {
+		item.Error = err.Error()
+		return
+	}
+	item.Fixed = true
+}
+
+// =====================================================
+//
+//	ADMIN ENDPOINT
+//
+// =====================================================
+// ReconcileReport godoc
+// @Summary Reconcile report (dry run)
+// @Description Scan orphaned Mongo documents, dangling references and status mismatches between PostgreSQL and MongoDB (Admin only)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} model.ReconcileReport
+// @Failure 500 {object} map[string]string
+// @Router /admin/reconcile [get]
+func (s *ReconcileService) Report(c *fiber.Ctx) error {
+	report, err := s.Reconcile(context.Background(), false, "")
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(report)
+}
+
+// ReconcileFix godoc
+// @Summary Reconcile and fix
+// @Description Repair drift between PostgreSQL and MongoDB using the chosen source of truth (Admin only)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param source query string true "Source of truth (pg | mongo)"
+// @Success 200 {object} model.ReconcileReport
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/reconcile [post]
+func (s *ReconcileService) Fix(c *fiber.Ctx) error {
+	report, err := s.Reconcile(context.Background(), true, c.Query("source"))
+	if errors.Is(err, ErrInvalidSource) {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(report)
+}
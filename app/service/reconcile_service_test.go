@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// satu contoh untuk tiap jenis selisih
+func seedDrift() (*repository.MockAchievementPGRepository, *repository.MockAchievementMongoRepository, primitive.ObjectID) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+
+	// konsisten
+	ok := primitive.NewObjectID()
+	pg.Data["ok"] = model.AchievementReference{ID: "ok", StudentID: "s1", MongoAchievementID: ok.Hex(), Status: StatusDraft}
+	mg.Data[ok.Hex()] = model.AchievementMongo{StudentID: "s1", Status: StatusDraft}
+
+	// status mismatch
+	mm := primitive.NewObjectID()
+	pg.Data["mm"] = model.AchievementReference{ID: "mm", StudentID: "s1", MongoAchievementID: mm.Hex(), Status: StatusVerified}
+	mg.Data[mm.Hex()] = model.AchievementMongo{StudentID: "s1", Status: StatusSubmitted}
+
+	// dangling reference
+	pg.Data["dg"] = model.AchievementReference{ID: "dg", StudentID: "s1", MongoAchievementID: primitive.NewObjectID().Hex(), Status: StatusDraft}
+
+	// orphaned document
+	orphan := primitive.NewObjectID()
+	mg.Data[orphan.Hex()] = model.AchievementMongo{StudentID: "s2", Status: StatusSubmitted}
+
+	return pg, mg, orphan
+}
+
+func TestReconcile_ReportOnly(t *testing.T) {
+	pg, mg, _ := seedDrift()
+	svc := NewReconcileService(pg, mg, nil, nil)
+
+	report, err := svc.Reconcile(context.Background(), false, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sum := report.Summary
+	if sum.PGReferences != 3 || sum.MongoDocuments != 3 {
+		t.Fatalf("unexpected totals: %+v", sum)
+	}
+	if sum.OrphanedDocuments != 1 || sum.DanglingReferences != 1 || sum.StatusMismatches != 1 {
+		t.Fatalf("unexpected summary: %+v", sum)
+	}
+	if sum.Fixed != 0 || len(pg.Data) != 3 || len(mg.Data) != 3 {
+		t.Fatalf("dry run must not change data")
+	}
+}
+
+func TestReconcile_FixFromPG(t *testing.T) {
+	pg, mg, orphan := seedDrift()
+	svc := NewReconcileService(pg, mg, nil, nil)
+
+	report, err := svc.Reconcile(context.Background(), true, SourcePG)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Summary.Fixed != 2 || report.Summary.Unfixable != 1 {
+		t.Fatalf("unexpected summary: %+v", report.Summary)
+	}
+	if _, ok := mg.Data[orphan.Hex()]; ok {
+		t.Fatalf("orphaned document should be deleted")
+	}
+	if mg.Data[pg.Data["mm"].MongoAchievementID].Status != StatusVerified {
+		t.Fatalf("mongo status should follow PostgreSQL")
+	}
+}
+
+func TestReconcile_FixFromMongo(t *testing.T) {
+	pg, mg, orphan := seedDrift()
+	hs := repository.NewMockAchievementHistoryRepository()
+	pg.History = hs
+	svc := NewReconcileService(pg, mg, hs, nil)
+
+	report, err := svc.Reconcile(context.Background(), true, SourceMongo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// verified → submitted bukan transisi sah → dilaporkan, tidak diubah
+	if report.Summary.Fixed != 2 || report.Summary.Unfixable != 1 {
+		t.Fatalf("unexpected summary: %+v", report.Summary)
+	}
+	if pg.Data["mm"].Status != StatusVerified {
+		t.Fatalf("illegal transition must not be applied, got %s", pg.Data["mm"].Status)
+	}
+
+	// dangling reference di-soft delete, bukan dihapus
+	if ref, ok := pg.Data["dg"]; !ok || ref.Status != StatusDeleted {
+		t.Fatalf("dangling reference should be soft deleted, got %+v", ref)
+	}
+
+	adopted := false
+	for _, ref := range pg.Data {
+		if ref.MongoAchievementID == orphan.Hex() && ref.StudentID == "s2" {
+			adopted = true
+		}
+	}
+	if !adopted {
+		t.Fatalf("orphaned document should get a reference")
+	}
+
+	if len(hs.Data) != 2 {
+		t.Fatalf("expected history for soft delete and adoption, got %+v", hs.Data)
+	}
+	for _, h := range hs.Data {
+		if h.ActorRole != ReconcileActor {
+			t.Fatalf("unexpected actor %q", h.ActorRole)
+		}
+	}
+
+	// run berikutnya tidak melaporkan reference yang sudah di-soft delete
+	report, _ = svc.Reconcile(context.Background(), false, "")
+	if report.Summary.DanglingReferences != 0 {
+		t.Fatalf("soft deleted reference reported again: %+v", report.Summary)
+	}
+}
+
+func TestReconcile_FixFromMongo_LegalTransition(t *testing.T) {
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	hs := repository.NewMockAchievementHistoryRepository()
+	pg.History = hs
+
+	oid := primitive.NewObjectID()
+	pg.Data["a1"] = model.AchievementReference{ID: "a1", StudentID: "s1", MongoAchievementID: oid.Hex(), Status: StatusDraft}
+	mg.Data[oid.Hex()] = model.AchievementMongo{ID: oid, StudentID: "s1", Status: StatusSubmitted}
+
+	svc := NewReconcileService(pg, mg, hs, nil)
+
+	report, err := svc.Reconcile(context.Background(), true, SourceMongo)
+	if err != nil || report.Summary.Fixed != 1 {
+		t.Fatalf("expected 1 fix, got %+v err=%v", report.Summary, err)
+	}
+	if pg.Data["a1"].Status != StatusSubmitted {
+		t.Fatalf("PostgreSQL status should follow Mongo, got %s", pg.Data["a1"].Status)
+	}
+	if len(hs.Data) != 1 || hs.Data[0].FromStatus != StatusDraft || hs.Data[0].ToStatus != StatusSubmitted || hs.Data[0].ActorRole != ReconcileActor {
+		t.Fatalf("unexpected history: %+v", hs.Data)
+	}
+}
+
+func TestReconcile_FixRequiresSource(t *testing.T) {
+	pg, mg, _ := seedDrift()
+	svc := NewReconcileService(pg, mg, nil, nil)
+
+	if _, err := svc.Reconcile(context.Background(), true, ""); err != ErrInvalidSource {
+		t.Fatalf("expected ErrInvalidSource, got %v", err)
+	}
+}
+
+func TestReconcile_FixRefreshesStatistics(t *testing.T) {
+	pg, mg, _ := seedDrift()
+	hs := repository.NewMockAchievementHistoryRepository()
+	pg.History = hs
+	repo := repository.NewMockStatsRepository()
+	stats := NewStatsMaterializer(repo, pg, mg, time.Minute)
+	svc := NewReconcileService(pg, mg, hs, stats)
+
+	if _, err := svc.Reconcile(context.Background(), true, SourceMongo); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// soft delete reference dangling + adopsi dokumen orphan
+	if repo.Applied != 2 {
+		t.Fatalf("expected statistics refresh for each PostgreSQL change, got %d", repo.Applied)
+	}
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"backenduas/app/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// =====================================================
+//  EKSPOR LAPORAN STATISTIK — ?format=csv|xlsx|pdf
+// =====================================================
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatPDF  = "pdf"
+)
+
+var errInvalidExportFormat = errors.New("format must be one of json, csv, xlsx, pdf")
+
+// exportFormat membaca ?format=; kosong = json
+func exportFormat(c *fiber.Ctx) (string, error) {
+	switch f := strings.ToLower(c.Query("format", FormatJSON)); f {
+	case FormatJSON, FormatCSV, FormatXLSX, FormatPDF:
+		return f, nil
+	default:
+		return "", errInvalidExportFormat
+	}
+}
+
+// reportTable = satu bagian laporan (per jenis, per periode, dst.)
+type reportTable struct {
+	Title   string
+	Columns []pdfColumn
+	Numeric []bool
+	Rows    [][]string
+}
+
+func (t reportTable) headers() []string {
+	h := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		h[i] = c.Header
+	}
+	return h
+}
+
+// statisticsReport = isi laporan yang sama untuk ketiga format
+type statisticsReport struct {
+	Title       string
+	Scope       string // mis. "Seluruh mahasiswa", "Mahasiswa bimbingan Dr. Budi"
+	Filter      string // ringkasan filter (describeReportFilter)
+	GeneratedAt time.Time
+	Total       int
+	Tables      []reportTable
+}
+
+type countEntry struct {
+	Key   string
+	Count int
+}
+
+// sortedCounts: jumlah terbanyak dulu, lalu nama
+func sortedCounts(m map[string]int) []countEntry {
+	out := make([]countEntry, 0, len(m))
+	for k, v := range m {
+		out = append(out, countEntry{k, v})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+func percentOf(n, total int) string {
+	if total == 0 {
+		return "0.0"
+	}
+	return strconv.FormatFloat(float64(n)*100/float64(total), 'f', 1, 64)
+}
+
+func orDash(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	return s
+}
+
+func buildStatisticsReport(title, scope string, f model.ReportFilter, stats model.StudentAchievementStat, now time.Time) statisticsReport {
+	total := 0
+	for _, n := range stats.PerType {
+		total += n
+	}
+
+	rep := statisticsReport{Title: title, Scope: scope, Filter: describeReportFilter(f), GeneratedAt: now, Total: total}
+
+	perType := reportTable{
+		Title:   "Prestasi per Jenis",
+		Columns: []pdfColumn{{Header: "Jenis Prestasi", Width: 6}, {Header: "Jumlah", Width: 2, Right: true}, {Header: "Persentase (%)", Width: 2, Right: true}},
+		Numeric: []bool{false, true, true},
+	}
+	for _, e := range sortedCounts(stats.PerType) {
+		perType.Rows = append(perType.Rows, []string{orDash(e.Key), strconv.Itoa(e.Count), percentOf(e.Count, total)})
+	}
+
+	// periode diurutkan kronologis
+	periods := make([]string, 0, len(stats.PerPeriod))
+	for p := range stats.PerPeriod {
+		periods = append(periods, p)
+	}
+	sort.Strings(periods)
+	perPeriod := reportTable{
+		Title:   "Prestasi per Periode",
+		Columns: []pdfColumn{{Header: periodLabel(f.Period), Width: 6}, {Header: "Jumlah", Width: 2, Right: true}, {Header: "Persentase (%)", Width: 2, Right: true}},
+		Numeric: []bool{false, true, true},
+	}
+	for _, p := range periods {
+		n := stats.PerPeriod[p]
+		perPeriod.Rows = append(perPeriod.Rows, []string{p, strconv.Itoa(n), percentOf(n, total)})
+	}
+
+	competition := reportTable{
+		Title:   "Tingkat Kompetisi",
+		Columns: []pdfColumn{{Header: "Tingkat / Tag", Width: 6}, {Header: "Jumlah", Width: 2, Right: true}, {Header: "Persentase (%)", Width: 2, Right: true}},
+		Numeric: []bool{false, true, true},
+	}
+	for _, e := range sortedCounts(stats.Competition) {
+		competition.Rows = append(competition.Rows, []string{orDash(e.Key), strconv.Itoa(e.Count), percentOf(e.Count, total)})
+	}
+
+	top := append([]model.TopStudentDetail(nil), stats.TopStudents...)
+	sort.SliceStable(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].FullName < top[j].FullName
+	})
+	students := reportTable{
+		Title: "Mahasiswa Berprestasi",
+		Columns: []pdfColumn{
+			{Header: "No", Width: 0.7, Right: true}, {Header: "Nama", Width: 4}, {Header: "Program Studi", Width: 3},
+			{Header: "Angkatan", Width: 1.4}, {Header: "Jumlah", Width: 1.2, Right: true},
+		},
+		Numeric: []bool{true, false, false, false, true},
+	}
+	for i, st := range top {
+		students.Rows = append(students.Rows, []string{
+			strconv.Itoa(i + 1), orDash(st.FullName), orDash(st.ProgramStudy), orDash(st.AcademicYear), strconv.Itoa(st.Count),
+		})
+	}
+
+	rep.Tables = []reportTable{perType, perPeriod, competition, students}
+	return rep
+}
+
+// ---------- CSV ----------
+// BOM UTF-8 agar Excel membaca karakter non-ASCII dengan benar
+func (r statisticsReport) csv() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	w := csv.NewWriter(&buf)
+	write := func(record ...string) {
+		safe := make([]string, len(record))
+		for i, v := range record {
+			safe[i] = spreadsheetSafe(v)
+		}
+		w.Write(safe)
+	}
+
+	write(r.Title)
+	write("Cakupan", r.Scope)
+	write("Filter", r.Filter)
+	write("Dibuat", r.GeneratedAt.Format("2006-01-02 15:04 MST"))
+	write("Total Prestasi", strconv.Itoa(r.Total))
+
+	for _, t := range r.Tables {
+		write()
+		write(t.Title)
+		write(t.headers()...)
+		for _, row := range t.Rows {
+			write(row...)
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// ---------- XLSX ----------
+func (r statisticsReport) xlsx() ([]byte, error) {
+	summary := xlsxSheet{
+		Name:   "Ringkasan",
+		Title:  r.Title,
+		Header: []string{"Keterangan", "Nilai"},
+		Rows: [][]string{
+			{"Cakupan", r.Scope},
+			{"Filter", r.Filter},
+			{"Dibuat", r.GeneratedAt.Format("2006-01-02 15:04 MST")},
+			{"Total Prestasi", strconv.Itoa(r.Total)},
+		},
+		Numeric: []bool{false, true},
+	}
+	sheets := []xlsxSheet{summary}
+	for _, t := range r.Tables {
+		sheets = append(sheets, xlsxSheet{
+			Name:    t.Title,
+			Title:   t.Title,
+			Header:  t.headers(),
+			Rows:    t.Rows,
+			Numeric: t.Numeric,
+		})
+	}
+
+	var buf bytes.Buffer
+	err := writeXLSX(&buf, r.Title, r.GeneratedAt, sheets)
+	return buf.Bytes(), err
+}
+
+// ---------- PDF ----------
+func (r statisticsReport) pdf() ([]byte, error) {
+	doc := newPDFDocument(r.Title, r.GeneratedAt)
+	doc.Footer = "Sistem Prestasi Mahasiswa — dicetak " + r.GeneratedAt.Format("02-01-2006 15:04")
+	l := newPDFLayout(doc)
+
+	l.Heading(r.Title, 16)
+	l.KeyValue("Cakupan", r.Scope, 10, 90)
+	l.KeyValue("Filter", r.Filter, 10, 90)
+	l.KeyValue("Tanggal", r.GeneratedAt.Format("02-01-2006 15:04 MST"), 10, 90)
+	l.KeyValue("Total Prestasi", strconv.Itoa(r.Total), 10, 90)
+
+	for _, t := range r.Tables {
+		l.Space(14)
+		l.Heading(t.Title, 12)
+		if len(t.Rows) == 0 {
+			l.Paragraph("Belum ada data.", 9)
+			continue
+		}
+		l.Table(t.Columns, t.Rows, 9)
+	}
+
+	var buf bytes.Buffer
+	_, err := doc.WriteTo(&buf)
+	return buf.Bytes(), err
+}
+
+// exportSlug: nilai pertama yang tidak kosong, hanya huruf/angka/-/_ (aman untuk nama file)
+func exportSlug(values ...string) string {
+	for _, v := range values {
+		slug := strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+				return r
+			case r == ' ':
+				return '-'
+			}
+			return -1
+		}, strings.TrimSpace(v))
+		if slug != "" {
+			return slug
+		}
+	}
+	return "laporan"
+}
+
+// sendStatisticsExport mengirim laporan sebagai file unduhan
+func sendStatisticsExport(c *fiber.Ctx, format, filename string, rep statisticsReport) error {
+	var body []byte
+	var contentType string
+	var err error
+
+	switch format {
+	case FormatCSV:
+		body, err = rep.csv()
+		contentType = "text/csv; charset=utf-8"
+	case FormatXLSX:
+		body, err = rep.xlsx()
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatPDF:
+		body, err = rep.pdf()
+		contentType = "application/pdf"
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": errInvalidExportFormat.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set("Content-Type", contentType)
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, format))
+	return c.Send(body)
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"backenduas/app/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var exportNow = time.Date(2026, 4, 1, 10, 30, 0, 0, time.UTC)
+
+func sampleStatisticsReport() statisticsReport {
+	stats := model.StudentAchievementStat{
+		PerType:     map[string]int{"competition": 3, "publication": 1},
+		PerPeriod:   map[string]int{"2026": 1, "2025": 3},
+		Competition: map[string]int{"Nasional": 2, "Internasional": 1},
+		TopStudents: []model.TopStudentDetail{
+			{StudentID: "s2", FullName: "Citra", ProgramStudy: "SI", AcademicYear: "2022", Count: 1},
+			{StudentID: "s1", FullName: "Andi Pratama", ProgramStudy: "Teknik Informatika", AcademicYear: "2021", Count: 3},
+		},
+	}
+	return buildStatisticsReport("Laporan Statistik Prestasi Mahasiswa", "Seluruh mahasiswa", model.ReportFilter{Statuses: []string{StatusVerified}}, stats, exportNow)
+}
+
+func TestBuildStatisticsReport_SortedSections(t *testing.T) {
+	rep := sampleStatisticsReport()
+
+	if rep.Total != 4 || len(rep.Tables) != 4 {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+	if got := rep.Tables[0].Rows[0]; got[0] != "competition" || got[1] != "3" || got[2] != "75.0" {
+		t.Errorf("per type must be sorted by count: %v", got)
+	}
+	if rep.Tables[1].Rows[0][0] != "2025" {
+		t.Errorf("periods must be chronological: %v", rep.Tables[1].Rows)
+	}
+	if got := rep.Tables[3].Rows[0]; got[0] != "1" || got[1] != "Andi Pratama" {
+		t.Errorf("top students must be ranked by count: %v", got)
+	}
+}
+
+func TestStatisticsReport_CSV(t *testing.T) {
+	body, err := sampleStatisticsReport().csv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := string(body)
+	if !strings.HasPrefix(text, "\ufeff") {
+		t.Error("csv must start with a UTF-8 BOM")
+	}
+	for _, want := range []string{
+		"Prestasi per Jenis\nJenis Prestasi,Jumlah,Persentase (%)\ncompetition,3,75.0\n",
+		"Tingkat Kompetisi\n",
+		"Filter,status: verified; periode: tahun\n",
+		"Prestasi per Periode\nTahun,Jumlah,Persentase (%)\n2025,3,75.0\n",
+		"1,Andi Pratama,Teknik Informatika,2021,3\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("csv missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestStatisticsReport_XLSX(t *testing.T) {
+	body, err := sampleStatisticsReport().xlsx()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		raw, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(raw)
+
+		if strings.HasSuffix(f.Name, ".xml") || strings.HasSuffix(f.Name, ".rels") {
+			if err := xml.Unmarshal(raw, new(interface{})); err != nil {
+				t.Errorf("%s is not well-formed XML: %v", f.Name, err)
+			}
+		}
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/styles.xml", "xl/worksheets/sheet5.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing part %s", name)
+		}
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `<sheet name="Prestasi per Jenis" sheetId="2" r:id="rId2"/>`) {
+		t.Errorf("unexpected workbook:\n%s", files["xl/workbook.xml"])
+	}
+
+	// angka ditulis sebagai nilai numerik, teks sebagai inline string
+	sheet := files["xl/worksheets/sheet2.xml"]
+	if !strings.Contains(sheet, `<c r="B4" s="0"><v>3</v></c>`) || !strings.Contains(sheet, `<c r="C4" s="3"><v>75.0</v></c>`) {
+		t.Errorf("expected numeric cells:\n%s", sheet)
+	}
+	if !strings.Contains(sheet, `<t xml:space="preserve">competition</t>`) {
+		t.Errorf("expected inline string cell:\n%s", sheet)
+	}
+}
+
+func TestStatisticsReport_NeutralizesFormulas(t *testing.T) {
+	rep := sampleStatisticsReport()
+	rep.Tables[3].Rows[0][1] = `=HYPERLINK("http://evil.example","klik")`
+	rep.Tables[3].Rows[1][2] = "@SUM(A1:A2)"
+	rep.Tables[0].Rows[0][0] = "-2+3"
+
+	body, _ := rep.csv()
+	text := string(body)
+	for _, want := range []string{`'=HYPERLINK(""http://evil.example"",""klik"")`, "'@SUM(A1:A2)", "'-2+3,3,75.0"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("csv missing %q:\n%s", want, text)
+		}
+	}
+
+	for in, want := range map[string]string{
+		"+62812": "+62812", "-5": "-5", "\tx": "'\tx", "\rx": "'\rx", "+cmd": "'+cmd", "Andi": "Andi", "": "",
+	} {
+		if got := spreadsheetSafe(in); got != want {
+			t.Errorf("spreadsheetSafe(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if cell := xlsxCell("A1", "=1+1", false, 0); !strings.Contains(cell, "<t xml:space=\"preserve\">&#39;=1+1</t>") {
+		t.Errorf("xlsx text cell must be neutralized: %s", cell)
+	}
+}
+
+func TestXLSXHelpers(t *testing.T) {
+	if xlsxColumn(0) != "A" || xlsxColumn(25) != "Z" || xlsxColumn(26) != "AA" || xlsxColumn(701) != "ZZ" {
+		t.Error("unexpected column names")
+	}
+
+	used := map[string]bool{}
+	a := xlsxSheetName("Prestasi: per/Jenis [2026] dengan nama yang sangat panjang", used)
+	b := xlsxSheetName("Prestasi: per/Jenis [2026] dengan nama yang sangat panjang", used)
+	if len([]rune(a)) > 31 || strings.ContainsAny(a, `[]:*?/\`) || a == b || len([]rune(b)) > 31 {
+		t.Errorf("invalid sheet names %q, %q", a, b)
+	}
+}
+
+func TestStatisticsReport_PDF(t *testing.T) {
+	body, err := sampleStatisticsReport().pdf()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(body, []byte("%PDF-1.4")) || !bytes.HasSuffix(body, []byte("%%EOF\n")) {
+		t.Fatal("not a PDF document")
+	}
+
+	// setiap entri xref harus menunjuk ke awal objek yang benar
+	m := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(body)
+	start, _ := strconv.Atoi(string(m[1]))
+	lines := strings.Split(string(body[start:]), "\n")
+	count, _ := strconv.Atoi(strings.Fields(lines[1])[1])
+	for i := 1; i < count; i++ {
+		off, _ := strconv.Atoi(strings.Fields(lines[2+i])[0])
+		if want := fmt.Sprintf("%d 0 obj", i); !bytes.HasPrefix(body[off:], []byte(want)) {
+			t.Fatalf("xref entry %d points to the wrong offset", i)
+		}
+	}
+
+	// isi halaman (FlateDecode) memuat judul bagian
+	stream := regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindSubmatch(body)
+	zr, err := zlib.NewReader(bytes.NewReader(stream[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(zr)
+	for _, want := range []string{"(Laporan Statistik Prestasi Mahasiswa) Tj", "(Mahasiswa Berprestasi) Tj", "(Halaman 1 / 1) Tj"} {
+		if !bytes.Contains(content, []byte(want)) {
+			t.Errorf("page content missing %q", want)
+		}
+	}
+}
+
+func TestPDFTablePaginatesWithRepeatedHeader(t *testing.T) {
+	doc := newPDFDocument("Uji", exportNow)
+	l := newPDFLayout(doc)
+
+	rows := make([][]string, 120)
+	for i := range rows {
+		rows[i] = []string{strconv.Itoa(i + 1), "Mahasiswa"}
+	}
+	l.Table([]pdfColumn{{Header: "No", Width: 1, Right: true}, {Header: "Nama", Width: 4}}, rows, 9)
+
+	if len(doc.pages) < 2 {
+		t.Fatalf("expected multiple pages, got %d", len(doc.pages))
+	}
+	for i, p := range doc.pages {
+		if !bytes.Contains(p.Bytes(), []byte("(Nama) Tj")) {
+			t.Errorf("page %d is missing the table header", i+1)
+		}
+	}
+}
+
+func TestPDFEscape(t *testing.T) {
+	if got := pdfEscape(`Café (A\B) — 日本`); got != `Caf\351 \(A\\B\) \227 ??` {
+		t.Errorf("unexpected escape: %q", got)
+	}
+}
+
+func TestExportFormat(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		f, err := exportFormat(c)
+		if err != nil {
+			return c.Status(400).SendString(err.Error())
+		}
+		return c.SendString(f)
+	})
+
+	for query, want := range map[string]string{"": "json", "?format=XLSX": "xlsx", "?format=docx": "format must be one of json, csv, xlsx, pdf"} {
+		resp, _ := app.Test(httptest.NewRequest("GET", "/"+query, nil))
+		body, _ := io.ReadAll(resp.Body)
+		if string(body) != want {
+			t.Errorf("%q: got %q", query, body)
+		}
+	}
+}
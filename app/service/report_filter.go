@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// =====================================================
+//  FILTER LAPORAN STATISTIK
+// =====================================================
+//
+// Berlaku sama untuk semua scope (Admin, Dosen Wali, Mahasiswa).
+// Rentang tanggal & periode memakai tanggal prestasi dibuat (createdAt),
+// dalam zona waktu server.
+
+const (
+	PeriodYear     = "year"
+	PeriodSemester = "semester"
+	PeriodMonth    = "month"
+)
+
+var reportStatuses = []string{StatusDraft, StatusSubmitted, StatusVerified, StatusRejected}
+
+// parseReportFilter membaca query: from, to (YYYY-MM-DD, inklusif), status
+// (dipisah koma, "all" = semua; default verified), program_study,
+// academic_year, achievementType, period (year | semester | month).
+func parseReportFilter(query func(key string) string) (model.ReportFilter, error) {
+	f := model.ReportFilter{Statuses: []string{StatusVerified}, Period: PeriodYear}
+	errs := []model.FieldError{}
+
+	parseDate := func(field string) *time.Time {
+		v := strings.TrimSpace(query(field))
+		if v == "" {
+			return nil
+		}
+		d, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			errs = append(errs, model.FieldError{Field: field, Message: "must be a date in YYYY-MM-DD format"})
+			return nil
+		}
+		return &d
+	}
+	f.From = parseDate("from")
+	if to := parseDate("to"); to != nil {
+		end := to.AddDate(0, 0, 1)
+		f.To = &end
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		errs = append(errs, model.FieldError{Field: "to", Message: "must not be before from"})
+	}
+
+	if v := strings.TrimSpace(query("status")); v != "" {
+		if strings.EqualFold(v, "all") {
+			f.Statuses = nil
+		} else {
+			f.Statuses = []string{}
+			for _, s := range strings.Split(v, ",") {
+				s = strings.ToLower(strings.TrimSpace(s))
+				if !containsString(reportStatuses, s) {
+					errs = append(errs, model.FieldError{
+						Field:   "status",
+						Message: "must be all or a comma-separated list of " + strings.Join(reportStatuses, ", "),
+					})
+					break
+				}
+				if !containsString(f.Statuses, s) {
+					f.Statuses = append(f.Statuses, s)
+				}
+			}
+		}
+	}
+
+	switch p := strings.ToLower(strings.TrimSpace(query("period"))); p {
+	case "":
+	case PeriodYear, PeriodSemester, PeriodMonth:
+		f.Period = p
+	default:
+		errs = append(errs, model.FieldError{Field: "period", Message: "must be one of year, semester, month"})
+	}
+
+	f.ProgramStudy = strings.TrimSpace(query("program_study"))
+	f.AcademicYear = strings.TrimSpace(query("academic_year"))
+	f.AchievementType = strings.TrimSpace(query("achievementType"))
+
+	if len(errs) > 0 {
+		return f, &ValidationError{Fields: errs}
+	}
+	return f, nil
+}
+
+// periodKey: year = "2026", month = "2026-03", semester = tahun akademik
+// ("2025/2026 Ganjil" untuk Agustus–Januari, "2025/2026 Genap" untuk Februari–Juli)
+func periodKey(t time.Time, period string) string {
+	switch period {
+	case PeriodMonth:
+		return t.Format("2006-01")
+	case PeriodSemester:
+		y := t.Year()
+		switch {
+		case t.Month() >= time.August:
+			return fmt.Sprintf("%d/%d Ganjil", y, y+1)
+		case t.Month() == time.January:
+			return fmt.Sprintf("%d/%d Ganjil", y-1, y)
+		default:
+			return fmt.Sprintf("%d/%d Genap", y-1, y)
+		}
+	default:
+		return t.Format("2006")
+	}
+}
+
+func periodLabel(period string) string {
+	switch period {
+	case PeriodMonth:
+		return "Bulan"
+	case PeriodSemester:
+		return "Semester"
+	default:
+		return "Tahun"
+	}
+}
+
+// describeReportFilter = ringkasan filter untuk dicetak di laporan ekspor
+func describeReportFilter(f model.ReportFilter) string {
+	parts := []string{}
+
+	status := "semua"
+	if f.Statuses != nil {
+		status = strings.Join(f.Statuses, ", ")
+	}
+	parts = append(parts, "status: "+status)
+
+	if f.From != nil || f.To != nil {
+		from, to := "awal", "sekarang"
+		if f.From != nil {
+			from = f.From.Format("2006-01-02")
+		}
+		if f.To != nil {
+			to = f.To.AddDate(0, 0, -1).Format("2006-01-02")
+		}
+		parts = append(parts, "tanggal: "+from+" s.d. "+to)
+	}
+	if f.ProgramStudy != "" {
+		parts = append(parts, "program studi: "+f.ProgramStudy)
+	}
+	if f.AcademicYear != "" {
+		parts = append(parts, "angkatan: "+f.AcademicYear)
+	}
+	if f.AchievementType != "" {
+		parts = append(parts, "jenis: "+f.AchievementType)
+	}
+	parts = append(parts, "periode: "+strings.ToLower(periodLabel(f.Period)))
+
+	return strings.Join(parts, "; ")
+}
+
+// matchStatus: Statuses nil = semua status kecuali deleted
+func matchStatus(f model.ReportFilter, status string) bool {
+	if f.Statuses == nil {
+		return status != StatusDeleted
+	}
+	return containsString(f.Statuses, status)
+}
+
+func matchStudent(f model.ReportFilter, st model.StudentDetail) bool {
+	if f.ProgramStudy != "" && !strings.EqualFold(st.ProgramStudy, f.ProgramStudy) {
+		return false
+	}
+	if f.AcademicYear != "" && st.AcademicYear != f.AcademicYear {
+		return false
+	}
+	return true
+}
+
+func matchAchievement(f model.ReportFilter, doc model.AchievementMongo) bool {
+	if f.AchievementType != "" && !strings.EqualFold(doc.AchievementType, f.AchievementType) {
+		return false
+	}
+	created := time.Unix(doc.CreatedAt, 0)
+	if f.From != nil && created.Before(*f.From) {
+		return false
+	}
+	if f.To != nil && !created.Before(*f.To) {
+		return false
+	}
+	return true
+}
+
+// matchReference: status & data mahasiswa (tanpa perlu dokumen Mongo)
+func matchReference(f model.ReportFilter, r model.AchievementReference, students map[string]model.StudentDetail) bool {
+	if !matchStatus(f, r.Status) {
+		return false
+	}
+	if f.ProgramStudy != "" || f.AcademicYear != "" {
+		st, ok := students[r.StudentID]
+		return ok && matchStudent(f, st)
+	}
+	return true
+}
+
+// statisticsDocs mengambil dokumen Mongo untuk reference yang lolos filter
+// dalam satu panggilan batch (bukan FindById per reference). Key = ObjectID hex.
+func statisticsDocs(
+	ctx context.Context,
+	mg repository.IReportMongoRepository,
+	refs []model.AchievementReference,
+	students map[string]model.StudentDetail,
+	f model.ReportFilter,
+) (map[string]model.AchievementMongo, error) {
+	ids := []primitive.ObjectID{}
+	seen := map[string]bool{}
+	for _, r := range refs {
+		if seen[r.MongoAchievementID] || !matchReference(f, r, students) {
+			continue
+		}
+		oid, err := primitive.ObjectIDFromHex(r.MongoAchievementID)
+		if err != nil {
+			continue
+		}
+		seen[r.MongoAchievementID] = true
+		ids = append(ids, oid)
+	}
+	if len(ids) == 0 {
+		return map[string]model.AchievementMongo{}, nil
+	}
+	return mg.FindStatsByIDs(ctx, ids)
+}
+
+// aggregateStatistics menghitung statistik dari reference yang lolos filter.
+// docs = dokumen Mongo per mongo_achievement_id (lihat statisticsDocs).
+func aggregateStatistics(
+	refs []model.AchievementReference,
+	students map[string]model.StudentDetail,
+	f model.ReportFilter,
+	docs map[string]model.AchievementMongo,
+) model.StudentAchievementStat {
+	stats := model.StudentAchievementStat{
+		PerType:     map[string]int{},
+		PerPeriod:   map[string]int{},
+		Competition: map[string]int{},
+		TopStudents: []model.TopStudentDetail{},
+	}
+
+	counter := map[string]int{}
+	for _, r := range refs {
+		if !matchReference(f, r, students) {
+			continue
+		}
+
+		doc, ok := docs[r.MongoAchievementID]
+		if !ok || !matchAchievement(f, doc) {
+			continue
+		}
+
+		stats.PerType[doc.AchievementType]++
+		stats.PerPeriod[periodKey(time.Unix(doc.CreatedAt, 0), f.Period)]++
+		for _, tag := range doc.Tags {
+			stats.Competition[tag]++
+		}
+
+		counter[r.StudentID]++
+	}
+
+	stats.TopStudents = topStudents(counter, students)
+	return stats
+}
+
+// topStudents menyusun peringkat mahasiswa dari jumlah prestasi per student_id.
+// Dipakai agregasi langsung & StatsMaterializer supaya urutannya sama:
+// jumlah terbanyak dulu, seri → student_id.
+func topStudents(counter map[string]int, students map[string]model.StudentDetail) []model.TopStudentDetail {
+	out := []model.TopStudentDetail{}
+	for sid, n := range counter {
+		if st, ok := students[sid]; ok {
+			out = append(out, model.TopStudentDetail{
+				StudentID:    sid,
+				FullName:     st.FullName,
+				ProgramStudy: st.ProgramStudy,
+				AcademicYear: st.AcademicYear,
+				Count:        n,
+			})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].StudentID < out[j].StudentID
+	})
+	return out
+}
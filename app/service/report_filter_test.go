@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func queryOf(values map[string]string) func(string) string {
+	return func(key string) string { return values[key] }
+}
+
+func TestParseReportFilter_Defaults(t *testing.T) {
+	f, err := parseReportFilter(queryOf(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.Statuses) != 1 || f.Statuses[0] != StatusVerified {
+		t.Errorf("status must default to verified, got %v", f.Statuses)
+	}
+	if f.Period != PeriodYear || f.From != nil || f.To != nil {
+		t.Errorf("unexpected defaults: %+v", f)
+	}
+}
+
+func TestParseReportFilter_Values(t *testing.T) {
+	f, err := parseReportFilter(queryOf(map[string]string{
+		"from":            "2026-01-01",
+		"to":              "2026-01-31",
+		"status":          "verified, Submitted,verified",
+		"program_study":   " Teknik Informatika ",
+		"academic_year":   "2022",
+		"achievementType": "competition",
+		"period":          "Semester",
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !f.To.Equal(time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("to must be exclusive end of day, got %v", f.To)
+	}
+	if len(f.Statuses) != 2 || f.Statuses[1] != StatusSubmitted {
+		t.Errorf("unexpected statuses: %v", f.Statuses)
+	}
+	if f.ProgramStudy != "Teknik Informatika" || f.Period != PeriodSemester {
+		t.Errorf("unexpected filter: %+v", f)
+	}
+
+	all, _ := parseReportFilter(queryOf(map[string]string{"status": "all"}))
+	if all.Statuses != nil {
+		t.Errorf("status=all must disable the status filter, got %v", all.Statuses)
+	}
+}
+
+func TestParseReportFilter_Invalid(t *testing.T) {
+	_, err := parseReportFilter(queryOf(map[string]string{
+		"from":   "2026-02-01",
+		"to":     "2026-01-01",
+		"status": "approved",
+		"period": "week",
+	}))
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	fields := map[string]bool{}
+	for _, fe := range verr.Fields {
+		fields[fe.Field] = true
+	}
+	for _, want := range []string{"to", "status", "period"} {
+		if !fields[want] {
+			t.Errorf("missing field error for %s: %+v", want, verr.Fields)
+		}
+	}
+}
+
+func TestPeriodKey(t *testing.T) {
+	cases := map[string]string{
+		"2025-08-01 " + PeriodSemester: "2025/2026 Ganjil",
+		"2026-01-15 " + PeriodSemester: "2025/2026 Ganjil",
+		"2026-02-01 " + PeriodSemester: "2025/2026 Genap",
+		"2026-07-31 " + PeriodSemester: "2025/2026 Genap",
+		"2026-03-10 " + PeriodMonth:    "2026-03",
+		"2026-03-10 " + PeriodYear:     "2026",
+	}
+	for in, want := range cases {
+		d, _ := time.Parse("2006-01-02", in[:10])
+		if got := periodKey(d, in[11:]); got != want {
+			t.Errorf("%s: got %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestAggregateStatistics_Filters(t *testing.T) {
+	jan := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC).Unix()
+	mar := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC).Unix()
+
+	docs := map[string]model.AchievementMongo{
+		"m1": {AchievementType: "competition", CreatedAt: jan, Tags: []string{"Nasional"}},
+		"m2": {AchievementType: "competition", CreatedAt: mar},
+		"m3": {AchievementType: "publication", CreatedAt: mar},
+		"m4": {AchievementType: "competition", CreatedAt: mar},
+		"m5": {AchievementType: "competition", CreatedAt: mar},
+	}
+	refs := []model.AchievementReference{
+		{StudentID: "s1", MongoAchievementID: "m1", Status: StatusVerified},
+		{StudentID: "s1", MongoAchievementID: "m2", Status: StatusVerified},
+		{StudentID: "s1", MongoAchievementID: "m3", Status: StatusVerified},
+		{StudentID: "s1", MongoAchievementID: "m4", Status: StatusDraft},
+		{StudentID: "s2", MongoAchievementID: "m5", Status: StatusVerified},
+		{StudentID: "s1", MongoAchievementID: "missing", Status: StatusVerified},
+	}
+	students := map[string]model.StudentDetail{
+		"s1": {FullName: "Andi", ProgramStudy: "Teknik Informatika", AcademicYear: "2022"},
+		"s2": {FullName: "Citra", ProgramStudy: "Sistem Informasi", AcademicYear: "2022"},
+	}
+
+	from := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	stats := aggregateStatistics(refs, students, model.ReportFilter{
+		From:            &from,
+		Statuses:        []string{StatusVerified},
+		ProgramStudy:    "teknik informatika",
+		AchievementType: "competition",
+		Period:          PeriodMonth,
+	}, docs)
+
+	if stats.PerType["competition"] != 1 || len(stats.PerType) != 1 {
+		t.Errorf("unexpected per type: %v", stats.PerType)
+	}
+	if stats.PerPeriod["2026-03"] != 1 || len(stats.PerPeriod) != 1 {
+		t.Errorf("unexpected per period: %v", stats.PerPeriod)
+	}
+	if len(stats.TopStudents) != 1 || stats.TopStudents[0].FullName != "Andi" {
+		t.Errorf("unexpected top students: %+v", stats.TopStudents)
+	}
+
+	// filter kosong = semua status, semua mahasiswa
+	all := aggregateStatistics(refs, students, model.ReportFilter{}, docs)
+	if all.PerType["competition"] != 4 || all.PerPeriod["2026"] != 5 {
+		t.Errorf("zero filter must count everything: %+v", all)
+	}
+}
+
+// roundTripMongo mensimulasikan latensi jaringan per query ke MongoDB
+type roundTripMongo struct {
+	*repository.MockAchievementMongoRepository
+	latency time.Duration
+	calls   int
+}
+
+func (r *roundTripMongo) FindById(ctx context.Context, id primitive.ObjectID) (model.AchievementMongo, error) {
+	r.calls++
+	time.Sleep(r.latency)
+	return r.MockAchievementMongoRepository.FindById(ctx, id)
+}
+
+func (r *roundTripMongo) FindStatsByIDs(ctx context.Context, ids []primitive.ObjectID) (map[string]model.AchievementMongo, error) {
+	r.calls++
+	time.Sleep(r.latency)
+	return r.MockAchievementMongoRepository.FindStatsByIDs(ctx, ids)
+}
+
+func statisticsFixture(n int) ([]model.AchievementReference, map[string]model.StudentDetail, *repository.MockAchievementMongoRepository) {
+	mg := repository.NewMockAchievementMongoRepository()
+	students := map[string]model.StudentDetail{}
+	refs := make([]model.AchievementReference, 0, n)
+
+	types := []string{"competition", "publication", "organization", "certification"}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		sid := fmt.Sprintf("s%d", i%50)
+		students[sid] = model.StudentDetail{ID: sid, FullName: "Mahasiswa " + sid, ProgramStudy: "TI", AcademicYear: "2022"}
+
+		oid := primitive.NewObjectID()
+		mg.Data[oid.Hex()] = model.AchievementMongo{
+			AchievementType: types[i%len(types)],
+			CreatedAt:       base.AddDate(0, 0, i%700).Unix(),
+			Tags:            []string{"Nasional"},
+		}
+		refs = append(refs, model.AchievementReference{StudentID: sid, MongoAchievementID: oid.Hex(), Status: StatusVerified})
+	}
+	return refs, students, mg
+}
+
+// perReferenceDocs = cara lama: satu FindById per reference (N+1)
+func perReferenceDocs(ctx context.Context, mg repository.IReportMongoRepository, refs []model.AchievementReference) map[string]model.AchievementMongo {
+	docs := map[string]model.AchievementMongo{}
+	for _, r := range refs {
+		oid, _ := primitive.ObjectIDFromHex(r.MongoAchievementID)
+		if d, err := mg.FindById(ctx, oid); err == nil {
+			docs[r.MongoAchievementID] = d
+		}
+	}
+	return docs
+}
+
+func TestStatisticsDocs_BatchedMatchesPerReference(t *testing.T) {
+	ctx := context.Background()
+	refs, students, mock := statisticsFixture(300)
+	f := model.ReportFilter{Statuses: []string{StatusVerified}, Period: PeriodSemester}
+
+	old := &roundTripMongo{MockAchievementMongoRepository: mock}
+	want := aggregateStatistics(refs, students, f, perReferenceDocs(ctx, old, refs))
+
+	batched := &roundTripMongo{MockAchievementMongoRepository: mock}
+	docs, err := statisticsDocs(ctx, batched, refs, students, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := aggregateStatistics(refs, students, f, docs)
+
+	if batched.calls != 1 || old.calls != len(refs) {
+		t.Errorf("expected 1 batched query vs %d, got %d vs %d", len(refs), batched.calls, old.calls)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("batched statistics differ:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestStatisticsDocs_SkipsFilteredReferences(t *testing.T) {
+	refs, students, mock := statisticsFixture(10)
+	refs[0].Status = StatusDraft
+
+	mg := &roundTripMongo{MockAchievementMongoRepository: mock}
+	docs, _ := statisticsDocs(context.Background(), mg, refs, students, model.ReportFilter{Statuses: []string{StatusVerified}})
+	if _, ok := docs[refs[0].MongoAchievementID]; ok || len(docs) != 9 {
+		t.Errorf("draft reference must not be fetched, got %d docs", len(docs))
+	}
+
+	docs, _ = statisticsDocs(context.Background(), mg, refs, students, model.ReportFilter{ProgramStudy: "SI"})
+	if len(docs) != 0 || mg.calls != 1 {
+		t.Errorf("no query expected when nothing matches, got %d docs / %d calls", len(docs), mg.calls)
+	}
+}
+
+// go test ./app/service -run '^$' -bench Statistics
+// latensi 200µs per query ≈ round trip ke MongoDB di jaringan lokal
+func benchmarkStatistics(b *testing.B, batched bool) {
+	ctx := context.Background()
+	refs, students, mock := statisticsFixture(2000)
+	mg := &roundTripMongo{MockAchievementMongoRepository: mock, latency: 200 * time.Microsecond}
+	f := model.ReportFilter{Statuses: []string{StatusVerified}, Period: PeriodYear}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var docs map[string]model.AchievementMongo
+		if batched {
+			docs, _ = statisticsDocs(ctx, mg, refs, students, f)
+		} else {
+			docs = perReferenceDocs(ctx, mg, refs)
+		}
+		aggregateStatistics(refs, students, f, docs)
+	}
+	b.ReportMetric(float64(mg.calls)/float64(b.N), "queries/op")
+}
+
+func BenchmarkStatistics_PerReferenceFindById(b *testing.B) { benchmarkStatistics(b, false) }
+
+func BenchmarkStatistics_Batched(b *testing.B) { benchmarkStatistics(b, true) }
@@ -3,11 +3,12 @@ package service
 import (
 	"context"
 	"errors"
+	"math"
+	"sort"
 	"time"
 
 	"backenduas/app/model"
 	"backenduas/app/repository"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type ReportLogicService struct {
@@ -31,6 +32,7 @@ func NewReportLogicService(
 func (s *ReportLogicService) GlobalStatisticsLogic(
 	role string,
 	userID string,
+	f model.ReportFilter,
 ) (model.StudentAchievementStat, error) {
 
 	ctx := context.Background()
@@ -68,45 +70,12 @@ func (s *ReportLogicService) GlobalStatisticsLogic(
 		return model.StudentAchievementStat{}, err
 	}
 
-	stats := model.StudentAchievementStat{
-		PerType:     map[string]int{},
-		PerPeriod:   map[string]int{},
-		Competition: map[string]int{},
-		TopStudents: []model.TopStudentDetail{},
-	}
-
-	counter := map[string]int{}
-
-	for _, r := range refs {
-		oid, _ := primitive.ObjectIDFromHex(r.MongoAchievementID)
-		detail, err := s.mongoRepo.FindById(ctx, oid)
-		if err != nil {
-			continue
-		}
-
-		stats.PerType[detail.AchievementType]++
-		year := time.Unix(detail.CreatedAt, 0).Format("2006")
-		stats.PerPeriod[year]++
-
-		for _, tag := range detail.Tags {
-			stats.Competition[tag]++
-		}
-
-		counter[r.StudentID]++
-	}
-
 	stMap, _ := s.studentRepo.GetStudentsByIDs(ctx, studentIDs)
-	for sid, cnt := range counter {
-		if st, ok := stMap[sid]; ok {
-			stats.TopStudents = append(stats.TopStudents, model.TopStudentDetail{
-				StudentID:    sid,
-				FullName:     st.FullName,
-				ProgramStudy: st.ProgramStudy,
-				AcademicYear: st.AcademicYear,
-				Count:        cnt,
-			})
-		}
+	docs, err := statisticsDocs(ctx, s.mongoRepo, refs, stMap, f)
+	if err != nil {
+		return model.StudentAchievementStat{}, err
 	}
+	stats := aggregateStatistics(refs, stMap, f, docs)
 
 	return stats, nil
 }
@@ -115,6 +84,7 @@ func (s *ReportLogicService) StudentStatisticsLogic(
 	role string,
 	userID string,
 	studentID string,
+	f model.ReportFilter,
 ) (*model.StudentAchievementStat, error) {
 
 	ctx := context.Background()
@@ -152,44 +122,95 @@ func (s *ReportLogicService) StudentStatisticsLogic(
 	// ================= DATA =================
 	refs, _ := s.pgRepo.GetByStudentID(ctx, studentID)
 
-	stats := &model.StudentAchievementStat{
-		PerType:     map[string]int{},
-		PerPeriod:   map[string]int{},
-		Competition: map[string]int{},
-		TopStudents: []model.TopStudentDetail{},
+	studentMap, _ := s.studentRepo.GetStudentsByIDs(ctx, []string{studentID})
+	st := studentMap[studentID]
+
+	docs, err := statisticsDocs(ctx, s.mongoRepo, refs, studentMap, f)
+	if err != nil {
+		return nil, err
+	}
+	stats := aggregateStatistics(refs, studentMap, f, docs)
+	if len(stats.TopStudents) == 0 {
+		stats.TopStudents = append(stats.TopStudents, model.TopStudentDetail{
+			StudentID:    studentID,
+			FullName:     st.FullName,
+			ProgramStudy: st.ProgramStudy,
+			AcademicYear: st.AcademicYear,
+		})
 	}
 
-	count := 0
+	return &stats, nil
+}
 
-	for _, r := range refs {
-		oid, _ := primitive.ObjectIDFromHex(r.MongoAchievementID)
-		detail, err := s.mongoRepo.FindById(ctx, oid)
-		if err != nil {
-			continue
-		}
+// ================= TURNAROUND REVIEW =================
 
-		stats.PerType[detail.AchievementType]++
+// percentile dengan interpolasi linear; sorted harus terurut naik
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
 
-		year := time.Unix(detail.CreatedAt, 0).Format("2006")
-		stats.PerPeriod[year]++
+func turnaroundStat(rows []model.ReviewTurnaround, reviewDays int) model.TurnaroundStat {
+	stat := model.TurnaroundStat{Reviews: len(rows)}
+	if len(rows) == 0 {
+		return stat
+	}
 
-		for _, tag := range detail.Tags {
-			stats.Competition[tag]++
+	limit := time.Duration(reviewDays) * 24 * time.Hour
+	hours := make([]float64, 0, len(rows))
+	for _, r := range rows {
+		d := r.ReviewedAt.Sub(r.SubmittedAt)
+		hours = append(hours, d.Hours())
+		if d <= limit {
+			stat.WithinSLA++
 		}
+		switch r.ToStatus {
+		case StatusVerified:
+			stat.Verified++
+		case StatusRejected:
+			stat.Rejected++
+		}
+	}
+	sort.Float64s(hours)
 
-		count++
+	stat.MedianHours = math.Round(percentile(hours, 50)*10) / 10
+	stat.P90Hours = math.Round(percentile(hours, 90)*10) / 10
+	stat.WithinSLARate = math.Round(float64(stat.WithinSLA)/float64(stat.Reviews)*1000) / 1000
+	return stat
+}
+
+// buildTurnaroundReport: median & p90 waktu review per dosen, dosen paling lambat dulu
+func buildTurnaroundReport(rows []model.ReviewTurnaround, reviewDays int, from, to time.Time) model.TurnaroundReport {
+	report := model.TurnaroundReport{
+		From:       from,
+		To:         to,
+		ReviewDays: reviewDays,
+		Overall:    turnaroundStat(rows, reviewDays),
+		Lecturers:  []model.TurnaroundStat{},
 	}
 
-	studentMap, _ := s.studentRepo.GetStudentsByIDs(ctx, []string{studentID})
-	st := studentMap[studentID]
+	byLecturer := map[string][]model.ReviewTurnaround{}
+	for _, r := range rows {
+		byLecturer[r.LecturerID] = append(byLecturer[r.LecturerID], r)
+	}
 
-	stats.TopStudents = append(stats.TopStudents, model.TopStudentDetail{
-		StudentID:    studentID,
-		FullName:     st.FullName,
-		ProgramStudy: st.ProgramStudy,
-		AcademicYear: st.AcademicYear,
-		Count:        count,
+	for id, list := range byLecturer {
+		stat := turnaroundStat(list, reviewDays)
+		stat.LecturerID = id
+		stat.LecturerName = list[0].LecturerName
+		report.Lecturers = append(report.Lecturers, stat)
+	}
+	sort.Slice(report.Lecturers, func(i, j int) bool {
+		a, b := report.Lecturers[i], report.Lecturers[j]
+		if a.MedianHours != b.MedianHours {
+			return a.MedianHours > b.MedianHours
+		}
+		return a.LecturerID < b.LecturerID
 	})
-
-	return stats, nil
+	return report
 }
@@ -4,27 +4,32 @@ import (
     "backenduas/app/model"
     "backenduas/app/repository"
     "context"
+    "errors"
     "time"
 
     "github.com/gofiber/fiber/v2"
-    "github.com/golang-jwt/jwt/v5"
-    "go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type ReportService struct {
     pgRepo      *repository.AchievementPGRepository
     mongoRepo   *repository.AchievementMongoRepository
-    studentRepo *repository.StudentRepository
+    studentRepo repository.IStudentRepository
+    slaRepo     *repository.SLARepository
+    stats       *StatsMaterializer
 }
 
 func NewReportService(pg *repository.AchievementPGRepository,
     mg *repository.AchievementMongoRepository,
-    st *repository.StudentRepository) *ReportService {
+    st repository.IStudentRepository,
+    sla *repository.SLARepository,
+    stats *StatsMaterializer) *ReportService {
 
     return &ReportService{
         pgRepo:      pg,
         mongoRepo:   mg,
         studentRepo: st,
+        slaRepo:     sla,
+        stats:       stats,
     }
 }
 
@@ -38,7 +43,19 @@ func NewReportService(pg *repository.AchievementPGRepository,
 // @Tags Report
 // @Security BearerAuth
 // @Produce json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/pdf
+// @Param format query string false "json (default) | csv | xlsx | pdf"
+// @Param from query string false "Start date YYYY-MM-DD (createdAt)"
+// @Param to query string false "End date YYYY-MM-DD (inclusive)"
+// @Param status query string false "verified (default) | all | comma-separated draft,submitted,verified,rejected"
+// @Param program_study query string false "Program studi mahasiswa"
+// @Param academic_year query string false "Angkatan mahasiswa"
+// @Param achievementType query string false "Jenis prestasi"
+// @Param period query string false "year (default) | semester | month"
 // @Success 200 {object} model.StudentAchievementStat
+// @Failure 400 {object} map[string]any
 // @Failure 403 {object} map[string]any
 // @Failure 404 {object} map[string]any
 // @Failure 500 {object} map[string]any
@@ -46,95 +63,97 @@ func NewReportService(pg *repository.AchievementPGRepository,
 func (s *ReportService) GlobalStatistics(c *fiber.Ctx) error {
     ctx := context.Background()
 
-    claims := c.Locals("user").(jwt.MapClaims)
-    role := claims["role_name"].(string)
-    userID := claims["user_id"].(string)
-
-    var studentIDs []string
-    var err error
+    format, err := exportFormat(c)
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+    }
 
-    switch role {
+    filter, err := parseReportFilter(func(key string) string { return c.Query(key) })
+    if err != nil {
+        return validationFailed(c, err)
+    }
 
-    case "Admin":
-        studentIDs, err = s.studentRepo.GetAllStudentIDs(ctx)
+    // cakupan dari permission / keterkaitan user, bukan role_name; tiap
+    // cakupan punya kunci cache sendiri
+    access, err := callerScope(ctx, c, s.studentRepo)
+    if err != nil {
+        return scopeError(c, err)
+    }
 
-    case "Dosen Wali":
-        lecID, err1 := s.studentRepo.GetLecturerIDByUserID(ctx, userID)
-        if err1 != nil {
-            return c.Status(404).JSON(fiber.Map{"error": "lecturer not found"})
-        }
-        studentIDs, err = s.studentRepo.GetStudentsByAdvisor(ctx, lecID)
+    var studentIDs []string
+    var scope string
 
-    case "Mahasiswa":
-        sid, err1 := s.studentRepo.GetStudentIDByUserID(ctx, userID)
-        if err1 != nil {
-            return c.Status(404).JSON(fiber.Map{"error": "student not found"})
-        }
-        studentIDs = []string{sid}
+    switch {
+    case access.All:
+        studentIDs, err = s.studentRepo.GetAllStudentIDs(ctx)
+        scope = "Seluruh mahasiswa"
+    case access.LecturerID != "":
+        studentIDs, err = s.studentRepo.GetStudentsByAdvisor(ctx, access.LecturerID)
+        scope = "Mahasiswa bimbingan"
+    default:
+        studentIDs = []string{access.StudentID}
+        scope = "Prestasi pribadi"
     }
 
     if err != nil {
         return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
 
-    // -------- Ambil data prestasi --------
-    refs, err := s.pgRepo.GetByStudentIDs(ctx, studentIDs)
+    // -------- Ambil data detail student (filter + TOP STUDENTS) --------
+    studentMap, err := s.studentRepo.GetStudentsByIDs(ctx, studentIDs)
     if err != nil {
         return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
 
-    stats := model.StudentAchievementStat{
-        PerType:     map[string]int{},
-        PerPeriod:   map[string]int{},
-        Competition: map[string]int{},
-        TopStudents: []model.TopStudentDetail{},
+    // -------- Hitung statistik (cache → materialized → langsung) --------
+    stats, err := s.statistics(ctx, access.cacheKey(), studentIDs, studentMap, filter)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
 
-    topCounter := map[string]int{}
-
-    // -------- Hitung statistik --------
-    for _, r := range refs {
-        oid, err := primitive.ObjectIDFromHex(r.MongoAchievementID)
-        if err != nil {
-            continue
-        }
-
-        detail, err := s.mongoRepo.FindById(ctx, oid)
-        if err != nil {
-            continue
-        }
-
-        stats.PerType[detail.AchievementType]++
-
-        year := time.Unix(detail.CreatedAt, 0).Format("2006")
-        stats.PerPeriod[year]++
+    if format != FormatJSON {
+        now := time.Now()
+        rep := buildStatisticsReport("Laporan Statistik Prestasi Mahasiswa", scope, filter, stats, now)
+        return sendStatisticsExport(c, format, "statistik-prestasi-"+now.Format("20060102"), rep)
+    }
 
-        for _, tag := range detail.Tags {
-            stats.Competition[tag]++
-        }
+    return c.JSON(stats)
+}
 
-        topCounter[r.StudentID]++
+// statistics: cache per scope + filter → tabel materialized → hitung langsung
+func (s *ReportService) statistics(
+    ctx context.Context,
+    scope string,
+    studentIDs []string,
+    students map[string]model.StudentDetail,
+    f model.ReportFilter,
+) (model.StudentAchievementStat, error) {
+    if scope == "" {
+        return model.StudentAchievementStat{}, errors.New("statistics scope is required")
+    }
+    key := scope + "|" + describeReportFilter(f)
+    if stats, ok := s.stats.cached(key); ok {
+        return stats, nil
     }
 
-    // -------- Ambil data detail student untuk TOP STUDENTS --------
-    studentMap, err := s.studentRepo.GetStudentsByIDs(ctx, studentIDs)
+    stats, ok, err := s.stats.Statistics(ctx, studentIDs, students, f)
     if err != nil {
-        return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+        return stats, err
     }
-
-    for sid, cnt := range topCounter {
-        if st, ok := studentMap[sid]; ok {
-            stats.TopStudents = append(stats.TopStudents, model.TopStudentDetail{
-                StudentID:    sid,
-                FullName:     st.FullName,
-                ProgramStudy: st.ProgramStudy,
-                AcademicYear: st.AcademicYear,
-                Count:        cnt,
-            })
+    if !ok {
+        refs, err := s.pgRepo.GetByStudentIDs(ctx, studentIDs)
+        if err != nil {
+            return stats, err
         }
+        docs, err := statisticsDocs(ctx, s.mongoRepo, refs, students, f)
+        if err != nil {
+            return stats, err
+        }
+        stats = aggregateStatistics(refs, students, f, docs)
     }
 
-    return c.JSON(stats)
+    s.stats.store(key, stats)
+    return stats, nil
 }
 
 //
@@ -147,8 +166,20 @@ func (s *ReportService) GlobalStatistics(c *fiber.Ctx) error {
 // @Tags Report
 // @Security BearerAuth
 // @Produce json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/pdf
 // @Param id path string true "Student ID"
+// @Param format query string false "json (default) | csv | xlsx | pdf"
+// @Param from query string false "Start date YYYY-MM-DD (createdAt)"
+// @Param to query string false "End date YYYY-MM-DD (inclusive)"
+// @Param status query string false "verified (default) | all | comma-separated draft,submitted,verified,rejected"
+// @Param program_study query string false "Program studi mahasiswa"
+// @Param academic_year query string false "Angkatan mahasiswa"
+// @Param achievementType query string false "Jenis prestasi"
+// @Param period query string false "year (default) | semester | month"
 // @Success 200 {object} model.StudentAchievementStat
+// @Failure 400 {object} map[string]any
 // @Failure 403 {object} map[string]any
 // @Failure 404 {object} map[string]any
 // @Failure 500 {object} map[string]any
@@ -158,93 +189,158 @@ func (s *ReportService) StudentStatistics(c *fiber.Ctx) error {
 
     studentID := c.Params("id")
 
-    // Ambil role + userID dari token
-    claims := c.Locals("user").(jwt.MapClaims)
-    role := claims["role_name"].(string)
-    userID := claims["user_id"].(string)
+    format, err := exportFormat(c)
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+    }
+
+    filter, err := parseReportFilter(func(key string) string { return c.Query(key) })
+    if err != nil {
+        return validationFailed(c, err)
+    }
 
     // ================================================
-    // 🔐 ROLE VALIDATION (Mahasiswa / Dosen / Admin)
+    // 🔐 AKSES: diri sendiri / mahasiswa bimbingan / scope:all
     // ================================================
-
-    switch role {
-
-    case "Mahasiswa":
-        // mahasiswa hanya boleh akses dirinya sendiri
-        sid, err := s.studentRepo.GetStudentIDByUserID(ctx, userID)
-        if err != nil {
-            return c.Status(404).JSON(fiber.Map{"error": "student not found"})
-        }
-        if sid != studentID {
-            return c.Status(403).JSON(fiber.Map{"error": "Forbidden: You can only view your own statistics"})
-        }
-
-    case "Dosen Wali":
-        // dosen wali hanya boleh akses mahasiswa bimbingannya
-        lecID, err := s.studentRepo.GetLecturerIDByUserID(ctx, userID)
-        if err != nil {
-            return c.Status(404).JSON(fiber.Map{"error": "lecturer not found"})
-        }
-
-        ok, _ := s.studentRepo.IsStudentUnderAdvisor(ctx, studentID, lecID)
-        if !ok {
-            return c.Status(403).JSON(fiber.Map{"error": "Forbidden: This student is not your advisee"})
-        }
-
-    case "Admin":
-        // bebas
-    default:
-        return c.Status(403).JSON(fiber.Map{"error": "Forbidden"})
+    access, err := callerScope(ctx, c, s.studentRepo)
+    if err != nil {
+        return scopeError(c, err)
+    }
+    if !access.canSeeStudent(ctx, s.studentRepo, studentID) {
+        return c.Status(403).JSON(fiber.Map{"error": "Forbidden: statistik mahasiswa ini di luar cakupan Anda"})
     }
 
     // ================================================
     // Jika lolos validasi → ambil statistik student
     // ================================================
-    refs, err := s.pgRepo.GetByStudentID(ctx, studentID)
+    // Ambil detail student
+    studentMap, err := s.studentRepo.GetStudentsByIDs(ctx, []string{studentID})
     if err != nil {
         return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
+    st := studentMap[studentID]
 
-    stats := model.StudentAchievementStat{
-        PerType:     map[string]int{},
-        PerPeriod:   map[string]int{},
-        Competition: map[string]int{},
-        TopStudents: []model.TopStudentDetail{},
+    stats, err := s.statistics(ctx, "student:"+studentID, []string{studentID}, studentMap, filter)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
 
-    totalCount := 0
+    // mahasiswa tetap tampil walau jumlahnya 0 setelah difilter
+    if len(stats.TopStudents) == 0 {
+        stats.TopStudents = append(stats.TopStudents, model.TopStudentDetail{
+            StudentID:    studentID,
+            FullName:     st.FullName,
+            ProgramStudy: st.ProgramStudy,
+            AcademicYear: st.AcademicYear,
+        })
+    }
 
-    for _, r := range refs {
-        oid, _ := primitive.ObjectIDFromHex(r.MongoAchievementID)
-        detail, err := s.mongoRepo.FindById(ctx, oid)
-        if err != nil {
-            continue
+    if format != FormatJSON {
+        now := time.Now()
+        scope := orDash(st.FullName)
+        if st.StudentID != "" {
+            scope += " (" + st.StudentID + ")"
         }
+        rep := buildStatisticsReport("Laporan Statistik Prestasi Mahasiswa", scope, filter, stats, now)
+        return sendStatisticsExport(c, format, "statistik-prestasi-"+exportSlug(st.StudentID, studentID)+"-"+now.Format("20060102"), rep)
+    }
 
-        stats.PerType[detail.AchievementType]++
+    return c.JSON(stats)
+}
 
-        year := time.Unix(detail.CreatedAt, 0).Format("2006")
-        stats.PerPeriod[year]++
+//
+// ======================================================
+//  REVIEW TURNAROUND (SLA)
+// ======================================================
+// Turnaround godoc
+// @Summary Review turnaround per lecturer
+// @Description Median & p90 waktu submit → verified/rejected per dosen. Admin: semua dosen, Dosen Wali: dirinya sendiri
+// @Tags Report
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Start date YYYY-MM-DD (default 90 hari terakhir)"
+// @Param to query string false "End date YYYY-MM-DD (inclusive, default hari ini)"
+// @Success 200 {object} model.TurnaroundReport
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /reports/turnaround [get]
+func (s *ReportService) Turnaround(c *fiber.Ctx) error {
+    ctx := context.Background()
 
-        for _, tag := range detail.Tags {
-            stats.Competition[tag]++
+    // hanya scope:all (semua dosen) atau dosen (angka miliknya sendiri)
+    access, err := callerScope(ctx, c, s.studentRepo)
+    if err != nil {
+        return scopeError(c, err)
+    }
+    if !access.All && access.LecturerID == "" {
+        return c.Status(403).JSON(fiber.Map{"error": "Forbidden: waktu review hanya untuk dosen"})
+    }
+
+    // -------- Periode [from, to+1 hari) --------
+    today := time.Now().Truncate(24 * time.Hour)
+    to := today.AddDate(0, 0, 1)
+    from := today.AddDate(0, 0, -90)
+
+    if v := c.Query("from"); v != "" {
+        d, err := time.Parse("2006-01-02", v)
+        if err != nil {
+            return c.Status(400).JSON(fiber.Map{"error": "from must be YYYY-MM-DD"})
         }
+        from = d
+    }
+    if v := c.Query("to"); v != "" {
+        d, err := time.Parse("2006-01-02", v)
+        if err != nil {
+            return c.Status(400).JSON(fiber.Map{"error": "to must be YYYY-MM-DD"})
+        }
+        to = d.AddDate(0, 0, 1)
+    }
+    if !from.Before(to) {
+        return c.Status(400).JSON(fiber.Map{"error": "from must be before to"})
+    }
 
-        totalCount++
+    settings, err := s.slaRepo.GetSettings(ctx)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
 
-    // Ambil detail student
-    studentMap, _ := s.studentRepo.GetStudentsByIDs(ctx, []string{studentID})
-    st := studentMap[studentID]
+    rows, err := s.slaRepo.ListTurnarounds(ctx, from, to)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+    }
 
-    stats.TopStudents = append(stats.TopStudents, model.TopStudentDetail{
-        StudentID:    studentID,
-        FullName:     st.FullName,
-        ProgramStudy: st.ProgramStudy,
-        AcademicYear: st.AcademicYear,
-        Count:        totalCount,
-    })
+    // dosen hanya melihat angka miliknya sendiri
+    if !access.All {
+        own := []model.ReviewTurnaround{}
+        for _, r := range rows {
+            if r.LecturerID == access.LecturerID {
+                own = append(own, r)
+            }
+        }
+        rows = own
+    }
 
-    return c.JSON(stats)
+    return c.JSON(buildTurnaroundReport(rows, settings.ReviewDays, from, to))
 }
 
+//
+// ======================================================
+//  REBUILD STATISTIK MATERIALIZED (Admin)
+// ======================================================
+// RebuildStatistics godoc
+// @Summary Rebuild materialized statistics
+// @Description Hitung ulang seluruh tabel statistik dari PostgreSQL + MongoDB dan kosongkan cache laporan (Admin)
+// @Tags Report
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} model.StatsRebuildResult
+// @Failure 500 {object} map[string]any
+// @Router /reports/statistics/rebuild [post]
+func (s *ReportService) RebuildStatistics(c *fiber.Ctx) error {
+    res, err := s.stats.Rebuild(context.Background())
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+    }
+    return c.JSON(res)
+}
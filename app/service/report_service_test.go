@@ -1,12 +1,16 @@
 package service
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"backenduas/app/model"
 	"backenduas/app/repository"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 func TestGlobalStatistics_Admin(t *testing.T) {
@@ -38,7 +42,7 @@ func TestGlobalStatistics_Admin(t *testing.T) {
 
 	svc := NewReportLogicService(pg, mg, st)
 
-	stats, err := svc.GlobalStatisticsLogic("Admin", "admin-id")
+	stats, err := svc.GlobalStatisticsLogic("Admin", "admin-id", model.ReportFilter{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -67,6 +71,7 @@ func TestStudentStatisticsLogic_Mahasiswa_Forbidden(t *testing.T) {
 		"Mahasiswa",
 		"u1",
 		"S002", // student lain
+		model.ReportFilter{},
 	)
 
 	if err == nil {
@@ -106,7 +111,7 @@ func TestGlobalStatisticsLogic_DosenWali(t *testing.T) {
 
 	svc := NewReportLogicService(pg, mg, st)
 
-	stats, err := svc.GlobalStatisticsLogic("Dosen Wali", "uLect")
+	stats, err := svc.GlobalStatisticsLogic("Dosen Wali", "uLect", model.ReportFilter{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -116,3 +121,83 @@ func TestGlobalStatisticsLogic_DosenWali(t *testing.T) {
 	}
 }
 
+
+func TestCallerScope_PermissionAndLinkage(t *testing.T) {
+	st := repository.NewMockStudentRepository()
+	st.UserToStudent["u-student"] = "s1"
+	st.UserToLecturer["u-lecturer"] = "l1"
+
+	scopeOf := func(claims jwt.MapClaims) (dataScope, int) {
+		var got dataScope
+		code := 0
+		app := fiber.New()
+		app.Get("/", func(c *fiber.Ctx) error {
+			c.Locals("user", claims)
+			var err error
+			got, err = callerScope(context.Background(), c, st)
+			if err != nil {
+				return scopeError(c, err)
+			}
+			return c.SendStatus(204)
+		})
+		code, _ = call(t, app, "GET", "/", "")
+		return got, code
+	}
+
+	// role buatan Admin: nama role tidak menentukan apa pun
+	if got, code := scopeOf(jwt.MapClaims{"user_id": "u-lecturer", "role_name": "Kaprodi"}); code != 204 || got.LecturerID != "l1" {
+		t.Errorf("lecturer linkage must give the advisee scope, got %d %+v", code, got)
+	}
+	if got, code := scopeOf(jwt.MapClaims{"user_id": "u-student", "role_name": "Alumni"}); code != 204 || got.StudentID != "s1" {
+		t.Errorf("student linkage must give the own scope, got %d %+v", code, got)
+	}
+	if got, code := scopeOf(jwt.MapClaims{"user_id": "u9", "permissions": []interface{}{PermScopeAll}}); code != 204 || !got.All {
+		t.Errorf("scope:all must give the global scope, got %d %+v", code, got)
+	}
+
+	// role "Admin" tanpa scope:all & tanpa keterkaitan → ditolak
+	if _, code := scopeOf(jwt.MapClaims{"user_id": "u9", "role_name": "Admin"}); code != 403 {
+		t.Errorf("unlinked caller without scope:all must be forbidden, got %d", code)
+	}
+
+	if (dataScope{}).cacheKey() != "" || (dataScope{StudentID: "s1"}).cacheKey() == (dataScope{LecturerID: "s1"}).cacheKey() {
+		t.Error("cache keys of different scopes must not alias")
+	}
+}
+
+func TestReportHandlers_FailClosed(t *testing.T) {
+	st := repository.NewMockStudentRepository()
+	st.UserToStudent["u-student"] = "s1"
+	report := &ReportService{studentRepo: st}
+	sla := &SLAService{studentRepo: st}
+
+	as := func(userID string) *fiber.App {
+		app := fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			c.Locals("user", jwt.MapClaims{"user_id": userID, "role_name": "Kaprodi", "permissions": []interface{}{"report:read", "report:review", "sla:read"}})
+			return c.Next()
+		})
+		app.Get("/reports/statistics", report.GlobalStatistics)
+		app.Get("/reports/turnaround", report.Turnaround)
+		app.Get("/sla/breaches", sla.Breaches)
+		return app
+	}
+
+	// tanpa scope:all & tanpa keterkaitan → tidak ada data sama sekali
+	for _, path := range []string{"/reports/statistics", "/reports/turnaround", "/sla/breaches"} {
+		if code, _ := call(t, as("u9"), "GET", path, ""); code != 403 {
+			t.Errorf("%s: unlinked caller must be forbidden, got %d", path, code)
+		}
+	}
+
+	// mahasiswa tidak pernah mendapat tampilan seluruh dosen
+	for _, path := range []string{"/reports/turnaround", "/sla/breaches"} {
+		if code, _ := call(t, as("u-student"), "GET", path, ""); code != 403 {
+			t.Errorf("%s: student must be forbidden, got %d", path, code)
+		}
+	}
+
+	if _, err := report.statistics(context.Background(), "", nil, nil, model.ReportFilter{}); err == nil {
+		t.Error("statistics must refuse an empty cache scope")
+	}
+}
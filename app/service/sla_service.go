@@ -0,0 +1,405 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+	"backenduas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// =====================================================
+//  SLA REVIEW — batas waktu verifikasi prestasi
+// =====================================================
+//
+// Prestasi yang berstatus submitted lebih dari ReviewDays hari dianggap
+// melanggar SLA. RunChecks mencatat setiap pelanggaran sekali per putaran
+// submit (sla_breaches) lalu menjalankan tindakan sesuai pengaturan:
+// escalate = notifikasi ke Admin, reassign = review dialihkan ke dosen
+// cadangan (fallback ke escalate jika dosen wali belum punya cadangan).
+type SLAService struct {
+	slaRepo     repository.ISLARepository
+	studentRepo repository.IStudentRepository
+	userRepo    repository.IUserRepository
+	perms       middleware.PermissionResolver
+	notifier    *Notifier
+
+	now func() time.Time
+}
+
+func NewSLAService(
+	slaRepo repository.ISLARepository,
+	st repository.IStudentRepository,
+	users repository.IUserRepository,
+	perms middleware.PermissionResolver,
+	notifier *Notifier,
+) *SLAService {
+	return &SLAService{
+		slaRepo:     slaRepo,
+		studentRepo: st,
+		userRepo:    users,
+		perms:       perms,
+		notifier:    notifier,
+		now:         time.Now,
+	}
+}
+
+// canReview: dosen wali mahasiswa, atau dosen cadangan yang ditunjuk saat SLA dilanggar
+func canReview(ctx context.Context, st repository.IStudentRepository, ref model.AchievementReference, lecturerID string) bool {
+	if lecturerID == "" {
+		return false
+	}
+	if ref.ReviewerID != nil && *ref.ReviewerID == lecturerID {
+		return true
+	}
+	ok, err := st.IsStudentUnderAdvisor(ctx, ref.StudentID, lecturerID)
+	return err == nil && ok
+}
+
+func validateSLASettings(req model.SLASettingsRequest, s *model.SLASettings) error {
+	errs := []model.FieldError{}
+
+	if req.ReviewDays != nil {
+		if *req.ReviewDays < 1 || *req.ReviewDays > 365 {
+			errs = append(errs, model.FieldError{Field: "review_days", Message: "must be between 1 and 365"})
+		} else {
+			s.ReviewDays = *req.ReviewDays
+		}
+	}
+
+	if req.Action != nil {
+		switch *req.Action {
+		case model.SLAActionEscalate, model.SLAActionReassign, model.SLAActionNone:
+			s.Action = *req.Action
+		default:
+			errs = append(errs, model.FieldError{Field: "action", Message: "must be one of escalate, reassign, none"})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Fields: errs}
+	}
+	return nil
+}
+
+// overdue = prestasi melewati batas beserta umur & keterlambatannya
+func (s *SLAService) overdue(ctx context.Context, settings model.SLASettings) ([]model.SLABreachItem, error) {
+	now := s.now()
+	limit := time.Duration(settings.ReviewDays) * 24 * time.Hour
+
+	items, err := s.slaRepo.ListOverdue(ctx, now.Add(-limit))
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		age := now.Sub(items[i].SubmittedAt)
+		items[i].AgeDays = int(age.Hours() / 24)
+		items[i].OverdueDays = int((age - limit).Hours() / 24)
+	}
+	return items, nil
+}
+
+// BuildBreaches mengelompokkan pelanggaran per dosen wali.
+// lecturerID kosong = semua; selain itu hanya prestasi bimbingan dosen tsb
+// atau yang dialihkan kepadanya.
+func (s *SLAService) BuildBreaches(ctx context.Context, lecturerID string) (model.SLABreachReport, error) {
+	report := model.SLABreachReport{GeneratedAt: s.now(), Lecturers: []model.LecturerSLABreaches{}}
+
+	settings, err := s.slaRepo.GetSettings(ctx)
+	if err != nil {
+		return report, err
+	}
+	report.ReviewDays = settings.ReviewDays
+
+	items, err := s.overdue(ctx, settings)
+	if err != nil {
+		return report, err
+	}
+
+	groups := map[string]*model.LecturerSLABreaches{}
+	order := []string{}
+	for _, it := range items {
+		if lecturerID != "" && it.LecturerID != lecturerID && (it.ReviewerID == nil || *it.ReviewerID != lecturerID) {
+			continue
+		}
+
+		g, ok := groups[it.LecturerID]
+		if !ok {
+			g = &model.LecturerSLABreaches{LecturerID: it.LecturerID, LecturerName: it.LecturerName, Items: []model.SLABreachItem{}}
+			groups[it.LecturerID] = g
+			order = append(order, it.LecturerID)
+		}
+		g.Items = append(g.Items, it)
+		report.Total++
+	}
+
+	for _, id := range order {
+		report.Lecturers = append(report.Lecturers, *groups[id])
+	}
+	sort.SliceStable(report.Lecturers, func(i, j int) bool {
+		return len(report.Lecturers[i].Items) > len(report.Lecturers[j].Items)
+	})
+	return report, nil
+}
+
+// adminUserIDs = penerima eskalasi: user aktif dengan permission sla:manage
+func (s *SLAService) adminUserIDs(ctx context.Context) []string {
+	users, err := s.userRepo.GetAll(ctx)
+	if err != nil {
+		log.Printf("⚠️ SLA: daftar admin tidak terbaca: %v", err)
+		return nil
+	}
+	ids := []string{}
+	for _, u := range usersWithPermission(ctx, users, s.perms, "sla:manage") {
+		ids = append(ids, u.ID)
+	}
+	return ids
+}
+
+// RunChecks menindaklanjuti pelanggaran SLA yang belum pernah dicatat
+func (s *SLAService) RunChecks(ctx context.Context) (model.SLACheckSummary, error) {
+	summary := model.SLACheckSummary{CheckedAt: s.now()}
+
+	settings, err := s.slaRepo.GetSettings(ctx)
+	if err != nil {
+		return summary, err
+	}
+
+	items, err := s.overdue(ctx, settings)
+	if err != nil {
+		return summary, err
+	}
+	summary.Overdue = len(items)
+
+	var admins []string
+	for _, it := range items {
+		if it.Action != "" {
+			continue // sudah ditindaklanjuti pada putaran submit ini
+		}
+
+		breach := model.SLABreach{
+			AchievementID: it.AchievementID,
+			SubmittedAt:   it.SubmittedAt,
+			LecturerID:    it.LecturerID,
+			Action:        settings.Action,
+		}
+
+		if breach.Action == model.SLAActionReassign {
+			backup := ""
+			if it.LecturerID != "" {
+				if backup, err = s.slaRepo.GetBackupLecturer(ctx, it.LecturerID); err != nil {
+					log.Printf("⚠️ SLA %s: dosen cadangan tidak terbaca: %v", it.AchievementID, err)
+				}
+			}
+			if backup == "" || backup == it.LecturerID {
+				breach.Action = model.SLAActionEscalate
+			} else {
+				// reviewer di-set sebelum dicatat agar kegagalan dapat diulang pada cek berikutnya
+				if err := s.slaRepo.AssignReviewer(ctx, it.AchievementID, backup); err != nil {
+					log.Printf("❌ SLA %s: gagal mengalihkan ke %s: %v", it.AchievementID, backup, err)
+					continue
+				}
+				breach.ReassignedTo = backup
+			}
+		}
+
+		recorded, err := s.slaRepo.RecordBreach(ctx, breach)
+		if err != nil {
+			return summary, err
+		}
+		if !recorded {
+			continue // sudah dicatat instance lain
+		}
+		summary.NewBreach++
+
+		switch breach.Action {
+		case model.SLAActionReassign:
+			s.notifier.ReviewReassigned(ctx, it, breach.ReassignedTo)
+			summary.Reassigned++
+		case model.SLAActionEscalate:
+			if admins == nil {
+				admins = s.adminUserIDs(ctx)
+			}
+			s.notifier.SLABreached(ctx, admins, it, settings.ReviewDays)
+			summary.Escalated++
+		}
+	}
+
+	return summary, nil
+}
+
+// Run = background worker: RunChecks setiap interval
+func (s *SLAService) Run(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		summary, err := s.RunChecks(ctx)
+		if err != nil {
+			log.Printf("❌ SLA check: %v", err)
+			continue
+		}
+		if summary.NewBreach > 0 {
+			log.Printf("⏰ SLA: %d pelanggaran baru (%d eskalasi, %d dialihkan)",
+				summary.NewBreach, summary.Escalated, summary.Reassigned)
+		}
+	}
+}
+
+// =====================================================
+//
+//	ENDPOINT
+//
+// =====================================================
+// GetSettings godoc
+// @Summary Get review SLA settings
+// @Tags SLA
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} model.SLASettings
+// @Failure 500 {object} map[string]string
+// @Router /sla/settings [get]
+func (s *SLAService) GetSettings(c *fiber.Ctx) error {
+	settings, err := s.slaRepo.GetSettings(context.Background())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(settings)
+}
+
+// UpdateSettings godoc
+// @Summary Update review SLA settings
+// @Description review_days = batas hari verifikasi; action = escalate | reassign | none (Admin only)
+// @Tags SLA
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body model.SLASettingsRequest true "SLA settings"
+// @Success 200 {object} model.SLASettings
+// @Failure 400 {object} map[string]any
+// @Failure 500 {object} map[string]string
+// @Router /sla/settings [put]
+func (s *SLAService) UpdateSettings(c *fiber.Ctx) error {
+	var req model.SLASettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	ctx := context.Background()
+	settings, err := s.slaRepo.GetSettings(ctx)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := validateSLASettings(req, &settings); err != nil {
+		return validationFailed(c, err)
+	}
+
+	if err := s.slaRepo.UpdateSettings(ctx, &settings); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(settings)
+}
+
+// Breaches godoc
+// @Summary List SLA breaches per lecturer
+// @Description Permission scope:all: semua dosen (opsional filter lecturer_id). Dosen: bimbingan sendiri + yang dialihkan kepadanya
+// @Tags SLA
+// @Security BearerAuth
+// @Produce json
+// @Param lecturer_id query string false "Lecturer ID (scope:all only)"
+// @Success 200 {object} model.SLABreachReport
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /sla/breaches [get]
+func (s *SLAService) Breaches(c *fiber.Ctx) error {
+	ctx := context.Background()
+	// scope:all → semua dosen (opsional filter); dosen → miliknya sendiri
+	access, err := callerScope(ctx, c, s.studentRepo)
+	if err != nil {
+		return scopeError(c, err)
+	}
+
+	lecturerID := c.Query("lecturer_id")
+	if !access.All {
+		if access.LecturerID == "" {
+			return c.Status(403).JSON(fiber.Map{"error": "Forbidden: pelanggaran SLA hanya untuk dosen"})
+		}
+		lecturerID = access.LecturerID
+	}
+
+	report, err := s.BuildBreaches(ctx, lecturerID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(report)
+}
+
+// SetBackup godoc
+// @Summary Set backup lecturer
+// @Description Dosen cadangan yang menerima review saat SLA dilanggar (action = reassign). null = hapus (Admin only)
+// @Tags SLA
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param lecturerId path string true "Lecturer ID"
+// @Param request body model.BackupLecturerRequest true "Backup lecturer"
+// @Success 200 {object} map[string]any
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]string
+// @Router /sla/backups/{lecturerId} [put]
+func (s *SLAService) SetBackup(c *fiber.Ctx) error {
+	ctx := context.Background()
+	lecturerID := c.Params("lecturerId")
+
+	var req model.BackupLecturerRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	if req.BackupLecturerID != nil {
+		if *req.BackupLecturerID == lecturerID {
+			return validationFailed(c, &ValidationError{Fields: []model.FieldError{
+				{Field: "backup_lecturer_id", Message: "must differ from the lecturer"},
+			}})
+		}
+		if _, err := s.studentRepo.GetUserIDByLecturerID(ctx, *req.BackupLecturerID); err != nil {
+			return validationFailed(c, &ValidationError{Fields: []model.FieldError{
+				{Field: "backup_lecturer_id", Message: "lecturer not found"},
+			}})
+		}
+	}
+
+	if err := s.slaRepo.SetBackupLecturer(ctx, lecturerID, req.BackupLecturerID); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"lecturer_id": lecturerID, "backup_lecturer_id": req.BackupLecturerID})
+}
+
+// Check godoc
+// @Summary Run SLA check now
+// @Description Menindaklanjuti pelanggaran SLA baru di luar jadwal (Admin only)
+// @Tags SLA
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} model.SLACheckSummary
+// @Failure 500 {object} map[string]string
+// @Router /sla/check [post]
+func (s *SLAService) Check(c *fiber.Ctx) error {
+	summary, err := s.RunChecks(context.Background())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(summary)
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+)
+
+var slaNow = time.Date(2026, 3, 20, 9, 0, 0, 0, time.UTC)
+
+// staticPermissions: PermissionResolver tetap (role id → permission)
+type staticPermissions map[string][]string
+
+func (p staticPermissions) PermissionsForRole(ctx context.Context, roleID string) ([]string, error) {
+	return p[roleID], nil
+}
+
+var slaPermissions = staticPermissions{"rAdmin": {"sla:manage", "system:manage"}}
+
+// seedSLAQueue mengisi satu admin aktif, satu admin nonaktif, dan tiga
+// pengajuan (dua melewati batas 14 hari, satu masih dalam batas).
+func seedSLAQueue(sla *repository.MockSLARepository, users *repository.MockUserRepository) {
+	users.Data["uAdmin"] = model.User{ID: "uAdmin", RoleID: "rAdmin", RoleName: "Admin", IsActive: true}
+	users.Data["uAdminOld"] = model.User{ID: "uAdminOld", RoleID: "rAdmin", RoleName: "Admin", IsActive: false}
+
+	overdue := func(id, studentID, name, lecturerID, lecturerName string, ageDays int) model.SLABreachItem {
+		return model.SLABreachItem{
+			AchievementID: id, StudentID: studentID, StudentName: name,
+			LecturerID: lecturerID, LecturerName: lecturerName,
+			SubmittedAt: slaNow.Add(-time.Duration(ageDays) * 24 * time.Hour),
+		}
+	}
+	sla.Overdue = []model.SLABreachItem{
+		overdue("a1", "s1", "Andi", "lect1", "Dr. Budi", 20),
+		overdue("a2", "s2", "Citra", "", "", 16),
+		overdue("a3", "s1", "Andi", "lect1", "Dr. Budi", 3), // masih dalam batas
+	}
+}
+
+func TestSLARunChecks_EscalatesOncePerSubmission(t *testing.T) {
+	st := repository.NewMockStudentRepository()
+	users := repository.NewMockUserRepository()
+	sla := repository.NewMockSLARepository()
+	notifs := repository.NewMockNotificationRepository()
+	seedNotifierUsers(st)
+	seedSLAQueue(sla, users)
+
+	svc := NewSLAService(sla, st, users, slaPermissions, NewNotifier(notifs, st, nil, nil))
+	svc.now = func() time.Time { return slaNow }
+	ctx := context.Background()
+
+	summary, err := svc.RunChecks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Overdue != 2 || summary.NewBreach != 2 || summary.Escalated != 2 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if len(notifs.Data) != 2 || notifs.Data[0].RecipientID != "uAdmin" || notifs.Data[0].Type != model.NotifSLABreached {
+		t.Fatalf("expected one notification per breach for the active admin: %+v", notifs.Data)
+	}
+
+	summary, _ = svc.RunChecks(ctx)
+	if summary.NewBreach != 0 || len(notifs.Data) != 2 {
+		t.Errorf("second check must not notify again: %+v", summary)
+	}
+}
+
+func TestSLARunChecks_EscalatesByPermission(t *testing.T) {
+	st := repository.NewMockStudentRepository()
+	users := repository.NewMockUserRepository()
+	sla := repository.NewMockSLARepository()
+	notifs := repository.NewMockNotificationRepository()
+	seedNotifierUsers(st)
+	seedSLAQueue(sla, users)
+	sla.Overdue = sla.Overdue[:1]
+
+	// role custom dengan sla:manage ikut menerima; role bernama "Admin" tanpa permission tidak
+	users.Data["uKaprodi"] = model.User{ID: "uKaprodi", RoleID: "rKaprodi", RoleName: "Kaprodi", IsActive: true}
+	users.Data["uFakeAdmin"] = model.User{ID: "uFakeAdmin", RoleID: "rOther", RoleName: "Admin", IsActive: true}
+	perms := staticPermissions{"rAdmin": {"sla:manage"}, "rKaprodi": {"sla:manage"}, "rOther": {"achievement:read"}}
+
+	svc := NewSLAService(sla, st, users, perms, NewNotifier(notifs, st, nil, nil))
+	svc.now = func() time.Time { return slaNow }
+
+	if _, err := svc.RunChecks(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	for _, n := range notifs.Data {
+		got[n.RecipientID] = true
+	}
+	if len(got) != 2 || !got["uAdmin"] || !got["uKaprodi"] {
+		t.Errorf("expected escalation to sla:manage holders only, got %+v", got)
+	}
+}
+
+func TestSLARunChecks_ReassignsToBackup(t *testing.T) {
+	st := repository.NewMockStudentRepository()
+	users := repository.NewMockUserRepository()
+	sla := repository.NewMockSLARepository()
+	notifs := repository.NewMockNotificationRepository()
+	seedNotifierUsers(st)
+	seedSLAQueue(sla, users)
+
+	svc := NewSLAService(sla, st, users, slaPermissions, NewNotifier(notifs, st, nil, nil))
+	svc.now = func() time.Time { return slaNow }
+	ctx := context.Background()
+	sla.Settings.Action = model.SLAActionReassign
+	sla.Backups["lect1"] = "lect2"
+
+	summary, err := svc.RunChecks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a1 → dosen cadangan; a2 tanpa dosen wali → eskalasi
+	if summary.Reassigned != 1 || summary.Escalated != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if sla.Reviewers["a1"] != "lect2" {
+		t.Errorf("expected a1 assigned to lect2, got %q", sla.Reviewers["a1"])
+	}
+
+	got := map[string]string{}
+	for _, n := range notifs.Data {
+		got[n.RecipientID] = n.Type
+	}
+	if got["uLect2"] != model.NotifReviewReassigned || got["uAdmin"] != model.NotifSLABreached {
+		t.Errorf("unexpected notifications: %+v", got)
+	}
+}
+
+func TestSLABreaches_LecturerSeesOwnAndReassigned(t *testing.T) {
+	st := repository.NewMockStudentRepository()
+	users := repository.NewMockUserRepository()
+	sla := repository.NewMockSLARepository()
+	notifs := repository.NewMockNotificationRepository()
+	seedNotifierUsers(st)
+	seedSLAQueue(sla, users)
+
+	svc := NewSLAService(sla, st, users, slaPermissions, NewNotifier(notifs, st, nil, nil))
+	svc.now = func() time.Time { return slaNow }
+	ctx := context.Background()
+	sla.Reviewers["a2"] = "lect2"
+
+	all, err := svc.BuildBreaches(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all.Total != 2 || len(all.Lecturers) != 2 || all.ReviewDays != 14 {
+		t.Fatalf("unexpected report: %+v", all)
+	}
+	if item := all.Lecturers[0].Items[0]; item.AgeDays != 20 || item.OverdueDays != 6 {
+		t.Errorf("unexpected age: %+v", item)
+	}
+
+	own, _ := svc.BuildBreaches(ctx, "lect2")
+	if own.Total != 1 || own.Lecturers[0].Items[0].AchievementID != "a2" {
+		t.Errorf("backup must see reassigned item only: %+v", own)
+	}
+}
+
+func TestCanReview_AllowsAssignedReviewer(t *testing.T) {
+	st := repository.NewMockStudentRepository()
+	st.AdvisorMap["s1"] = "lect1"
+	ctx := context.Background()
+
+	ref := model.AchievementReference{ID: "a1", StudentID: "s1"}
+	if !canReview(ctx, st, ref, "lect1") || canReview(ctx, st, ref, "lect2") {
+		t.Fatal("only the advisor may review an unassigned achievement")
+	}
+
+	backup := "lect2"
+	ref.ReviewerID = &backup
+	if !canReview(ctx, st, ref, "lect2") || !canReview(ctx, st, ref, "lect1") {
+		t.Error("advisor and assigned reviewer may both review")
+	}
+	if canReview(ctx, st, ref, "") {
+		t.Error("empty lecturer must be rejected")
+	}
+}
+
+func TestValidateSLASettings(t *testing.T) {
+	s := model.SLASettings{ReviewDays: 14, Action: model.SLAActionEscalate}
+	days, action := 0, "delete"
+
+	if err := validateSLASettings(model.SLASettingsRequest{ReviewDays: &days, Action: &action}, &s); err == nil {
+		t.Fatal("expected validation error")
+	}
+
+	days, action = 10, model.SLAActionReassign
+	if err := validateSLASettings(model.SLASettingsRequest{ReviewDays: &days, Action: &action}, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.ReviewDays != 10 || s.Action != model.SLAActionReassign {
+		t.Errorf("settings not applied: %+v", s)
+	}
+}
+
+func TestBuildTurnaroundReport_MedianAndP90(t *testing.T) {
+	base := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	review := func(lecturerID string, hours int, status string) model.ReviewTurnaround {
+		return model.ReviewTurnaround{
+			LecturerID: lecturerID, LecturerName: lecturerID, ToStatus: status,
+			SubmittedAt: base, ReviewedAt: base.Add(time.Duration(hours) * time.Hour),
+		}
+	}
+
+	rows := []model.ReviewTurnaround{}
+	for _, h := range []int{10, 20, 30, 40, 50, 60, 70, 80, 90, 400} {
+		rows = append(rows, review("lect1", h, StatusVerified))
+	}
+	rows = append(rows, review("lect2", 5, StatusRejected))
+
+	report := buildTurnaroundReport(rows, 14, base, base.AddDate(0, 1, 0))
+
+	if report.Overall.Reviews != 11 || len(report.Lecturers) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	l1 := report.Lecturers[0]
+	if l1.LecturerID != "lect1" || l1.MedianHours != 55 || l1.P90Hours != 121 {
+		t.Errorf("unexpected lect1 stat: %+v", l1)
+	}
+	if l1.WithinSLA != 9 || l1.WithinSLARate != 0.9 || l1.Verified != 10 {
+		t.Errorf("unexpected SLA rate: %+v", l1)
+	}
+	if l2 := report.Lecturers[1]; l2.MedianHours != 5 || l2.P90Hours != 5 || l2.Rejected != 1 {
+		t.Errorf("unexpected lect2 stat: %+v", l2)
+	}
+}
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// =====================================================
+//  STATISTIK MATERIALIZED + CACHE LAPORAN
+// =====================================================
+//
+// Setiap transisi / edit prestasi memanggil Refresh: kontribusi prestasi itu
+// di tabel statistik diganti (counter lama dikurangi, yang baru ditambah).
+// Counter berbutir bulan, jadi filter tanggal yang tidak sejajar awal/akhir
+// bulan tetap dihitung langsung (batch Mongo, lihat statisticsDocs).
+
+type StatsMaterializer struct {
+	repo      repository.IStatsRepository
+	pgRepo    repository.IAchievementPGRepository
+	mongoRepo repository.IReportMongoRepository
+	cache     *reportCache
+	ready     atomic.Bool // tabel sudah pernah dibangun ulang penuh
+}
+
+// NewStatsMaterializer: ttl = umur cache respons laporan (0 = tanpa cache)
+func NewStatsMaterializer(
+	repo repository.IStatsRepository,
+	pg repository.IAchievementPGRepository,
+	mg repository.IReportMongoRepository,
+	ttl time.Duration,
+) *StatsMaterializer {
+	return &StatsMaterializer{
+		repo:      repo,
+		pgRepo:    pg,
+		mongoRepo: mg,
+		cache:     newReportCache(ttl),
+	}
+}
+
+// statsMonth = awal bulan createdAt (zona waktu server, sama dengan periodKey)
+func statsMonth(createdAt int64) time.Time {
+	t := time.Unix(createdAt, 0)
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func statsFact(ref model.AchievementReference, doc model.AchievementMongo) model.StatsFact {
+	tags := doc.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return model.StatsFact{
+		AchievementID:   ref.ID,
+		StudentID:       ref.StudentID,
+		Status:          ref.Status,
+		AchievementType: doc.AchievementType,
+		Tags:            tags,
+		Month:           statsMonth(doc.CreatedAt),
+	}
+}
+
+// Refresh memperbarui kontribusi satu prestasi; aman dipanggil pada nil
+func (m *StatsMaterializer) Refresh(ctx context.Context, achievementID string) {
+	if m == nil {
+		return
+	}
+	defer m.cache.Clear()
+
+	ref, err := m.pgRepo.GetByID(ctx, achievementID)
+	if err != nil {
+		log.Printf("⚠️ gagal memperbarui statistik prestasi %s: %v", achievementID, err)
+		return
+	}
+
+	var fact *model.StatsFact
+	if ref.Status != StatusDeleted {
+		docs, err := statisticsDocs(ctx, m.mongoRepo, []model.AchievementReference{ref}, nil, model.ReportFilter{})
+		if err != nil {
+			log.Printf("⚠️ gagal memperbarui statistik prestasi %s: %v", achievementID, err)
+			return
+		}
+		if doc, ok := docs[ref.MongoAchievementID]; ok {
+			f := statsFact(ref, doc)
+			fact = &f
+		}
+	}
+
+	if err := m.repo.ApplyFact(ctx, achievementID, fact); err != nil {
+		log.Printf("⚠️ gagal memperbarui statistik prestasi %s: %v", achievementID, err)
+	}
+}
+
+// Rebuild menghitung ulang seluruh tabel statistik dari PostgreSQL + MongoDB.
+// Snapshot dibaca setelah tabel statistik dikunci (lihat IStatsRepository).
+func (m *StatsMaterializer) Rebuild(ctx context.Context) (model.StatsRebuildResult, error) {
+	start := time.Now()
+
+	count := 0
+	rebuiltAt, err := m.repo.Rebuild(ctx, func(ctx context.Context) ([]model.StatsFact, error) {
+		facts, err := m.snapshot(ctx)
+		count = len(facts)
+		return facts, err
+	})
+	if err != nil {
+		return model.StatsRebuildResult{}, err
+	}
+	m.ready.Store(true)
+	m.cache.Clear()
+
+	return model.StatsRebuildResult{
+		Achievements: count,
+		RebuiltAt:    rebuiltAt,
+		DurationMs:   time.Since(start).Milliseconds(),
+	}, nil
+}
+
+// snapshot = fakta seluruh prestasi yang belum dihapus
+func (m *StatsMaterializer) snapshot(ctx context.Context) ([]model.StatsFact, error) {
+	refs, err := m.pgRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	live := make([]model.AchievementReference, 0, len(refs))
+	ids := make([]primitive.ObjectID, 0, len(refs))
+	for _, r := range refs {
+		if r.Status == StatusDeleted {
+			continue
+		}
+		oid, err := primitive.ObjectIDFromHex(r.MongoAchievementID)
+		if err != nil {
+			continue
+		}
+		live = append(live, r)
+		ids = append(ids, oid)
+	}
+
+	docs, err := m.mongoRepo.FindStatsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	facts := make([]model.StatsFact, 0, len(live))
+	for _, r := range live {
+		if doc, ok := docs[r.MongoAchievementID]; ok {
+			facts = append(facts, statsFact(r, doc))
+		}
+	}
+	return facts, nil
+}
+
+// InvalidateCache mengosongkan cache respons laporan tanpa menyentuh tabel
+// statistik, mis. setelah dosen wali mahasiswa berubah (scope advisor:<id>
+// berubah isinya). Aman dipanggil pada nil.
+func (m *StatsMaterializer) InvalidateCache() {
+	if m == nil {
+		return
+	}
+	m.cache.Clear()
+}
+
+// EnsureBuilt membangun tabel statistik saat start jika belum pernah dibangun
+func (m *StatsMaterializer) EnsureBuilt(ctx context.Context) {
+	if m.isReady(ctx) {
+		return
+	}
+	res, err := m.Rebuild(ctx)
+	if err != nil {
+		log.Printf("⚠️ gagal membangun statistik materialized: %v", err)
+		return
+	}
+	log.Printf("📊 Statistik materialized dibangun: %d prestasi (%d ms)", res.Achievements, res.DurationMs)
+}
+
+func (m *StatsMaterializer) isReady(ctx context.Context) bool {
+	if m.ready.Load() {
+		return true
+	}
+	at, err := m.repo.RebuiltAt(ctx)
+	if err != nil || at == nil {
+		return false
+	}
+	m.ready.Store(true)
+	return true
+}
+
+// statsQuery: ok = false jika filter tanggal tidak sejajar bulan
+func statsQuery(studentIDs []string, f model.ReportFilter) (model.StatsQuery, bool) {
+	q := model.StatsQuery{StudentIDs: studentIDs, Statuses: f.Statuses, AchievementType: f.AchievementType}
+
+	for _, b := range []struct {
+		in  *time.Time
+		out **time.Time
+	}{{f.From, &q.From}, {f.To, &q.To}} {
+		if b.in == nil {
+			continue
+		}
+		if b.in.Day() != 1 {
+			return q, false
+		}
+		month := time.Date(b.in.Year(), b.in.Month(), 1, 0, 0, 0, 0, time.UTC)
+		*b.out = &month
+	}
+	return q, true
+}
+
+// Statistics membaca tabel materialized; ok = false → hitung langsung
+func (m *StatsMaterializer) Statistics(
+	ctx context.Context,
+	studentIDs []string,
+	students map[string]model.StudentDetail,
+	f model.ReportFilter,
+) (model.StudentAchievementStat, bool, error) {
+	if m == nil || !m.isReady(ctx) {
+		return model.StudentAchievementStat{}, false, nil
+	}
+	q, ok := statsQuery(studentIDs, f)
+	if !ok {
+		return model.StudentAchievementStat{}, false, nil
+	}
+
+	// filter program studi / angkatan diterapkan ke daftar mahasiswa
+	if f.ProgramStudy != "" || f.AcademicYear != "" {
+		q.StudentIDs = []string{}
+		for _, sid := range studentIDs {
+			if st, ok := students[sid]; ok && matchStudent(f, st) {
+				q.StudentIDs = append(q.StudentIDs, sid)
+			}
+		}
+	}
+
+	types, err := m.repo.CountTypes(ctx, q)
+	if err != nil {
+		return model.StudentAchievementStat{}, false, err
+	}
+	tags, err := m.repo.CountTags(ctx, q)
+	if err != nil {
+		return model.StudentAchievementStat{}, false, err
+	}
+
+	stats := model.StudentAchievementStat{
+		PerType:     map[string]int{},
+		PerPeriod:   map[string]int{},
+		Competition: map[string]int{},
+		TopStudents: []model.TopStudentDetail{},
+	}
+
+	counter := map[string]int{}
+	for _, c := range types {
+		stats.PerType[c.Key] += c.Total
+		stats.PerPeriod[periodKey(c.Month, f.Period)] += c.Total
+		counter[c.StudentID] += c.Total
+	}
+	for _, c := range tags {
+		stats.Competition[c.Key] += c.Total
+	}
+
+	stats.TopStudents = topStudents(counter, students)
+
+	return stats, true, nil
+}
+
+func (m *StatsMaterializer) cached(key string) (model.StudentAchievementStat, bool) {
+	if m == nil {
+		return model.StudentAchievementStat{}, false
+	}
+	return m.cache.Get(key)
+}
+
+func (m *StatsMaterializer) store(key string, stats model.StudentAchievementStat) {
+	if m != nil {
+		m.cache.Set(key, stats)
+	}
+}
+
+// ---------- TTL cache respons statistik ----------
+// key = scope role (all / advisor:<id> / student:<id>) + ringkasan filter
+
+type reportCacheEntry struct {
+	stats   model.StudentAchievementStat
+	expires time.Time
+}
+
+type reportCache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]reportCacheEntry
+}
+
+func newReportCache(ttl time.Duration) *reportCache {
+	return &reportCache{ttl: ttl, now: time.Now, entries: map[string]reportCacheEntry{}}
+}
+
+func (c *reportCache) Get(key string) (model.StudentAchievementStat, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return model.StudentAchievementStat{}, false
+	}
+	if !c.now().Before(e.expires) {
+		delete(c.entries, key)
+		return model.StudentAchievementStat{}, false
+	}
+	return e.stats, true
+}
+
+func (c *reportCache) Set(key string, stats model.StudentAchievementStat) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = reportCacheEntry{stats: stats, expires: c.now().Add(c.ttl)}
+}
+
+func (c *reportCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]reportCacheEntry{}
+}
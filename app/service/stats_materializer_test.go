@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var statsStudents = map[string]model.StudentDetail{
+	"s1": {FullName: "Andi", ProgramStudy: "Teknik Informatika", AcademicYear: "2022"},
+	"s2": {FullName: "Citra", ProgramStudy: "Sistem Informasi", AcademicYear: "2023"},
+}
+
+func seedStat(pg *repository.MockAchievementPGRepository, mg *repository.MockAchievementMongoRepository, id, studentID, status, achType string, created time.Time, tags ...string) {
+	oid := primitive.NewObjectID()
+	mg.Data[oid.Hex()] = model.AchievementMongo{AchievementType: achType, CreatedAt: created.Unix(), Tags: tags}
+	pg.Data[id] = model.AchievementReference{ID: id, StudentID: studentID, MongoAchievementID: oid.Hex(), Status: status}
+}
+
+// liveStats menghitung statistik langsung dari PG + Mongo sebagai pembanding.
+func liveStats(t *testing.T, pg *repository.MockAchievementPGRepository, mg *repository.MockAchievementMongoRepository, filter model.ReportFilter) model.StudentAchievementStat {
+	ctx := context.Background()
+	refs, _ := pg.GetByStudentIDs(ctx, []string{"s1", "s2"})
+	docs, err := statisticsDocs(ctx, mg, refs, statsStudents, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return aggregateStatistics(refs, statsStudents, filter, docs)
+}
+
+func sameStats(t *testing.T, got, want model.StudentAchievementStat) {
+	t.Helper()
+	for name, pair := range map[string][2]map[string]int{
+		"per_type":          {got.PerType, want.PerType},
+		"per_period":        {got.PerPeriod, want.PerPeriod},
+		"competition_level": {got.Competition, want.Competition},
+	} {
+		if len(pair[0]) != len(pair[1]) {
+			t.Errorf("%s: got %v, want %v", name, pair[0], pair[1])
+			continue
+		}
+		for k, v := range pair[1] {
+			if pair[0][k] != v {
+				t.Errorf("%s: got %v, want %v", name, pair[0], pair[1])
+				break
+			}
+		}
+	}
+
+	// top students: isi DAN urutan harus sama di kedua jalur
+	counts := func(list []model.TopStudentDetail) string {
+		out := []string{}
+		for _, s := range list {
+			out = append(out, fmt.Sprintf("%s:%s:%d", s.StudentID, s.FullName, s.Count))
+		}
+		return strings.Join(out, ",")
+	}
+	if g, w := counts(got.TopStudents), counts(want.TopStudents); g != w {
+		t.Errorf("top students: got %v, want %v", g, w)
+	}
+}
+
+func TestStatsMaterializer_TopStudentsOrderMatchesLive(t *testing.T) {
+	ctx := context.Background()
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	repo := repository.NewMockStatsRepository()
+	m := NewStatsMaterializer(repo, pg, mg, time.Minute)
+
+	// s1 muncul lebih dulu tetapi prestasinya lebih sedikit; pada filter
+	// competition keduanya seri → urut student_id
+	jan := time.Date(2026, 1, 15, 10, 0, 0, 0, time.Local)
+	seedStat(pg, mg, "a1", "s1", StatusVerified, "competition", jan)
+	seedStat(pg, mg, "a2", "s2", StatusVerified, "competition", jan)
+	seedStat(pg, mg, "a3", "s2", StatusVerified, "publication", jan)
+
+	if _, err := m.Rebuild(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for wantOrder, filter := range map[string]model.ReportFilter{
+		"s2:Citra:2,s1:Andi:1": {Period: PeriodYear},
+		"s1:Andi:1,s2:Citra:1": {AchievementType: "competition", Period: PeriodYear},
+	} {
+		got, ok, err := m.Statistics(ctx, []string{"s1", "s2"}, statsStudents, filter)
+		if err != nil || !ok {
+			t.Fatalf("expected materialized statistics, ok=%v err=%v", ok, err)
+		}
+		live := liveStats(t, pg, mg, filter)
+		sameStats(t, got, live)
+
+		order := []string{}
+		for _, s := range live.TopStudents {
+			order = append(order, fmt.Sprintf("%s:%s:%d", s.StudentID, s.FullName, s.Count))
+		}
+		if strings.Join(order, ",") != wantOrder {
+			t.Errorf("unexpected top students order: got %v, want %s", order, wantOrder)
+		}
+	}
+}
+
+func TestStatsMaterializer_IncrementalMatchesLive(t *testing.T) {
+	ctx := context.Background()
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	repo := repository.NewMockStatsRepository()
+	m := NewStatsMaterializer(repo, pg, mg, time.Minute)
+
+	jan := time.Date(2026, 1, 15, 10, 0, 0, 0, time.Local)
+	mar := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	seedStat(pg, mg, "a1", "s1", StatusVerified, "competition", jan, "Nasional")
+	seedStat(pg, mg, "a2", "s1", StatusSubmitted, "publication", mar)
+	seedStat(pg, mg, "a3", "s2", StatusVerified, "competition", mar, "Internasional", "Nasional")
+
+	if _, err := m.Rebuild(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// transisi setelah rebuild: submitted → verified, prestasi baru, lalu dihapus
+	ref := pg.Data["a2"]
+	ref.Status = StatusVerified
+	pg.Data["a2"] = ref
+	m.Refresh(ctx, "a2")
+
+	seedStat(pg, mg, "a4", "s2", StatusDraft, "competition", mar)
+	m.Refresh(ctx, "a4")
+	ref = pg.Data["a4"]
+	ref.Status = StatusDeleted
+	pg.Data["a4"] = ref
+	m.Refresh(ctx, "a4")
+
+	if _, ok := repo.Facts["a4"]; ok {
+		t.Error("deleted achievement must be removed from the facts")
+	}
+
+	from := time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2026, 4, 1, 0, 0, 0, 0, time.Local)
+	for _, filter := range []model.ReportFilter{
+		{Statuses: []string{StatusVerified}, Period: PeriodYear},
+		{Period: PeriodSemester},
+		{Statuses: []string{StatusVerified}, ProgramStudy: "sistem informasi", Period: PeriodMonth},
+		{From: &from, To: &to, AchievementType: "Competition", Period: PeriodMonth},
+	} {
+		got, ok, err := m.Statistics(ctx, []string{"s1", "s2"}, statsStudents, filter)
+		if err != nil || !ok {
+			t.Fatalf("expected materialized statistics, ok=%v err=%v", ok, err)
+		}
+		sameStats(t, got, liveStats(t, pg, mg, filter))
+	}
+}
+
+func TestStatsMaterializer_FallsBackToLive(t *testing.T) {
+	ctx := context.Background()
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	repo := repository.NewMockStatsRepository()
+	m := NewStatsMaterializer(repo, pg, mg, time.Minute)
+	seedStat(pg, mg, "a1", "s1", StatusVerified, "competition", time.Now())
+
+	// belum pernah dibangun ulang
+	if _, ok, _ := m.Statistics(ctx, []string{"s1"}, statsStudents, model.ReportFilter{}); ok {
+		t.Error("statistics must not be served before the first rebuild")
+	}
+
+	m.Rebuild(ctx)
+
+	// rentang tanggal tidak sejajar bulan
+	from := time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)
+	if _, ok, _ := m.Statistics(ctx, []string{"s1"}, statsStudents, model.ReportFilter{From: &from}); ok {
+		t.Error("day-level date ranges must be computed live")
+	}
+
+	// nil materializer = selalu langsung
+	var none *StatsMaterializer
+	none.Refresh(ctx, "a1")
+	if _, ok, _ := none.Statistics(ctx, []string{"s1"}, statsStudents, model.ReportFilter{}); ok {
+		t.Error("nil materializer must fall back")
+	}
+}
+
+func TestReportCache_TTLAndInvalidation(t *testing.T) {
+	ctx := context.Background()
+	pg := repository.NewMockAchievementPGRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	repo := repository.NewMockStatsRepository()
+	m := NewStatsMaterializer(repo, pg, mg, time.Minute)
+	seedStat(pg, mg, "a1", "s1", StatusVerified, "competition", time.Now())
+
+	now := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	m.cache.now = func() time.Time { return now }
+
+	stats := model.StudentAchievementStat{PerType: map[string]int{"competition": 1}}
+	m.store("all|status: verified", stats)
+	if got, ok := m.cached("all|status: verified"); !ok || got.PerType["competition"] != 1 {
+		t.Fatal("expected cache hit")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := m.cached("all|status: verified"); ok {
+		t.Error("entry must expire after the TTL")
+	}
+
+	// setiap transisi mengosongkan cache
+	m.store("all|status: verified", stats)
+	m.Refresh(ctx, "a1")
+	if _, ok := m.cached("all|status: verified"); ok {
+		t.Error("refresh must invalidate cached responses")
+	}
+	if repo.Applied != 1 {
+		t.Errorf("expected one incremental update, got %d", repo.Applied)
+	}
+	// perubahan dosen wali / nama jenis hanya mengosongkan cache
+	m.store("all|status: verified", stats)
+	m.InvalidateCache()
+	if _, ok := m.cached("all|status: verified"); ok {
+		t.Error("InvalidateCache must drop cached responses")
+	}
+	if repo.Applied != 1 {
+		t.Errorf("cache invalidation must not touch the stats tables, got %d updates", repo.Applied)
+	}
+	none := (*StatsMaterializer)(nil)
+	none.InvalidateCache()
+}
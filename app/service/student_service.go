@@ -6,26 +6,31 @@ import (
     "context"
 
     "github.com/gofiber/fiber/v2"
-    "github.com/golang-jwt/jwt/v5"
     "go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type StudentService struct {
-    repo      *repository.StudentRepository
+    repo      repository.IStudentRepository
     achPGRepo *repository.AchievementPGRepository
     achMongo  *repository.AchievementMongoRepository
+    notifier  *Notifier
+    stats     *StatsMaterializer
 }
 
 // 🔥 CONSTRUCTOR 
 func NewStudentService(
-    repo *repository.StudentRepository,
+    repo repository.IStudentRepository,
     achPG *repository.AchievementPGRepository,
     achMongo *repository.AchievementMongoRepository,
+    notifier *Notifier,
+    stats *StatsMaterializer,
 ) *StudentService {
     return &StudentService{
         repo:      repo,
         achPGRepo: achPG,
         achMongo:  achMongo,
+        notifier:  notifier,
+        stats:     stats,
     }
 }
 
@@ -130,11 +135,19 @@ func (s *StudentService) UpdateAdvisor(c *fiber.Ctx) error {
         return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
     }
 
-    err := s.repo.UpdateAdvisor(context.Background(), studentID, body.AdvisorID)
+    ctx := context.Background()
+
+    err := s.repo.UpdateAdvisor(ctx, studentID, body.AdvisorID)
     if err != nil {
         return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
 
+    // statistik dosen wali dihitung per mahasiswa bimbingan → cache lama basi
+    s.stats.InvalidateCache()
+
+    // Notifikasi ke mahasiswa & dosen wali baru
+    s.notifier.AdvisorAssigned(ctx, studentID, body.AdvisorID)
+
     return c.JSON(fiber.Map{"message": "Advisor berhasil diperbarui"})
 }
 
@@ -143,7 +156,7 @@ func (s *StudentService) UpdateAdvisor(c *fiber.Ctx) error {
 // =====================================================
 // GetAchievements godoc
 // @Summary Get student achievements
-// @Description Mengambil daftar prestasi mahasiswa (role-based access)
+// @Description Mengambil daftar prestasi mahasiswa (milik sendiri, mahasiswa bimbingan, atau scope:all)
 // @Tags Student
 // @Security BearerAuth
 // @Produce json
@@ -157,33 +170,13 @@ func (s *StudentService) GetAchievements(c *fiber.Ctx) error {
     ctx := context.Background()
     targetStudentID := c.Params("id")
 
-    claims := c.Locals("user").(jwt.MapClaims)
-    role := claims["role_name"].(string)
-    userID := claims["user_id"].(string)
-
-    // Mahasiswa hanya bisa lihat dirinya sendiri
-    if role == "Mahasiswa" {
-        sid, err := s.repo.GetStudentIDByUserID(ctx, userID)
-        if err != nil {
-            return c.Status(404).JSON(fiber.Map{"error": "student not found"})
-        }
-
-        if sid != targetStudentID {
-            return c.Status(403).JSON(fiber.Map{"error": "Forbidden"})
-        }
+    // diri sendiri / mahasiswa bimbingan / scope:all
+    access, err := callerScope(ctx, c, s.repo)
+    if err != nil {
+        return scopeError(c, err)
     }
-
-    // Dosen Wali hanya bisa lihat mahasiswa bimbingannya
-    if role == "Dosen Wali" {
-        lecturerID, err := s.repo.GetLecturerIDByUserID(ctx, userID)
-        if err != nil {
-            return c.Status(404).JSON(fiber.Map{"error": "lecturer not found"})
-        }
-
-        ok, err := s.repo.IsStudentUnderAdvisor(ctx, targetStudentID, lecturerID)
-        if err != nil || !ok {
-            return c.Status(403).JSON(fiber.Map{"error": "Not your advisee"})
-        }
+    if !access.canSeeStudent(ctx, s.repo, targetStudentID) {
+        return c.Status(403).JSON(fiber.Map{"error": "Forbidden"})
     }
 
     // Ambil reference PostgreSQL
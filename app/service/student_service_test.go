@@ -5,6 +5,8 @@ import (
 
 	"backenduas/app/model"
 	"backenduas/app/repository"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -196,3 +198,37 @@ func TestGetAchievements_DosenWali_Forbidden(t *testing.T) {
 		t.Fatalf("expected forbidden error")
 	}
 }
+
+func TestGetAchievements_CustomRoleForbiddenForOtherStudent(t *testing.T) {
+	st := repository.NewMockStudentRepository()
+	st.UserToStudent["u9"] = "s1"
+	st.UserToLecturer["uLect"] = "lect1"
+	st.AdvisorMap["s1"] = "lect1"
+
+	svc := NewStudentService(st, nil, nil, nil, nil)
+	app := fiber.New()
+	as := func(claims jwt.MapClaims) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			c.Locals("user", claims)
+			return svc.GetAchievements(c)
+		}
+	}
+	// role buatan Admin dengan achievement:read, tertaut ke s1
+	app.Get("/kaprodi/students/:id/achievements", as(jwt.MapClaims{
+		"user_id": "u9", "role_name": "Kaprodi", "permissions": []interface{}{"achievement:read"},
+	}))
+	// dosen wali lect1 membuka mahasiswa di luar bimbingan
+	app.Get("/lecturer/students/:id/achievements", as(jwt.MapClaims{"user_id": "uLect", "role_name": "Dosen Wali"}))
+	// tanpa keterkaitan mahasiswa/dosen & tanpa scope:all
+	app.Get("/admin/students/:id/achievements", as(jwt.MapClaims{"user_id": "uX", "role_name": "Admin"}))
+
+	for _, path := range []string{
+		"/kaprodi/students/s2/achievements",
+		"/lecturer/students/s2/achievements",
+		"/admin/students/s1/achievements",
+	} {
+		if code, _ := call(t, app, "GET", path, ""); code != 403 {
+			t.Errorf("%s: expected 403, got %d", path, code)
+		}
+	}
+}
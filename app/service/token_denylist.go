@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// =====================================================
+//  DENYLIST ACCESS TOKEN
+// =====================================================
+//
+// Access token berlaku 24 jam, jadi logout / penonaktifan user harus
+// mencabutnya secara eksplisit. Dicek JWTProtected di setiap request dari
+// salinan in-memory; salinan dimuat ulang dari database setiap `refresh`
+// (pencabutan dari instance lain berlaku paling lambat setelah interval itu),
+// pencabutan lokal langsung berlaku.
+//
+// Dicabut jika: jti ada di denylist, sesi (sid) dicabut, atau token terbit
+// (iat) sebelum / pada waktu pencabutan seluruh token user.
+
+type TokenDenylist struct {
+	repo     repository.ITokenDenylistRepository
+	sessions repository.IAuthRepository
+	refresh  time.Duration
+	now      func() time.Time
+
+	loadMu   sync.Mutex // satu reload dalam satu waktu
+	mu       sync.RWMutex
+	list     model.TokenDenylist
+	loadedAt time.Time
+	loaded   bool
+}
+
+func NewTokenDenylist(repo repository.ITokenDenylistRepository, sessions repository.IAuthRepository, refresh time.Duration) *TokenDenylist {
+	return &TokenDenylist{
+		repo:     repo,
+		sessions: sessions,
+		refresh:  refresh,
+		now:      time.Now,
+		list:     model.TokenDenylist{Tokens: map[string]time.Time{}, Users: map[string]time.Time{}, Sessions: map[string]bool{}},
+	}
+}
+
+// claimTime membaca klaim NumericDate (iat / exp), boleh pecahan detik
+func claimTime(claims jwt.MapClaims, key string) (time.Time, bool) {
+	switch v := claims[key].(type) {
+	case float64: // hasil parse JSON
+		return time.UnixMilli(int64(v * 1000)), true
+	case int64:
+		return time.Unix(v, 0), true
+	}
+	return time.Time{}, false
+}
+
+func (d *TokenDenylist) ensureLoaded(ctx context.Context) error {
+	d.mu.RLock()
+	fresh := d.loaded && d.now().Sub(d.loadedAt) < d.refresh
+	d.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	d.loadMu.Lock()
+	defer d.loadMu.Unlock()
+
+	d.mu.RLock()
+	fresh = d.loaded && d.now().Sub(d.loadedAt) < d.refresh
+	d.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	// pencabutan yang lebih tua dari umur access token tidak relevan lagi
+	since := d.now().Add(-accessTokenTTL)
+	if err := d.repo.PurgeDenylist(ctx, since); err != nil {
+		log.Printf("⚠️ gagal membersihkan denylist token: %v", err)
+	}
+
+	list, err := d.repo.LoadDenylist(ctx, since)
+	if err != nil {
+		if d.loaded {
+			// tetap pakai salinan lama; coba lagi pada request berikutnya
+			log.Printf("⚠️ gagal memuat ulang denylist token: %v", err)
+			return nil
+		}
+		return err
+	}
+
+	d.mu.Lock()
+	d.list, d.loadedAt, d.loaded = list, d.now(), true
+	d.mu.Unlock()
+	return nil
+}
+
+// IsRevoked memenuhi middleware.TokenRevocation. Token tanpa jti / iat
+// (terbit sebelum denylist ada) dianggap dicabut.
+func (d *TokenDenylist) IsRevoked(ctx context.Context, claims jwt.MapClaims) (bool, error) {
+	jti, _ := claims["jti"].(string)
+	userID, _ := claims["user_id"].(string)
+	sid, _ := claims["sid"].(string)
+	iat, ok := claimTime(claims, "iat")
+	if jti == "" || !ok {
+		return true, nil
+	}
+
+	if err := d.ensureLoaded(ctx); err != nil {
+		return false, err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if _, ok := d.list.Tokens[jti]; ok {
+		return true, nil
+	}
+	if d.list.Sessions[sid] {
+		return true, nil
+	}
+	// iat sama dengan waktu pencabutan (milidetik yang sama) ikut dicabut
+	if before, ok := d.list.Users[userID]; ok && !iat.After(before) {
+		return true, nil
+	}
+	return false, nil
+}
+
+// RevokeToken mencabut satu access token (logout)
+func (d *TokenDenylist) RevokeToken(ctx context.Context, claims jwt.MapClaims, reason string) error {
+	if d == nil {
+		return nil
+	}
+	jti, _ := claims["jti"].(string)
+	userID, _ := claims["user_id"].(string)
+	exp, ok := claimTime(claims, "exp")
+	if jti == "" || !ok {
+		return nil
+	}
+
+	if err := d.repo.RevokeToken(ctx, jti, userID, exp, reason); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	d.list.Tokens[jti] = exp
+	d.mu.Unlock()
+	return nil
+}
+
+// RevokeSession menandai sesi yang sudah dicabut di database agar access
+// token sesi itu langsung ditolak di instance ini
+func (d *TokenDenylist) RevokeSession(sessionID string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.list.Sessions[sessionID] = true
+	d.mu.Unlock()
+}
+
+// RevokeUser mencabut semua access token user yang sudah terbit; endSessions
+// juga mencabut semua sesi (refresh token) sehingga user harus login ulang
+func (d *TokenDenylist) RevokeUser(ctx context.Context, userID, reason string, endSessions bool) error {
+	if d == nil {
+		return nil
+	}
+
+	// presisi milidetik = presisi iat (lihat accessClaims)
+	before := d.now().Truncate(time.Millisecond)
+	if err := d.repo.RevokeUserTokens(ctx, userID, before, reason); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	if before.After(d.list.Users[userID]) {
+		d.list.Users[userID] = before
+	}
+	d.mu.Unlock()
+
+	if endSessions {
+		if _, err := d.sessions.RevokeUserSessions(ctx, userID, reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
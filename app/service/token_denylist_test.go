@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+	"backenduas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func issuedClaims(userID, sid string, issued time.Time) jwt.MapClaims {
+	claims := accessClaims(&model.User{ID: userID}, nil, sid)
+	claims["iat"] = float64(issued.UnixMilli()) / 1000
+	return claims
+}
+
+func isRevoked(t *testing.T, list *TokenDenylist, claims jwt.MapClaims) bool {
+	t.Helper()
+	revoked, err := list.IsRevoked(context.Background(), claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return revoked
+}
+
+func TestTokenDenylist_RevocationKinds(t *testing.T) {
+	ctx := context.Background()
+	auth := repository.NewMockAuthRepository()
+	repo := repository.NewMockTokenDenylistRepository()
+	clock := time.Now()
+	list := NewTokenDenylist(repo, auth, 30*time.Second)
+	list.now = func() time.Time { return clock }
+
+	old := issuedClaims("u1", "s1", clock.Add(-time.Hour))
+	other := issuedClaims("u1", "s2", clock.Add(-time.Hour))
+	if isRevoked(t, list, old) {
+		t.Fatal("fresh token must be accepted")
+	}
+
+	// token lama tanpa jti / iat
+	if !isRevoked(t, list, jwt.MapClaims{"user_id": "u1", "exp": float64(clock.Unix())}) {
+		t.Error("tokens without jti must be rejected")
+	}
+
+	list.RevokeToken(ctx, old, model.RevokedLogout)
+	if !isRevoked(t, list, old) || isRevoked(t, list, other) {
+		t.Error("logout must revoke exactly the presented token")
+	}
+
+	list.RevokeSession("s2")
+	if !isRevoked(t, list, other) {
+		t.Error("tokens of a revoked session must be rejected")
+	}
+
+	// logout-all: token sebelum pencabutan ditolak, login sesudahnya (milidetik berikutnya) diterima
+	before := issuedClaims("u2", "s3", clock.Add(-time.Minute))
+	list.RevokeUser(ctx, "u2", model.RevokedLogoutAll, false)
+	after := issuedClaims("u2", "s4", clock.Add(time.Millisecond))
+	if !isRevoked(t, list, before) || isRevoked(t, list, after) {
+		t.Error("user revocation must only reject tokens issued before it")
+	}
+}
+
+func TestTokenDenylist_ReloadsFromDatabase(t *testing.T) {
+	auth := repository.NewMockAuthRepository()
+	repo := repository.NewMockTokenDenylistRepository()
+	clock := time.Now()
+	list := NewTokenDenylist(repo, auth, 30*time.Second)
+	list.now = func() time.Time { return clock }
+	claims := issuedClaims("u1", "s1", clock.Add(-time.Minute))
+	isRevoked(t, list, claims)
+
+	// dicabut oleh instance lain
+	repo.Denylist.Tokens[claims["jti"].(string)] = clock.Add(time.Hour)
+	if isRevoked(t, list, claims) || repo.Loads != 1 {
+		t.Errorf("denylist must be served from memory until the refresh interval, loads=%d", repo.Loads)
+	}
+
+	clock = clock.Add(30 * time.Second)
+	if !isRevoked(t, list, claims) || repo.Loads != 2 {
+		t.Errorf("denylist must reload after the refresh interval, loads=%d", repo.Loads)
+	}
+
+	// database tidak tersedia: salinan lama tetap dipakai
+	repo.FailWith = errors.New("connection refused")
+	clock = clock.Add(time.Minute)
+	if !isRevoked(t, list, claims) {
+		t.Error("stale denylist must be kept when reloading fails")
+	}
+
+	down := repository.NewMockTokenDenylistRepository()
+	down.FailWith = errors.New("connection refused")
+	if _, err := NewTokenDenylist(down, auth, 30*time.Second).IsRevoked(context.Background(), claims); err == nil {
+		t.Error("first load failure must be reported")
+	}
+}
+
+func TestJWTProtected_RejectsRevokedTokens(t *testing.T) {
+	auth := repository.NewMockAuthRepository()
+	list := NewTokenDenylist(repository.NewMockTokenDenylistRepository(), auth, 30*time.Second)
+	auth.SeedUser("u1", "andi", "andi123", "Mahasiswa")
+	middleware.UseTokenRevocation(list)
+	middleware.UseKeyRing(testKeys)
+	defer middleware.UseTokenRevocation(nil)
+	defer middleware.UseKeyRing(nil)
+
+	logic := NewAuthLogicService(auth, testKeys)
+	laptop, _, _, _ := logic.LoginLogic("andi", "andi123")
+	phone, _, _, _ := logic.LoginLogic("andi", "andi123")
+
+	svc := NewAuthService(auth, list, testKeys, nil)
+	app := fiber.New()
+	app.Use(middleware.JWTProtected())
+	app.Get("/profile", func(c *fiber.Ctx) error { return c.SendStatus(204) })
+	app.Post("/logout-all", svc.LogoutAll)
+
+	request := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	if code := request("GET", "/profile", phone); code != 204 {
+		t.Fatalf("expected access before logout-all, got %d", code)
+	}
+	if code := request("POST", "/logout-all", laptop); code != 200 {
+		t.Fatalf("unexpected status %d", code)
+	}
+	for _, tok := range []string{laptop, phone} {
+		if code := request("GET", "/profile", tok); code != 401 {
+			t.Errorf("token must be revoked after logout-all, got %d", code)
+		}
+	}
+	for _, s := range auth.Sessions {
+		if s.RevokedAt == nil {
+			t.Errorf("session %s must be revoked", s.ID)
+		}
+	}
+
+	// login ulang tetap bisa
+	again, _, _, _ := logic.LoginLogic("andi", "andi123")
+	if code := request("GET", "/profile", again); code != 204 {
+		t.Errorf("new login after logout-all must be accepted, got %d", code)
+	}
+}
+
+func TestUserService_RevokesTokensOnAdminChanges(t *testing.T) {
+	auth := repository.NewMockAuthRepository()
+	repo := repository.NewMockTokenDenylistRepository()
+	list := NewTokenDenylist(repo, auth, 30*time.Second)
+	auth.SeedUser("u1", "andi", "andi123", "Mahasiswa")
+	_, refresh, _, _ := NewAuthLogicService(auth, testKeys).LoginLogic("andi", "andi123")
+
+	users := repository.NewMockUserRepository()
+	users.Data["u1"] = model.User{ID: "u1", Username: "andi", RoleID: "role-1", IsActive: true}
+	svc := NewUserService(users, list, nil)
+
+	app := fiber.New()
+	app.Put("/users/:id/role", svc.UpdateRole)
+	app.Put("/users/:id/status", svc.UpdateStatus)
+	app.Delete("/users/:id", svc.Delete)
+
+	send := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+	issued := issuedClaims("u1", "s1", time.Now().Add(-time.Second))
+
+	// ganti role: access token dicabut, sesi tetap (refresh memberi role baru)
+	if code := send("PUT", "/users/u1/role", `{"role_id":"role-2"}`); code != 200 {
+		t.Fatalf("unexpected status %d", code)
+	}
+	if !isRevoked(t, list, issued) {
+		t.Error("role change must revoke issued access tokens")
+	}
+	if _, err := rotateSession(context.Background(), auth, testKeys, refresh); err != nil {
+		t.Errorf("role change must keep sessions, got %v", err)
+	}
+
+	if code := send("PUT", "/users/u1/status", `{}`); code != 400 {
+		t.Errorf("is_active is required, got %d", code)
+	}
+	if code := send("PUT", "/users/u1/status", `{"is_active":false}`); code != 200 || users.Data["u1"].IsActive {
+		t.Fatalf("user must be deactivated, got %d", code)
+	}
+	for _, s := range auth.Sessions {
+		if s.RevokedAt == nil || s.RevokedReason != model.RevokedUserDeactivated {
+			t.Errorf("deactivation must revoke sessions: %+v", s)
+		}
+	}
+
+	if code := send("DELETE", "/users/u1", ""); code != 200 {
+		t.Errorf("unexpected status %d", code)
+	}
+	if _, ok := repo.Denylist.Users["u1"]; !ok {
+		t.Error("revocation must be persisted for other instances")
+	}
+}
@@ -0,0 +1,408 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"rsc.io/qr"
+)
+
+// =====================================================
+//  TRANSKRIP PRESTASI (SKPI)
+// =====================================================
+//
+// Dokumen hanya memuat prestasi verified. Setiap dokumen yang diterbitkan
+// disimpan (snapshot) dengan kode verifikasi = HMAC-SHA256 isi dokumen,
+// sehingga pihak luar dapat mengecek keasliannya lewat GET /verify/:hash
+// tanpa login. Dokumen mencantumkan URL verifikasi, kode, dan QR code URL
+// tersebut. Key id ikut disimpan agar secret bisa dirotasi tanpa membatalkan
+// dokumen lama.
+type TranscriptService struct {
+	repo        repository.ITranscriptRepository
+	studentRepo repository.IStudentRepository
+	mongoRepo   repository.IAchievementMongoRepository
+
+	keys      TranscriptKeys
+	verifyURL string // prefix URL publik, mis. https://api.kampus.ac.id/api/v1/verify
+	now       func() time.Time
+
+	Location *time.Location // zona waktu tanggal yang dicetak di dokumen
+}
+
+func NewTranscriptService(
+	repo repository.ITranscriptRepository,
+	st repository.IStudentRepository,
+	mg repository.IAchievementMongoRepository,
+	keys TranscriptKeys,
+	verifyURL string,
+) *TranscriptService {
+	return &TranscriptService{
+		repo:        repo,
+		studentRepo: st,
+		mongoRepo:   mg,
+		keys:        keys,
+		verifyURL:   strings.TrimRight(verifyURL, "/"),
+		now:         time.Now,
+		Location:    time.Local,
+	}
+}
+
+var ErrTranscriptNotFound = errors.New("transcript not found")
+
+// sisi QR code URL verifikasi di dokumen (point, termasuk quiet zone)
+const transcriptQRSize = 96.0
+
+// TranscriptLegacyKeyID = key id untuk dokumen yang terbit sebelum key id
+// disimpan (kolom key_id kosong)
+const TranscriptLegacyKeyID = "legacy"
+
+// TranscriptKeys = secret HMAC transkrip per key id. Dokumen baru ditandatangani
+// dengan KeyID; secret lain hanya dipakai memverifikasi dokumen lama.
+type TranscriptKeys struct {
+	KeyID   string
+	Secrets map[string][]byte
+}
+
+// ParseTranscriptKeys membaca TRANSCRIPT_SECRET / TRANSCRIPT_KEY_ID dan daftar
+// secret lama "kid:secret,kid:secret" (TRANSCRIPT_OLD_SECRETS).
+func ParseTranscriptKeys(keyID, secret, old string) (TranscriptKeys, error) {
+	keyID = strings.TrimSpace(keyID)
+	if secret == "" {
+		return TranscriptKeys{}, errors.New("TRANSCRIPT_SECRET is required")
+	}
+	if keyID == "" || keyID == TranscriptLegacyKeyID {
+		return TranscriptKeys{}, fmt.Errorf("invalid transcript key id %q", keyID)
+	}
+
+	keys := TranscriptKeys{KeyID: keyID, Secrets: map[string][]byte{keyID: []byte(secret)}}
+	for _, pair := range strings.Split(old, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kid, value, ok := strings.Cut(pair, ":")
+		kid = strings.TrimSpace(kid)
+		if !ok || kid == "" || value == "" {
+			return TranscriptKeys{}, fmt.Errorf("invalid TRANSCRIPT_OLD_SECRETS entry %q (want kid:secret)", kid)
+		}
+		if _, dup := keys.Secrets[kid]; dup {
+			return TranscriptKeys{}, fmt.Errorf("duplicate transcript key id %q", kid)
+		}
+		keys.Secrets[kid] = []byte(value)
+	}
+	return keys, nil
+}
+
+// transcriptHash = HMAC-SHA256(secret[t.KeyID], isi dokumen tanpa hash)
+func (s *TranscriptService) transcriptHash(t model.Transcript) (string, error) {
+	kid := t.KeyID
+	if kid == "" {
+		kid = TranscriptLegacyKeyID
+	}
+	secret, ok := s.keys.Secrets[kid]
+	if !ok {
+		return "", fmt.Errorf("unknown transcript key id %q", kid)
+	}
+
+	t.Hash = ""
+	payload, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Issue membangun transkrip dari prestasi verified lalu menyimpannya
+func (s *TranscriptService) Issue(ctx context.Context, studentID, issuedBy string) (model.Transcript, error) {
+	st, err := s.studentRepo.GetByID(ctx, studentID)
+	if err != nil {
+		return model.Transcript{}, err
+	}
+
+	entries, err := s.repo.ListVerified(ctx, studentID)
+	if err != nil {
+		return model.Transcript{}, err
+	}
+
+	oids := make([]primitive.ObjectID, 0, len(entries))
+	for _, e := range entries {
+		if oid, err := primitive.ObjectIDFromHex(e.MongoAchievementID); err == nil {
+			oids = append(oids, oid)
+		}
+	}
+	docs, err := s.mongoRepo.FindManyByIDs(ctx, oids)
+	if err != nil {
+		return model.Transcript{}, err
+	}
+
+	t := model.Transcript{
+		StudentID:    st.ID,
+		StudentNIM:   st.StudentID,
+		StudentName:  st.FullName,
+		ProgramStudy: st.ProgramStudy,
+		AcademicYear: st.AcademicYear,
+		KeyID:        s.keys.KeyID,
+		IssuedBy:     issuedBy,
+		IssuedAt:     s.now().UTC().Truncate(time.Second),
+		Entries:      []model.TranscriptEntry{},
+	}
+	if st.AdvisorName != nil {
+		t.AdvisorName = *st.AdvisorName
+	}
+
+	for _, e := range entries {
+		doc, ok := docs[e.MongoAchievementID]
+		if !ok {
+			continue // detail hilang di Mongo → tidak dicetak
+		}
+		e.Title = doc.Title
+		e.AchievementType = doc.AchievementType
+		e.Points = doc.Points
+		t.TotalPoints += doc.Points
+		t.Entries = append(t.Entries, e)
+	}
+
+	if t.Hash, err = s.transcriptHash(t); err != nil {
+		return t, err
+	}
+	if err := s.repo.Create(ctx, &t); err != nil {
+		return t, err
+	}
+	return t, nil
+}
+
+// VerifyHash memeriksa keaslian dokumen dan apakah isinya masih berlaku
+func (s *TranscriptService) VerifyHash(ctx context.Context, hash string) (model.TranscriptVerification, error) {
+	hash = strings.ToLower(strings.TrimSpace(hash))
+
+	t, err := s.repo.GetByHash(ctx, hash)
+	if err != nil {
+		return model.TranscriptVerification{}, ErrTranscriptNotFound
+	}
+
+	// snapshot yang diubah langsung di database tidak lolos
+	expected, err := s.transcriptHash(t)
+	if err != nil || !hmac.Equal([]byte(expected), []byte(hash)) {
+		return model.TranscriptVerification{}, ErrTranscriptNotFound
+	}
+
+	res := model.TranscriptVerification{
+		Valid:            true,
+		Hash:             t.Hash,
+		StudentNIM:       t.StudentNIM,
+		StudentName:      t.StudentName,
+		ProgramStudy:     t.ProgramStudy,
+		IssuedAt:         t.IssuedAt,
+		AchievementCount: len(t.Entries),
+		TotalPoints:      t.TotalPoints,
+		Entries:          t.Entries,
+		AllStillVerified: true,
+	}
+
+	current, err := s.repo.ListVerified(ctx, t.StudentID)
+	if err != nil {
+		return res, err
+	}
+	still := map[string]bool{}
+	for _, e := range current {
+		still[e.AchievementID] = true
+	}
+	for _, e := range t.Entries {
+		if !still[e.AchievementID] {
+			res.AllStillVerified = false
+			break
+		}
+	}
+	return res, nil
+}
+
+// groupHash: 64 hex → 8 kelompok 8 karakter agar mudah dibaca / diketik
+func groupHash(hash string) string {
+	parts := []string{}
+	for i := 0; i < len(hash); i += 8 {
+		end := i + 8
+		if end > len(hash) {
+			end = len(hash)
+		}
+		parts = append(parts, hash[i:end])
+	}
+	return strings.Join(parts, " ")
+}
+
+var indonesianMonths = [...]string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+func formatTanggal(t time.Time) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), indonesianMonths[t.Month()-1], t.Year())
+}
+
+// RenderPDF mencetak transkrip; loc = zona waktu tanggal di dokumen
+func (s *TranscriptService) RenderPDF(t model.Transcript, loc *time.Location) ([]byte, error) {
+	doc := newPDFDocument("SKPI - "+t.StudentName, t.IssuedAt)
+	doc.Footer = "Kode verifikasi: " + groupHash(t.Hash)
+	l := newPDFLayout(doc)
+
+	center := func(text string, size float64, bold bool) {
+		l.ensure(size * 1.6)
+		l.y -= size * 1.6
+		doc.Text((pdfPageWidth-pdfTextWidth(text, size, bold))/2, l.y, size, bold, text)
+	}
+
+	center("SURAT KETERANGAN PENDAMPING IJAZAH", 15, true)
+	center("Daftar Prestasi Mahasiswa Terverifikasi", 11, false)
+	l.Space(6)
+	doc.Line(pdfMargin, l.y, pdfPageWidth-pdfMargin, l.y, 0.8)
+	l.Space(6)
+
+	advisor := t.AdvisorName
+	if advisor == "" {
+		advisor = "-"
+	}
+	l.KeyValue("Nama", orDash(t.StudentName), 10, 100)
+	l.KeyValue("NIM", orDash(t.StudentNIM), 10, 100)
+	l.KeyValue("Program Studi", orDash(t.ProgramStudy), 10, 100)
+	l.KeyValue("Angkatan", orDash(t.AcademicYear), 10, 100)
+	l.KeyValue("Dosen Wali", advisor, 10, 100)
+
+	l.Space(14)
+	l.Heading("Prestasi", 12)
+	if len(t.Entries) == 0 {
+		l.Paragraph("Belum ada prestasi yang terverifikasi.", 10)
+	} else {
+		rows := make([][]string, len(t.Entries))
+		for i, e := range t.Entries {
+			rows[i] = []string{
+				strconv.Itoa(i + 1), orDash(e.Title), orDash(e.AchievementType), strconv.Itoa(e.Points),
+				e.VerifiedAt.In(loc).Format("02-01-2006"), orDash(e.VerifierName),
+			}
+		}
+		l.Table([]pdfColumn{
+			{Header: "No", Width: 0.6, Right: true},
+			{Header: "Prestasi", Width: 4.2},
+			{Header: "Jenis", Width: 1.8},
+			{Header: "Poin", Width: 0.9, Right: true},
+			{Header: "Diverifikasi", Width: 1.5},
+			{Header: "Verifikator", Width: 2.4},
+		}, rows, 8.5)
+	}
+
+	l.Space(8)
+	l.KeyValue("Jumlah prestasi", strconv.Itoa(len(t.Entries)), 10, 100)
+	l.KeyValue("Total poin", strconv.Itoa(t.TotalPoints), 10, 100)
+
+	l.Space(18)
+	l.Heading("Verifikasi Keaslian", 11)
+	l.Paragraph("Diterbitkan "+formatTanggal(t.IssuedAt.In(loc))+". Keaslian dan isi dokumen ini dapat diperiksa tanpa login di:", 9)
+	l.Paragraph(s.verifyURL+"/"+t.Hash, 8)
+	l.Space(4)
+	l.KeyValue("Kode verifikasi", groupHash(t.Hash), 9, 100)
+
+	code, err := qr.Encode(s.verifyURL+"/"+t.Hash, qr.M)
+	if err != nil {
+		return nil, err
+	}
+	l.Space(6)
+	l.ensure(transcriptQRSize)
+	l.y -= transcriptQRSize
+	doc.Modules(pdfMargin, l.y, transcriptQRSize, code.Size, code.Black)
+
+	var buf bytes.Buffer
+	_, err = doc.WriteTo(&buf)
+	return buf.Bytes(), err
+}
+
+// =====================================================
+//
+//	ENDPOINT
+//
+// =====================================================
+// Transcript godoc
+// @Summary Generate achievement transcript (SKPI)
+// @Description PDF berisi prestasi verified beserta verifikator & tanggal, dengan kode verifikasi. Mahasiswa: diri sendiri, Dosen Wali: bimbingan, Admin: semua
+// @Tags Student
+// @Security BearerAuth
+// @Produce application/pdf
+// @Param id path string true "Student ID"
+// @Success 200 {file} file
+// @Failure 403 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /students/{id}/transcript [get]
+func (s *TranscriptService) Transcript(c *fiber.Ctx) error {
+	ctx := context.Background()
+	studentID := c.Params("id")
+
+	claims := c.Locals("user").(jwt.MapClaims)
+	userID := claims["user_id"].(string)
+
+	// diri sendiri / mahasiswa bimbingan / scope:all
+	access, err := callerScope(ctx, c, s.studentRepo)
+	if err != nil {
+		return scopeError(c, err)
+	}
+	if !access.canSeeStudent(ctx, s.studentRepo, studentID) {
+		return c.Status(403).JSON(fiber.Map{"error": "Forbidden"})
+	}
+
+	if _, err := s.studentRepo.GetByID(ctx, studentID); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "student not found"})
+	}
+
+	t, err := s.Issue(ctx, studentID, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	body, err := s.RenderPDF(t, s.Location)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set("Content-Type", "application/pdf")
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="skpi-%s.pdf"`, exportSlug(t.StudentNIM, t.StudentID)))
+	c.Set("X-Transcript-Hash", t.Hash)
+	return c.Send(body)
+}
+
+// Verify godoc
+// @Summary Verify transcript authenticity
+// @Description Endpoint publik (tanpa login) untuk memeriksa kode verifikasi SKPI
+// @Tags Public
+// @Produce json
+// @Param hash path string true "Verification hash"
+// @Success 200 {object} model.TranscriptVerification
+// @Failure 404 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /verify/{hash} [get]
+func (s *TranscriptService) Verify(c *fiber.Ctx) error {
+	// kode boleh diketik berkelompok ("ab12cd34 ef56...")
+	hash := strings.ReplaceAll(c.Params("hash"), " ", "")
+	hash = strings.ReplaceAll(hash, "%20", "")
+
+	res, err := s.VerifyHash(context.Background(), hash)
+	if errors.Is(err, ErrTranscriptNotFound) {
+		return c.Status(404).JSON(fiber.Map{"valid": false, "error": "document not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(res)
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"backenduas/app/model"
+	"backenduas/app/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var transcriptNow = time.Date(2026, 7, 15, 3, 0, 0, 0, time.UTC)
+
+// transcriptKeys("k2:baru", "k1:lama") = kunci k2 untuk menandatangani, sisanya kunci lama
+func transcriptKeys(current string, old ...string) TranscriptKeys {
+	kid, secret, _ := strings.Cut(current, ":")
+	keys, err := ParseTranscriptKeys(kid, secret, strings.Join(old, ","))
+	if err != nil {
+		panic(err)
+	}
+	return keys
+}
+
+// seedTranscript mengisi mahasiswa s1 dengan dua prestasi terverifikasi.
+func seedTranscript(st *repository.MockStudentRepository, mg *repository.MockAchievementMongoRepository, repo *repository.MockTranscriptRepository) {
+	budi := "Dr. Budi"
+	st.Students["s1"] = model.StudentDetail{ID: "s1", StudentID: "2021001", FullName: "Andi", ProgramStudy: "Teknik Informatika", AcademicYear: "2021", AdvisorName: &budi}
+
+	verified := func(id, title string, points int, day int) model.TranscriptEntry {
+		oid := primitive.NewObjectID()
+		mg.Data[oid.Hex()] = model.AchievementMongo{ID: oid, StudentID: "s1", Title: title, AchievementType: "competition", Points: points}
+		return model.TranscriptEntry{
+			AchievementID: id, MongoAchievementID: oid.Hex(), VerifierName: budi,
+			VerifiedAt: time.Date(2026, 3, day, 9, 0, 0, 0, time.UTC),
+		}
+	}
+	repo.Verified["s1"] = []model.TranscriptEntry{
+		verified("a1", "Juara 1 Hackathon (Nasional)", 50, 2),
+		verified("a2", "Finalis Gemastik", 20, 9),
+	}
+}
+
+func TestTranscriptIssue_VerifiedOnlyWithHash(t *testing.T) {
+	st := repository.NewMockStudentRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	repo := repository.NewMockTranscriptRepository()
+	seedTranscript(st, mg, repo)
+
+	svc := NewTranscriptService(repo, st, mg, transcriptKeys("k1:secret"), "https://api.kampus.ac.id/api/v1/verify/")
+	svc.now = func() time.Time { return transcriptNow }
+
+	tr, err := svc.Issue(context.Background(), "s1", "uAdmin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tr.Entries) != 2 || tr.TotalPoints != 70 || tr.Entries[0].Title != "Juara 1 Hackathon (Nasional)" {
+		t.Fatalf("unexpected transcript: %+v", tr)
+	}
+	if len(tr.Hash) != 64 || repo.Issued[tr.Hash].StudentNIM != "2021001" {
+		t.Fatalf("transcript must be stored under its hash: %q", tr.Hash)
+	}
+
+	other := NewTranscriptService(repo, nil, nil, transcriptKeys("k1:other-secret"), "")
+	if h, _ := other.transcriptHash(tr); h == tr.Hash {
+		t.Error("hash must depend on the server secret")
+	}
+}
+
+func TestTranscriptVerifyHash(t *testing.T) {
+	st := repository.NewMockStudentRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	repo := repository.NewMockTranscriptRepository()
+	seedTranscript(st, mg, repo)
+
+	svc := NewTranscriptService(repo, st, mg, transcriptKeys("k1:secret"), "https://api.kampus.ac.id/api/v1/verify/")
+	svc.now = func() time.Time { return transcriptNow }
+	ctx := context.Background()
+	tr, _ := svc.Issue(ctx, "s1", "uAdmin")
+
+	res, err := svc.VerifyHash(ctx, tr.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Valid || !res.AllStillVerified || res.AchievementCount != 2 || res.StudentName != "Andi" {
+		t.Errorf("unexpected verification: %+v", res)
+	}
+
+	// prestasi dibatalkan setelah dokumen terbit
+	repo.Verified["s1"] = repo.Verified["s1"][:1]
+	if res, _ := svc.VerifyHash(ctx, tr.Hash); !res.Valid || res.AllStillVerified {
+		t.Errorf("expected valid document with revoked entry: %+v", res)
+	}
+
+	// snapshot diubah langsung di database
+	tampered := repo.Issued[tr.Hash]
+	tampered.TotalPoints = 999
+	repo.Issued[tr.Hash] = tampered
+	if _, err := svc.VerifyHash(ctx, tr.Hash); !errors.Is(err, ErrTranscriptNotFound) {
+		t.Errorf("tampered snapshot must fail, got %v", err)
+	}
+
+	if _, err := svc.VerifyHash(ctx, "deadbeef"); !errors.Is(err, ErrTranscriptNotFound) {
+		t.Errorf("unknown hash must fail, got %v", err)
+	}
+}
+
+func TestTranscriptRenderPDF(t *testing.T) {
+	st := repository.NewMockStudentRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	repo := repository.NewMockTranscriptRepository()
+	seedTranscript(st, mg, repo)
+
+	svc := NewTranscriptService(repo, st, mg, transcriptKeys("k1:secret"), "https://api.kampus.ac.id/api/v1/verify/")
+	svc.now = func() time.Time { return transcriptNow }
+	tr, _ := svc.Issue(context.Background(), "s1", "uAdmin")
+
+	body, err := svc.RenderPDF(tr, time.FixedZone("WIB", 7*3600))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream := regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindSubmatch(body)
+	zr, err := zlib.NewReader(bytes.NewReader(stream[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(zr)
+
+	// QR code = satu blok isian hitam berisi kotak-kotak modul
+	qrBlock := regexp.MustCompile(`(?s)q 0 g\n((?:[\d.]+ [\d.]+ [\d.]+ [\d.]+ re\n)+)f Q`).FindSubmatch(content)
+	if qrBlock == nil || bytes.Count(qrBlock[1], []byte(" re\n")) < 50 {
+		t.Error("transcript must contain the verification QR code")
+	}
+
+	for _, want := range []string{
+		"(SURAT KETERANGAN PENDAMPING IJAZAH) Tj",
+		"(Juara 1 Hackathon \\(Nasional\\)) Tj",
+		"(02-03-2026) Tj",
+		"(Dr. Budi) Tj",
+		"(https://api.kampus.ac.id/api/v1/verify/" + tr.Hash + ") Tj",
+		"(: " + groupHash(tr.Hash) + ") Tj",
+		"Diterbitkan 15 Juli 2026",
+	} {
+		if !bytes.Contains(content, []byte(want)) {
+			t.Errorf("transcript missing %q", want)
+		}
+	}
+}
+
+func TestGroupHash(t *testing.T) {
+	if got := groupHash("0123456789abcdef01"); got != "01234567 89abcdef 01" {
+		t.Errorf("unexpected grouping: %q", got)
+	}
+}
+
+func TestTranscriptKeys_RotationAndLegacy(t *testing.T) {
+	st := repository.NewMockStudentRepository()
+	mg := repository.NewMockAchievementMongoRepository()
+	repo := repository.NewMockTranscriptRepository()
+	seedTranscript(st, mg, repo)
+	ctx := context.Background()
+
+	old := NewTranscriptService(repo, st, mg, transcriptKeys("k1:lama"), "")
+	tr, _ := old.Issue(ctx, "s1", "uAdmin")
+	if tr.KeyID != "k1" || repo.Issued[tr.Hash].KeyID != "k1" {
+		t.Fatalf("key id must be stored with the transcript: %+v", tr)
+	}
+
+	// dokumen sebelum ada key id: ditandatangani API_KEY lama tanpa key_id
+	legacy := tr
+	legacy.KeyID = ""
+	legacyKeys := transcriptKeys("k0:x", "legacy:secret123")
+	legacy.Hash, _ = NewTranscriptService(repo, st, mg, legacyKeys, "").transcriptHash(legacy)
+	repo.Issued[legacy.Hash] = legacy
+
+	rotated := NewTranscriptService(repo, st, mg, transcriptKeys("k2:baru", "k1:lama", "legacy:secret123"), "")
+	if res, err := rotated.VerifyHash(ctx, tr.Hash); err != nil || !res.Valid {
+		t.Errorf("documents signed with a retired key must still verify, got %v", err)
+	}
+	if res, err := rotated.VerifyHash(ctx, legacy.Hash); err != nil || !res.Valid {
+		t.Errorf("legacy documents must verify with the legacy key, got %v", err)
+	}
+	if tr2, _ := rotated.Issue(ctx, "s1", "uAdmin"); tr2.KeyID != "k2" {
+		t.Errorf("new documents must use the current key, got %q", tr2.KeyID)
+	}
+
+	// kunci lama dibuang → dokumen lama tidak lagi dapat diverifikasi
+	dropped := NewTranscriptService(repo, st, mg, transcriptKeys("k2:baru"), "")
+	if _, err := dropped.VerifyHash(ctx, tr.Hash); !errors.Is(err, ErrTranscriptNotFound) {
+		t.Errorf("unknown key id must fail, got %v", err)
+	}
+}
+
+func TestParseTranscriptKeys(t *testing.T) {
+	if _, err := ParseTranscriptKeys("k1", "", ""); err == nil {
+		t.Error("empty TRANSCRIPT_SECRET must be rejected")
+	}
+	if _, err := ParseTranscriptKeys("legacy", "x", ""); err == nil {
+		t.Error("legacy key id must not sign new documents")
+	}
+	if _, err := ParseTranscriptKeys("k2", "x", "k1"); err == nil {
+		t.Error("old secret without kid:secret format must be rejected")
+	}
+	if _, err := ParseTranscriptKeys("k2", "x", "k2:y"); err == nil {
+		t.Error("duplicate key id must be rejected")
+	}
+
+	keys, err := ParseTranscriptKeys(" k2 ", "baru", "k1:lama:dengan:titik-dua, legacy:abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys.KeyID != "k2" || string(keys.Secrets["k1"]) != "lama:dengan:titik-dua" || string(keys.Secrets["legacy"]) != "abc" {
+		t.Errorf("unexpected keys: %+v", keys)
+	}
+}
@@ -12,11 +12,13 @@ import (
 )
 
 type UserService struct {
-	repo *repository.UserRepository
+	repo     repository.IUserRepository
+	denylist *TokenDenylist // mencabut token user yang dinonaktifkan / dihapus / diganti role
+	guard    *LoginGuard    // kunci login akibat brute-force + audit login gagal
 }
 
-func NewUserService(repo *repository.UserRepository) *UserService {
-	return &UserService{repo}
+func NewUserService(repo repository.IUserRepository, denylist *TokenDenylist, guard *LoginGuard) *UserService {
+	return &UserService{repo: repo, denylist: denylist, guard: guard}
 }
 
 // ===============================
@@ -137,7 +139,7 @@ func (s *UserService) Update(c *fiber.Ctx) error {
 // ===============================
 // Delete godoc
 // @Summary Delete user
-// @Description Menghapus user berdasarkan ID
+// @Description Menghapus user berdasarkan ID; semua sesi & token user dicabut
 // @Tags User
 // @Security BearerAuth
 // @Produce json
@@ -147,8 +149,12 @@ func (s *UserService) Update(c *fiber.Ctx) error {
 // @Router /users/{id} [delete]
 func (s *UserService) Delete(c *fiber.Ctx) error {
 	id := c.Params("id")
+	ctx := context.Background()
 
-	if err := s.repo.Delete(context.Background(), id); err != nil {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := s.denylist.RevokeUser(ctx, id, model.RevokedUserDeleted, true); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -160,7 +166,7 @@ func (s *UserService) Delete(c *fiber.Ctx) error {
 // ===============================
 // UpdateRole godoc
 // @Summary Update user role
-// @Description Mengubah role user
+// @Description Mengubah role user; access token lama dicabut, refresh berikutnya memakai role baru
 // @Tags User
 // @Security BearerAuth
 // @Accept json
@@ -182,9 +188,115 @@ func (s *UserService) UpdateRole(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
 	}
 
-	if err := s.repo.UpdateRole(context.Background(), userID, body.RoleID); err != nil {
+	ctx := context.Background()
+	if err := s.repo.UpdateRole(ctx, userID, body.RoleID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := s.denylist.RevokeUser(ctx, userID, model.RevokedRoleChanged, false); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	return c.JSON(fiber.Map{"message": "Role user berhasil diperbarui"})
 }
+
+// ===============================
+// AKTIF / NONAKTIFKAN USER
+// ===============================
+// UpdateStatus godoc
+// @Summary Activate or deactivate user
+// @Description Menonaktifkan user mencabut semua sesi & access token miliknya
+// @Tags User
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body object true "Status payload (is_active)"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /users/{id}/status [put]
+func (s *UserService) UpdateStatus(c *fiber.Ctx) error {
+	userID := c.Params("id")
+
+	body := struct {
+		IsActive *bool `json:"is_active"`
+	}{}
+
+	if err := c.BodyParser(&body); err != nil || body.IsActive == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "is_active wajib diisi"})
+	}
+
+	ctx := context.Background()
+	if _, err := s.repo.GetByID(ctx, userID); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+	if err := s.repo.SetActive(ctx, userID, *body.IsActive); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !*body.IsActive {
+		if err := s.denylist.RevokeUser(ctx, userID, model.RevokedUserDeactivated, true); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	return c.JSON(fiber.Map{"message": "Status user berhasil diperbarui"})
+}
+
+// ===============================
+// BUKA KUNCI LOGIN
+// ===============================
+// Unlock godoc
+// @Summary Unlock user login
+// @Description Menghapus penghitung login gagal akun sehingga user bisa langsung login lagi. Blokir per IP tetap berlaku sampai kedaluwarsa.
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /users/{id}/unlock [post]
+func (s *UserService) Unlock(c *fiber.Ctx) error {
+	ctx := context.Background()
+	user, err := s.repo.GetByID(ctx, c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+	if err := s.guard.Unlock(ctx, user.Username); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Kunci login user berhasil dibuka"})
+}
+
+// ===============================
+// AUDIT LOGIN GAGAL
+// ===============================
+// LoginAttempts godoc
+// @Summary List failed login attempts
+// @Description Audit login gagal terbaru (password salah, username tidak dikenal, akun nonaktif, ditolak karena diblokir)
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Param username query string false "Filter username"
+// @Param ip query string false "Filter IP"
+// @Param limit query int false "Jumlah maksimum (default 50, maks 500)"
+// @Success 200 {array} model.LoginAttempt
+// @Failure 500 {object} map[string]any
+// @Router /users/login-attempts [get]
+func (s *UserService) LoginAttempts(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	list, err := s.guard.Attempts(context.Background(), model.LoginAttemptFilter{
+		Username: c.Query("username"),
+		IP:       c.Query("ip"),
+		Limit:    limit,
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": list})
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =====================================================
+//  XLSX WRITER — workbook Office Open XML minimal
+// =====================================================
+//
+// Satu sheet per tabel; string ditulis sebagai inline string sehingga tidak
+// perlu sharedStrings.xml. Style: 0 = normal, 1 = tebal (header),
+// 2 = judul, 3 = angka desimal 0.0.
+
+type xlsxSheet struct {
+	Name   string
+	Title  string     // baris pertama (kosong = tanpa judul)
+	Header []string   // baris header (tebal)
+	Rows   [][]string // nilai sel; kolom Numeric ditulis sebagai angka
+	// Numeric[i] = kolom i berisi angka
+	Numeric []bool
+	Widths  []float64 // lebar kolom (karakter); kosong = otomatis
+}
+
+// xlsxColumn: 0 → A, 26 → AA
+func xlsxColumn(i int) string {
+	name := ""
+	for i >= 0 {
+		name = string(rune('A'+i%26)) + name
+		i = i/26 - 1
+	}
+	return name
+}
+
+// xlsxSheetName membuang karakter terlarang dan memotong ke 31 karakter
+func xlsxSheetName(name string, used map[string]bool) string {
+	clean := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+		return r
+	}, name)
+	if r := []rune(clean); len(r) > 31 {
+		clean = string(r[:31])
+	}
+	if clean == "" {
+		clean = "Sheet"
+	}
+
+	base, n := clean, 2
+	for used[strings.ToLower(clean)] {
+		suffix := fmt.Sprintf(" (%d)", n)
+		r := []rune(base)
+		if len(r)+len(suffix) > 31 {
+			r = r[:31-len(suffix)]
+		}
+		clean = string(r) + suffix
+		n++
+	}
+	used[strings.ToLower(clean)] = true
+	return clean
+}
+
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// spreadsheetSafe mencegah formula injection (CSV/XLSX): teks yang diawali
+// =, +, -, @, tab, atau CR diberi awalan ' supaya Excel / LibreOffice
+// menampilkannya sebagai teks. Angka murni (mis. -5) dibiarkan.
+func spreadsheetSafe(value string) string {
+	if value == "" || !strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return value
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return "'" + value
+}
+
+func xlsxCell(ref, value string, numeric bool, style int) string {
+	if numeric {
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return fmt.Sprintf(`<c r="%s" s="%d"><v>%s</v></c>`, ref, style, value)
+		}
+	}
+	return fmt.Sprintf(`<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, xmlEscape(spreadsheetSafe(value)))
+}
+
+func (s xlsxSheet) xml() string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+
+	// lebar kolom otomatis dari isi terpanjang
+	cols := len(s.Header)
+	widths := make([]float64, cols)
+	for i := range widths {
+		if i < len(s.Widths) && s.Widths[i] > 0 {
+			widths[i] = s.Widths[i]
+			continue
+		}
+		w := len([]rune(s.Header[i]))
+		for _, r := range s.Rows {
+			if i < len(r) && len([]rune(r[i])) > w {
+				w = len([]rune(r[i]))
+			}
+		}
+		widths[i] = float64(w) + 2
+		if widths[i] > 60 {
+			widths[i] = 60
+		}
+	}
+	if cols > 0 {
+		b.WriteString("<cols>")
+		for i, w := range widths {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%.1f" customWidth="1"/>`, i+1, i+1, w)
+		}
+		b.WriteString("</cols>")
+	}
+
+	b.WriteString("<sheetData>")
+	row := 0
+	writeRow := func(values []string, style func(col int) int, numeric bool) {
+		row++
+		fmt.Fprintf(&b, `<row r="%d">`, row)
+		for i, v := range values {
+			isNum := numeric && i < len(s.Numeric) && s.Numeric[i]
+			b.WriteString(xlsxCell(xlsxColumn(i)+strconv.Itoa(row), v, isNum, style(i)))
+		}
+		b.WriteString("</row>")
+	}
+
+	if s.Title != "" {
+		writeRow([]string{s.Title}, func(int) int { return 2 }, false)
+		row++ // baris kosong
+	}
+	writeRow(s.Header, func(int) int { return 1 }, false)
+	for _, r := range s.Rows {
+		writeRow(r, func(col int) int {
+			if col < len(s.Numeric) && s.Numeric[col] && strings.Contains(r[col], ".") {
+				return 3
+			}
+			return 0
+		}, true)
+	}
+	b.WriteString("</sheetData></worksheet>")
+	return b.String()
+}
+
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="1"><numFmt numFmtId="164" formatCode="0.0"/></numFmts>
+<fonts count="3">
+<font><sz val="11"/><name val="Calibri"/></font>
+<font><b/><sz val="11"/><name val="Calibri"/></font>
+<font><b/><sz val="14"/><name val="Calibri"/></font>
+</fonts>
+<fills count="3">
+<fill><patternFill patternType="none"/></fill>
+<fill><patternFill patternType="gray125"/></fill>
+<fill><patternFill patternType="solid"><fgColor rgb="FFE7E6E6"/><bgColor indexed="64"/></patternFill></fill>
+</fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="4">
+<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>
+<xf numFmtId="0" fontId="1" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/>
+<xf numFmtId="0" fontId="2" fillId="0" borderId="0" xfId="0" applyFont="1"/>
+<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+</cellXfs>
+</styleSheet>`
+
+// writeXLSX menulis workbook berisi sheets ke w
+func writeXLSX(w io.Writer, title string, created time.Time, sheets []xlsxSheet) error {
+	zw := zip.NewWriter(w)
+
+	add := func(name, content string) error {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: created})
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(f, content)
+		return err
+	}
+
+	var types, rels, sheetList strings.Builder
+	used := map[string]bool{}
+	for i, s := range sheets {
+		n := i + 1
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+		fmt.Fprintf(&sheetList, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(xlsxSheetName(s.Name, used)), n, n)
+	}
+	stylesID := len(sheets) + 1
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>
+` + types.String() + `
+</Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>
+</Relationships>`},
+		{"docProps/core.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+<dc:title>` + xmlEscape(title) + `</dc:title>
+<dc:creator>Sistem Prestasi Mahasiswa</dc:creator>
+<dcterms:created xsi:type="dcterms:W3CDTF">` + created.UTC().Format(time.RFC3339) + `</dcterms:created>
+</cp:coreProperties>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets>` + sheetList.String() + `</sheets>
+</workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+` + rels.String() + fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, stylesID) + `
+</Relationships>`},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, p := range parts {
+		if err := add(p.name, p.content); err != nil {
+			return err
+		}
+	}
+	for i, s := range sheets {
+		if err := add(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), s.xml()); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"backenduas/app/repository"
+	"backenduas/app/service"
+)
+
+// runBackfillTypes: backenduas backfill-types [--dry-run]
+// Exit code: 0 = semua nilai dikenal, 1 = ada nilai di luar registry, 2 = error.
+func runBackfillTypes(types repository.IAchievementTypeRepository, mg repository.IAchievementMongoRepository, stats *service.StatsMaterializer, args []string) int {
+	fs := flag.NewFlagSet("backfill-types", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only report what would change")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx := context.Background()
+	report, err := service.BackfillAchievementTypes(ctx, types, mg, *dryRun)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "backfill failed:", err)
+		return 2
+	}
+
+	// jenis prestasi di tabel statistik ikut berubah → bangun ulang
+	if !*dryRun && report.Updated > 0 {
+		if _, err := stats.Rebuild(ctx); err != nil {
+			fmt.Fprintln(os.Stderr, "stats rebuild failed:", err)
+			return 2
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		fmt.Fprintln(os.Stderr, "cannot encode report:", err)
+		return 2
+	}
+
+	if len(report.Unknown) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -3,16 +3,87 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Env struct {
+	// APP_ENV: development | production (default). Fallback yang hanya aman
+	// untuk lokal (mis. kunci JWT sementara) ditolak di luar development.
+	AppEnv  string
 	AppPort string
 	DBDsn   string
 	ApiKey  string
 	MongoURI string `mapstructure:"MONGO_URI"`
     MongoDB  string `mapstructure:"MONGO_DB"`
+
+	// Worker sinkronisasi PostgreSQL ↔ MongoDB
+	SyncInterval      time.Duration
+	ReconcileInterval time.Duration
+
+	// Email notifikasi. MAIL_DRIVER: smtp | file | kosong (email mati)
+	MailDriver    string
+	MailFrom      string
+	MailOutboxDir string
+	MailInterval  time.Duration
+	SMTPHost      string
+	SMTPPort      int
+	SMTPUsername  string
+	SMTPPassword  string
+	AppBaseURL    string // URL frontend untuk link di email
+
+	// Digest harian dosen wali. DIGEST_DELIVERY: email | file (default)
+	DigestTime     string // HH:MM
+	DigestTimezone string
+	DigestDelivery string
+	DigestDir      string // kosong = digest hanya ditulis ke log
+	EscalationDays int
+
+	// Interval pengecekan SLA review (batas hari diatur Admin lewat /sla/settings)
+	SLACheckInterval time.Duration
+
+	// URL publik verifikasi transkrip (SKPI), dicetak di dokumen
+	TranscriptVerifyURL string
+
+	// Secret HMAC kode verifikasi transkrip (wajib). TRANSCRIPT_OLD_SECRETS =
+	// "kid:secret,..." untuk memverifikasi dokumen setelah rotasi; dokumen yang
+	// terbit sebelum ada key id diverifikasi dengan kid "legacy" (isi API_KEY lama).
+	TranscriptSecret     string
+	TranscriptKeyID      string
+	TranscriptOldSecrets string
+
+	// Umur cache respons /reports/statistics (dikosongkan juga setiap ada transisi prestasi)
+	ReportCacheTTL time.Duration
+
+	// Interval muat ulang denylist access token dari database (pencabutan di instance lain)
+	TokenDenylistRefresh time.Duration
+
+	// Kunci JWT asimetris: direktori <kid>.pem (kosong = kunci sementara di
+	// memori, hanya diizinkan saat APP_ENV=development)
+	JWTKeysDir    string
+	JWTSigningKID string // kosong = kid terbesar yang punya kunci privat
+	JWTIssuer     string
+	JWTAudience   string
+
+	// Proteksi brute-force login (per akun dan per IP)
+	LoginMaxAttempts   int
+	LoginIPMaxAttempts int
+	LoginFreeAttempts  int           // gagal tanpa jeda sebelum jeda bertahap dimulai
+	LoginDelayBase     time.Duration // jeda pertama, berlipat dua tiap gagal
+	LoginLockout       time.Duration
+	LoginAttemptWindow time.Duration // penghitung direset jika tidak ada gagal selama ini
+
+	// Audit login gagal disimpan selama LoginAttemptRetention, dibersihkan tiap LoginPurgeInterval
+	LoginAttemptRetention time.Duration
+	LoginPurgeInterval    time.Duration
+
+	// Reverse proxy tepercaya (IP / CIDR, dipisah koma). Hanya request dari
+	// alamat ini yang IP kliennya dibaca dari ProxyHeader.
+	TrustedProxies []string
+	ProxyHeader    string
 }
 
 var AppEnv *Env
@@ -24,12 +95,109 @@ func LoadEnv() {
 	}
 
 	AppEnv = &Env{
+		AppEnv:  getString("APP_ENV", "production"),
 		AppPort: os.Getenv("APP_PORT"),
 		DBDsn:   os.Getenv("DB_DSN"),
 		ApiKey:  os.Getenv("API_KEY"),
 		MongoURI: os.Getenv("MONGO_URI"),
 		MongoDB:  os.Getenv("MONGO_DB"),
 
+		SyncInterval:      getDuration("SYNC_INTERVAL", 30*time.Second),
+		ReconcileInterval: getDuration("RECONCILE_INTERVAL", time.Hour),
+
+		MailDriver:    os.Getenv("MAIL_DRIVER"),
+		MailFrom:      getString("MAIL_FROM", "Sistem Prestasi <no-reply@localhost>"),
+		MailOutboxDir: getString("MAIL_OUTBOX_DIR", "./mail_outbox"),
+		MailInterval:  getDuration("MAIL_INTERVAL", time.Minute),
+		SMTPHost:      os.Getenv("SMTP_HOST"),
+		SMTPPort:      getInt("SMTP_PORT", 587),
+		SMTPUsername:  os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:  os.Getenv("SMTP_PASSWORD"),
+		AppBaseURL:    getString("APP_BASE_URL", "http://localhost:5173"),
+
+		DigestTime:     getString("DIGEST_TIME", "07:00"),
+		DigestTimezone: getString("DIGEST_TIMEZONE", "Asia/Jakarta"),
+		DigestDelivery: getString("DIGEST_DELIVERY", "file"),
+		DigestDir:      os.Getenv("DIGEST_DIR"),
+		EscalationDays: getInt("ESCALATION_DAYS", 7),
+
+		SLACheckInterval: getDuration("SLA_CHECK_INTERVAL", time.Hour),
+
+		TranscriptVerifyURL:  getString("TRANSCRIPT_VERIFY_URL", "http://localhost:3000/api/v1/verify"),
+		TranscriptSecret:     os.Getenv("TRANSCRIPT_SECRET"),
+		TranscriptKeyID:      getString("TRANSCRIPT_KEY_ID", "k1"),
+		TranscriptOldSecrets: os.Getenv("TRANSCRIPT_OLD_SECRETS"),
+
+		ReportCacheTTL: getDuration("REPORT_CACHE_TTL", 5*time.Minute),
+
+		TokenDenylistRefresh: getDuration("TOKEN_DENYLIST_REFRESH", 30*time.Second),
+
+		JWTKeysDir:    os.Getenv("JWT_KEYS_DIR"),
+		JWTSigningKID: os.Getenv("JWT_SIGNING_KID"),
+		JWTIssuer:     getString("JWT_ISSUER", "http://localhost:3000"),
+		JWTAudience:   getString("JWT_AUDIENCE", "sistem-prestasi"),
 
+		LoginMaxAttempts:   getInt("LOGIN_MAX_ATTEMPTS", 5),
+		LoginIPMaxAttempts: getInt("LOGIN_IP_MAX_ATTEMPTS", 20),
+		LoginFreeAttempts:  getInt("LOGIN_FREE_ATTEMPTS", 2),
+		LoginDelayBase:     getDuration("LOGIN_DELAY_BASE", time.Second),
+		LoginLockout:       getDuration("LOGIN_LOCKOUT", 15*time.Minute),
+		LoginAttemptWindow: getDuration("LOGIN_ATTEMPT_WINDOW", 15*time.Minute),
+
+		LoginAttemptRetention: getDuration("LOGIN_ATTEMPT_RETENTION", 90*24*time.Hour),
+		LoginPurgeInterval:    getDuration("LOGIN_PURGE_INTERVAL", time.Hour),
+
+		TrustedProxies: getList("TRUSTED_PROXIES"),
+		ProxyHeader:    getString("PROXY_HEADER", "X-Real-IP"),
+	}
+}
+
+// IsDevelopment: APP_ENV=development
+func (e *Env) IsDevelopment() bool {
+	return e.AppEnv == "development"
+}
+
+func getString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func getInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		log.Printf("Warning: invalid %s=%q, using %d", key, v, def)
+		return def
+	}
+	return n
+}
+
+// getList membaca env berisi daftar dipisah koma ("a, b" → [a b])
+func getList(key string) []string {
+	list := []string{}
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// getDuration membaca env berformat time.ParseDuration ("30s", "1h"), fallback ke default
+func getDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("Warning: invalid %s=%q, using %s", key, v, def)
+		return def
 	}
+	return d
 }
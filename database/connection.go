@@ -66,5 +66,7 @@ func ConnectMongo() {
 // ===============================
 func ConnectDatabases() {
 	ConnectPostgre()
+	RunMigrations()
 	ConnectMongo()
+	EnsureMongoIndexes()
 }
@@ -0,0 +1,361 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// ===============================
+// SCHEMA TAMBAHAN (idempotent)
+// ===============================
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS achievement_histories (
+		id              UUID PRIMARY KEY,
+		achievement_id  UUID NOT NULL,
+		actor_user_id   UUID,
+		actor_role      VARCHAR(50) NOT NULL DEFAULT '',
+		from_status     VARCHAR(20) NOT NULL DEFAULT '',
+		to_status       VARCHAR(20) NOT NULL,
+		note            TEXT NOT NULL DEFAULT '',
+		changed_fields  TEXT[] NOT NULL DEFAULT '{}',
+		created_at      TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_achievement_histories_achievement
+		ON achievement_histories (achievement_id, created_at)`,
+
+	// rejection_note: TEXT tunggal → JSONB list putaran review
+	`DO $$
+	BEGIN
+		IF EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_name = 'achievement_references'
+			  AND column_name = 'rejection_note'
+			  AND data_type <> 'jsonb'
+		) THEN
+			ALTER TABLE achievement_references
+				ALTER COLUMN rejection_note TYPE JSONB
+				USING CASE
+					WHEN rejection_note IS NULL OR rejection_note = '' THEN '[]'::jsonb
+					ELSE jsonb_build_array(jsonb_build_object(
+						'round', 1,
+						'note', rejection_note,
+						'rejected_by', verified_by,
+						'rejected_at', updated_at
+					))
+				END;
+			UPDATE achievement_references SET rejection_note = '[]'::jsonb WHERE rejection_note IS NULL;
+			ALTER TABLE achievement_references
+				ALTER COLUMN rejection_note SET DEFAULT '[]'::jsonb,
+				ALTER COLUMN rejection_note SET NOT NULL;
+		END IF;
+	END $$`,
+
+	`CREATE TABLE IF NOT EXISTS sync_outbox (
+		id            UUID PRIMARY KEY,
+		operation     VARCHAR(20) NOT NULL,
+		mongo_id      VARCHAR(24) NOT NULL,
+		status        VARCHAR(20) NOT NULL DEFAULT '',
+		attempts      INT NOT NULL DEFAULT 0,
+		last_error    TEXT NOT NULL DEFAULT '',
+		created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		processed_at  TIMESTAMPTZ
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_sync_outbox_pending
+		ON sync_outbox (created_at) WHERE processed_at IS NULL`,
+
+	`CREATE TABLE IF NOT EXISTS achievement_types (
+		id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		code              VARCHAR(50) NOT NULL UNIQUE,
+		name              VARCHAR(100) NOT NULL,
+		description       TEXT NOT NULL DEFAULT '',
+		aliases           TEXT[] NOT NULL DEFAULT '{}',
+		fields            JSONB NOT NULL DEFAULT '[]',
+		allow_additional  BOOLEAN NOT NULL DEFAULT FALSE,
+		is_active         BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	// jenis prestasi bawaan (tidak menimpa perubahan Admin)
+	`INSERT INTO achievement_types (code, name, aliases, fields, allow_additional) VALUES
+		('competition', 'Kompetisi', '{lomba,kompetisi}', '[
+			{"name":"competitionName","label":"Nama kompetisi","type":"string","required":true,"max_length":200},
+			{"name":"competitionLevel","label":"Tingkat","type":"enum","required":true,"enum":["international","national","regional","campus"]},
+			{"name":"rank","label":"Peringkat","type":"integer","min":1},
+			{"name":"teamSize","label":"Jumlah anggota tim","type":"integer","min":1},
+			{"name":"medalType","label":"Medali","type":"string"},
+			{"name":"organizer","label":"Penyelenggara","type":"string"},
+			{"name":"eventDate","label":"Tanggal","type":"date"}
+		]', FALSE),
+		('publication', 'Publikasi', '{publikasi}', '[
+			{"name":"publicationType","label":"Jenis publikasi","type":"enum","required":true,"enum":["journal","conference","book"]},
+			{"name":"publicationTitle","label":"Judul","type":"string","required":true},
+			{"name":"authors","label":"Penulis","type":"array","items":"string","required":true},
+			{"name":"publisher","label":"Penerbit","type":"string"},
+			{"name":"issn","label":"ISSN","type":"string","pattern":"^[0-9]{4}-[0-9]{3}[0-9X]$"},
+			{"name":"year","label":"Tahun","type":"integer","min":1900,"max":2100}
+		]', FALSE),
+		('certification', 'Sertifikasi', '{sertifikasi,sertifikat}', '[
+			{"name":"certificationName","label":"Nama sertifikasi","type":"string","required":true},
+			{"name":"issuedBy","label":"Penerbit","type":"string","required":true},
+			{"name":"certificationNumber","label":"Nomor","type":"string"},
+			{"name":"validUntil","label":"Berlaku sampai","type":"date"}
+		]', FALSE),
+		('organization', 'Organisasi', '{organisasi}', '[
+			{"name":"organizationName","label":"Nama organisasi","type":"string","required":true},
+			{"name":"position","label":"Jabatan","type":"string","required":true},
+			{"name":"periodStart","label":"Mulai","type":"date","required":true},
+			{"name":"periodEnd","label":"Selesai","type":"date"}
+		]', FALSE),
+		('internship', 'Magang', '{magang}', '[
+			{"name":"companyName","label":"Nama perusahaan","type":"string","required":true},
+			{"name":"position","label":"Posisi","type":"string"},
+			{"name":"periodStart","label":"Mulai","type":"date","required":true},
+			{"name":"periodEnd","label":"Selesai","type":"date"}
+		]', FALSE),
+		('other', 'Lainnya', '{lainnya}', '[]', TRUE)
+	ON CONFLICT (code) DO NOTHING`,
+
+	`CREATE TABLE IF NOT EXISTS point_rules (
+		id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		name              VARCHAR(150) NOT NULL,
+		achievement_type  VARCHAR(50) NOT NULL DEFAULT '',
+		kind              VARCHAR(20) NOT NULL,
+		conditions        JSONB NOT NULL DEFAULT '[]',
+		points            DOUBLE PRECISION NOT NULL DEFAULT 0,
+		factor            DOUBLE PRECISION NOT NULL DEFAULT 1,
+		priority          INT NOT NULL DEFAULT 0,
+		is_active         BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	// aturan poin bawaan fakultas — hanya jika tabel masih kosong
+	`INSERT INTO point_rules (name, achievement_type, kind, conditions, points, factor, priority)
+	SELECT * FROM (VALUES
+		('Kompetisi internasional', 'competition', 'base', '[{"field":"competitionLevel","op":"eq","value":"international"}]'::jsonb, 100::float8, 1::float8, 10),
+		('Kompetisi nasional',      'competition', 'base', '[{"field":"competitionLevel","op":"eq","value":"national"}]'::jsonb, 60, 1, 10),
+		('Kompetisi regional',      'competition', 'base', '[{"field":"competitionLevel","op":"eq","value":"regional"}]'::jsonb, 40, 1, 10),
+		('Kompetisi kampus',        'competition', 'base', '[{"field":"competitionLevel","op":"eq","value":"campus"}]'::jsonb, 20, 1, 10),
+		('Juara 1',                 'competition', 'bonus', '[{"field":"rank","op":"eq","value":1}]'::jsonb, 30, 1, 0),
+		('Juara 2',                 'competition', 'bonus', '[{"field":"rank","op":"eq","value":2}]'::jsonb, 20, 1, 0),
+		('Juara 3',                 'competition', 'bonus', '[{"field":"rank","op":"eq","value":3}]'::jsonb, 10, 1, 0),
+		('Tim 2-5 orang',           'competition', 'multiplier', '[{"field":"teamSize","op":"gte","value":2},{"field":"teamSize","op":"lte","value":5}]'::jsonb, 0, 0.75, 0),
+		('Tim lebih dari 5 orang',  'competition', 'multiplier', '[{"field":"teamSize","op":"gte","value":6}]'::jsonb, 0, 0.5, 0),
+		('Publikasi jurnal',        'publication', 'base', '[{"field":"publicationType","op":"eq","value":"journal"}]'::jsonb, 50, 1, 10),
+		('Publikasi konferensi',    'publication', 'base', '[{"field":"publicationType","op":"eq","value":"conference"}]'::jsonb, 40, 1, 10),
+		('Buku',                    'publication', 'base', '[{"field":"publicationType","op":"eq","value":"book"}]'::jsonb, 60, 1, 10),
+		('Sertifikasi',             'certification', 'base', '[]'::jsonb, 25, 1, 0),
+		('Organisasi',              'organization', 'base', '[]'::jsonb, 15, 1, 0),
+		('Magang',                  'internship', 'base', '[]'::jsonb, 30, 1, 0),
+		('Lainnya',                 'other', 'base', '[]'::jsonb, 5, 1, 0)
+	) AS seed
+	WHERE NOT EXISTS (SELECT 1 FROM point_rules)`,
+
+	`CREATE TABLE IF NOT EXISTS notifications (
+		id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		recipient_user_id  UUID NOT NULL,
+		type               VARCHAR(50) NOT NULL,
+		title              VARCHAR(200) NOT NULL,
+		message            TEXT NOT NULL DEFAULT '',
+		achievement_id     UUID,
+		link               TEXT NOT NULL DEFAULT '',
+		is_read            BOOLEAN NOT NULL DEFAULT FALSE,
+		read_at            TIMESTAMPTZ,
+		created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_notifications_recipient
+		ON notifications (recipient_user_id, created_at DESC)`,
+	`CREATE INDEX IF NOT EXISTS idx_notifications_unread
+		ON notifications (recipient_user_id) WHERE NOT is_read`,
+
+	`CREATE TABLE IF NOT EXISTS notification_preferences (
+		user_id        UUID PRIMARY KEY,
+		email_enabled  BOOLEAN NOT NULL DEFAULT TRUE,
+		language       VARCHAR(5) NOT NULL DEFAULT 'id',
+		muted_types    TEXT[] NOT NULL DEFAULT '{}',
+		updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+
+	`CREATE TABLE IF NOT EXISTS email_outbox (
+		id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		recipient_user_id  UUID,
+		to_address         VARCHAR(255) NOT NULL,
+		template           VARCHAR(50) NOT NULL,
+		subject            TEXT NOT NULL,
+		text_body          TEXT NOT NULL,
+		html_body          TEXT NOT NULL,
+		status             VARCHAR(20) NOT NULL DEFAULT 'pending',
+		attempts           INT NOT NULL DEFAULT 0,
+		last_error         TEXT NOT NULL DEFAULT '',
+		next_attempt_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		sent_at            TIMESTAMPTZ
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_email_outbox_due
+		ON email_outbox (next_attempt_at) WHERE status = 'pending'`,
+
+	// satu baris per eksekusi job terjadwal → mencegah eksekusi ganda antar instance
+	`CREATE TABLE IF NOT EXISTS scheduled_job_runs (
+		job         VARCHAR(50) NOT NULL,
+		run_key     VARCHAR(50) NOT NULL,
+		claimed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (job, run_key)
+	)`,
+
+	// SLA review: pengaturan (satu baris), dosen cadangan, reviewer pengganti, catatan pelanggaran
+	`CREATE TABLE IF NOT EXISTS sla_settings (
+		id           BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+		review_days  INT NOT NULL DEFAULT 14,
+		action       VARCHAR(20) NOT NULL DEFAULT 'escalate',
+		updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`INSERT INTO sla_settings (id) VALUES (TRUE) ON CONFLICT DO NOTHING`,
+	`ALTER TABLE lecturers ADD COLUMN IF NOT EXISTS backup_lecturer_id UUID`,
+	`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS assigned_reviewer_id UUID`,
+	`CREATE TABLE IF NOT EXISTS sla_breaches (
+		achievement_id  UUID NOT NULL,
+		submitted_at    TIMESTAMPTZ NOT NULL,
+		lecturer_id     UUID,
+		action          VARCHAR(20) NOT NULL,
+		reassigned_to   UUID,
+		breached_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (achievement_id, submitted_at)
+	)`,
+
+	// Transkrip prestasi (SKPI) yang pernah diterbitkan, untuk verifikasi publik
+	`CREATE TABLE IF NOT EXISTS transcripts (
+		hash         VARCHAR(64) PRIMARY KEY,
+		student_id   UUID NOT NULL,
+		issued_by    UUID,
+		issued_at    TIMESTAMPTZ NOT NULL,
+		snapshot     JSONB NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_transcripts_student ON transcripts (student_id, issued_at DESC)`,
+	`ALTER TABLE transcripts ADD COLUMN IF NOT EXISTS key_id VARCHAR(64) NOT NULL DEFAULT ''`,
+
+	// Statistik materialized: satu fakta per prestasi + counter per mahasiswa/status/bulan.
+	// Rekap per dosen wali / program studi / periode = GROUP BY counter JOIN students.
+	`CREATE TABLE IF NOT EXISTS achievement_stat_facts (
+		achievement_id    UUID PRIMARY KEY,
+		student_id        UUID NOT NULL,
+		status            VARCHAR(20) NOT NULL,
+		achievement_type  TEXT NOT NULL,
+		tags              TEXT[] NOT NULL DEFAULT '{}',
+		month             DATE NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS achievement_stat_types (
+		student_id        UUID NOT NULL,
+		status            VARCHAR(20) NOT NULL,
+		achievement_type  TEXT NOT NULL,
+		month             DATE NOT NULL,
+		total             INT NOT NULL,
+		PRIMARY KEY (student_id, status, achievement_type, month)
+	)`,
+	`CREATE TABLE IF NOT EXISTS achievement_stat_tags (
+		student_id  UUID NOT NULL,
+		status      VARCHAR(20) NOT NULL,
+		tag         TEXT NOT NULL,
+		month       DATE NOT NULL,
+		total       INT NOT NULL,
+		PRIMARY KEY (student_id, status, tag, month)
+	)`,
+	`CREATE TABLE IF NOT EXISTS achievement_stat_meta (
+		id          BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+		rebuilt_at  TIMESTAMPTZ
+	)`,
+
+	// RBAC: kolom yang dipakai API pengelolaan role & permission
+	`ALTER TABLE roles ADD COLUMN IF NOT EXISTS description TEXT`,
+	`ALTER TABLE permissions ADD COLUMN IF NOT EXISTS resource VARCHAR(50)`,
+	`ALTER TABLE permissions ADD COLUMN IF NOT EXISTS action VARCHAR(50)`,
+	`ALTER TABLE permissions ADD COLUMN IF NOT EXISTS description TEXT`,
+
+	// Sesi login: refresh token dirotasi per sesi (family), token disimpan sebagai hash SHA-256
+	`CREATE TABLE IF NOT EXISTS auth_sessions (
+		id              UUID PRIMARY KEY,
+		user_id         UUID NOT NULL,
+		user_agent      TEXT NOT NULL DEFAULT '',
+		ip_address      VARCHAR(64) NOT NULL DEFAULT '',
+		created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		last_used_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		expires_at      TIMESTAMPTZ NOT NULL,
+		revoked_at      TIMESTAMPTZ,
+		revoked_reason  VARCHAR(30)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_auth_sessions_user
+		ON auth_sessions (user_id) WHERE revoked_at IS NULL`,
+	`ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS session_id UUID`,
+	`ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS used_at TIMESTAMPTZ`,
+	`CREATE INDEX IF NOT EXISTS idx_refresh_tokens_token ON refresh_tokens (token)`,
+
+	// Denylist access token: per jti (logout) dan per user (logout-all, nonaktif, hapus, ganti role)
+	`CREATE TABLE IF NOT EXISTS revoked_tokens (
+		jti         VARCHAR(64) PRIMARY KEY,
+		user_id     UUID NOT NULL,
+		expires_at  TIMESTAMPTZ NOT NULL,
+		reason      VARCHAR(30) NOT NULL,
+		revoked_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE TABLE IF NOT EXISTS user_token_revocations (
+		user_id         UUID PRIMARY KEY,
+		revoked_before  TIMESTAMPTZ NOT NULL,
+		reason          VARCHAR(30) NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_auth_sessions_revoked
+		ON auth_sessions (revoked_at) WHERE revoked_at IS NOT NULL`,
+
+	// Proteksi brute-force login: penghitung gagal per akun / IP + audit login gagal
+	`CREATE TABLE IF NOT EXISTS login_throttles (
+		key             VARCHAR(150) PRIMARY KEY,
+		failures        INT NOT NULL DEFAULT 0,
+		last_failed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		blocked_until   TIMESTAMPTZ
+	)`,
+	`CREATE TABLE IF NOT EXISTS login_attempts (
+		id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		username    VARCHAR(100) NOT NULL,
+		user_id     UUID,
+		ip_address  VARCHAR(64) NOT NULL,
+		user_agent  TEXT NOT NULL DEFAULT '',
+		reason      VARCHAR(30) NOT NULL,
+		created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_login_attempts_username
+		ON login_attempts (LOWER(username), created_at DESC)`,
+	`CREATE INDEX IF NOT EXISTS idx_login_attempts_ip
+		ON login_attempts (ip_address, created_at DESC)`,
+	// percobaan throttled digabung per username + IP (lihat LoginGuard.Fail)
+	`ALTER TABLE login_attempts ADD COLUMN IF NOT EXISTS attempt_count INT NOT NULL DEFAULT 1`,
+	`CREATE INDEX IF NOT EXISTS idx_login_attempts_created
+		ON login_attempts (created_at)`,
+
+	// sync outbox transaksional: versi status per prestasi + dead letter
+	`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS status_version BIGINT NOT NULL DEFAULT 0`,
+	`ALTER TABLE sync_outbox ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 0`,
+	`ALTER TABLE sync_outbox ADD COLUMN IF NOT EXISTS dead_at TIMESTAMPTZ`,
+	`DROP INDEX IF EXISTS idx_sync_outbox_pending`,
+	`CREATE INDEX IF NOT EXISTS idx_sync_outbox_live
+		ON sync_outbox (mongo_id, created_at) WHERE processed_at IS NULL AND dead_at IS NULL`,
+
+	// total poin di PostgreSQL untuk sort GET /achievements?sort=points
+	// (data lama terisi saat POST /point-rules/recalculate dijalankan)
+	`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS points INT NOT NULL DEFAULT 0`,
+	`CREATE INDEX IF NOT EXISTS idx_achievement_references_points
+		ON achievement_references (points DESC, created_at DESC)`,
+}
+
+// ===============================
+// RUN MIGRATIONS (PostgreSQL)
+// ===============================
+func RunMigrations() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, stmt := range migrations {
+		if _, err := DB.Exec(ctx, stmt); err != nil {
+			log.Fatalf("❌ Failed to run migration: %v", err)
+		}
+	}
+
+	log.Println("✅ PostgreSQL migrations applied")
+}
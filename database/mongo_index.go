@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ===============================
+// MONGODB INDEXES (idempotent)
+// ===============================
+func EnsureMongoIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	achievements := MongoDB.Collection("achievements")
+
+	// versi lama mengindeks "$**" (termasuk nama file lampiran, status, dll).
+	// Satu koleksi hanya boleh punya satu text index, jadi dibuang dulu.
+	if err := dropWildcardTextIndex(ctx, achievements); err != nil {
+		log.Fatalf("❌ Failed to drop old MongoDB text index: %v", err)
+	}
+
+	// Full-text search: title, tags, description + isi details yang diratakan
+	// (detailsText), bobot title > tags > description > details.
+	// default_language "none" → tanpa stemming bahasa Inggris (data mayoritas bahasa Indonesia)
+	_, err := achievements.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "title", Value: "text"},
+				{Key: "tags", Value: "text"},
+				{Key: "description", Value: "text"},
+				{Key: "detailsText", Value: "text"},
+			},
+			Options: options.Index().
+				SetName("achievement_text_search").
+				SetDefaultLanguage("none").
+				SetWeights(bson.D{
+					{Key: "title", Value: 10},
+					{Key: "tags", Value: 5},
+					{Key: "description", Value: 3},
+					{Key: "detailsText", Value: 1},
+				}),
+		},
+		{
+			Keys:    bson.D{{Key: "studentId", Value: 1}, {Key: "status", Value: 1}},
+			Options: options.Index().SetName("student_status"),
+		},
+	})
+	if err != nil {
+		log.Fatalf("❌ Failed to create MongoDB indexes: %v", err)
+	}
+
+	log.Println("✅ MongoDB indexes ensured")
+}
+
+// dropWildcardTextIndex membuang achievement_text_search jika masih "$**"
+// (text index menyimpan daftar field-nya di weights)
+func dropWildcardTextIndex(ctx context.Context, coll *mongo.Collection) error {
+	cursor, err := coll.Indexes().List(ctx)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var idx struct {
+			Name    string `bson:"name"`
+			Weights bson.M `bson:"weights"`
+		}
+		if err := cursor.Decode(&idx); err != nil {
+			return err
+		}
+		if _, wildcard := idx.Weights["$**"]; idx.Name == "achievement_text_search" && wildcard {
+			_, err := coll.Indexes().DropOne(ctx, idx.Name)
+			return err
+		}
+	}
+	return cursor.Err()
+}
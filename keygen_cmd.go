@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"backenduas/app/jwtkeys"
+	"backenduas/config"
+)
+
+// runKeygen: backenduas keygen [--alg=EdDSA|RS256] [--kid=2026-10] [--dir=JWT_KEYS_DIR]
+// Menulis <kid>.pem (privat) dan <kid>.pub.pem (untuk instance yang hanya
+// memverifikasi). Kunci baru baru menandatangani setelah JWT_SIGNING_KID diganti.
+func runKeygen(env *config.Env, args []string) int {
+	fs := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	alg := fs.String("alg", jwtkeys.AlgEdDSA, "signing algorithm: EdDSA | RS256")
+	kid := fs.String("kid", time.Now().Format("2006-01-02"), "key id (file name)")
+	dir := fs.String("dir", env.JWTKeysDir, "output directory")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *dir == "" {
+		fmt.Fprintln(os.Stderr, "keygen: --dir or JWT_KEYS_DIR is required")
+		return 2
+	}
+
+	key, err := jwtkeys.Generate(*kid, *alg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "keygen failed:", err)
+		return 2
+	}
+	private, err := key.PrivatePEM()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "keygen failed:", err)
+		return 2
+	}
+	public, err := key.PublicPEM()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "keygen failed:", err)
+		return 2
+	}
+
+	if err := os.MkdirAll(*dir, 0o700); err != nil {
+		fmt.Fprintln(os.Stderr, "cannot create key directory:", err)
+		return 2
+	}
+	path := filepath.Join(*dir, *kid+".pem")
+	if _, err := os.Stat(path); err == nil {
+		fmt.Fprintln(os.Stderr, "keygen: key already exists:", path)
+		return 2
+	}
+	if err := os.WriteFile(path, private, 0o600); err != nil {
+		fmt.Fprintln(os.Stderr, "cannot write key:", err)
+		return 2
+	}
+	if err := os.WriteFile(filepath.Join(*dir, *kid+".pub.pem"), public, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "cannot write public key:", err)
+		return 2
+	}
+
+	fmt.Printf("%s key %q written to %s\n", *alg, *kid, path)
+	return 0
+}
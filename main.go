@@ -1,10 +1,15 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"time"
 
+	"backenduas/app/jwtkeys"
 	"backenduas/config"
 	"backenduas/database"
+	"backenduas/middleware"
 	"backenduas/routes"
 	"backenduas/app/repository"
 	"backenduas/app/service"
@@ -40,11 +45,16 @@ func main() {
 	// 1. Load ENV
 	config.LoadEnv()
 
+	// Subcommand: backenduas keygen [--alg=EdDSA|RS256] [--kid=...] [--dir=...] (tanpa database)
+	if len(os.Args) > 1 && os.Args[1] == "keygen" {
+		os.Exit(runKeygen(config.AppEnv, os.Args[2:]))
+	}
+
 	// 2. Connect PostgreSQL + MongoDB
 	database.ConnectDatabases()
 
 	// 3. Setup Fiber
-	app := fiber.New()
+	app := fiber.New(fiberConfig(config.AppEnv))
 	app.Use(cors.New())
 	app.Use(logger.New())
 
@@ -59,14 +69,93 @@ func main() {
 
 	pgAchRepo := repository.NewAchievementPGRepository()
 	mongoAchRepo := repository.NewAchievementMongoRepository()
+	historyRepo := repository.NewAchievementHistoryRepository()
+	outboxRepo := repository.NewSyncOutboxRepository()
+	achTypeRepo := repository.NewAchievementTypeRepository()
+	pointRuleRepo := repository.NewPointRuleRepository()
+	notificationRepo := repository.NewNotificationRepository()
+	notificationPrefRepo := repository.NewNotificationPreferenceRepository()
+	emailOutboxRepo := repository.NewEmailOutboxRepository()
+	jobRunRepo := repository.NewJobRunRepository()
+	slaRepo := repository.NewSLARepository()
+	transcriptRepo := repository.NewTranscriptRepository()
+	statsRepo := repository.NewStatsRepository()
+	rbacRepo := repository.NewRBACRepository()
+	denylistRepo := repository.NewTokenDenylistRepository()
+	loginAttemptRepo := repository.NewLoginAttemptRepository()
+
+	// detailsText untuk text index (dokumen sebelum index dipersempit)
+	if n, err := mongoAchRepo.BackfillDetailsText(context.Background()); err != nil {
+		log.Fatalf("❌ Failed to backfill achievement search text: %v", err)
+	} else if n > 0 {
+		log.Printf("🔎 detailsText diisi untuk %d prestasi", n)
+	}
+
+	// === Kunci JWT ===
+	jwtKeys := loadKeyRing(config.AppEnv)
 
 	// === Init services ===
-	authService := service.NewAuthService(authRepo)
-	userService := service.NewUserService(userRepo)
-	studentService := service.NewStudentService(studentRepo, pgAchRepo, mongoAchRepo)
+	achievementSyncer := service.NewAchievementSyncer(pgAchRepo, mongoAchRepo, outboxRepo)
+	pointsEngine := service.NewPointsEngine(pointRuleRepo, pgAchRepo, mongoAchRepo)
+	achievementSyncer.Points = pointsEngine
+	eventHub := service.NewMemoryHub()
+	var mailQueue *service.MailQueue
+	var emailNotifier *service.EmailNotifier
+	if mailer := newMailer(config.AppEnv); mailer != nil {
+		mailQueue = service.NewMailQueue(emailOutboxRepo, mailer)
+		emailNotifier = service.NewEmailNotifier(userRepo, notificationPrefRepo, mailQueue, config.AppEnv.AppBaseURL)
+	}
+	notifier := service.NewNotifier(notificationRepo, studentRepo, eventHub, emailNotifier)
+	statsMaterializer := service.NewStatsMaterializer(statsRepo, pgAchRepo, mongoAchRepo, config.AppEnv.ReportCacheTTL)
+	tokenDenylist := service.NewTokenDenylist(denylistRepo, authRepo, config.AppEnv.TokenDenylistRefresh)
+	loginGuard := service.NewLoginGuard(loginAttemptRepo, service.LoginPolicy{
+		MaxAttempts:   config.AppEnv.LoginMaxAttempts,
+		IPMaxAttempts: config.AppEnv.LoginIPMaxAttempts,
+		FreeAttempts:  config.AppEnv.LoginFreeAttempts,
+		DelayBase:     config.AppEnv.LoginDelayBase,
+		Lockout:       config.AppEnv.LoginLockout,
+		Window:        config.AppEnv.LoginAttemptWindow,
+	})
+	authService := service.NewAuthService(authRepo, tokenDenylist, jwtKeys, loginGuard)
+	userService := service.NewUserService(userRepo, tokenDenylist, loginGuard)
+	studentService := service.NewStudentService(studentRepo, pgAchRepo, mongoAchRepo, notifier, statsMaterializer)
 	lecturerService := service.NewLecturerService(lecturerRepo)
-	achievementService := service.NewAchievementService(pgAchRepo, mongoAchRepo, studentRepo)
-	reportService := service.NewReportService(pgAchRepo, mongoAchRepo, studentRepo)
+	achievementService := service.NewAchievementService(pgAchRepo, mongoAchRepo, studentRepo, historyRepo, achTypeRepo, pointsEngine, notifier, achievementSyncer, statsMaterializer)
+	reportService := service.NewReportService(pgAchRepo, mongoAchRepo, studentRepo, slaRepo, statsMaterializer)
+	reconcileService := service.NewReconcileService(pgAchRepo, mongoAchRepo, historyRepo, statsMaterializer)
+	achTypeService := service.NewAchievementTypeService(achTypeRepo, statsMaterializer)
+	pointRuleService := service.NewPointRuleService(pointRuleRepo, achTypeRepo, pointsEngine)
+	notificationService := service.NewNotificationService(notificationRepo, notificationPrefRepo, eventHub, tokenDenylist)
+	rbacService := service.NewRBACService(rbacRepo)
+	digestService := service.NewDigestService(lecturerRepo, studentRepo, pgAchRepo, mongoAchRepo, jobRunRepo,
+		newDigestDelivery(config.AppEnv, emailNotifier, userRepo, rbacService), config.AppEnv.EscalationDays)
+	slaService := service.NewSLAService(slaRepo, studentRepo, userRepo, rbacService, notifier)
+	transcriptKeys, err := service.ParseTranscriptKeys(config.AppEnv.TranscriptKeyID,
+		config.AppEnv.TranscriptSecret, config.AppEnv.TranscriptOldSecrets)
+	if err != nil {
+		log.Fatal("cannot load transcript keys: ", err)
+	}
+	transcriptService := service.NewTranscriptService(transcriptRepo, studentRepo, mongoAchRepo,
+		transcriptKeys, config.AppEnv.TranscriptVerifyURL)
+	transcriptService.Location = loadLocation(config.AppEnv.DigestTimezone)
+
+	// Subcommand: backenduas reconcile [--fix --source=pg|mongo]
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		os.Exit(runReconcile(reconcileService, os.Args[2:]))
+	}
+
+	// Subcommand: backenduas backfill-types [--dry-run]
+	if len(os.Args) > 1 && os.Args[1] == "backfill-types" {
+		os.Exit(runBackfillTypes(achTypeRepo, mongoAchRepo, statsMaterializer, os.Args[2:]))
+	}
+
+	// === Permission + denylist token untuk middleware ===
+	if err := rbacService.Seed(context.Background()); err != nil {
+		log.Printf("⚠️ gagal menambahkan permission bawaan: %v", err)
+	}
+	middleware.UsePermissionResolver(rbacService)
+	middleware.UseTokenRevocation(tokenDenylist)
+	middleware.UseKeyRing(jwtKeys)
 
 	// === Setup routes ===
 	routes.SetupRoutes(
@@ -77,10 +166,115 @@ func main() {
 		lecturerService,
 		achievementService,
 		reportService,
+		reconcileService,
+		achTypeService,
+		pointRuleService,
+		notificationService,
+		digestService,
+		slaService,
+		transcriptService,
+		rbacService,
 	)
 
-	// 5. Start server
+	// 4. Background worker sinkronisasi PostgreSQL ↔ MongoDB
+	go achievementSyncer.Run(context.Background(), config.AppEnv.SyncInterval, config.AppEnv.ReconcileInterval)
+
+	// 5. Worker pengiriman email (antrean + retry)
+	if mailQueue != nil {
+		go mailQueue.Run(context.Background(), config.AppEnv.MailInterval)
+	}
+
+	// 6. Digest harian dosen wali + eskalasi Admin
+	go digestService.RunScheduler(context.Background(), config.AppEnv.DigestTime, loadLocation(config.AppEnv.DigestTimezone))
+
+	// 7. Pengecekan SLA review (eskalasi / pengalihan ke dosen cadangan)
+	go slaService.Run(context.Background(), config.AppEnv.SLACheckInterval)
+
+	// 8. Bangun statistik materialized jika belum pernah (deploy pertama)
+	go statsMaterializer.EnsureBuilt(context.Background())
+
+	// 9. Purge audit login lama & penghitung login yang tidak aktif
+	go loginGuard.Run(context.Background(), config.AppEnv.LoginPurgeInterval, config.AppEnv.LoginAttemptRetention)
+
+	// 10. Start server
 	port := ":" + config.AppEnv.AppPort
 	log.Println("🚀 Server running on port", port)
 	app.Listen(port)
 }
+
+// newMailer memilih backend email dari MAIL_DRIVER; nil = email dimatikan
+func newMailer(env *config.Env) service.Mailer {
+	switch env.MailDriver {
+	case "smtp":
+		log.Println("📧 Email via SMTP", env.SMTPHost)
+		return service.NewSMTPMailer(env.SMTPHost, env.SMTPPort, env.SMTPUsername, env.SMTPPassword, env.MailFrom)
+	case "file":
+		log.Println("📧 Email ditulis ke", env.MailOutboxDir)
+		return service.NewFileMailer(env.MailOutboxDir, env.MailFrom)
+	case "":
+		return nil
+	default:
+		log.Printf("Warning: unknown MAIL_DRIVER=%q, email disabled", env.MailDriver)
+		return nil
+	}
+}
+
+// newDigestDelivery: email hanya jika MAIL_DRIVER aktif, selain itu file/log
+func newDigestDelivery(env *config.Env, mail *service.EmailNotifier, users *repository.UserRepository, perms middleware.PermissionResolver) service.DigestDelivery {
+	if env.DigestDelivery == "email" {
+		if mail != nil {
+			return service.NewEmailDigestDelivery(mail, users, perms)
+		}
+		log.Println("Warning: DIGEST_DELIVERY=email but MAIL_DRIVER is not set, using file sink")
+	}
+	return service.NewFileDigestSink(env.DigestDir)
+}
+
+// fiberConfig: IP klien (penghitung login per IP, audit, sesi) dibaca dari
+// PROXY_HEADER hanya untuk request yang datang dari TRUSTED_PROXIES; proxy
+// wajib menimpa header tersebut. Tanpa TRUSTED_PROXIES dipakai alamat koneksi
+// dan header dari klien diabaikan.
+func fiberConfig(env *config.Env) fiber.Config {
+	if len(env.TrustedProxies) == 0 {
+		return fiber.Config{}
+	}
+	return fiber.Config{
+		ProxyHeader:             env.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          env.TrustedProxies,
+		EnableIPValidation:      true,
+	}
+}
+
+// loadKeyRing membaca kunci JWT dari JWT_KEYS_DIR. Tanpa direktori, kunci
+// sementara hanya dipakai saat APP_ENV=development (token tidak berlaku lagi
+// setelah restart dan tiap instance punya kunci berbeda); selain itu fatal.
+func loadKeyRing(env *config.Env) *jwtkeys.KeyRing {
+	if env.JWTKeysDir == "" {
+		if !env.IsDevelopment() {
+			log.Fatal("JWT_KEYS_DIR is required when APP_ENV is not development")
+		}
+		log.Println("Warning: JWT_KEYS_DIR is not set, using an ephemeral signing key (development only)")
+		keys, err := jwtkeys.Ephemeral(env.JWTIssuer, env.JWTAudience)
+		if err != nil {
+			log.Fatal("cannot generate JWT key: ", err)
+		}
+		return keys
+	}
+
+	keys, err := jwtkeys.Load(env.JWTKeysDir, env.JWTIssuer, env.JWTAudience, env.JWTSigningKID)
+	if err != nil {
+		log.Fatal("cannot load JWT keys: ", err)
+	}
+	log.Println("🔑 JWT signing key:", keys.SigningKID())
+	return keys
+}
+
+func loadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("Warning: invalid timezone %q, using local time", name)
+		return time.Local
+	}
+	return loc
+}
@@ -1,12 +1,34 @@
 package middleware
 
 import (
-	"os"
+	"context"
+	"log"
 	"strings"
+
+	"backenduas/app/jwtkeys"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// TokenRevocation = denylist access token (logout, logout-all, user dinonaktifkan, ...)
+type TokenRevocation interface {
+	IsRevoked(ctx context.Context, claims jwt.MapClaims) (bool, error)
+}
+
+var tokenRevocation TokenRevocation
+
+func UseTokenRevocation(r TokenRevocation) {
+	tokenRevocation = r
+}
+
+// keyRing = kunci verifikasi access token (tanda tangan, alg, iss, aud)
+var keyRing *jwtkeys.KeyRing
+
+func UseKeyRing(k *jwtkeys.KeyRing) {
+	keyRing = k
+}
+
 func JWTProtected() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 
@@ -23,17 +45,45 @@ func JWTProtected() fiber.Handler {
 
 		tokenString := parts[1]
 
-		token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("API_KEY")), nil
-		})
+		if keyRing == nil {
+			log.Println("⚠️ JWT key ring belum dipasang (middleware.UseKeyRing)")
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa token"})
+		}
 
-		if err != nil || !token.Valid {
+		claims, err := keyRing.Verify(tokenString, keyRing.Audience)
+		if err != nil {
 			return c.Status(401).JSON(fiber.Map{"error": "Token invalid"})
 		}
 
-		claims := token.Claims.(jwt.MapClaims)
+		// refresh token hanya untuk /auth/refresh
+		if claims["typ"] == "refresh" {
+			return c.Status(401).JSON(fiber.Map{"error": "Token invalid"})
+		}
+
+		if tokenRevocation != nil {
+			revoked, err := tokenRevocation.IsRevoked(context.Background(), claims)
+			if err != nil {
+				log.Printf("⚠️ gagal memeriksa denylist token: %v", err)
+				return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa token"})
+			}
+			if revoked {
+				return c.Status(401).JSON(fiber.Map{"error": "Token sudah dicabut"})
+			}
+		}
 		c.Locals("user", claims)
 
 		return c.Next()
 	}
 }
+
+// JWTProtectedStream = JWTProtected, tetapi token juga diterima dari query
+// ?access_token= karena EventSource di browser tidak bisa mengirim header
+func JWTProtectedStream() fiber.Handler {
+	protected := JWTProtected()
+	return func(c *fiber.Ctx) error {
+		if c.Get("Authorization") == "" && c.Query("access_token") != "" {
+			c.Request().Header.Set("Authorization", "Bearer "+c.Query("access_token"))
+		}
+		return protected(c)
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// PermissionResolver memetakan role_id → daftar permission (role_permissions).
+// Jika terpasang, permission dibaca dari database (dengan cache) sehingga
+// perubahan Admin langsung berlaku tanpa login ulang.
+type PermissionResolver interface {
+	PermissionsForRole(ctx context.Context, roleID string) ([]string, error)
+}
+
+var permissionResolver PermissionResolver
+
+func UsePermissionResolver(r PermissionResolver) {
+	permissionResolver = r
+}
+
+// tokenPermissions: permission yang di-embed di JWT saat login
+func tokenPermissions(claims jwt.MapClaims) []string {
+	raw, _ := claims["permissions"].([]interface{})
+	perms := make([]string, 0, len(raw))
+	for _, p := range raw {
+		if s, ok := p.(string); ok {
+			perms = append(perms, s)
+		}
+	}
+	return perms
+}
+
+// Permissions: permission pemanggil hasil pemeriksaan RequirePermission,
+// atau yang di-embed di JWT jika handler tidak melewati middleware tersebut
+func Permissions(c *fiber.Ctx) []string {
+	if perms, ok := c.Locals("permissions").([]string); ok {
+		return perms
+	}
+	claims, _ := c.Locals("user").(jwt.MapClaims)
+	return tokenPermissions(claims)
+}
+
+func RequirePermission(permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(401).JSON(fiber.Map{"error": "Token tidak ditemukan"})
+		}
+
+		perms := tokenPermissions(claims)
+		if permissionResolver != nil {
+			roleID, _ := claims["role_id"].(string)
+			if roleID == "" {
+				return c.Status(403).JSON(fiber.Map{"error": "Role tidak ditemukan dalam token"})
+			}
+			resolved, err := permissionResolver.PermissionsForRole(context.Background(), roleID)
+			if err != nil {
+				log.Printf("⚠️ gagal membaca permission role %s: %v", roleID, err)
+				return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa hak akses"})
+			}
+			perms = resolved
+		}
+
+		for _, p := range perms {
+			if p == permission {
+				c.Locals("permissions", perms)
+				return c.Next()
+			}
+		}
+
+		return c.Status(403).JSON(fiber.Map{
+			"error":      "Forbidden: Anda tidak memiliki akses",
+			"permission": permission,
+		})
+	}
+}
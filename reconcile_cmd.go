@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"backenduas/app/service"
+)
+
+// runReconcile: backenduas reconcile [--fix --source=pg|mongo] [--out=report.json]
+// Exit code: 0 = konsisten / semua diperbaiki, 1 = ada selisih, 2 = error.
+func runReconcile(svc *service.ReconcileService, args []string) int {
+	fs := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	fix := fs.Bool("fix", false, "apply fixes using --source as source of truth")
+	source := fs.String("source", "", "source of truth when fixing: pg | mongo")
+	out := fs.String("out", "", "write JSON report to file instead of stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	report, err := svc.Reconcile(context.Background(), *fix, *source)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "reconcile failed:", err)
+		return 2
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "cannot write report:", err)
+			return 2
+		}
+		defer f.Close()
+		w = f
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		fmt.Fprintln(os.Stderr, "cannot encode report:", err)
+		return 2
+	}
+
+	if len(report.Items) > report.Summary.Fixed {
+		return 1
+	}
+	return 0
+}
@@ -11,32 +11,42 @@ func AchievementRoutes(api fiber.Router, ach *service.AchievementService) {
 	r := api.Group("/achievements", middleware.JWTProtected())
 
 	// LIST (filtered by role)
-	r.Get("/", middleware.AllowRoles("Mahasiswa", "Dosen Wali", "Admin"), ach.GetAll)
+	r.Get("/", middleware.RequirePermission("achievement:read"), ach.GetAll)
+
+	// FULL-TEXT SEARCH (filtered by role) — harus sebelum /:id
+	r.Get("/search", middleware.RequirePermission("achievement:read"), ach.Search)
 
 	// DETAIL
-	r.Get("/:id", middleware.AllowRoles("Mahasiswa", "Dosen Wali", "Admin"), ach.GetByID)
+	r.Get("/:id", middleware.RequirePermission("achievement:read"), ach.GetByID)
 
 	// CREATE (Mahasiswa)
-	r.Post("/", middleware.AllowRoles("Mahasiswa"), ach.Create)
+	r.Post("/", middleware.RequirePermission("achievement:create"), ach.Create)
 
 	// UPDATE (Mahasiswa)
-	r.Put("/:id", middleware.AllowRoles("Mahasiswa"), ach.Update)
+	r.Put("/:id", middleware.RequirePermission("achievement:update"), ach.Update)
 
 	// DELETE (Mahasiswa)
-	r.Delete("/:id", middleware.AllowRoles("Mahasiswa"), ach.Delete)
+	r.Delete("/:id", middleware.RequirePermission("achievement:delete"), ach.Delete)
 
 	// SUBMIT (Mahasiswa)
-	r.Post("/:id/submit", middleware.AllowRoles("Mahasiswa"), ach.Submit)
+	r.Post("/:id/submit", middleware.RequirePermission("achievement:submit"), ach.Submit)
+
+	// BULK VERIFY / REJECT (Dosen Wali) — harus sebelum /:id/...
+	r.Post("/bulk/verify", middleware.RequirePermission("achievement:verify"), ach.BulkVerify)
+	r.Post("/bulk/reject", middleware.RequirePermission("achievement:verify"), ach.BulkReject)
 
 	// VERIFY (Dosen Wali)
-	r.Post("/:id/verify", middleware.AllowRoles("Dosen Wali"), ach.Verify)
+	r.Post("/:id/verify", middleware.RequirePermission("achievement:verify"), ach.Verify)
 
 	// REJECT (Dosen Wali)
-	r.Post("/:id/reject", middleware.AllowRoles("Dosen Wali"), ach.Reject)
+	r.Post("/:id/reject", middleware.RequirePermission("achievement:verify"), ach.Reject)
+
+	// REVISE setelah ditolak (Mahasiswa)
+	r.Post("/:id/revise", middleware.RequirePermission("achievement:update"), ach.Revise)
 
 	// HISTORY (Semua role)
-	r.Get("/:id/history", middleware.AllowRoles("Mahasiswa", "Dosen Wali", "Admin"), ach.History)
+	r.Get("/:id/history", middleware.RequirePermission("achievement:read"), ach.History)
 
 	// UPLOAD ATTACHMENT (Mahasiswa)
-	r.Post("/:id/attachments", middleware.AllowRoles("Mahasiswa"), ach.UploadAttachment)
+	r.Post("/:id/attachments", middleware.RequirePermission("achievement:update"), ach.UploadAttachment)
 }
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"backenduas/app/service"
+	"backenduas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AchievementTypeRoutes(api fiber.Router, types *service.AchievementTypeService) {
+	r := api.Group("/achievement-types", middleware.JWTProtected())
+
+	// LIST & DETAIL (semua role — dipakai form input prestasi)
+	r.Get("/", middleware.RequirePermission("achievement_type:read"), types.GetAll)
+	r.Get("/:code", middleware.RequirePermission("achievement_type:read"), types.GetByCode)
+
+	// KELOLA REGISTRY (Admin)
+	r.Post("/", middleware.RequirePermission("achievement_type:manage"), types.Create)
+	r.Put("/:code", middleware.RequirePermission("achievement_type:manage"), types.Update)
+	r.Delete("/:code", middleware.RequirePermission("achievement_type:manage"), types.Delete)
+}
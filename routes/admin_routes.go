@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"backenduas/app/service"
+	"backenduas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AdminRoutes(api fiber.Router, reconcileService *service.ReconcileService, digestService *service.DigestService) {
+	admin := api.Group("/admin",
+		middleware.JWTProtected(),
+		middleware.RequirePermission("system:manage"),
+	)
+
+	// Konsistensi PostgreSQL ↔ MongoDB
+	admin.Get("/reconcile", reconcileService.Report)
+	admin.Post("/reconcile", reconcileService.Fix)
+
+	// Digest harian dosen wali & eskalasi prestasi yang lama menunggu
+	admin.Get("/digests", digestService.Preview)
+	admin.Post("/digests/run", digestService.Run)
+	admin.Get("/escalations", digestService.Escalations)
+}
@@ -23,8 +23,23 @@ func AuthRoutes(api fiber.Router, authService *service.AuthService) {
 		authService.Logout,
 	)
 
-	auth.Post("/refresh",
+	// Cabut semua sesi & access token user
+	auth.Post("/logout-all",
 		middleware.JWTProtected(),
-		authService.Refresh,
+		authService.LogoutAll,
+	)
+
+	// Refresh diautentikasi oleh refresh token itu sendiri (access token boleh sudah kedaluwarsa)
+	auth.Post("/refresh", authService.Refresh)
+
+	// Sesi login per perangkat
+	auth.Get("/sessions",
+		middleware.JWTProtected(),
+		authService.Sessions,
+	)
+
+	auth.Delete("/sessions/:id",
+		middleware.JWTProtected(),
+		authService.RevokeSession,
 	)
 }
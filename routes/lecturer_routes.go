@@ -10,8 +10,8 @@ func LecturerRoutes(api fiber.Router, lecturerService *service.LecturerService)
 	lect := api.Group("/lecturers", middleware.JWTProtected())
 
 	// Admin & dosen wali bisa lihat ini
-	lect.Get("/", middleware.AllowRoles("Admin", "Dosen Wali"), lecturerService.GetAll)
+	lect.Get("/", middleware.RequirePermission("lecturer:read"), lecturerService.GetAll)
 
 	// List mahasiswa bimbingan → hanya dosen wali yg bersangkutan + admin
-	lect.Get("/:id/advisees", middleware.AllowRoles("Admin", "Dosen Wali"), lecturerService.GetAdvisees)
+	lect.Get("/:id/advisees", middleware.RequirePermission("lecturer:read"), lecturerService.GetAdvisees)
 }
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"backenduas/app/service"
+	"backenduas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func NotificationRoutes(api fiber.Router, notif *service.NotificationService) {
+	// SSE didaftarkan sebelum group supaya middleware JWT group tidak menolak token dari query
+	api.Get("/notifications/stream",
+		middleware.JWTProtectedStream(),
+		middleware.RequirePermission("notification:read"),
+		notif.Stream,
+	)
+
+	r := api.Group("/notifications",
+		middleware.JWTProtected(),
+		middleware.RequirePermission("notification:read"),
+	)
+
+	r.Get("/", notif.GetAll)
+	r.Get("/preferences", notif.GetPreferences)
+	r.Put("/preferences", notif.UpdatePreferences)
+	r.Post("/read-all", notif.MarkAllRead)
+	r.Post("/:id/read", notif.MarkRead)
+}
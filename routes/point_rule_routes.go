@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"backenduas/app/service"
+	"backenduas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func PointRuleRoutes(api fiber.Router, rules *service.PointRuleService) {
+	r := api.Group("/point-rules",
+		middleware.JWTProtected(),
+		middleware.RequirePermission("point_rule:manage"),
+	)
+
+	r.Get("/", rules.GetAll)
+	r.Post("/", rules.Create)
+	r.Put("/:id", rules.Update)
+	r.Delete("/:id", rules.Delete)
+
+	// Hitung ulang poin seluruh prestasi verified setelah aturan berubah
+	r.Post("/recalculate", rules.Recalculate)
+}
@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"backenduas/app/service"
+	"backenduas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func RBACRoutes(api fiber.Router, svc *service.RBACService) {
+	roles := api.Group("/roles",
+		middleware.JWTProtected(),
+		middleware.RequirePermission("role:manage"),
+	)
+
+	roles.Get("/", svc.ListRoles)
+	roles.Post("/", svc.CreateRole)
+	roles.Get("/:id", svc.GetRole)
+	roles.Put("/:id", svc.UpdateRole)
+	roles.Delete("/:id", svc.DeleteRole)
+
+	// Ganti seluruh permission milik role
+	roles.Put("/:id/permissions", svc.SetRolePermissions)
+
+	perms := api.Group("/permissions",
+		middleware.JWTProtected(),
+		middleware.RequirePermission("role:manage"),
+	)
+
+	perms.Get("/", svc.ListPermissions)
+	perms.Post("/", svc.CreatePermission)
+	perms.Put("/:id", svc.UpdatePermission)
+	perms.Delete("/:id", svc.DeletePermission)
+}
@@ -10,13 +10,23 @@ import (
 func ReportRoutes(api fiber.Router, svc *service.ReportService) {
 	r := api.Group("/reports", middleware.JWTProtected())
 
-	// Global Statistics (Admin & Dosen Wali)
+	// Global Statistics (scope mengikuti role: semua / bimbingan / diri sendiri)
 	r.Get("/statistics", 
-		middleware.AllowRoles("Admin", "Dosen Wali", "Mahasiswa"), 
+		middleware.RequirePermission("report:read"), 
 		svc.GlobalStatistics)
 
+	// Hitung ulang statistik materialized + kosongkan cache
+	r.Post("/statistics/rebuild",
+		middleware.RequirePermission("report:manage"),
+		svc.RebuildStatistics)
+
 	// Per Student Statistics
 	r.Get("/student/:id",
-		middleware.AllowRoles("Admin", "Dosen Wali", "Mahasiswa"),
+		middleware.RequirePermission("report:read"),
 		svc.StudentStatistics)
+
+	// Waktu review per dosen (median, p90)
+	r.Get("/turnaround",
+		middleware.RequirePermission("report:review"),
+		svc.Turnaround)
 }
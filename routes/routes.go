@@ -15,9 +15,20 @@ func SetupRoutes(
     lecturerService *service.LecturerService,
     achievementService *service.AchievementService,
     reportService *service.ReportService, 
+    reconcileService *service.ReconcileService,
+    achTypeService *service.AchievementTypeService,
+    pointRuleService *service.PointRuleService,
+    notificationService *service.NotificationService,
+    digestService *service.DigestService,
+    slaService *service.SLAService,
+    transcriptService *service.TranscriptService,
+    rbacService *service.RBACService,
 ) {
     fmt.Println("🔥 REGISTERING ROUTES...")
 
+    // Kunci publik JWT (RFC 8414 / OIDC: di root, bukan di /api/v1)
+    app.Get("/.well-known/jwks.json", authService.JWKS)
+
     api := app.Group("/api/v1")
 
     // Auth Routes
@@ -35,9 +46,30 @@ func SetupRoutes(
     // Achievement Routes
     AchievementRoutes(api, achievementService)
 
+    // Achievement type registry
+    AchievementTypeRoutes(api, achTypeService)
+
+    // Aturan poin prestasi (Admin)
+    PointRuleRoutes(api, pointRuleService)
+
+    // Notifikasi in-app
+    NotificationRoutes(api, notificationService)
+
     // Reports Routes (NEW)
     ReportRoutes(api, reportService)
 
+    // Transkrip prestasi (SKPI) + verifikasi publik
+    TranscriptRoutes(api, transcriptService)
+
+    // SLA verifikasi prestasi
+    SLARoutes(api, slaService)
+
+    // Role & permission (Admin)
+    RBACRoutes(api, rbacService)
+
+    // Admin maintenance routes
+    AdminRoutes(api, reconcileService, digestService)
+
     fmt.Println("🔥 ROUTES REGISTERED")
 }
 
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"backenduas/app/service"
+	"backenduas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SLARoutes(api fiber.Router, svc *service.SLAService) {
+	sla := api.Group("/sla", middleware.JWTProtected())
+
+	// Pengaturan batas waktu review
+	sla.Get("/settings", middleware.RequirePermission("sla:read"), svc.GetSettings)
+	sla.Put("/settings", middleware.RequirePermission("sla:manage"), svc.UpdateSettings)
+
+	// Prestasi yang melewati batas, per dosen wali
+	sla.Get("/breaches", middleware.RequirePermission("sla:read"), svc.Breaches)
+
+	// Dosen cadangan & pengecekan manual
+	sla.Put("/backups/:lecturerId", middleware.RequirePermission("sla:manage"), svc.SetBackup)
+	sla.Post("/check", middleware.RequirePermission("sla:manage"), svc.Check)
+}
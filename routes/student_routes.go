@@ -12,15 +12,15 @@ func StudentRoutes(api fiber.Router, studentService *service.StudentService) {
 	students := api.Group("/students", middleware.JWTProtected())
 
 	// Admin only
-	students.Get("/", middleware.AllowRoles("Admin"), studentService.GetAll)
-	students.Post("/", middleware.AllowRoles("Admin"), studentService.Create)
-	students.Put("/:id/advisor", middleware.AllowRoles("Admin"), studentService.UpdateAdvisor)
+	students.Get("/", middleware.RequirePermission("student:manage"), studentService.GetAll)
+	students.Post("/", middleware.RequirePermission("student:manage"), studentService.Create)
+	students.Put("/:id/advisor", middleware.RequirePermission("student:manage"), studentService.UpdateAdvisor)
 
 	// Admin + Dosen Wali (lihat detail mahasiswa)
-	students.Get("/:id", middleware.AllowRoles("Admin", "Dosen Wali"), studentService.GetByID)
+	students.Get("/:id", middleware.RequirePermission("student:read"), studentService.GetByID)
 
 	students.Get("/:id/achievements",
-    middleware.AllowRoles("Mahasiswa", "Dosen Wali", "Admin"),
+    middleware.RequirePermission("achievement:read"),
     studentService.GetAchievements)
 
 }
\ No newline at end of file
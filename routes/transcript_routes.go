@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"backenduas/app/service"
+	"backenduas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TranscriptRoutes(api fiber.Router, svc *service.TranscriptService) {
+	// PDF transkrip prestasi terverifikasi
+	api.Get("/students/:id/transcript",
+		middleware.JWTProtected(),
+		middleware.RequirePermission("transcript:read"),
+		svc.Transcript)
+
+	// Publik (tanpa login): cek keaslian dokumen
+	api.Get("/verify/:hash", svc.Verify)
+}
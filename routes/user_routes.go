@@ -10,13 +10,16 @@ func UserRoutes(api fiber.Router, userService *service.UserService) {
 
 	u := api.Group("/users",
 		middleware.JWTProtected(),
-		middleware.AllowRoles("Admin"),
+		middleware.RequirePermission("user:manage"),
 	)
 
 	u.Get("/", userService.GetAll)
+	u.Get("/login-attempts", userService.LoginAttempts)
 	u.Get("/:id", userService.GetByID)
 	u.Post("/", userService.Create)
 	u.Put("/:id", userService.Update)
 	u.Delete("/:id", userService.Delete)
 	u.Put("/:id/role", userService.UpdateRole)
+	u.Put("/:id/status", userService.UpdateStatus)
+	u.Post("/:id/unlock", userService.Unlock)
 }